@@ -0,0 +1,427 @@
+package alert
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/report"
+)
+
+// Config 告警规则配置
+type Config struct {
+	SuccessRateThreshold float64       // 成功率低于该值（百分比）时告警
+	RepeatInterval       time.Duration // 持续触发的告警重复通知间隔
+	MissingClientTTL     time.Duration // 客户端消失超过该时间后不再告警
+}
+
+// Manager 定义告警管理接口
+type Manager interface {
+	// Start 启动告警评估goroutine，定期评估规则
+	Start(ctx context.Context, interval time.Duration) error
+
+	// Evaluate 立即评估一次所有规则并发送通知
+	Evaluate()
+
+	// GetAlerts 获取当前处于触发状态的告警
+	GetAlerts() []Alert
+
+	// AddSilence 添加静默规则
+	AddSilence(silence Silence) (*Silence, error)
+
+	// GetSilences 获取所有未过期的静默规则
+	GetSilences() []Silence
+
+	// DeleteSilence 删除静默规则
+	DeleteSilence(id string) error
+}
+
+// alertState 活跃告警及其通知状态
+type alertState struct {
+	alert        Alert
+	lastNotified time.Time
+}
+
+// nodeState 已知节点的最后在线信息
+type nodeState struct {
+	podName  string
+	lastSeen time.Time
+}
+
+// managerImpl 是Manager的实现
+type managerImpl struct {
+	config          Config
+	reportGenerator report.ReportGenerator
+	clientManager   client.ClientManager
+	notifiers       []Notifier
+
+	mu         sync.Mutex
+	active     map[string]*alertState
+	knownNodes map[string]*nodeState
+	silences   map[string]*Silence
+	running    bool
+	now        func() time.Time
+}
+
+// NewManager 创建一个新的告警管理器
+func NewManager(cfg Config, reportGenerator report.ReportGenerator, clientManager client.ClientManager, notifiers []Notifier) Manager {
+	if cfg.RepeatInterval <= 0 {
+		cfg.RepeatInterval = time.Hour
+	}
+	if cfg.MissingClientTTL <= 0 {
+		cfg.MissingClientTTL = time.Hour
+	}
+
+	return &managerImpl{
+		config:          cfg,
+		reportGenerator: reportGenerator,
+		clientManager:   clientManager,
+		notifiers:       notifiers,
+		active:          make(map[string]*alertState),
+		knownNodes:      make(map[string]*nodeState),
+		silences:        make(map[string]*Silence),
+		now:             time.Now,
+	}
+}
+
+// Start 启动告警评估goroutine
+func (m *managerImpl) Start(ctx context.Context, interval time.Duration) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return fmt.Errorf("告警管理器已经在运行")
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("告警管理器已启动，评估间隔: %v, 通知渠道数: %d", interval, len(m.notifiers))
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("告警管理器收到context取消信号，正在停止...")
+				m.mu.Lock()
+				m.running = false
+				m.mu.Unlock()
+				return
+			case <-ticker.C:
+				m.Evaluate()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Evaluate 评估所有规则，更新告警状态并发送通知
+func (m *managerImpl) Evaluate() {
+	var candidates []Alert
+	if m.reportGenerator != nil {
+		networkReport, err := m.reportGenerator.GenerateReport()
+		if err != nil {
+			log.Printf("告警评估生成报告失败: %v", err)
+		} else {
+			candidates = append(candidates, m.evaluateSuccessRate(networkReport)...)
+			candidates = append(candidates, m.evaluateLatency(networkReport)...)
+			candidates = append(candidates, m.evaluatePairs(networkReport)...)
+		}
+	}
+	candidates = append(candidates, m.evaluateClients()...)
+
+	m.mu.Lock()
+	now := m.now()
+
+	var toNotify []Alert
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		seen[candidate.Fingerprint] = true
+
+		state, exists := m.active[candidate.Fingerprint]
+		if !exists {
+			candidate.StartsAt = now
+			state = &alertState{alert: candidate}
+			m.active[candidate.Fingerprint] = state
+			log.Printf("告警触发: %s", candidate.Summary)
+		} else {
+			candidate.StartsAt = state.alert.StartsAt
+			state.alert = candidate
+		}
+
+		state.alert.Silenced = m.isSilencedLocked(&state.alert, now)
+		if state.alert.Silenced {
+			continue
+		}
+		if state.lastNotified.IsZero() || now.Sub(state.lastNotified) >= m.config.RepeatInterval {
+			state.lastNotified = now
+			toNotify = append(toNotify, state.alert)
+		}
+	}
+
+	// 未再出现的告警视为已恢复，仅对已通知过的告警发送恢复通知
+	for fp, state := range m.active {
+		if seen[fp] {
+			continue
+		}
+		delete(m.active, fp)
+
+		resolved := state.alert
+		resolved.Status = StatusResolved
+		endsAt := now
+		resolved.EndsAt = &endsAt
+		log.Printf("告警恢复: %s", resolved.Summary)
+
+		if !state.lastNotified.IsZero() && !m.isSilencedLocked(&resolved, now) {
+			toNotify = append(toNotify, resolved)
+		}
+	}
+	m.mu.Unlock()
+
+	m.notify(toNotify)
+}
+
+// evaluateSuccessRate 评估成功率规则
+func (m *managerImpl) evaluateSuccessRate(networkReport *models.NetworkReport) []Alert {
+	if m.config.SuccessRateThreshold <= 0 {
+		return nil
+	}
+
+	type summary struct {
+		testType    string
+		totalTests  int
+		successRate float64
+	}
+	summaries := []summary{
+		{models.TestTypeHost, networkReport.HostTestSummary.TotalTests, networkReport.HostTestSummary.SuccessRate},
+		{models.TestTypePod, networkReport.PodTestSummary.TotalTests, networkReport.PodTestSummary.SuccessRate},
+		{models.TestTypeService, networkReport.ServiceTestSummary.TotalTests, networkReport.ServiceTestSummary.SuccessRate},
+	}
+
+	var alerts []Alert
+	for _, s := range summaries {
+		if s.totalTests == 0 || s.successRate >= m.config.SuccessRateThreshold {
+			continue
+		}
+		alerts = append(alerts, newAlert(RuleLowSuccessRate, SeverityCritical,
+			map[string]string{"type": s.testType},
+			fmt.Sprintf("%s连通性成功率 %.2f%% 低于阈值 %.2f%%", s.testType, s.successRate, m.config.SuccessRateThreshold),
+			s.successRate))
+	}
+	return alerts
+}
+
+// evaluateLatency 评估延迟异常规则，异常由报告中的延迟基线检测结果提供
+func (m *managerImpl) evaluateLatency(networkReport *models.NetworkReport) []Alert {
+	var alerts []Alert
+	for _, anomaly := range networkReport.LatencyAnomalies {
+		alerts = append(alerts, newAlert(RuleLatencyAnomaly, SeverityWarning,
+			map[string]string{"type": anomaly.TestType, "source": anomaly.SourceIP, "target": anomaly.TargetIP},
+			fmt.Sprintf("%s探测 %s -> %s 延迟 %s 高于基线 %s（%.1f倍）",
+				anomaly.TestType, anomaly.SourceIP, anomaly.TargetIP, anomaly.Latency, anomaly.Baseline.EWMA, anomaly.Ratio),
+			float64(time.Duration(anomaly.Latency))/float64(time.Millisecond)))
+	}
+	return alerts
+}
+
+// evaluatePairs 评估探测对故障规则，探测对的有效状态为 down 或 flapping 时告警
+// 有效状态由结果管理器按 PAIR_FAILURE_THRESHOLD 等滞后阈值判断，没有状态记录的自定义服务探测不参与
+func (m *managerImpl) evaluatePairs(networkReport *models.NetworkReport) []Alert {
+	var alerts []Alert
+	for _, pair := range networkReport.Pairs {
+		var summary string
+		switch pair.State {
+		case models.PairStateDown:
+			summary = fmt.Sprintf("%s探测 %s -> %s 连续失败 %d 次", pair.TestType, pair.SourceIP, pair.TargetIP, pair.ConsecutiveFailures)
+		case models.PairStateFlapping:
+			summary = fmt.Sprintf("%s探测 %s -> %s 结果频繁切换 %d 次", pair.TestType, pair.SourceIP, pair.TargetIP, pair.FlapCount)
+		default:
+			continue
+		}
+		alerts = append(alerts, newAlert(RulePairFailing, SeverityWarning,
+			map[string]string{"type": pair.TestType, "source": pair.SourceIP, "target": pair.TargetIP},
+			summary, float64(pair.ConsecutiveFailures)))
+	}
+	return alerts
+}
+
+// evaluateClients 评估客户端缺失规则
+// 以节点IP为维度跟踪，DaemonSet 滚动更新时同一节点的新 Pod 上线即视为恢复
+func (m *managerImpl) evaluateClients() []Alert {
+	if m.clientManager == nil {
+		return nil
+	}
+
+	clients, err := m.clientManager.GetAllClients()
+	if err != nil {
+		log.Printf("告警评估获取客户端列表失败: %v", err)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	current := make(map[string]bool, len(clients))
+	for podName, record := range clients {
+		nodeIP := record.NodeInfo.NodeIP
+		current[nodeIP] = true
+		m.knownNodes[nodeIP] = &nodeState{podName: podName, lastSeen: now}
+	}
+
+	var alerts []Alert
+	for nodeIP, state := range m.knownNodes {
+		if current[nodeIP] {
+			continue
+		}
+		if now.Sub(state.lastSeen) > m.config.MissingClientTTL {
+			delete(m.knownNodes, nodeIP)
+			continue
+		}
+		alerts = append(alerts, newAlert(RuleClientMissing, SeverityWarning,
+			map[string]string{"node_ip": nodeIP},
+			fmt.Sprintf("节点 %s 上的客户端 %s 已失联，最后心跳于 %s",
+				nodeIP, state.podName, state.lastSeen.Format("2006-01-02 15:04:05")),
+			now.Sub(state.lastSeen).Seconds()))
+	}
+	return alerts
+}
+
+// notify 向所有通知渠道发送告警
+func (m *managerImpl) notify(alerts []Alert) {
+	if len(alerts) == 0 || len(m.notifiers) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, alerts); err != nil {
+			log.Printf("发送告警通知失败: notifier=%s, error=%v", notifier.Name(), err)
+			continue
+		}
+		log.Printf("告警通知发送成功: notifier=%s, count=%d", notifier.Name(), len(alerts))
+	}
+}
+
+// GetAlerts 获取当前处于触发状态的告警，按开始时间排序
+func (m *managerImpl) GetAlerts() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(m.active))
+	for _, state := range m.active {
+		alerts = append(alerts, state.alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].StartsAt.Equal(alerts[j].StartsAt) {
+			return alerts[i].Fingerprint < alerts[j].Fingerprint
+		}
+		return alerts[i].StartsAt.Before(alerts[j].StartsAt)
+	})
+	return alerts
+}
+
+// AddSilence 添加静默规则
+func (m *managerImpl) AddSilence(silence Silence) (*Silence, error) {
+	if len(silence.Matchers) == 0 {
+		return nil, fmt.Errorf("静默规则至少需要一个匹配项")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return nil, fmt.Errorf("静默结束时间必须晚于开始时间")
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("生成静默ID失败: %w", err)
+	}
+	silence.ID = id
+
+	m.silences[id] = &silence
+	log.Printf("添加静默规则: id=%s, matchers=%v, ends_at=%s", id, silence.Matchers, silence.EndsAt.Format(time.RFC3339))
+
+	result := silence
+	return &result, nil
+}
+
+// GetSilences 获取所有未过期的静默规则，并清理已过期的规则
+func (m *managerImpl) GetSilences() []Silence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	silences := make([]Silence, 0, len(m.silences))
+	for id, silence := range m.silences {
+		if !now.Before(silence.EndsAt) {
+			delete(m.silences, id)
+			continue
+		}
+		silences = append(silences, *silence)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].StartsAt.Before(silences[j].StartsAt)
+	})
+	return silences
+}
+
+// DeleteSilence 删除静默规则
+func (m *managerImpl) DeleteSilence(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.silences[id]; !ok {
+		return fmt.Errorf("静默规则不存在: %s", id)
+	}
+	delete(m.silences, id)
+	return nil
+}
+
+// isSilencedLocked 判断告警是否被静默（调用者需持有锁）
+func (m *managerImpl) isSilencedLocked(a *Alert, now time.Time) bool {
+	for _, silence := range m.silences {
+		if silence.Active(now) && silence.Matches(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAlert 创建处于触发状态的告警
+func newAlert(rule, severity string, labels map[string]string, summary string, value float64) Alert {
+	return Alert{
+		Fingerprint: fingerprint(rule, labels),
+		Rule:        rule,
+		Severity:    severity,
+		Status:      StatusFiring,
+		Labels:      labels,
+		Summary:     summary,
+		Value:       value,
+	}
+}
+
+// newID 生成随机ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
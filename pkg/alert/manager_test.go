@@ -0,0 +1,290 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/latency"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/report"
+	"github.com/yezihack/k8snet-checker/pkg/result"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver 本地告警接收方，记录收到的通用 webhook 请求
+type webhookReceiver struct {
+	mu       sync.Mutex
+	payloads []struct {
+		Status string  `json:"status"`
+		Alerts []Alert `json:"alerts"`
+	}
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var payload struct {
+		Status string  `json:"status"`
+		Alerts []Alert `json:"alerts"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.payloads = append(r.payloads, payload)
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.payloads)
+}
+
+// setupTestManager 创建使用真实组件和本地接收方的告警管理器
+func setupTestManager(t *testing.T, cfg Config, opts ...result.Option) (*managerImpl, client.ClientManager, result.TestResultManager, *webhookReceiver) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager, opts...)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager)
+
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+
+	notifier, err := NewNotifier(NotifierWebhook, srv.URL)
+	require.NoError(t, err)
+
+	manager := NewManager(cfg, reportGenerator, clientManager, []Notifier{notifier}).(*managerImpl)
+	return manager, clientManager, resultManager, receiver
+}
+
+func failingResult(target string) models.ConnectivityResult {
+	return models.ConnectivityResult{
+		TargetIP:   target,
+		PingStatus: "unreachable",
+		PortStatus: map[int]string{22: "closed"},
+	}
+}
+
+func okResult(target string) models.ConnectivityResult {
+	return models.ConnectivityResult{
+		TargetIP:   target,
+		PingStatus: "reachable",
+		PortStatus: map[int]string{22: "open"},
+	}
+}
+
+// TestManager_PairFailingAndResolve 测试探测对进入 down 时告警、去重与恢复通知
+func TestManager_PairFailingAndResolve(t *testing.T) {
+	manager, _, resultManager, receiver := setupTestManager(t, Config{},
+		result.WithHealthConfig(result.HealthConfig{FailureThreshold: 2, RecoveryThreshold: 1}))
+
+	// 第一次失败未达到阈值
+	require.NoError(t, resultManager.SaveHostTestResults("192.168.1.1", []models.ConnectivityResult{failingResult("192.168.1.2")}))
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+	assert.Equal(t, 0, receiver.count())
+
+	// 第二次失败进入 down，触发告警
+	require.NoError(t, resultManager.SaveHostTestResults("192.168.1.1", []models.ConnectivityResult{failingResult("192.168.1.2")}))
+	manager.Evaluate()
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, RulePairFailing, alerts[0].Rule)
+	assert.Equal(t, "192.168.1.2", alerts[0].Labels["target"])
+	assert.Equal(t, 1, receiver.count())
+
+	// 重复评估不应重复通知
+	manager.Evaluate()
+	assert.Equal(t, 1, receiver.count())
+
+	// 恢复后发送恢复通知
+	require.NoError(t, resultManager.SaveHostTestResults("192.168.1.1", []models.ConnectivityResult{okResult("192.168.1.2")}))
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+	require.Equal(t, 2, receiver.count())
+	assert.Equal(t, StatusResolved, receiver.payloads[1].Status)
+	assert.NotNil(t, receiver.payloads[1].Alerts[0].EndsAt)
+}
+
+// TestManager_PairFlapping 测试探测对频繁切换（flapping）时告警
+func TestManager_PairFlapping(t *testing.T) {
+	manager, _, resultManager, _ := setupTestManager(t, Config{},
+		result.WithHealthConfig(result.HealthConfig{FailureThreshold: 10, FlapThreshold: 3}))
+
+	for _, r := range []models.ConnectivityResult{okResult("10.0.0.2"), failingResult("10.0.0.2"), okResult("10.0.0.2")} {
+		require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{r}))
+		manager.Evaluate()
+		assert.Empty(t, manager.GetAlerts())
+	}
+
+	require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{failingResult("10.0.0.2")}))
+	manager.Evaluate()
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, RulePairFailing, alerts[0].Rule)
+	assert.Contains(t, alerts[0].Summary, "频繁切换")
+}
+
+// TestManager_RepeatInterval 测试重复通知间隔
+func TestManager_RepeatInterval(t *testing.T) {
+	manager, _, resultManager, receiver := setupTestManager(t, Config{RepeatInterval: time.Minute},
+		result.WithHealthConfig(result.HealthConfig{FailureThreshold: 1}))
+
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{failingResult("10.0.0.2")}))
+	manager.Evaluate()
+	assert.Equal(t, 1, receiver.count())
+
+	now = now.Add(30 * time.Second)
+	manager.Evaluate()
+	assert.Equal(t, 1, receiver.count())
+
+	now = now.Add(31 * time.Second)
+	manager.Evaluate()
+	assert.Equal(t, 2, receiver.count())
+}
+
+// TestManager_LowSuccessRate 测试成功率告警
+func TestManager_LowSuccessRate(t *testing.T) {
+	manager, _, resultManager, receiver := setupTestManager(t, Config{SuccessRateThreshold: 90})
+
+	// 连续失败达到阈值后探测对进入 down，才计入失败
+	for i := 0; i < result.DefaultHealthConfig().FailureThreshold; i++ {
+		require.NoError(t, resultManager.SaveHostTestResults("192.168.1.1", []models.ConnectivityResult{
+			okResult("192.168.1.2"),
+			failingResult("192.168.1.3"),
+		}))
+	}
+	manager.Evaluate()
+
+	// 进入 down 的探测对同时触发探测对故障告警
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 2)
+	rules := map[string]Alert{}
+	for _, a := range alerts {
+		rules[a.Rule] = a
+	}
+	require.Contains(t, rules, RuleLowSuccessRate)
+	assert.Equal(t, models.TestTypeHost, rules[RuleLowSuccessRate].Labels["type"])
+	assert.Equal(t, float64(50), rules[RuleLowSuccessRate].Value)
+	assert.Equal(t, "192.168.1.3", rules[RulePairFailing].Labels["target"])
+	assert.Equal(t, 1, receiver.count())
+}
+
+// TestManager_LatencyAnomaly 测试延迟异常告警与恢复
+func TestManager_LatencyAnomaly(t *testing.T) {
+	manager, _, resultManager, receiver := setupTestManager(t, Config{})
+	detector := latency.NewDetector(latency.Config{MinSamples: 5, Consecutive: 2})
+	resultManager.AddObserver(detector)
+	manager.reportGenerator.AddEnricher(detector)
+
+	save := func(d time.Duration) {
+		r := okResult("10.0.0.2")
+		r.Latency = models.Duration(d)
+		require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{r}))
+	}
+	for i := 0; i < 5; i++ {
+		save(500 * time.Microsecond)
+	}
+	save(10 * time.Millisecond)
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+
+	save(10 * time.Millisecond)
+	manager.Evaluate()
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, RuleLatencyAnomaly, alerts[0].Rule)
+	assert.Equal(t, "10.0.0.2", alerts[0].Labels["target"])
+	assert.Equal(t, float64(10), alerts[0].Value)
+	assert.Equal(t, 1, receiver.count())
+
+	save(500 * time.Microsecond)
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+	assert.Equal(t, 2, receiver.count())
+}
+
+// TestManager_ClientMissing 测试客户端缺失告警
+func TestManager_ClientMissing(t *testing.T) {
+	manager, clientManager, _, _ := setupTestManager(t, Config{})
+
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{
+		PodName: "checker-a", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", Namespace: "kube-system",
+	}))
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+
+	// 模拟客户端记录过期
+	manager.clientManager = client.NewClientManager(cache.NewCacheManager())
+	manager.Evaluate()
+
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, RuleClientMissing, alerts[0].Rule)
+	assert.Equal(t, "192.168.1.1", alerts[0].Labels["node_ip"])
+
+	// 超过TTL后不再告警
+	manager.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	manager.Evaluate()
+	assert.Empty(t, manager.GetAlerts())
+}
+
+// TestManager_Silence 测试静默规则
+func TestManager_Silence(t *testing.T) {
+	manager, _, resultManager, receiver := setupTestManager(t, Config{},
+		result.WithHealthConfig(result.HealthConfig{FailureThreshold: 1}))
+
+	silence, err := manager.AddSilence(Silence{
+		Matchers: map[string]string{"rule": RulePairFailing, "target": "10.0.0.2"},
+		EndsAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, silence.ID)
+	assert.Len(t, manager.GetSilences(), 1)
+
+	require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		failingResult("10.0.0.2"),
+		failingResult("10.0.0.3"),
+	}))
+	manager.Evaluate()
+
+	alerts := manager.GetAlerts()
+	require.Len(t, alerts, 2)
+	require.Equal(t, 1, receiver.count())
+	require.Len(t, receiver.payloads[0].Alerts, 1)
+	assert.Equal(t, "10.0.0.3", receiver.payloads[0].Alerts[0].Labels["target"])
+
+	// 删除静默后发送通知
+	require.NoError(t, manager.DeleteSilence(silence.ID))
+	manager.Evaluate()
+	assert.Equal(t, 2, receiver.count())
+	assert.Error(t, manager.DeleteSilence(silence.ID))
+}
+
+// TestManager_AddSilenceValidation 测试静默规则校验
+func TestManager_AddSilenceValidation(t *testing.T) {
+	manager, _, _, _ := setupTestManager(t, Config{})
+
+	_, err := manager.AddSilence(Silence{EndsAt: time.Now().Add(time.Hour)})
+	assert.Error(t, err, "缺少匹配项应该返回错误")
+
+	_, err = manager.AddSilence(Silence{
+		Matchers: map[string]string{"rule": RuleClientMissing},
+		EndsAt:   time.Now().Add(-time.Hour),
+	})
+	assert.Error(t, err, "结束时间早于开始时间应该返回错误")
+}
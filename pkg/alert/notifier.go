@@ -0,0 +1,197 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 通知渠道类型
+const (
+	NotifierWebhook  = "webhook"
+	NotifierSlack    = "slack"
+	NotifierDingTalk = "dingtalk"
+	NotifierWeCom    = "wecom"
+)
+
+// Notifier 定义告警通知接口
+type Notifier interface {
+	// Name 返回通知渠道名称
+	Name() string
+
+	// Notify 发送一批告警（包含触发和恢复）
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// webhookNotifier 通过 HTTP POST 发送告警，payload 由 buildPayload 决定
+type webhookNotifier struct {
+	kind         string
+	url          string
+	httpClient   *http.Client
+	buildPayload func(alerts []Alert) interface{}
+}
+
+// NewNotifier 根据类型创建通知器
+// kind 取值: webhook、slack、dingtalk、wecom
+func NewNotifier(kind, url string) (Notifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("通知地址不能为空")
+	}
+
+	n := &webhookNotifier{
+		kind: kind,
+		url:  url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+
+	switch kind {
+	case NotifierWebhook:
+		n.buildPayload = buildWebhookPayload
+	case NotifierSlack:
+		n.buildPayload = buildSlackPayload
+	case NotifierDingTalk:
+		n.buildPayload = buildDingTalkPayload
+	case NotifierWeCom:
+		n.buildPayload = buildWeComPayload
+	default:
+		return nil, fmt.Errorf("不支持的通知类型: %s", kind)
+	}
+
+	return n, nil
+}
+
+// ParseNotifiers 解析通知配置
+// 格式: "类型=URL,类型=URL"，例如 "slack=https://hooks.slack.com/xxx,webhook=http://receiver/alerts"
+func ParseNotifiers(spec string) ([]Notifier, error) {
+	var notifiers []Notifier
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的通知配置: %s", item)
+		}
+
+		notifier, err := NewNotifier(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
+
+// Name 返回通知渠道名称
+func (n *webhookNotifier) Name() string {
+	return n.kind
+}
+
+// Notify 发送告警
+func (n *webhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(n.buildPayload(alerts))
+	if err != nil {
+		return fmt.Errorf("序列化告警失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送告警失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("通知接收方返回错误 (状态码=%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// buildWebhookPayload 通用 webhook 格式
+func buildWebhookPayload(alerts []Alert) interface{} {
+	status := StatusResolved
+	for _, a := range alerts {
+		if a.Status == StatusFiring {
+			status = StatusFiring
+			break
+		}
+	}
+
+	return map[string]interface{}{
+		"version": "1",
+		"source":  "k8snet-checker",
+		"status":  status,
+		"alerts":  alerts,
+	}
+}
+
+// buildSlackPayload Slack 兼容格式
+func buildSlackPayload(alerts []Alert) interface{} {
+	return map[string]interface{}{
+		"text": formatText(alerts, false),
+	}
+}
+
+// buildDingTalkPayload 钉钉机器人 markdown 格式
+func buildDingTalkPayload(alerts []Alert) interface{} {
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": "K8s网络连通性告警",
+			"text":  formatText(alerts, true),
+		},
+	}
+}
+
+// buildWeComPayload 企业微信机器人 markdown 格式
+func buildWeComPayload(alerts []Alert) interface{} {
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": formatText(alerts, true),
+		},
+	}
+}
+
+// formatText 将告警格式化为文本
+func formatText(alerts []Alert, markdown bool) string {
+	var b strings.Builder
+	if markdown {
+		b.WriteString("### K8s网络连通性告警\n\n")
+	} else {
+		b.WriteString("*K8s网络连通性告警*\n")
+	}
+
+	for _, a := range alerts {
+		tag := "[触发]"
+		if a.Status == StatusResolved {
+			tag = "[恢复]"
+		}
+		if markdown {
+			b.WriteString("- ")
+		}
+		fmt.Fprintf(&b, "%s [%s] %s (开始于 %s)\n",
+			tag, a.Severity, a.Summary, a.StartsAt.Format("2006-01-02 15:04:05"))
+	}
+	return b.String()
+}
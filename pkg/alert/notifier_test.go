@@ -0,0 +1,100 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseNotifiers 测试通知配置解析
+func TestParseNotifiers(t *testing.T) {
+	notifiers, err := ParseNotifiers("slack=http://a, dingtalk=http://b,wecom=http://c,webhook=http://d")
+	require.NoError(t, err)
+	require.Len(t, notifiers, 4)
+	assert.Equal(t, NotifierSlack, notifiers[0].Name())
+	assert.Equal(t, NotifierWebhook, notifiers[3].Name())
+
+	notifiers, err = ParseNotifiers("")
+	assert.NoError(t, err)
+	assert.Empty(t, notifiers)
+
+	_, err = ParseNotifiers("email=http://a")
+	assert.Error(t, err)
+
+	_, err = ParseNotifiers("http://a")
+	assert.Error(t, err)
+}
+
+// TestNotifierPayloads 测试各通知渠道的请求格式
+func TestNotifierPayloads(t *testing.T) {
+	alerts := []Alert{
+		newAlert(RulePairFailing, SeverityWarning, map[string]string{"target": "10.0.0.2"}, "pod探测 10.0.0.1 -> 10.0.0.2 连续失败 3 次", 3),
+	}
+	alerts[0].StartsAt = time.Now()
+
+	tests := []struct {
+		kind  string
+		check func(t *testing.T, payload map[string]interface{})
+	}{
+		{NotifierWebhook, func(t *testing.T, payload map[string]interface{}) {
+			assert.Equal(t, StatusFiring, payload["status"])
+			assert.Len(t, payload["alerts"], 1)
+		}},
+		{NotifierSlack, func(t *testing.T, payload map[string]interface{}) {
+			assert.Contains(t, payload["text"], "10.0.0.2")
+		}},
+		{NotifierDingTalk, func(t *testing.T, payload map[string]interface{}) {
+			assert.Equal(t, "markdown", payload["msgtype"])
+			markdown := payload["markdown"].(map[string]interface{})
+			assert.Contains(t, markdown["text"], "[触发]")
+		}},
+		{NotifierWeCom, func(t *testing.T, payload map[string]interface{}) {
+			assert.Equal(t, "markdown", payload["msgtype"])
+			markdown := payload["markdown"].(map[string]interface{})
+			assert.Contains(t, markdown["content"], "10.0.0.2")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			var body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			notifier, err := NewNotifier(tt.kind, srv.URL)
+			require.NoError(t, err)
+			require.NoError(t, notifier.Notify(context.Background(), alerts))
+
+			var payload map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &payload))
+			tt.check(t, payload)
+		})
+	}
+}
+
+// TestNotifierErrorStatus 测试接收方返回错误状态码
+func TestNotifierErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+
+	notifier, err := NewNotifier(NotifierWebhook, srv.URL)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []Alert{{Status: StatusFiring}})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "500"))
+}
@@ -0,0 +1,100 @@
+package alert
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 告警状态
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// 告警规则名称
+const (
+	RuleLowSuccessRate = "low_success_rate"
+	RulePairFailing    = "pair_failing"
+	RuleClientMissing  = "client_missing"
+	RuleLatencyAnomaly = "latency_anomaly"
+)
+
+// 告警级别
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert 表示一条告警
+type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
+	Rule        string            `json:"rule"`
+	Severity    string            `json:"severity"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Summary     string            `json:"summary"`
+	Value       float64           `json:"value"`
+	StartsAt    time.Time         `json:"starts_at"`
+	EndsAt      *time.Time        `json:"ends_at,omitempty"`
+	Silenced    bool              `json:"silenced"`
+}
+
+// Silence 表示一条静默规则，匹配的告警不会发送通知
+type Silence struct {
+	ID        string            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+}
+
+// Active 判断静默规则在指定时间是否生效
+func (s *Silence) Active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Matches 判断静默规则是否匹配告警
+// 所有匹配项都与告警标签（包含 rule）相等时视为匹配
+func (s *Silence) Matches(a *Alert) bool {
+	if len(s.Matchers) == 0 {
+		return false
+	}
+	for key, value := range s.Matchers {
+		if key == "rule" {
+			if a.Rule != value {
+				return false
+			}
+			continue
+		}
+		if a.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprint 根据规则名称和标签计算告警指纹，用于去重
+func fingerprint(rule string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(rule)
+	for _, key := range keys {
+		b.WriteString("|")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(labels[key])
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(b.String()))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
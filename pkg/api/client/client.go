@@ -2,14 +2,27 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+
+	"google.golang.org/grpc"
 )
 
 const (
@@ -19,18 +32,27 @@ const (
 	GET_HOST_IPS_URI = "/api/v1/hosts"
 	// 获取所有pod IP列表
 	GET_POD_IPS_URI = "/api/v1/pods"
+	// 获取探测目标对象列表
+	GET_TARGETS_URI = "/api/v1/targets/%s"
 	// 上报测试结果
 	REPORT_TEST_RESULT_URI = "/api/v1/test-results/hosts"
 	// 上报自定义服务测试结果
 	REPORT_POD_TEST_RESULTS_URI = "/api/v1/test-results/pods"
 	// 上报自定义服务测试结果
 	REPORT_SERVICE_TEST_RESULTS_URI = "/api/v1/test-results/service"
+	// 长轮询获取按需测试任务
+	POLL_RUNS_URI = "/api/v1/runs/pending"
+	// 报告按需测试任务已完成
+	COMPLETE_RUN_URI = "/api/v1/runs/%s/complete"
+	// 同步心跳、测试结果、目标列表与按需测试任务
+	SYNC_URI = "/api/v1/sync"
 )
 
 // APIClient defines the interface for client-side API interactions with the server
 type APIClient interface {
 	// SendHeartbeat sends node information to the server as a heartbeat
-	SendHeartbeat(info *models.NodeInfo) error
+	// The response carries the latest probe configuration when the client's version is outdated
+	SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error)
 
 	// GetHostIPs retrieves the list of all host IPs from the server
 	GetHostIPs() ([]string, error)
@@ -38,6 +60,14 @@ type APIClient interface {
 	// GetPodIPs retrieves the list of all pod IPs from the server
 	GetPodIPs() ([]string, error)
 
+	// GetHostTargets retrieves the host targets with their node identity
+	// Targets carry only the IP when the server does not provide target objects
+	GetHostTargets() ([]models.Target, error)
+
+	// GetPodTargets retrieves the pod targets with their pod, node and label identity
+	// Targets carry only the IP when the server does not provide target objects
+	GetPodTargets() ([]models.Target, error)
+
 	// ReportHostTestResults sends host connectivity test results to the server
 	ReportHostTestResults(results []models.ConnectivityResult) error
 
@@ -46,83 +76,417 @@ type APIClient interface {
 
 	// ReportServiceTestResults sends custom service test results to the server
 	ReportServiceTestResults(result *models.ConnectivityResult) error
+
+	// PollRuns long-polls the server for on-demand test runs assigned to this client
+	PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error)
+
+	// CompleteRun tells the server this client has finished an on-demand test run
+	CompleteRun(runID string, podName string) error
 }
 
 // apiClientImpl 是APIClient的实现
 type apiClientImpl struct {
 	serverURL  string
 	httpClient *http.Client
-	sourceIP   string // 用于上报测试结果时标识源IP
+	sourceIP   string      // 用于上报测试结果时标识源IP
+	signer     auth.Signer // 可选，为nil时不添加认证信息
+	podName    string      // 可选，获取目标列表时携带，服务器据此分配分片
+	syncMode   bool        // 是否通过同步接口合并心跳与测试结果上报
+
+	tlsConfig   func() *tls.Config // 可选，gRPC 连接使用的TLS配置
+	probeStore  probeconfig.Store  // 可选，gRPC 连接应用服务器推送的探测配置
+	dialOptions []grpc.DialOption  // gRPC 连接的附加选项
+
+	targetsMu   sync.Mutex
+	targetLists map[string]*targetList // 按接口地址缓存的带版本标识的目标列表
+
+	// 重试策略：第 n 次重试前等待 min(maxDelay, baseDelay*2^(n-1)) 的一半到全部之间的随机时长
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// 默认重试策略
+const (
+	defaultMaxRetries = 5
+	defaultBaseDelay  = 1 * time.Second
+	defaultMaxDelay   = 30 * time.Second
+)
+
+// StatusError 服务器返回的非2xx响应
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error 实现 error 接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("服务器返回错误 (状态码=%d): %s", e.StatusCode, e.Message)
+}
+
+// IsPermanent 判断错误是否重试也无法成功，例如请求无效或认证失败
+// 408 与 429 视为临时错误
+func IsPermanent(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	code := statusErr.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+var (
+	// errNotModified 服务器返回 304，请求的资源未变化
+	errNotModified = errors.New("资源未变化")
+	// errTargetDeltaMismatch 应用增量变更后的目标数量与服务器不一致
+	errTargetDeltaMismatch = errors.New("增量目标列表与服务器不一致")
+)
+
+// Option APIClient的可选配置项
+type Option func(c *apiClientImpl)
+
+// WithSigner 为所有请求添加认证信息
+func WithSigner(signer auth.Signer) Option {
+	return func(c *apiClientImpl) {
+		c.signer = signer
+	}
+}
+
+// WithRetryPolicy 设置请求失败时的重试次数与退避时长
+// maxRetries 为总尝试次数，每次重试前的等待时间按指数增长并加入随机抖动，不超过 maxDelay
+func WithRetryPolicy(maxRetries int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *apiClientImpl) {
+		if maxRetries > 0 {
+			c.maxRetries = maxRetries
+		}
+		if baseDelay > 0 {
+			c.baseDelay = baseDelay
+		}
+		if maxDelay > 0 {
+			c.maxDelay = maxDelay
+		}
+	}
+}
+
+// WithPodName 获取宿主机与Pod目标列表时携带Pod名称
+// 服务器启用目标分片时只返回本轮应测试的目标
+func WithPodName(podName string) Option {
+	return func(c *apiClientImpl) {
+		c.podName = podName
+	}
+}
+
+// WithTLSConfig 使用TLS连接服务器，serverURL 需为 https 地址
+// 每次建立连接时调用 getConfig 获取最新配置，证书更新后新连接自动使用新证书；
+// 配置未指定 ServerName 时使用连接地址的主机名
+func WithTLSConfig(getConfig func() *tls.Config) Option {
+	return func(c *apiClientImpl) {
+		c.tlsConfig = getConfig
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			config := getConfig().Clone()
+			if config.ServerName == "" {
+				host, _, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				config.ServerName = host
+			}
+			dialer := &tls.Dialer{Config: config}
+			return dialer.DialContext(ctx, network, addr)
+		}
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithSync 通过同步接口与服务器交互
+// 心跳时一并上报期间产生的测试结果并获取目标列表与按需测试任务，减少请求数量；
+// 测试结果在下一次心跳时才上报，按需测试任务的下发延迟也取决于心跳间隔
+func WithSync() Option {
+	return func(c *apiClientImpl) {
+		c.syncMode = true
+	}
+}
+
+// WithProbeConfig 应用服务器通过 gRPC 推送的探测配置，并在订阅推送时携带当前配置版本
+// 只用于 NewGRPCClient，HTTP 方式通过心跳响应获取配置
+func WithProbeConfig(store probeconfig.Store) Option {
+	return func(c *apiClientImpl) {
+		c.probeStore = store
+	}
+}
+
+// WithDialOptions 为 gRPC 连接添加选项，只用于 NewGRPCClient
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *apiClientImpl) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
 }
 
 // NewAPIClient 创建一个新的APIClient实例
-func NewAPIClient(serverURL string, sourceIP string) APIClient {
-	return &apiClientImpl{
+func NewAPIClient(serverURL string, sourceIP string, opts ...Option) APIClient {
+	c := &apiClientImpl{
 		serverURL: serverURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		sourceIP: sourceIP,
+		sourceIP:   sourceIP,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.syncMode {
+		return newSyncClient(c)
+	}
+	return c
 }
 
 // SendHeartbeat 发送心跳到服务器
-func (c *apiClientImpl) SendHeartbeat(info *models.NodeInfo) error {
+func (c *apiClientImpl) SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error) {
 	url := fmt.Sprintf("%s"+SEND_HEART_BEAT_URI, c.serverURL)
 
 	// 序列化请求体
 	body, err := json.Marshal(info)
 	if err != nil {
-		return fmt.Errorf("序列化心跳数据失败: %w", err)
+		return nil, fmt.Errorf("序列化心跳数据失败: %w", err)
 	}
 
-	// 发送POST请求，带重试逻辑
-	err = c.doRequestWithRetry("POST", url, body, nil)
+	// 心跳只发送一次，失败后由心跳上报器按服务器状态退避，避免重试拖延服务器恢复的发现
+	var response models.HeartbeatResponse
+	err = c.doRequest("POST", url, body, &response)
 	if err != nil {
-		return fmt.Errorf("发送心跳失败: %w", err)
+		return nil, fmt.Errorf("发送心跳失败: %w", err)
 	}
 
 	log.Printf("心跳发送成功: pod=%s, node_ip=%s, pod_ip=%s",
 		info.PodName, info.NodeIP, info.PodIP)
-	return nil
+	return &response, nil
 }
 
 // GetHostIPs 从服务器获取所有宿主机IP列表
 func (c *apiClientImpl) GetHostIPs() ([]string, error) {
-	url := fmt.Sprintf("%s"+GET_HOST_IPS_URI, c.serverURL)
-
-	var response struct {
-		HostIPs []string `json:"host_ips"`
-		Count   int      `json:"count"`
-	}
-
-	// 发送GET请求，带重试逻辑
-	err := c.doRequestWithRetry("GET", url, nil, &response)
+	hosts, err := c.getTargetList(GET_HOST_IPS_URI, false)
 	if err != nil {
 		return nil, fmt.Errorf("获取宿主机IP列表失败: %w", err)
 	}
 
-	log.Printf("获取宿主机IP列表成功: count=%d", response.Count)
-	return response.HostIPs, nil
+	log.Printf("获取宿主机IP列表成功: count=%d", len(hosts))
+	return models.TargetIPs(hosts), nil
 }
 
 // GetPodIPs 从服务器获取所有Pod IP列表
 func (c *apiClientImpl) GetPodIPs() ([]string, error) {
-	url := fmt.Sprintf("%s"+GET_POD_IPS_URI, c.serverURL)
+	pods, err := c.getTargetList(GET_POD_IPS_URI, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取Pod IP列表失败: %w", err)
+	}
 
-	var response struct {
-		PodIPs []string `json:"pod_ips"`
-		Count  int      `json:"count"`
+	log.Printf("获取Pod IP列表成功: count=%d", len(pods))
+	return models.TargetIPs(pods), nil
+}
+
+// GetHostTargets 从服务器获取宿主机探测目标，服务器不支持目标对象时只包含IP
+func (c *apiClientImpl) GetHostTargets() ([]models.Target, error) {
+	return c.getTargetObjects(models.TestTypeHost, c.GetHostIPs)
+}
+
+// GetPodTargets 从服务器获取Pod探测目标，服务器不支持目标对象时只包含IP
+func (c *apiClientImpl) GetPodTargets() ([]models.Target, error) {
+	return c.getTargetObjects(models.TestTypePod, c.GetPodIPs)
+}
+
+// getTargetObjects 通过目标对象接口获取探测目标，服务器返回 404 时改用IP列表接口
+func (c *apiClientImpl) getTargetObjects(testType string, getIPs func() ([]string, error)) ([]models.Target, error) {
+	targets, err := c.getTargetList(fmt.Sprintf(GET_TARGETS_URI, testType), true)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		ips, err := getIPs()
+		if err != nil {
+			return nil, err
+		}
+		return models.TargetsFromIPs(ips), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取%s探测目标失败: %w", testTypeNames[testType], err)
 	}
 
-	// 发送GET请求，带重试逻辑
-	err := c.doRequestWithRetry("GET", url, nil, &response)
+	log.Printf("获取%s探测目标成功: count=%d", testTypeNames[testType], len(targets))
+	return targets, nil
+}
+
+// testTypeNames 测试类型在日志中的名称
+var testTypeNames = map[string]string{
+	models.TestTypeHost: "宿主机",
+	models.TestTypePod:  "Pod",
+}
+
+// targetList 缓存的目标列表
+type targetList struct {
+	etag    string
+	targets []models.Target
+}
+
+// targetListResponse 目标列表接口的响应，delta 为 true 时只包含自请求版本以来的变更
+// 目标对象接口的 added 为目标对象，IP列表接口的 added 为IP
+type targetListResponse struct {
+	HostIPs []string        `json:"host_ips"`
+	PodIPs  []string        `json:"pod_ips"`
+	Targets []models.Target `json:"targets"`
+	Count   int             `json:"count"`
+	ETag    string          `json:"etag"`
+	Delta   bool            `json:"delta"`
+	Added   json.RawMessage `json:"added"`
+	Removed []string        `json:"removed"`
+}
+
+// getTargetList 获取目标列表，服务器返回版本标识时缓存列表
+// 之后的请求携带版本标识：列表未变化时服务器返回 304，变化时只返回增量变更；
+// 应用增量后数量与服务器不一致时丢弃缓存，重新获取完整列表。structured 表示接口返回目标对象
+func (c *apiClientImpl) getTargetList(uri string, structured bool) ([]models.Target, error) {
+	for attempt := 0; ; attempt++ {
+		c.targetsMu.Lock()
+		cached := c.targetLists[uri]
+		c.targetsMu.Unlock()
+
+		targets, etag, err := c.fetchTargetList(uri, structured, cached)
+		if errors.Is(err, errTargetDeltaMismatch) && attempt == 0 {
+			log.Printf("增量目标列表与服务器不一致，重新获取完整列表: uri=%s", uri)
+			c.storeTargetList(uri, nil)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if etag == "" {
+			c.storeTargetList(uri, nil)
+		} else {
+			c.storeTargetList(uri, &targetList{etag: etag, targets: targets})
+		}
+		return append([]models.Target(nil), targets...), nil
+	}
+}
+
+// fetchTargetList 请求目标列表并与缓存合并，返回最新列表与版本标识
+func (c *apiClientImpl) fetchTargetList(uri string, structured bool, cached *targetList) ([]models.Target, string, error) {
+	requestURL := c.targetsURL(uri)
+	if cached != nil {
+		if strings.Contains(requestURL, "?") {
+			requestURL += "&"
+		} else {
+			requestURL += "?"
+		}
+		requestURL += "since=" + url.QueryEscape(cached.etag)
+	}
+
+	var response targetListResponse
+	notModified := false
+	err := c.retry(func() error {
+		response = targetListResponse{}
+		req, err := http.NewRequest("GET", requestURL, nil)
+		if err != nil {
+			return fmt.Errorf("创建HTTP请求失败: %w", err)
+		}
+		if cached != nil {
+			req.Header.Set("If-None-Match", `"`+cached.etag+`"`)
+		}
+		err = c.send(c.httpClient, req, nil, &response)
+		if errors.Is(err, errNotModified) {
+			notModified = true
+			return nil
+		}
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("获取Pod IP列表失败: %w", err)
+		return nil, "", err
+	}
+
+	switch {
+	case notModified:
+		return cached.targets, cached.etag, nil
+	case response.Delta && cached != nil:
+		added, err := response.added(structured)
+		if err != nil {
+			return nil, "", err
+		}
+		targets := applyTargetDelta(cached.targets, added, response.Removed)
+		if len(targets) != response.Count {
+			return nil, "", errTargetDeltaMismatch
+		}
+		return targets, response.ETag, nil
+	case structured:
+		return response.Targets, response.ETag, nil
+	case uri == GET_HOST_IPS_URI:
+		return models.TargetsFromIPs(response.HostIPs), response.ETag, nil
+	default:
+		return models.TargetsFromIPs(response.PodIPs), response.ETag, nil
+	}
+}
+
+// added 解析增量中新增的目标
+func (r *targetListResponse) added(structured bool) ([]models.Target, error) {
+	if len(r.Added) == 0 {
+		return nil, nil
+	}
+	if structured {
+		var targets []models.Target
+		if err := json.Unmarshal(r.Added, &targets); err != nil {
+			return nil, fmt.Errorf("解析增量目标失败: %w", err)
+		}
+		return targets, nil
+	}
+	var ips []string
+	if err := json.Unmarshal(r.Added, &ips); err != nil {
+		return nil, fmt.Errorf("解析增量目标失败: %w", err)
+	}
+	return models.TargetsFromIPs(ips), nil
+}
+
+// storeTargetList 更新缓存的目标列表，list 为nil时删除缓存
+func (c *apiClientImpl) storeTargetList(uri string, list *targetList) {
+	c.targetsMu.Lock()
+	defer c.targetsMu.Unlock()
+
+	if list == nil {
+		delete(c.targetLists, uri)
+		return
+	}
+	if c.targetLists == nil {
+		c.targetLists = make(map[string]*targetList)
+	}
+	c.targetLists[uri] = list
+}
+
+// applyTargetDelta 将增量变更应用到目标列表，同IP的目标以新增的为准，返回按IP排序的新列表
+func applyTargetDelta(targets, added []models.Target, removed []string) []models.Target {
+	byIP := make(map[string]models.Target, len(targets)+len(added))
+	for _, target := range targets {
+		byIP[target.IP] = target
+	}
+	for _, ip := range removed {
+		delete(byIP, ip)
+	}
+	for _, target := range added {
+		byIP[target.IP] = target
+	}
+
+	result := make([]models.Target, 0, len(byIP))
+	for _, target := range byIP {
+		result = append(result, target)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IP < result[j].IP })
+	return result
+}
 
-	log.Printf("获取Pod IP列表成功: count=%d", response.Count)
-	return response.PodIPs, nil
+// targetsURL 返回目标列表地址，设置了Pod名称时携带 pod_name 参数
+func (c *apiClientImpl) targetsURL(uri string) string {
+	if c.podName == "" {
+		return c.serverURL + uri
+	}
+	return c.serverURL + uri + "?pod_name=" + url.QueryEscape(c.podName)
 }
 
 // ReportHostTestResults 上报宿主机测试结果到服务器
@@ -215,38 +579,109 @@ func (c *apiClientImpl) ReportServiceTestResults(result *models.ConnectivityResu
 	return nil
 }
 
-// doRequestWithRetry 执行HTTP请求，带指数退避重试逻辑（最多5次）
+// PollRuns 长轮询获取下发给当前客户端的按需测试任务
+// 不重试，由调用方循环调用
+func (c *apiClientImpl) PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error) {
+	query := url.Values{}
+	query.Set("pod_name", podName)
+	query.Set("wait", wait.String())
+	requestURL := fmt.Sprintf("%s%s?%s", c.serverURL, POLL_RUNS_URI, query.Encode())
+
+	var response struct {
+		Runs  []models.RunRequest `json:"runs"`
+		Count int                 `json:"count"`
+	}
+
+	// 请求超时需大于服务器的等待时间
+	pollClient := &http.Client{
+		Transport: c.httpClient.Transport,
+		Timeout:   wait + c.httpClient.Timeout,
+	}
+	if err := c.doRequestWithClient(pollClient, "GET", requestURL, nil, &response); err != nil {
+		return nil, fmt.Errorf("获取按需测试任务失败: %w", err)
+	}
+
+	return response.Runs, nil
+}
+
+// CompleteRun 报告按需测试任务已完成
+func (c *apiClientImpl) CompleteRun(runID string, podName string) error {
+	requestURL := c.serverURL + fmt.Sprintf(COMPLETE_RUN_URI, url.PathEscape(runID))
+
+	body, err := json.Marshal(map[string]string{"pod_name": podName})
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	if err := c.doRequestWithRetry("POST", requestURL, body, nil); err != nil {
+		return fmt.Errorf("报告按需测试任务完成失败: %w", err)
+	}
+
+	log.Printf("按需测试任务已完成: run_id=%s", runID)
+	return nil
+}
+
+// doRequestWithRetry 执行HTTP请求，失败时按重试策略重试
 func (c *apiClientImpl) doRequestWithRetry(method, url string, body []byte, response interface{}) error {
-	maxRetries := 5
-	baseDelay := 1 * time.Second
+	return c.retry(func() error {
+		return c.doRequest(method, url, body, response)
+	})
+}
 
+// retry 执行请求，带指数退避与随机抖动的重试逻辑（默认最多5次）
+// 请求无效、认证失败等重试也无法成功的错误立即返回
+func (c *apiClientImpl) retry(request func() error) error {
 	var lastErr error
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		// 如果不是第一次尝试，等待一段时间（指数退避）
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
+		// 如果不是第一次尝试，等待一段时间（指数退避加随机抖动，避免所有客户端同时重试）
 		if attempt > 0 {
-			delay := baseDelay * time.Duration(1<<uint(attempt-1)) // 1s, 2s, 4s, 8s, 16s
-			log.Printf("请求失败，%v后重试 (尝试 %d/%d): %v", delay, attempt+1, maxRetries, lastErr)
+			delay := c.backoff(attempt)
+			log.Printf("请求失败，%v后重试 (尝试 %d/%d): %v", delay, attempt+1, c.maxRetries, lastErr)
 			time.Sleep(delay)
 		}
 
-		// 执行HTTP请求
-		err := c.doRequest(method, url, body, response)
+		// 执行请求
+		err := request()
 		if err == nil {
 			// 请求成功
 			return nil
 		}
 
 		lastErr = err
-		log.Printf("请求失败 (尝试 %d/%d): %v", attempt+1, maxRetries, err)
+		log.Printf("请求失败 (尝试 %d/%d): %v", attempt+1, c.maxRetries, err)
+		if IsPermanent(err) {
+			return err
+		}
 	}
 
 	// 所有重试都失败
-	return fmt.Errorf("请求失败，已重试%d次: %w", maxRetries, lastErr)
+	return fmt.Errorf("请求失败，已重试%d次: %w", c.maxRetries, lastErr)
+}
+
+// backoff 返回第 attempt 次重试前的等待时间，在指数退避时长的一半到全部之间随机取值
+func (c *apiClientImpl) backoff(attempt int) time.Duration {
+	delay := c.baseDelay
+	for i := 1; i < attempt && delay < c.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
 // doRequest 执行单次HTTP请求
 func (c *apiClientImpl) doRequest(method, url string, body []byte, response interface{}) error {
+	return c.doRequestWithClient(c.httpClient, method, url, body, response)
+}
+
+// doRequestWithClient 使用指定的HTTP客户端执行单次请求
+func (c *apiClientImpl) doRequestWithClient(httpClient *http.Client, method, url string, body []byte, response interface{}) error {
 	var req *http.Request
 	var err error
 
@@ -261,11 +696,22 @@ func (c *apiClientImpl) doRequest(method, url string, body []byte, response inte
 		return fmt.Errorf("创建HTTP请求失败: %w", err)
 	}
 
+	return c.send(httpClient, req, body, response)
+}
+
+// send 为请求设置请求头与签名后发送，并解析响应
+// body 为实际传输的请求体，参与签名计算
+func (c *apiClientImpl) send(httpClient *http.Client, req *http.Request, body []byte, response interface{}) error {
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
+	if c.signer != nil {
+		if err := c.signer.Sign(req, body); err != nil {
+			return fmt.Errorf("签名请求失败: %w", err)
+		}
+	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -277,16 +723,18 @@ func (c *apiClientImpl) doRequest(method, url string, body []byte, response inte
 		return fmt.Errorf("读取响应体失败: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotModified {
+		return errNotModified
+	}
+
 	// 检查HTTP状态码
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// 尝试解析错误响应
 		var errorResp models.ErrorResponse
 		if err := json.Unmarshal(respBody, &errorResp); err == nil {
-			return fmt.Errorf("服务器返回错误 (状态码=%d): %s - %s",
-				resp.StatusCode, errorResp.Code, errorResp.Message)
+			return &StatusError{StatusCode: resp.StatusCode, Message: errorResp.Code + " - " + errorResp.Message}
 		}
-		return fmt.Errorf("服务器返回错误 (状态码=%d): %s",
-			resp.StatusCode, string(respBody))
+		return &StatusError{StatusCode: resp.StatusCode, Message: string(respBody)}
 	}
 
 	// 如果需要解析响应体
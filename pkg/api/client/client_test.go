@@ -1,15 +1,22 @@
 package client
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSendHeartbeat 测试发送心跳功能
@@ -55,7 +62,7 @@ func TestSendHeartbeat(t *testing.T) {
 		Timestamp: time.Now(),
 	}
 
-	err := client.SendHeartbeat(nodeInfo)
+	_, err := client.SendHeartbeat(nodeInfo)
 	assert.NoError(t, err)
 }
 
@@ -154,7 +161,7 @@ func TestReportHostTestResults(t *testing.T) {
 			TargetIP:   "192.168.1.1",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{22: "open"},
-			Latency:    10 * time.Millisecond,
+			Latency:    models.Duration(10 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 		{
@@ -210,7 +217,7 @@ func TestReportPodTestResults(t *testing.T) {
 			TargetIP:   "10.0.0.2",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{6100: "open"},
-			Latency:    5 * time.Millisecond,
+			Latency:    models.Duration(5 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 	}
@@ -257,7 +264,7 @@ func TestReportServiceTestResults(t *testing.T) {
 		TargetIP:   "my-service.default.svc.cluster.local",
 		PingStatus: "reachable",
 		PortStatus: map[int]string{80: "open"},
-		Latency:    15 * time.Millisecond,
+		Latency:    models.Duration(15 * time.Millisecond),
 		Timestamp:  time.Now(),
 	}
 
@@ -327,6 +334,40 @@ func TestMaxRetriesExceeded(t *testing.T) {
 	assert.Equal(t, 5, attemptCount)
 }
 
+// TestRetryPolicy 测试退避时长与不可重试错误
+func TestRetryPolicy(t *testing.T) {
+	c := NewAPIClient("http://localhost", "10.0.0.1", WithRetryPolicy(4, 100*time.Millisecond, 300*time.Millisecond)).(*apiClientImpl)
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			delay := c.backoff(attempt)
+			assert.GreaterOrEqual(t, delay, max/2)
+			assert.LessOrEqual(t, delay, max)
+		}
+	}
+
+	attempts := map[int]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		attempts[status]++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c = NewAPIClient(server.URL, "10.0.0.1", WithRetryPolicy(3, time.Millisecond, time.Millisecond)).(*apiClientImpl)
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		err := c.doRequestWithRetry("GET", server.URL+"?status="+strconv.Itoa(status), nil, nil)
+		require.Error(t, err)
+		permanent := status == http.StatusBadRequest || status == http.StatusUnauthorized
+		assert.Equal(t, permanent, IsPermanent(err), status)
+		if permanent {
+			assert.Equal(t, 1, attempts[status], status)
+		} else {
+			assert.Equal(t, 3, attempts[status], status)
+		}
+	}
+	assert.False(t, IsPermanent(errors.New("connection refused")))
+}
+
 // TestErrorResponse 测试错误响应处理
 func TestErrorResponse(t *testing.T) {
 	// 创建测试服务器，返回400错误
@@ -352,8 +393,190 @@ func TestErrorResponse(t *testing.T) {
 		Timestamp: time.Now(),
 	}
 
-	err := client.SendHeartbeat(nodeInfo)
+	_, err := client.SendHeartbeat(nodeInfo)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "INVALID_REQUEST")
 	assert.Contains(t, err.Error(), "无效的请求数据")
 }
+
+// TestWithSigner 测试请求携带认证信息
+func TestWithSigner(t *testing.T) {
+	verifier := auth.NewTokenVerifier("secret-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if _, err := verifier.Verify(r, body); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.1"}, "count": 1})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "10.0.0.1", WithSigner(auth.NewTokenSigner("secret-token")))
+	podIPs, err := client.GetPodIPs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, podIPs)
+}
+
+// TestWithPodName 测试获取目标列表时携带Pod名称
+func TestWithPodName(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("pod_name"))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"host_ips": []string{"192.168.1.1"}, "pod_ips": []string{"10.0.0.1"}, "count": 1})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "10.0.0.1", WithPodName("checker a&b"))
+	_, err := client.GetHostIPs()
+	assert.NoError(t, err)
+	_, err = client.GetPodIPs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"checker a&b", "checker a&b"}, queries)
+}
+
+// TestTargetListCache 测试缓存带版本标识的目标列表，未变化时使用缓存，变化时应用增量
+func TestTargetListCache(t *testing.T) {
+	var requests []string
+	responses := []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.1", "10.0.0.2"}, "count": 2, "etag": "v1"})
+		},
+		func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusNotModified)
+		},
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"delta": true, "added": []string{"10.0.0.3"}, "removed": []string{"10.0.0.1"}, "count": 2, "etag": "v2"})
+		},
+		// 增量与缓存不一致，重新获取完整列表
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"delta": true, "added": []string{}, "removed": []string{}, "count": 5, "etag": "v3"})
+		},
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.4"}, "count": 1, "etag": "v3"})
+		},
+		// 不带版本标识的响应清除缓存
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.5"}, "count": 1})
+		},
+		func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.5"}, "count": 1})
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("since")+"|"+r.Header.Get("If-None-Match"))
+		responses[len(requests)-1](w)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "10.0.0.1", WithPodName("pod-1"))
+	for _, expected := range [][]string{
+		{"10.0.0.1", "10.0.0.2"},
+		{"10.0.0.1", "10.0.0.2"},
+		{"10.0.0.2", "10.0.0.3"},
+		{"10.0.0.4"},
+		{"10.0.0.5"},
+		{"10.0.0.5"},
+	} {
+		podIPs, err := client.GetPodIPs()
+		require.NoError(t, err)
+		assert.Equal(t, expected, podIPs)
+	}
+	assert.Equal(t, []string{"|", `v1|"v1"`, `v1|"v1"`, `v2|"v2"`, "|", `v3|"v3"`, "|"}, requests)
+}
+
+// TestGetPodTargets 测试获取目标对象，服务器不支持目标对象接口时改用IP列表接口
+func TestGetPodTargets(t *testing.T) {
+	structured := true
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case r.URL.Path == "/api/v1/targets/pod" && structured:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"targets": []models.Target{{IP: "10.0.0.2", PodName: "pod-2", NodeName: "node-2", Ports: []int{6100}}},
+				"count":   1,
+			})
+		case r.URL.Path == "/api/v1/pods":
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.3"}, "count": 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "10.0.0.1", WithRetryPolicy(3, time.Millisecond, time.Millisecond))
+	pods, err := client.GetPodTargets()
+	require.NoError(t, err)
+	assert.Equal(t, []models.Target{{IP: "10.0.0.2", PodName: "pod-2", NodeName: "node-2", Ports: []int{6100}}}, pods)
+
+	// 旧版本服务器返回 404，不重试并改用IP列表接口
+	structured = false
+	pods, err = client.GetPodTargets()
+	require.NoError(t, err)
+	assert.Equal(t, []models.Target{{IP: "10.0.0.3"}}, pods)
+	assert.Equal(t, []string{"/api/v1/targets/pod", "/api/v1/targets/pod", "/api/v1/pods"}, paths)
+}
+
+// TestWithTLSConfig 测试通过TLS连接服务器，每次建立连接时获取最新配置
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"host_ips": []string{"192.168.1.1"}, "count": 1})
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	calls := 0
+	client := NewAPIClient(server.URL, "10.0.0.1", WithTLSConfig(func() *tls.Config {
+		calls++
+		return &tls.Config{RootCAs: pool}
+	}))
+
+	hostIPs, err := client.GetHostIPs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.1"}, hostIPs)
+	assert.Equal(t, 1, calls)
+
+	// 不信任服务器证书时连接失败
+	untrusted := &apiClientImpl{serverURL: server.URL, httpClient: &http.Client{Timeout: time.Second}}
+	WithTLSConfig(func() *tls.Config { return &tls.Config{RootCAs: x509.NewCertPool()} })(untrusted)
+	var response map[string]interface{}
+	assert.Error(t, untrusted.doRequest("GET", server.URL+GET_HOST_IPS_URI, nil, &response))
+}
+
+// TestPollAndCompleteRuns 测试长轮询获取按需测试任务并报告完成
+func TestPollAndCompleteRuns(t *testing.T) {
+	var completedBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/runs/pending":
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "pod-1", r.URL.Query().Get("pod_name"))
+			assert.Equal(t, "5s", r.URL.Query().Get("wait"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"runs":  []models.RunRequest{{ID: "run-1", TestTypes: []string{"pod"}}},
+				"count": 1,
+			})
+		case "/api/v1/runs/run-1/complete":
+			assert.Equal(t, "POST", r.Method)
+			json.NewDecoder(r.Body).Decode(&completedBody)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "10.0.0.1")
+	requests, err := client.PollRuns("pod-1", 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RunRequest{{ID: "run-1", TestTypes: []string{"pod"}}}, requests)
+
+	assert.NoError(t, client.CompleteRun("run-1", "pod-1"))
+	assert.Equal(t, map[string]string{"pod_name": "pod-1"}, completedBody)
+}
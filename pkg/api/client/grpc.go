@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/api/pb"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcClientImpl 通过gRPC与服务器交互的APIClient实现
+// 按需测试任务与探测配置变更通过 Watch 流实时接收，流断开后按重试策略退避重连
+type grpcClientImpl struct {
+	*apiClientImpl // 复用配置项与重试策略
+
+	conn   *grpc.ClientConn
+	client pb.CheckerClient
+
+	ctx       context.Context // 关闭客户端时取消，结束 Watch 流
+	cancel    context.CancelFunc
+	watchOnce sync.Once
+	runs      *runQueue
+}
+
+// NewGRPCClient 创建通过gRPC与服务器交互的APIClient实例，address 为服务器的 gRPC 地址（host:port）
+// 配置项与 NewAPIClient 相同，WithSync 不适用；连接在首次调用时建立
+func NewGRPCClient(address string, sourceIP string, opts ...Option) (APIClient, error) {
+	c := &apiClientImpl{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		sourceIP:   sourceIP,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if c.tlsConfig != nil {
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(&reloadingCredentials{getConfig: c.tlsConfig})}
+	}
+	if c.signer != nil {
+		dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(c.signUnary))
+	}
+	dialOptions = append(dialOptions, c.dialOptions...)
+
+	conn, err := grpc.NewClient(address, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("创建gRPC连接失败: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &grpcClientImpl{
+		apiClientImpl: c,
+		conn:          conn,
+		client:        pb.NewCheckerClient(conn),
+		ctx:           ctx,
+		cancel:        cancel,
+		runs:          newRunQueue(),
+	}, nil
+}
+
+// Close 结束 Watch 流并关闭连接
+func (c *grpcClientImpl) Close() error {
+	c.cancel()
+	return c.conn.Close()
+}
+
+// SendHeartbeat 发送心跳到服务器
+// 心跳只发送一次，失败后由心跳上报器按服务器状态退避
+func (c *grpcClientImpl) SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, c.httpClient.Timeout)
+	defer cancel()
+
+	response, err := c.client.Heartbeat(ctx, &pb.HeartbeatRequest{Node: pb.FromNodeInfo(info)})
+	if err != nil {
+		return nil, fmt.Errorf("发送心跳失败: %w", grpcError(err))
+	}
+
+	log.Printf("心跳发送成功: pod=%s, node_ip=%s, pod_ip=%s",
+		info.PodName, info.NodeIP, info.PodIP)
+	return response.ToModel(), nil
+}
+
+// GetHostIPs 从服务器获取宿主机IP列表
+func (c *grpcClientImpl) GetHostIPs() ([]string, error) {
+	response, err := c.getTargets(models.TestTypeHost)
+	if err != nil {
+		return nil, fmt.Errorf("获取宿主机IP列表失败: %w", err)
+	}
+
+	log.Printf("获取宿主机IP列表成功: count=%d", len(response.GetIps()))
+	return response.GetIps(), nil
+}
+
+// GetPodIPs 从服务器获取Pod IP列表
+func (c *grpcClientImpl) GetPodIPs() ([]string, error) {
+	response, err := c.getTargets(models.TestTypePod)
+	if err != nil {
+		return nil, fmt.Errorf("获取Pod IP列表失败: %w", err)
+	}
+
+	log.Printf("获取Pod IP列表成功: count=%d", len(response.GetIps()))
+	return response.GetIps(), nil
+}
+
+// GetHostTargets 从服务器获取宿主机探测目标
+func (c *grpcClientImpl) GetHostTargets() ([]models.Target, error) {
+	response, err := c.getTargets(models.TestTypeHost)
+	if err != nil {
+		return nil, fmt.Errorf("获取宿主机探测目标失败: %w", err)
+	}
+
+	targets := responseTargets(response)
+	log.Printf("获取宿主机探测目标成功: count=%d", len(targets))
+	return targets, nil
+}
+
+// GetPodTargets 从服务器获取Pod探测目标
+func (c *grpcClientImpl) GetPodTargets() ([]models.Target, error) {
+	response, err := c.getTargets(models.TestTypePod)
+	if err != nil {
+		return nil, fmt.Errorf("获取Pod探测目标失败: %w", err)
+	}
+
+	targets := responseTargets(response)
+	log.Printf("获取Pod探测目标成功: count=%d", len(targets))
+	return targets, nil
+}
+
+// responseTargets 返回响应中的目标对象，服务器未返回目标对象时由IP列表生成
+func responseTargets(response *pb.GetTargetsResponse) []models.Target {
+	if len(response.GetTargets()) != len(response.GetIps()) {
+		return models.TargetsFromIPs(response.GetIps())
+	}
+	return pb.ToTargets(response.GetTargets())
+}
+
+// getTargets 获取指定测试类型的目标列表，带重试逻辑
+func (c *grpcClientImpl) getTargets(testType string) (*pb.GetTargetsResponse, error) {
+	var response *pb.GetTargetsResponse
+	err := c.retry(func() error {
+		ctx, cancel := context.WithTimeout(c.ctx, c.httpClient.Timeout)
+		defer cancel()
+
+		var err error
+		response, err = c.client.GetTargets(ctx, &pb.GetTargetsRequest{TestType: testType, PodName: c.podName})
+		return grpcError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ReportHostTestResults 上报宿主机测试结果到服务器
+func (c *grpcClientImpl) ReportHostTestResults(results []models.ConnectivityResult) error {
+	if err := c.report(models.TestTypeHost, results); err != nil {
+		return fmt.Errorf("上报宿主机测试结果失败: %w", err)
+	}
+
+	log.Printf("宿主机测试结果上报成功: source_ip=%s, results_count=%d",
+		c.sourceIP, len(results))
+	return nil
+}
+
+// ReportPodTestResults 上报Pod测试结果到服务器
+func (c *grpcClientImpl) ReportPodTestResults(results []models.ConnectivityResult) error {
+	if err := c.report(models.TestTypePod, results); err != nil {
+		return fmt.Errorf("上报Pod测试结果失败: %w", err)
+	}
+
+	log.Printf("Pod测试结果上报成功: source_ip=%s, results_count=%d",
+		c.sourceIP, len(results))
+	return nil
+}
+
+// ReportServiceTestResults 上报自定义服务测试结果到服务器
+func (c *grpcClientImpl) ReportServiceTestResults(result *models.ConnectivityResult) error {
+	if err := c.report(models.TestTypeService, []models.ConnectivityResult{*result}); err != nil {
+		return fmt.Errorf("上报服务测试结果失败: %w", err)
+	}
+
+	log.Printf("服务测试结果上报成功: source_ip=%s, target=%s",
+		c.sourceIP, result.TargetIP)
+	return nil
+}
+
+// report 上报一批测试结果，带重试逻辑
+func (c *grpcClientImpl) report(testType string, results []models.ConnectivityResult) error {
+	request := &pb.ReportResultsRequest{
+		TestType: testType,
+		SourceIp: c.sourceIP,
+		Results:  pb.FromConnectivityResults(results),
+	}
+	return c.retry(func() error {
+		ctx, cancel := context.WithTimeout(c.ctx, c.httpClient.Timeout)
+		defer cancel()
+
+		_, err := c.client.ReportResults(ctx, request)
+		return grpcError(err)
+	})
+}
+
+// PollRuns 等待服务器推送的按需测试任务，最长等待 wait
+// 首次调用时建立 Watch 流
+func (c *grpcClientImpl) PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error) {
+	c.watchOnce.Do(func() {
+		go c.watch(podName)
+	})
+	return c.runs.wait(wait), nil
+}
+
+// CompleteRun 报告按需测试任务已完成
+func (c *grpcClientImpl) CompleteRun(runID string, podName string) error {
+	err := c.retry(func() error {
+		ctx, cancel := context.WithTimeout(c.ctx, c.httpClient.Timeout)
+		defer cancel()
+
+		_, err := c.client.CompleteRun(ctx, &pb.CompleteRunRequest{RunId: runID, PodName: podName})
+		return grpcError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("报告按需测试任务完成失败: %w", err)
+	}
+
+	log.Printf("按需测试任务已完成: run_id=%s", runID)
+	return nil
+}
+
+// watch 保持 Watch 流，断开后按重试策略退避重连，直到客户端关闭
+func (c *grpcClientImpl) watch(podName string) {
+	for attempt := 1; ; attempt++ {
+		received, err := c.receive(podName)
+		if c.ctx.Err() != nil {
+			return
+		}
+		if received {
+			attempt = 1
+		}
+
+		delay := c.backoff(attempt)
+		log.Printf("推送连接断开，%v后重连: %v", delay, err)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// receive 建立 Watch 流并处理推送的事件，返回是否收到过事件与流结束的原因
+func (c *grpcClientImpl) receive(podName string) (bool, error) {
+	request := &pb.WatchRequest{PodName: podName}
+	if c.probeStore != nil {
+		request.ProbeConfigVersion = c.probeStore.Get().Version
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	ctx, err := c.signContext(ctx, pb.Checker_Watch_FullMethodName, request)
+	if err != nil {
+		return false, err
+	}
+	stream, err := c.client.Watch(ctx, request)
+	if err != nil {
+		return false, grpcError(err)
+	}
+
+	received := false
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return received, grpcError(err)
+		}
+		received = true
+
+		switch e := event.GetEvent().(type) {
+		case *pb.WatchEvent_Run:
+			c.runs.push(e.Run.ToModel())
+		case *pb.WatchEvent_ProbeConfig:
+			c.applyProbeConfig(e.ProbeConfig.ToModel())
+		}
+	}
+}
+
+// applyProbeConfig 应用服务器推送的探测配置，配置无效时保留当前配置
+func (c *grpcClientImpl) applyProbeConfig(cfg models.ProbeConfig) {
+	if c.probeStore == nil {
+		return
+	}
+
+	changed, err := c.probeStore.Apply(cfg)
+	if err != nil {
+		log.Printf("错误: 服务器推送的探测配置无效，保留当前配置: version=%d, error=%v", cfg.Version, err)
+		return
+	}
+	if changed {
+		log.Printf("已应用服务器推送的探测配置: version=%d", cfg.Version)
+	}
+}
+
+// signUnary 为一元调用添加认证信息
+func (c *apiClientImpl) signUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return fmt.Errorf("无法签名非 protobuf 消息: %s", method)
+	}
+	ctx, err := c.signContext(ctx, method, message)
+	if err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// signContext 返回携带认证信息的上下文，签名覆盖方法名与请求消息
+// 未配置签名器时原样返回
+func (c *apiClientImpl) signContext(ctx context.Context, method string, message proto.Message) (context.Context, error) {
+	if c.signer == nil {
+		return ctx, nil
+	}
+
+	req, body, err := pb.SigningRequest(method, nil, message)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.signer.Sign(req, body); err != nil {
+		return nil, fmt.Errorf("签名请求失败: %w", err)
+	}
+	return metadata.NewOutgoingContext(ctx, pb.HeaderMetadata(req.Header)), nil
+}
+
+// grpcError 将服务器返回的gRPC状态转换为与HTTP接口一致的 StatusError，
+// 使重试与心跳退避按相同的规则判断；连接失败、超时等传输错误原样返回
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var statusCode int
+	switch st.Code() {
+	case codes.InvalidArgument:
+		statusCode = http.StatusBadRequest
+	case codes.Unauthenticated:
+		statusCode = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		statusCode = http.StatusForbidden
+	case codes.NotFound, codes.Unimplemented:
+		statusCode = http.StatusNotFound
+	case codes.ResourceExhausted:
+		statusCode = http.StatusTooManyRequests
+	case codes.Internal:
+		statusCode = http.StatusInternalServerError
+	default:
+		return err
+	}
+	return &StatusError{StatusCode: statusCode, Message: st.Code().String() + " - " + st.Message()}
+}
+
+// reloadingCredentials 每次握手时获取最新的TLS配置，证书更新后新连接自动使用新证书
+type reloadingCredentials struct {
+	getConfig func() *tls.Config
+}
+
+// ClientHandshake 使用最新的TLS配置完成握手
+func (r *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(r.getConfig()).ClientHandshake(ctx, authority, conn)
+}
+
+// ServerHandshake 仅用于客户端
+func (r *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("reloadingCredentials 仅用于客户端")
+}
+
+// Info 返回协议信息
+func (r *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return credentials.NewTLS(r.getConfig()).Info()
+}
+
+// Clone 返回副本
+func (r *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return &reloadingCredentials{getConfig: r.getConfig}
+}
+
+// OverrideServerName 已废弃，服务器名称由TLS配置或连接地址决定
+func (r *reloadingCredentials) OverrideServerName(string) error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/api/pb"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeCheckerServer 按调用次数返回预设结果的gRPC服务
+type fakeCheckerServer struct {
+	pb.UnimplementedCheckerServer
+
+	mu      sync.Mutex
+	reports int
+	targets int
+	watches int
+}
+
+// ReportResults 第一次调用返回服务器内部错误
+func (s *fakeCheckerServer) ReportResults(ctx context.Context, req *pb.ReportResultsRequest) (*pb.ReportResultsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports++
+	if s.reports == 1 {
+		return nil, status.Error(codes.Internal, "保存测试结果失败")
+	}
+	return &pb.ReportResultsResponse{}, nil
+}
+
+// GetTargets 始终返回参数错误
+func (s *fakeCheckerServer) GetTargets(ctx context.Context, req *pb.GetTargetsRequest) (*pb.GetTargetsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets++
+	return nil, status.Error(codes.InvalidArgument, "无效的目标类型")
+}
+
+// Watch 每个流推送一个任务后断开
+func (s *fakeCheckerServer) Watch(req *pb.WatchRequest, stream pb.Checker_WatchServer) error {
+	s.mu.Lock()
+	s.watches++
+	id := "run-" + string(rune('0'+s.watches))
+	s.mu.Unlock()
+
+	if err := stream.Send(&pb.WatchEvent{Event: &pb.WatchEvent_Run{Run: &pb.RunRequest{Id: id, TestTypes: []string{models.TestTypePod}}}}); err != nil {
+		return err
+	}
+	return status.Error(codes.Unavailable, "服务器重启")
+}
+
+// TestGRPCClient 测试gRPC错误按HTTP状态码判断是否重试，推送连接断开后自动重连
+func TestGRPCClient(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	fake := &fakeCheckerServer{}
+	pb.RegisterCheckerServer(server, fake)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	apiClient, err := NewGRPCClient("passthrough:///bufnet", "10.0.0.1",
+		WithRetryPolicy(3, time.Millisecond, time.Millisecond),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		})),
+	)
+	require.NoError(t, err)
+	defer apiClient.(*grpcClientImpl).Close()
+
+	// 服务器内部错误后重试成功
+	require.NoError(t, apiClient.ReportHostTestResults([]models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "192.168.1.2"}}))
+
+	// 参数错误不重试
+	_, err = apiClient.GetPodIPs()
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 400, statusErr.StatusCode)
+	assert.True(t, IsPermanent(err))
+
+	// 推送连接断开后重连，继续接收任务
+	var received []string
+	require.Eventually(t, func() bool {
+		runs, err := apiClient.PollRuns("pod-1", 10*time.Millisecond)
+		require.NoError(t, err)
+		for _, run := range runs {
+			received = append(received, run.ID)
+		}
+		return len(received) >= 2
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"run-1", "run-2"}, received[:2])
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, 2, fake.reports)
+	assert.Equal(t, 1, fake.targets)
+}
@@ -0,0 +1,339 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// 同步模式参数
+const (
+	// syncResultTimeout 测试结果等待随同步请求上报的最长时间，超时后由调用方按上报失败处理
+	syncResultTimeout = 30 * time.Second
+
+	// syncTargetRefresh 未分片的目标列表在同步时刷新的间隔
+	syncTargetRefresh = 30 * time.Second
+
+	// syncTargetMaxAge 缓存的目标列表可用于测试的最长时间，超过后回退到单独获取
+	syncTargetMaxAge = 10 * time.Minute
+)
+
+// errSyncUnsupported 服务器不支持同步接口
+var errSyncUnsupported = errors.New("服务器不支持同步接口，已切换为单独上报")
+
+// pendingResults 等待随下一次同步上报的测试结果
+type pendingResults struct {
+	batch models.SyncResults
+	done  chan error // 同步完成后写入该批结果的处理结果
+}
+
+// cachedTargets 同步响应中的目标列表
+type cachedTargets struct {
+	targets   []models.Target
+	fetchedAt time.Time
+	sharded   bool // 分片的目标每轮只能使用一次，用过后在下一次同步时获取新一轮
+	used      bool
+}
+
+// syncClientImpl 通过同步接口与服务器交互的APIClient实现
+// 心跳时将期间产生的测试结果与需要刷新的目标列表合并为一个请求，
+// 响应中的目标列表与按需测试任务缓存在本地，供下一轮测试与任务轮询使用；
+// 缓存不可用时回退到单独的接口，服务器不支持同步接口时整体回退为单独上报
+type syncClientImpl struct {
+	*apiClientImpl
+
+	unsupported atomic.Bool // 服务器返回404时置位
+
+	mu      sync.Mutex
+	pending []*pendingResults
+	targets map[string]*cachedTargets
+	runs    *runQueue
+}
+
+// newSyncClient 创建同步模式的客户端
+func newSyncClient(c *apiClientImpl) *syncClientImpl {
+	return &syncClientImpl{
+		apiClientImpl: c,
+		targets:       make(map[string]*cachedTargets),
+		runs:          newRunQueue(),
+	}
+}
+
+// Sync 发送同步请求，请求体使用 gzip 压缩，响应由 http.Transport 自动解压
+// 不重试，由心跳上报器按服务器状态退避
+func (c *apiClientImpl) Sync(request *models.SyncRequest) (*models.SyncResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化同步请求失败: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("压缩同步请求失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("压缩同步请求失败: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.serverURL+SYNC_URI, bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+
+	var response models.SyncResponse
+	if err := c.send(c.httpClient, req, compressed.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("同步失败: %w", err)
+	}
+	return &response, nil
+}
+
+// SendHeartbeat 发送同步请求，携带心跳、待上报的测试结果与需要刷新的目标类型
+func (s *syncClientImpl) SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error) {
+	if s.unsupported.Load() {
+		return s.apiClientImpl.SendHeartbeat(info)
+	}
+
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	request := &models.SyncRequest{
+		Version:   models.SyncVersion,
+		Heartbeat: *info,
+		SourceIP:  s.sourceIP,
+		Targets:   s.staleTargets(),
+	}
+	for _, p := range pending {
+		request.Results = append(request.Results, p.batch)
+	}
+	s.mu.Unlock()
+
+	response, err := s.Sync(request)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		log.Printf("警告: %v", errSyncUnsupported)
+		s.unsupported.Store(true)
+		for _, p := range pending {
+			p.done <- errSyncUnsupported
+		}
+		return s.apiClientImpl.SendHeartbeat(info)
+	}
+
+	for i, p := range pending {
+		p.done <- resultError(response, err, i)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	for testType, targets := range response.Targets {
+		list := targets.Targets
+		if len(list) != len(targets.IPs) {
+			list = models.TargetsFromIPs(targets.IPs)
+		}
+		s.targets[testType] = &cachedTargets{targets: list, fetchedAt: now, sharded: targets.Shard != nil}
+	}
+	s.mu.Unlock()
+	s.runs.push(response.Runs...)
+
+	log.Printf("同步成功: pod=%s, result_batches=%d, targets=%v, runs=%d",
+		info.PodName, len(request.Results), request.Targets, len(response.Runs))
+	return &response.HeartbeatResponse, nil
+}
+
+// staleTargets 返回需要在本次同步中刷新的目标类型，调用方需持有锁
+func (s *syncClientImpl) staleTargets() []string {
+	var stale []string
+	for _, testType := range []string{models.TestTypeHost, models.TestTypePod} {
+		cached, ok := s.targets[testType]
+		if !ok || (cached.sharded && cached.used) || (!cached.sharded && time.Since(cached.fetchedAt) >= syncTargetRefresh) {
+			stale = append(stale, testType)
+		}
+	}
+	return stale
+}
+
+// resultError 返回同步请求中第 i 批测试结果的处理结果
+func resultError(response *models.SyncResponse, err error, i int) error {
+	if err != nil {
+		return err
+	}
+	if i >= len(response.Results) {
+		return fmt.Errorf("同步响应缺少第%d批测试结果的处理结果", i+1)
+	}
+	if status := response.Results[i]; status.Status != http.StatusOK {
+		return &StatusError{StatusCode: status.Status, Message: status.Code + " - " + status.Message}
+	}
+	return nil
+}
+
+// GetHostIPs 返回同步获取的宿主机IP列表，缓存不可用时单独获取
+func (s *syncClientImpl) GetHostIPs() ([]string, error) {
+	if targets, ok := s.cachedTargets(models.TestTypeHost); ok {
+		return models.TargetIPs(targets), nil
+	}
+	return s.apiClientImpl.GetHostIPs()
+}
+
+// GetPodIPs 返回同步获取的Pod IP列表，缓存不可用时单独获取
+func (s *syncClientImpl) GetPodIPs() ([]string, error) {
+	if targets, ok := s.cachedTargets(models.TestTypePod); ok {
+		return models.TargetIPs(targets), nil
+	}
+	return s.apiClientImpl.GetPodIPs()
+}
+
+// GetHostTargets 返回同步获取的宿主机探测目标，缓存不可用时单独获取
+func (s *syncClientImpl) GetHostTargets() ([]models.Target, error) {
+	if targets, ok := s.cachedTargets(models.TestTypeHost); ok {
+		return targets, nil
+	}
+	return s.apiClientImpl.GetHostTargets()
+}
+
+// GetPodTargets 返回同步获取的Pod探测目标，缓存不可用时单独获取
+func (s *syncClientImpl) GetPodTargets() ([]models.Target, error) {
+	if targets, ok := s.cachedTargets(models.TestTypePod); ok {
+		return targets, nil
+	}
+	return s.apiClientImpl.GetPodTargets()
+}
+
+// cachedTargets 返回缓存的目标列表，分片的目标列表使用后标记为已用
+func (s *syncClientImpl) cachedTargets(testType string) ([]models.Target, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.targets[testType]
+	if !ok || (cached.sharded && cached.used) || time.Since(cached.fetchedAt) > syncTargetMaxAge {
+		return nil, false
+	}
+	cached.used = true
+	return cached.targets, true
+}
+
+// ReportHostTestResults 随下一次同步上报宿主机测试结果
+func (s *syncClientImpl) ReportHostTestResults(results []models.ConnectivityResult) error {
+	return s.report(models.TestTypeHost, results, func() error {
+		return s.apiClientImpl.ReportHostTestResults(results)
+	})
+}
+
+// ReportPodTestResults 随下一次同步上报Pod测试结果
+func (s *syncClientImpl) ReportPodTestResults(results []models.ConnectivityResult) error {
+	return s.report(models.TestTypePod, results, func() error {
+		return s.apiClientImpl.ReportPodTestResults(results)
+	})
+}
+
+// ReportServiceTestResults 随下一次同步上报自定义服务测试结果
+func (s *syncClientImpl) ReportServiceTestResults(result *models.ConnectivityResult) error {
+	return s.report(models.TestTypeService, []models.ConnectivityResult{*result}, func() error {
+		return s.apiClientImpl.ReportServiceTestResults(result)
+	})
+}
+
+// report 将测试结果加入待上报列表，等待携带它的同步请求完成并返回其处理结果
+// 超时仍未发出时从列表中移除并返回错误，由调用方按上报失败处理
+func (s *syncClientImpl) report(testType string, results []models.ConnectivityResult, direct func() error) error {
+	if s.unsupported.Load() {
+		return direct()
+	}
+
+	p := &pendingResults{
+		batch: models.SyncResults{TestType: testType, Results: results},
+		done:  make(chan error, 1),
+	}
+	s.mu.Lock()
+	s.pending = append(s.pending, p)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(syncResultTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-p.done:
+		return err
+	case <-timer.C:
+	}
+
+	s.mu.Lock()
+	if i := slices.Index(s.pending, p); i >= 0 {
+		s.pending = slices.Delete(s.pending, i, i+1)
+		s.mu.Unlock()
+		return fmt.Errorf("等待同步超时 (%v)", syncResultTimeout)
+	}
+	s.mu.Unlock()
+
+	// 已随同步请求发出，等待其处理结果
+	return <-p.done
+}
+
+// PollRuns 等待同步响应中下发的按需测试任务，最长等待 wait
+func (s *syncClientImpl) PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error) {
+	if s.unsupported.Load() {
+		return s.apiClientImpl.PollRuns(podName, wait)
+	}
+
+	return s.runs.wait(wait), nil
+}
+
+// runQueue 缓存服务器下发的按需测试任务，供 PollRuns 等待
+type runQueue struct {
+	mu    sync.Mutex
+	runs  []models.RunRequest
+	ready chan struct{} // 收到新任务时关闭并替换，用于唤醒等待者
+}
+
+// newRunQueue 创建任务队列
+func newRunQueue() *runQueue {
+	return &runQueue{ready: make(chan struct{})}
+}
+
+// push 加入新任务并唤醒等待者
+func (q *runQueue) push(runs ...models.RunRequest) {
+	if len(runs) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.runs = append(q.runs, runs...)
+	close(q.ready)
+	q.ready = make(chan struct{})
+}
+
+// wait 取出全部任务，没有任务时最长等待 wait
+func (q *runQueue) wait(wait time.Duration) []models.RunRequest {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		if len(q.runs) > 0 {
+			runs := q.runs
+			q.runs = nil
+			q.mu.Unlock()
+			return runs
+		}
+		ready := q.ready
+		q.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-timer.C:
+			return nil
+		}
+	}
+}
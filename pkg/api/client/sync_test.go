@@ -0,0 +1,173 @@
+package client
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSyncClient 测试同步模式下测试结果随心跳上报，目标列表与按需测试任务来自同步响应
+func TestSyncClient(t *testing.T) {
+	var mu sync.Mutex
+	var requests []models.SyncRequest
+	var targetRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case SYNC_URI:
+			assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+			reader, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var request models.SyncRequest
+			assert.NoError(t, json.NewDecoder(reader).Decode(&request))
+
+			mu.Lock()
+			requests = append(requests, request)
+			first := len(requests) == 1
+			mu.Unlock()
+
+			response := models.SyncResponse{Version: models.SyncVersion, Targets: map[string]models.SyncTargets{}}
+			for range request.Results {
+				response.Results = append(response.Results, models.SyncResultStatus{Status: http.StatusOK})
+			}
+			for _, testType := range request.Targets {
+				response.Targets[testType] = models.SyncTargets{IPs: []string{testType + "-1"}, Shard: &models.TargetShard{Count: 2}}
+			}
+			if first {
+				response.Runs = []models.RunRequest{{ID: "run-1", TestTypes: []string{models.TestTypePod}}}
+			}
+
+			// 客户端接受 gzip 时压缩响应
+			w.Header().Set("Content-Encoding", "gzip")
+			writer := gzip.NewWriter(w)
+			json.NewEncoder(writer).Encode(response)
+			writer.Close()
+		case GET_POD_IPS_URI:
+			mu.Lock()
+			targetRequests++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{"pod_ips": []string{"10.0.0.9"}, "count": 1})
+		default:
+			t.Errorf("同步模式下不应请求 %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := NewAPIClient(server.URL, "10.0.0.1", WithSync())
+	nodeInfo := &models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+
+	// 首次同步获取全部目标类型与按需测试任务
+	_, err := apiClient.SendHeartbeat(nodeInfo)
+	require.NoError(t, err)
+	runs, err := apiClient.PollRuns("pod-1", time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-1", runs[0].ID)
+
+	// 分片的目标每轮只使用一次，再次获取时回退到单独的接口
+	ips, err := apiClient.GetPodIPs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.TestTypePod + "-1"}, ips)
+	ips, err = apiClient.GetPodIPs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.9"}, ips)
+
+	// 测试结果等待下一次同步上报
+	reported := make(chan error, 1)
+	go func() {
+		reported <- apiClient.ReportPodTestResults([]models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2"}})
+	}()
+	require.Eventually(t, func() bool {
+		s := apiClient.(*syncClientImpl)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.pending) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = apiClient.SendHeartbeat(nodeInfo)
+	require.NoError(t, err)
+	require.NoError(t, <-reported)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 2)
+	assert.ElementsMatch(t, []string{models.TestTypeHost, models.TestTypePod}, requests[0].Targets)
+	assert.Equal(t, []string{models.TestTypePod}, requests[1].Targets)
+	require.Len(t, requests[1].Results, 1)
+	assert.Equal(t, models.TestTypePod, requests[1].Results[0].TestType)
+	assert.Equal(t, "10.0.0.1", requests[1].SourceIP)
+	assert.Equal(t, 1, targetRequests)
+}
+
+// TestSyncClientRejectedAndUnsupported 测试被服务器拒绝的结果返回永久错误，服务器不支持同步接口时回退为单独上报
+func TestSyncClientRejectedAndUnsupported(t *testing.T) {
+	var mu sync.Mutex
+	supported := true
+	heartbeats := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == SYNC_URI && supported:
+			json.NewEncoder(w).Encode(models.SyncResponse{
+				Version: models.SyncVersion,
+				Results: []models.SyncResultStatus{{Status: http.StatusForbidden, Code: "FORBIDDEN", Message: "源IP不属于已注册的客户端"}},
+			})
+		case r.URL.Path == SEND_HEART_BEAT_URI:
+			heartbeats++
+			json.NewEncoder(w).Encode(models.HeartbeatResponse{Status: "success"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := NewAPIClient(server.URL, "10.0.0.1", WithSync())
+	nodeInfo := &models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+
+	report := func() chan error {
+		reported := make(chan error, 1)
+		go func() {
+			reported <- apiClient.ReportHostTestResults([]models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "192.168.1.2"}})
+		}()
+		require.Eventually(t, func() bool {
+			s := apiClient.(*syncClientImpl)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.pending) == 1
+		}, time.Second, 5*time.Millisecond)
+		return reported
+	}
+
+	reported := report()
+	_, err := apiClient.SendHeartbeat(nodeInfo)
+	require.NoError(t, err)
+	assert.True(t, IsPermanent(<-reported))
+
+	// 服务器不支持同步接口：等待中的结果返回可重试的错误，之后心跳走单独的接口
+	mu.Lock()
+	supported = false
+	mu.Unlock()
+	reported = report()
+	_, err = apiClient.SendHeartbeat(nodeInfo)
+	require.NoError(t, err)
+	err = <-reported
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	_, err = apiClient.SendHeartbeat(nodeInfo)
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, 2, heartbeats)
+	mu.Unlock()
+}
@@ -0,0 +1,317 @@
+package pb
+
+import (
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromNodeInfo 将节点信息转换为协议消息
+func FromNodeInfo(info *models.NodeInfo) *NodeInfo {
+	return &NodeInfo{
+		Namespace:          info.Namespace,
+		NodeIp:             info.NodeIP,
+		PodIp:              info.PodIP,
+		PodName:            info.PodName,
+		Timestamp:          timestamp(info.Timestamp),
+		ProbeConfigVersion: info.ProbeConfigVersion,
+		Zone:               info.Zone,
+		NodeName:           info.NodeName,
+		Labels:             info.Labels,
+		PodUid:             info.PodUID,
+	}
+}
+
+// ToModel 转换为节点信息
+func (x *NodeInfo) ToModel() models.NodeInfo {
+	return models.NodeInfo{
+		Namespace:          x.GetNamespace(),
+		NodeIP:             x.GetNodeIp(),
+		PodIP:              x.GetPodIp(),
+		PodName:            x.GetPodName(),
+		Timestamp:          toTime(x.GetTimestamp()),
+		ProbeConfigVersion: x.GetProbeConfigVersion(),
+		Zone:               x.GetZone(),
+		NodeName:           x.GetNodeName(),
+		Labels:             x.GetLabels(),
+		PodUID:             x.GetPodUid(),
+	}
+}
+
+// FromProbeConfig 将探测配置转换为协议消息
+func FromProbeConfig(cfg *models.ProbeConfig) *ProbeConfig {
+	return &ProbeConfig{
+		Version:         cfg.Version,
+		TestInterval:    duration(cfg.TestInterval),
+		TestTypes:       cfg.TestTypes,
+		Protocols:       cfg.Protocols,
+		HostPorts:       toInt32s(cfg.HostPorts),
+		PodPorts:        toInt32s(cfg.PodPorts),
+		ServiceName:     cfg.ServiceName,
+		ServicePorts:    toInt32s(cfg.ServicePorts),
+		MaxConcurrency:  int32(cfg.MaxConcurrency),
+		PingCount:       int32(cfg.PingCount),
+		Timeout:         duration(cfg.Timeout),
+		UpdatedAt:       timestamp(cfg.UpdatedAt),
+		HostInterval:    duration(cfg.HostInterval),
+		PodInterval:     duration(cfg.PodInterval),
+		ServiceInterval: duration(cfg.ServiceInterval),
+		PingInterval:    duration(cfg.PingInterval),
+		InitialDelay:    duration(cfg.InitialDelay),
+		Jitter:          duration(cfg.Jitter),
+		RateLimit:       cfg.RateLimit,
+		PingTimeout:     duration(cfg.PingTimeout),
+		DnsTimeout:      duration(cfg.DNSTimeout),
+	}
+}
+
+// ToModel 转换为探测配置
+func (x *ProbeConfig) ToModel() models.ProbeConfig {
+	return models.ProbeConfig{
+		Version:         x.GetVersion(),
+		TestInterval:    toDuration(x.GetTestInterval()),
+		TestTypes:       x.GetTestTypes(),
+		Protocols:       x.GetProtocols(),
+		HostPorts:       toInts(x.GetHostPorts()),
+		PodPorts:        toInts(x.GetPodPorts()),
+		ServiceName:     x.GetServiceName(),
+		ServicePorts:    toInts(x.GetServicePorts()),
+		MaxConcurrency:  int(x.GetMaxConcurrency()),
+		PingCount:       int(x.GetPingCount()),
+		Timeout:         toDuration(x.GetTimeout()),
+		UpdatedAt:       toTime(x.GetUpdatedAt()),
+		HostInterval:    toDuration(x.GetHostInterval()),
+		PodInterval:     toDuration(x.GetPodInterval()),
+		ServiceInterval: toDuration(x.GetServiceInterval()),
+		PingInterval:    toDuration(x.GetPingInterval()),
+		InitialDelay:    toDuration(x.GetInitialDelay()),
+		Jitter:          toDuration(x.GetJitter()),
+		RateLimit:       x.GetRateLimit(),
+		PingTimeout:     toDuration(x.GetPingTimeout()),
+		DNSTimeout:      toDuration(x.GetDnsTimeout()),
+	}
+}
+
+// FromHeartbeatResponse 将心跳响应转换为协议消息
+func FromHeartbeatResponse(response *models.HeartbeatResponse) *HeartbeatResponse {
+	message := &HeartbeatResponse{
+		Status:            response.Status,
+		Message:           response.Message,
+		HeartbeatInterval: duration(response.HeartbeatInterval),
+	}
+	if response.ProbeConfig != nil {
+		message.ProbeConfig = FromProbeConfig(response.ProbeConfig)
+	}
+	return message
+}
+
+// ToModel 转换为心跳响应
+func (x *HeartbeatResponse) ToModel() *models.HeartbeatResponse {
+	response := &models.HeartbeatResponse{
+		Status:            x.GetStatus(),
+		Message:           x.GetMessage(),
+		HeartbeatInterval: toDuration(x.GetHeartbeatInterval()),
+	}
+	if x.GetProbeConfig() != nil {
+		cfg := x.GetProbeConfig().ToModel()
+		response.ProbeConfig = &cfg
+	}
+	return response
+}
+
+// FromTargetShard 将目标分片范围转换为协议消息，shard 为nil时返回nil
+func FromTargetShard(shard *models.TargetShard) *TargetShard {
+	if shard == nil {
+		return nil
+	}
+	return &TargetShard{
+		Cycle:  shard.Cycle,
+		Index:  int32(shard.Index),
+		Count:  int32(shard.Count),
+		Total:  int32(shard.Total),
+		Pinned: int32(shard.Pinned),
+	}
+}
+
+// FromTarget 将探测目标转换为协议消息，target 为nil时返回nil
+func FromTarget(target *models.Target) *Target {
+	if target == nil {
+		return nil
+	}
+	return &Target{
+		Ip:       target.IP,
+		PodName:  target.PodName,
+		PodUid:   target.PodUID,
+		NodeName: target.NodeName,
+		NodeIp:   target.NodeIP,
+		Zone:     target.Zone,
+		Ports:    toInt32s(target.Ports),
+		Labels:   target.Labels,
+	}
+}
+
+// FromTargets 将探测目标列表转换为协议消息
+func FromTargets(targets []models.Target) []*Target {
+	messages := make([]*Target, 0, len(targets))
+	for i := range targets {
+		messages = append(messages, FromTarget(&targets[i]))
+	}
+	return messages
+}
+
+// ToModel 转换为探测目标
+func (x *Target) ToModel() models.Target {
+	return models.Target{
+		IP:       x.GetIp(),
+		PodName:  x.GetPodName(),
+		PodUID:   x.GetPodUid(),
+		NodeName: x.GetNodeName(),
+		NodeIP:   x.GetNodeIp(),
+		Zone:     x.GetZone(),
+		Ports:    toInts(x.GetPorts()),
+		Labels:   x.GetLabels(),
+	}
+}
+
+// ToTargets 将协议消息转换为探测目标列表
+func ToTargets(messages []*Target) []models.Target {
+	targets := make([]models.Target, 0, len(messages))
+	for _, message := range messages {
+		targets = append(targets, message.ToModel())
+	}
+	return targets
+}
+
+// FromConnectivityResults 将测试结果转换为协议消息
+func FromConnectivityResults(results []models.ConnectivityResult) []*ConnectivityResult {
+	messages := make([]*ConnectivityResult, 0, len(results))
+	for i := range results {
+		result := &results[i]
+		message := &ConnectivityResult{
+			SourceIp:      result.SourceIP,
+			TargetIp:      result.TargetIP,
+			PingStatus:    result.PingStatus,
+			Latency:       duration(result.Latency),
+			TestDuration:  duration(result.TestDuration),
+			Timestamp:     timestamp(result.Timestamp),
+			RunId:         result.RunID,
+			ConfigVersion: result.ConfigVersion,
+			Target:        FromTarget(result.Target),
+		}
+		if len(result.PortStatus) > 0 {
+			message.PortStatus = make(map[int32]string, len(result.PortStatus))
+			for port, status := range result.PortStatus {
+				message.PortStatus[int32(port)] = status
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// ToConnectivityResults 将协议消息转换为测试结果
+func ToConnectivityResults(messages []*ConnectivityResult) []models.ConnectivityResult {
+	results := make([]models.ConnectivityResult, 0, len(messages))
+	for _, message := range messages {
+		result := models.ConnectivityResult{
+			SourceIP:      message.GetSourceIp(),
+			TargetIP:      message.GetTargetIp(),
+			PingStatus:    message.GetPingStatus(),
+			Latency:       toDuration(message.GetLatency()),
+			TestDuration:  toDuration(message.GetTestDuration()),
+			Timestamp:     toTime(message.GetTimestamp()),
+			RunID:         message.GetRunId(),
+			ConfigVersion: message.GetConfigVersion(),
+		}
+		if message.GetTarget() != nil {
+			target := message.GetTarget().ToModel()
+			result.Target = &target
+		}
+		if len(message.GetPortStatus()) > 0 {
+			result.PortStatus = make(map[int]string, len(message.GetPortStatus()))
+			for port, status := range message.GetPortStatus() {
+				result.PortStatus[int(port)] = status
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// FromRunRequest 将按需测试任务转换为协议消息
+func FromRunRequest(run *models.RunRequest) *RunRequest {
+	return &RunRequest{
+		Id:        run.ID,
+		TestTypes: run.TestTypes,
+		CreatedAt: timestamp(run.CreatedAt),
+		Deadline:  timestamp(run.Deadline),
+		Cancel:    run.Cancel,
+	}
+}
+
+// ToModel 转换为按需测试任务
+func (x *RunRequest) ToModel() models.RunRequest {
+	return models.RunRequest{
+		ID:        x.GetId(),
+		TestTypes: x.GetTestTypes(),
+		CreatedAt: toTime(x.GetCreatedAt()),
+		Deadline:  toTime(x.GetDeadline()),
+		Cancel:    x.GetCancel(),
+	}
+}
+
+// timestamp 转换时间，零值转换为nil
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toTime 转换时间，nil转换为零值
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// duration 转换时长，0转换为nil
+func duration(d models.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(time.Duration(d))
+}
+
+// toDuration 转换时长，nil转换为0
+func toDuration(d *durationpb.Duration) models.Duration {
+	return models.Duration(d.AsDuration())
+}
+
+// toInt32s 转换端口列表
+func toInt32s(values []int) []int32 {
+	if values == nil {
+		return nil
+	}
+	result := make([]int32, len(values))
+	for i, v := range values {
+		result[i] = int32(v)
+	}
+	return result
+}
+
+// toInts 转换端口列表
+func toInts(values []int32) []int {
+	if values == nil {
+		return nil
+	}
+	result := make([]int, len(values))
+	for i, v := range values {
+		result[i] = int(v)
+	}
+	return result
+}
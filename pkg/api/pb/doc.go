@@ -0,0 +1,6 @@
+// Package pb 包含客户端与服务器之间 gRPC 协议的定义与生成代码，以及与 models 之间的转换
+// 协议与 REST 接口提供相同的能力：心跳、目标列表获取、测试结果上报，
+// 以及服务器通过流向客户端推送按需测试任务与探测配置变更
+package pb
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative checker.proto
@@ -0,0 +1,42 @@
+package pb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// SigningRequest 构造签名与校验 gRPC 调用使用的HTTP请求，使 auth.Signer 与 auth.Verifier 可用于 gRPC
+// 请求方法固定为 POST，路径为完整的 gRPC 方法名，请求头来自元数据；
+// 返回的请求体为确定性序列化的请求消息，参与签名计算
+func SigningRequest(fullMethod string, md metadata.MD, msg proto.Message) (*http.Request, []byte, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("序列化请求消息失败: %w", err)
+	}
+
+	req := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: fullMethod},
+		Header: make(http.Header, len(md)),
+	}
+	for key, values := range md {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, body, nil
+}
+
+// HeaderMetadata 将签名添加的请求头转换为 gRPC 元数据
+func HeaderMetadata(header http.Header) metadata.MD {
+	md := make(metadata.MD, len(header))
+	for key, values := range header {
+		md[strings.ToLower(key)] = values
+	}
+	return md
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/alert"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetAlerts 获取当前触发中的告警
+// GET /api/v1/alerts
+func (h *Handler) HandleGetAlerts(c *gin.Context) {
+	alerts := h.alertManager.GetAlerts()
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+// HandleGetSilences 获取所有生效中的静默规则
+// GET /api/v1/silences
+func (h *Handler) HandleGetSilences(c *gin.Context) {
+	silences := h.alertManager.GetSilences()
+
+	c.JSON(http.StatusOK, gin.H{
+		"silences": silences,
+		"count":    len(silences),
+	})
+}
+
+// HandleCreateSilence 创建静默规则
+// POST /api/v1/silences
+// 可通过 ends_at 指定结束时间，或通过 duration（如 "2h"）指定持续时间
+func (h *Handler) HandleCreateSilence(c *gin.Context) {
+	var request struct {
+		Matchers  map[string]string `json:"matchers" binding:"required"`
+		StartsAt  time.Time         `json:"starts_at"`
+		EndsAt    time.Time         `json:"ends_at"`
+		Duration  string            `json:"duration"`
+		CreatedBy string            `json:"created_by"`
+		Comment   string            `json:"comment"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的请求数据",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	silence := alert.Silence{
+		Matchers:  request.Matchers,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+		CreatedBy: request.CreatedBy,
+		Comment:   request.Comment,
+	}
+
+	if request.Duration != "" {
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil || duration <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的静默持续时间",
+				Details: request.Duration,
+			})
+			return
+		}
+		if silence.StartsAt.IsZero() {
+			silence.StartsAt = time.Now()
+		}
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	}
+
+	created, err := h.alertManager.AddSilence(silence)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "创建静默规则失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, created)
+}
+
+// HandleDeleteSilence 删除静默规则
+// DELETE /api/v1/silences/:id
+func (h *Handler) HandleDeleteSilence(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.alertManager.DeleteSilence(id); err != nil {
+		log.Printf("删除静默规则失败: %v", err)
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "静默规则不存在",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "静默规则已删除",
+	})
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/yezihack/k8snet-checker/pkg/auth"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/tlsutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIDKey 认证通过后客户端标识在gin.Context中的键
+const clientIDKey = "auth_client_id"
+
+// maxAuthBodySize 认证时读取的请求体大小上限
+const maxAuthBodySize = 10 << 20
+
+// authMiddleware 校验客户端上报请求的认证信息
+// 请求体参与签名计算，读取后重新放回供后续处理器解析；超过 maxAuthBodySize 时返回413
+func authMiddleware(verifier auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxAuthBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+				Code:    "REQUEST_TOO_LARGE",
+				Message: "请求体过大",
+				Details: fmt.Sprintf("请求体不能超过%d字节", tooLarge.Limit),
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "读取请求体失败",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		clientID, err := verifier.Verify(c.Request, body)
+		if err != nil {
+			log.Printf("请求认证失败: path=%s, remote=%s, error=%v", c.Request.URL.Path, c.ClientIP(), err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Code:    "UNAUTHORIZED",
+				Message: "认证失败",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		if clientID != "" {
+			c.Set(clientIDKey, clientID)
+		}
+		c.Next()
+	}
+}
+
+// clientCertMiddleware 要求请求提供经CA校验的客户端证书
+// TLS握手时客户端证书是可选的，这里只对上报接口强制要求
+func clientCertMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			log.Printf("请求缺少客户端证书: path=%s, remote=%s", c.Request.URL.Path, c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Code:    "UNAUTHORIZED",
+				Message: "认证失败",
+				Details: "需要有效的客户端证书",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkClientIdentity 校验客户端声明的Pod名称与签名的客户端标识及客户端证书一致
+// 不一致时返回403并返回false
+func (h *Handler) checkClientIdentity(c *gin.Context, podName string) bool {
+	if message := identityMismatch(c.GetString(clientIDKey), c.Request.TLS, podName); message != "" {
+		log.Printf("拒绝请求: %s, pod=%s, path=%s", message, podName, c.Request.URL.Path)
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: message,
+			Details: podName,
+		})
+		return false
+	}
+	return true
+}
+
+// identityMismatch 返回Pod名称与签名的客户端标识或客户端证书不一致的原因，一致时返回空字符串
+// 提供了经校验的客户端证书时，证书的 CN 或 SAN 必须包含Pod名称
+func identityMismatch(clientID string, state *tls.ConnectionState, podName string) string {
+	if clientID != "" && clientID != podName {
+		return "客户端标识与Pod名称不一致"
+	}
+	if state != nil && len(state.VerifiedChains) > 0 && !tlsutil.HasIdentity(state.PeerCertificates[0], podName) {
+		return "客户端证书与Pod名称不一致"
+	}
+	return ""
+}
+
+// checkSourceIP 校验上报的源IP属于已注册的客户端，防止伪造其他节点的结果
+// 经过HMAC认证的请求还要求源IP属于签名客户端本身
+// 校验失败时返回403并返回false；未启用校验时始终返回true
+func (h *Handler) checkSourceIP(c *gin.Context, sourceIP string) bool {
+	if !h.verifySourceIP {
+		return true
+	}
+
+	matched, err := h.sourceIPRegistered(c.GetString(clientIDKey), sourceIP)
+	if err != nil {
+		log.Printf("获取客户端列表失败: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "校验源IP失败",
+			Details: err.Error(),
+		})
+		return false
+	}
+
+	if !matched {
+		log.Printf("拒绝测试结果: 源IP %s 不属于已注册的客户端 (client_id=%s, remote=%s)",
+			sourceIP, c.GetString(clientIDKey), c.ClientIP())
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "源IP不属于已注册的客户端",
+			Details: sourceIP,
+		})
+		return false
+	}
+	return true
+}
+
+// sourceIPRegistered 判断源IP是否属于已注册的客户端
+// clientID 非空时（经过HMAC认证）要求源IP属于该客户端本身
+func (h *Handler) sourceIPRegistered(clientID, sourceIP string) (bool, error) {
+	clients, err := h.clientManager.GetAllClients()
+	if err != nil {
+		return false, err
+	}
+
+	if clientID != "" {
+		record, ok := clients[clientID]
+		return ok && (record.NodeInfo.PodIP == sourceIP || record.NodeInfo.NodeIP == sourceIP), nil
+	}
+	for _, record := range clients {
+		if record.NodeInfo.PodIP == sourceIP || record.NodeInfo.NodeIP == sourceIP {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAliveInterval SSE保活注释的发送间隔，避免代理因空闲断开连接
+var sseKeepAliveInterval = 15 * time.Second
+
+// HandleEventStream 以Server-Sent Events推送实时事件
+// GET /api/v1/events?type=result,state_change&test_type=pod&source=10.0.0.1&target=10.0.0.2
+// 各过滤参数支持逗号分隔多个取值；消费过慢时事件会被丢弃，并通过 dropped 事件告知丢弃总数
+func (h *Handler) HandleEventStream(c *gin.Context) {
+	filter := events.Filter{
+		Types:     splitQuery(c.Query("type")),
+		TestTypes: splitQuery(c.Query("test_type")),
+		Sources:   splitQuery(c.Query("source")),
+		Targets:   splitQuery(c.Query("target")),
+	}
+
+	sub := h.eventBus.Subscribe(filter, 0)
+	defer sub.Close()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	w.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	var reportedDropped uint64
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			// 先告知积压期间丢弃的事件数量
+			if dropped := sub.Dropped(); dropped > reportedDropped {
+				reportedDropped = dropped
+				fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("序列化事件失败: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+// splitQuery 拆分逗号分隔的查询参数
+func splitQuery(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/yezihack/k8snet-checker/pkg/api/pb"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// GRPCServer 定义gRPC服务器接口
+type GRPCServer interface {
+	Start(port string) error
+	Stop() error
+}
+
+// grpcServerImpl 是GRPCServer的实现，提供与HTTP上报接口相同的能力
+// 按需测试任务与探测配置变更通过 Watch 流实时推送给客户端
+type grpcServerImpl struct {
+	pb.UnimplementedCheckerServer
+
+	handler *Handler
+	server  *grpc.Server
+
+	mu       sync.Mutex
+	watchers map[chan models.ProbeConfig]struct{} // 订阅探测配置变更的 Watch 流
+}
+
+// clientIDContextKey 认证通过后客户端标识在上下文中的键
+type clientIDContextKey struct{}
+
+// NewGRPCServer 创建一个新的GRPCServer实例，与HTTP服务器使用相同的配置项
+// 只提供客户端上报接口，查询与管理接口仍通过HTTP提供
+func NewGRPCServer(clientManager client.ClientManager, resultManager result.TestResultManager, opts ...Option) GRPCServer {
+	handler := NewHandler(clientManager, resultManager)
+	for _, opt := range opts {
+		opt(handler)
+	}
+
+	s := &grpcServerImpl{
+		handler:  handler,
+		watchers: make(map[chan models.ProbeConfig]struct{}),
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamAuth),
+	}
+	if handler.tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(handler.tlsConfig)))
+	}
+	s.server = grpc.NewServer(serverOptions...)
+	pb.RegisterCheckerServer(s.server, s)
+
+	if handler.probeConfig != nil {
+		handler.probeConfig.AddObserver(s)
+	}
+	return s
+}
+
+// Start 启动gRPC服务器
+func (s *grpcServerImpl) Start(port string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("监听端口失败: %w", err)
+	}
+
+	log.Printf("gRPC服务器启动在端口: %s", port)
+	return s.server.Serve(listener)
+}
+
+// Stop 停止gRPC服务器
+// Watch 流不会主动结束，因此直接关闭所有连接，客户端重连后继续
+func (s *grpcServerImpl) Stop() error {
+	s.server.Stop()
+	return nil
+}
+
+// Heartbeat 处理心跳上报
+func (s *grpcServerImpl) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	nodeInfo := req.GetNode().ToModel()
+	if nodeInfo.PodName == "" || nodeInfo.NodeIP == "" || nodeInfo.PodIP == "" {
+		return nil, status.Error(codes.InvalidArgument, "缺少必需字段: PodName, NodeIP, PodIP不能为空")
+	}
+	if err := s.checkClientIdentity(ctx, nodeInfo.PodName); err != nil {
+		return nil, err
+	}
+
+	if err := s.handler.clientManager.HandleHeartbeat(&nodeInfo); err != nil {
+		log.Printf("处理心跳失败: %v", err)
+		return nil, status.Errorf(codes.Internal, "处理心跳失败: %v", err)
+	}
+
+	response := s.handler.heartbeatResponse(&nodeInfo, "心跳接收成功")
+	return pb.FromHeartbeatResponse(&response), nil
+}
+
+// GetTargets 获取目标列表，启用目标分片且携带Pod名称时只返回本轮应测试的目标
+func (s *grpcServerImpl) GetTargets(ctx context.Context, req *pb.GetTargetsRequest) (*pb.GetTargetsResponse, error) {
+	if req.GetTestType() != models.TestTypeHost && req.GetTestType() != models.TestTypePod {
+		return nil, status.Errorf(codes.InvalidArgument, "无效的目标类型: %s", req.GetTestType())
+	}
+
+	targets, err := s.handler.syncTargets(req.GetPodName(), req.GetTestType())
+	if err != nil {
+		log.Printf("获取目标列表失败: type=%s, error=%v", req.GetTestType(), err)
+		return nil, status.Errorf(codes.Internal, "获取目标列表失败: %v", err)
+	}
+	return &pb.GetTargetsResponse{Ips: targets.IPs, Shard: pb.FromTargetShard(targets.Shard), Targets: pb.FromTargets(targets.Targets)}, nil
+}
+
+// ReportResults 处理测试结果上报
+func (s *grpcServerImpl) ReportResults(ctx context.Context, req *pb.ReportResultsRequest) (*pb.ReportResultsResponse, error) {
+	if req.GetSourceIp() == "" || len(req.GetResults()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "缺少源IP或测试结果")
+	}
+	if !validResultType(req.GetTestType()) {
+		return nil, status.Errorf(codes.InvalidArgument, "无效的测试类型: %s", req.GetTestType())
+	}
+	if err := s.checkSourceIP(ctx, req.GetSourceIp()); err != nil {
+		return nil, err
+	}
+
+	results := pb.ToConnectivityResults(req.GetResults())
+	if err := s.handler.saveResults(req.GetTestType(), req.GetSourceIp(), results); err != nil {
+		log.Printf("保存测试结果失败: type=%s, error=%v", req.GetTestType(), err)
+		return nil, status.Errorf(codes.Internal, "保存测试结果失败: %v", err)
+	}
+
+	log.Printf("测试结果保存成功: type=%s, source_ip=%s, results_count=%d",
+		req.GetTestType(), req.GetSourceIp(), len(results))
+	return &pb.ReportResultsResponse{}, nil
+}
+
+// CompleteRun 客户端报告已完成测试任务
+func (s *grpcServerImpl) CompleteRun(ctx context.Context, req *pb.CompleteRunRequest) (*pb.CompleteRunResponse, error) {
+	if s.handler.runManager == nil {
+		return nil, status.Error(codes.Unimplemented, "未启用按需测试")
+	}
+	if req.GetPodName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "缺少必需字段: PodName不能为空")
+	}
+	if err := s.checkClientIdentity(ctx, req.GetPodName()); err != nil {
+		return nil, err
+	}
+
+	if err := s.handler.runManager.Complete(req.GetRunId(), req.GetPodName()); err != nil {
+		code := codes.InvalidArgument
+		if errors.Is(err, runs.ErrNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Errorf(code, "更新测试任务失败: %v", err)
+	}
+	return &pb.CompleteRunResponse{}, nil
+}
+
+// Watch 向客户端推送按需测试任务与探测配置变更，直到客户端断开连接
+func (s *grpcServerImpl) Watch(req *pb.WatchRequest, stream pb.Checker_WatchServer) error {
+	podName := req.GetPodName()
+	if podName == "" {
+		return status.Error(codes.InvalidArgument, "缺少必需字段: PodName不能为空")
+	}
+	ctx := stream.Context()
+	if err := s.checkClientIdentity(ctx, podName); err != nil {
+		return err
+	}
+
+	configs := s.subscribe()
+	defer s.unsubscribe(configs)
+
+	// 客户端的配置版本与服务器不一致时先推送当前配置，版本为0时客户端恢复初始配置
+	if s.handler.probeConfig != nil {
+		if cfg := s.handler.probeConfig.Get(); cfg.Version != req.GetProbeConfigVersion() {
+			if err := stream.Send(&pb.WatchEvent{Event: &pb.WatchEvent_ProbeConfig{ProbeConfig: pb.FromProbeConfig(&cfg)}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	runRequests := make(chan []models.RunRequest)
+	if s.handler.runManager != nil {
+		go func() {
+			for {
+				requests := s.handler.runManager.Poll(ctx, podName)
+				select {
+				case runRequests <- requests:
+				case <-ctx.Done():
+					// 流已结束，任务未送达客户端，重新放回待下发队列
+					s.handler.runManager.Requeue(podName, requests)
+					return
+				}
+			}
+		}()
+	}
+
+	log.Printf("客户端已订阅推送: pod=%s", podName)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("客户端已取消订阅推送: pod=%s", podName)
+			return nil
+		case cfg := <-configs:
+			if err := stream.Send(&pb.WatchEvent{Event: &pb.WatchEvent_ProbeConfig{ProbeConfig: pb.FromProbeConfig(&cfg)}}); err != nil {
+				return err
+			}
+		case requests := <-runRequests:
+			log.Printf("下发按需测试任务: pod=%s, count=%d", podName, len(requests))
+			for i := range requests {
+				if err := stream.Send(&pb.WatchEvent{Event: &pb.WatchEvent_Run{Run: pb.FromRunRequest(&requests[i])}}); err != nil {
+					s.handler.runManager.Requeue(podName, requests[i:])
+					return err
+				}
+			}
+		}
+	}
+}
+
+// OnProbeConfig 实现 probeconfig.Observer，将配置变更推送给所有 Watch 流
+// 每个流只保留最新的一份未发送配置
+func (s *grpcServerImpl) OnProbeConfig(cfg models.ProbeConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for watcher := range s.watchers {
+		select {
+		case <-watcher:
+		default:
+		}
+		watcher <- cfg
+	}
+}
+
+// subscribe 订阅探测配置变更
+func (s *grpcServerImpl) subscribe() chan models.ProbeConfig {
+	watcher := make(chan models.ProbeConfig, 1)
+	s.mu.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.mu.Unlock()
+	return watcher
+}
+
+// unsubscribe 取消订阅探测配置变更
+func (s *grpcServerImpl) unsubscribe(watcher chan models.ProbeConfig) {
+	s.mu.Lock()
+	delete(s.watchers, watcher)
+	s.mu.Unlock()
+}
+
+// checkClientIdentity 校验客户端声明的Pod名称与签名的客户端标识及客户端证书一致
+func (s *grpcServerImpl) checkClientIdentity(ctx context.Context, podName string) error {
+	if message := identityMismatch(clientIDFromContext(ctx), peerTLSState(ctx), podName); message != "" {
+		log.Printf("拒绝请求: %s, pod=%s", message, podName)
+		return status.Error(codes.PermissionDenied, message)
+	}
+	return nil
+}
+
+// checkSourceIP 启用源IP校验时，校验上报的源IP属于已注册的客户端
+func (s *grpcServerImpl) checkSourceIP(ctx context.Context, sourceIP string) error {
+	if !s.handler.verifySourceIP {
+		return nil
+	}
+
+	clientID := clientIDFromContext(ctx)
+	matched, err := s.handler.sourceIPRegistered(clientID, sourceIP)
+	if err != nil {
+		log.Printf("获取客户端列表失败: %v", err)
+		return status.Errorf(codes.Internal, "校验源IP失败: %v", err)
+	}
+	if !matched {
+		log.Printf("拒绝测试结果: 源IP %s 不属于已注册的客户端 (client_id=%s)", sourceIP, clientID)
+		return status.Errorf(codes.PermissionDenied, "源IP不属于已注册的客户端: %s", sourceIP)
+	}
+	return nil
+}
+
+// authenticate 校验调用的客户端证书与认证信息，返回携带客户端标识的上下文
+func (s *grpcServerImpl) authenticate(ctx context.Context, fullMethod string, msg any) (context.Context, error) {
+	if s.handler.requireClientCert {
+		if state := peerTLSState(ctx); state == nil || len(state.VerifiedChains) == 0 {
+			log.Printf("请求缺少客户端证书: method=%s", fullMethod)
+			return nil, status.Error(codes.Unauthenticated, "认证失败: 需要有效的客户端证书")
+		}
+	}
+	if s.handler.authVerifier == nil {
+		return ctx, nil
+	}
+
+	message, ok := msg.(proto.Message)
+	if !ok {
+		return nil, status.Error(codes.Internal, "无法校验非 protobuf 消息")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	req, body, err := pb.SigningRequest(fullMethod, md, message)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	clientID, err := s.handler.authVerifier.Verify(req, body)
+	if err != nil {
+		log.Printf("请求认证失败: method=%s, error=%v", fullMethod, err)
+		return nil, status.Errorf(codes.Unauthenticated, "认证失败: %v", err)
+	}
+	if clientID != "" {
+		ctx = context.WithValue(ctx, clientIDContextKey{}, clientID)
+	}
+	return ctx, nil
+}
+
+// unaryAuth 校验一元调用的认证信息
+func (s *grpcServerImpl) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth 校验流式调用的认证信息，签名覆盖客户端发送的第一条消息
+func (s *grpcServerImpl) streamAuth(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &authServerStream{ServerStream: stream, server: s, method: info.FullMethod, ctx: stream.Context()})
+}
+
+// authServerStream 在收到第一条消息时完成认证
+type authServerStream struct {
+	grpc.ServerStream
+	server        *grpcServerImpl
+	method        string
+	ctx           context.Context
+	authenticated bool
+}
+
+// Context 返回携带客户端标识的上下文
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg 接收消息，第一条消息需通过认证
+func (s *authServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authenticated {
+		return nil
+	}
+
+	ctx, err := s.server.authenticate(s.ctx, s.method, m)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	s.authenticated = true
+	return nil
+}
+
+// clientIDFromContext 返回认证通过的客户端标识，Token 方式或未启用认证时为空
+func clientIDFromContext(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientIDContextKey{}).(string)
+	return clientID
+}
+
+// peerTLSState 返回调用方的TLS连接状态，未使用TLS时返回nil
+func peerTLSState(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return &info.State
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	apiclient "github.com/yezihack/k8snet-checker/pkg/api/client"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startGRPCServer 在内存连接上启动gRPC服务器，返回创建客户端的函数
+func startGRPCServer(t *testing.T, clientManager client.ClientManager, resultManager result.TestResultManager, opts ...Option) func(sourceIP string, opts ...apiclient.Option) apiclient.APIClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := NewGRPCServer(clientManager, resultManager, opts...).(*grpcServerImpl)
+	go grpcServer.server.Serve(listener)
+	t.Cleanup(func() { grpcServer.Stop() })
+
+	return func(sourceIP string, opts ...apiclient.Option) apiclient.APIClient {
+		opts = append(opts,
+			apiclient.WithRetryPolicy(1, time.Millisecond, time.Millisecond),
+			apiclient.WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			})),
+		)
+		apiClient, err := apiclient.NewGRPCClient("passthrough:///bufnet", sourceIP, opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { apiClient.(interface{ Close() error }).Close() })
+		return apiClient
+	}
+}
+
+// TestGRPCServer 测试通过gRPC完成心跳、目标获取、结果上报与按需测试任务及探测配置的推送
+func TestGRPCServer(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	runManager := runs.NewManager(clientManager, 0)
+	resultManager.AddObserver(runManager)
+	serverStore, err := probeconfig.NewStore(probeconfig.Default())
+	require.NoError(t, err)
+	clientStore, err := probeconfig.NewStore(probeconfig.Default())
+	require.NoError(t, err)
+
+	newClient := startGRPCServer(t, clientManager, resultManager,
+		WithRunManager(runManager),
+		WithProbeConfig(serverStore),
+		WithHeartbeatInterval(3*time.Second),
+	)
+	apiClient := newClient("10.0.0.1", apiclient.WithPodName("pod-1"), apiclient.WithProbeConfig(clientStore))
+
+	response, err := apiClient.SendHeartbeat(&models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, models.Duration(3*time.Second), response.HeartbeatInterval)
+	assert.Nil(t, response.ProbeConfig)
+
+	hostIPs, err := apiClient.GetHostIPs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.1"}, hostIPs)
+
+	// 上报的结果与HTTP接口保存到相同的位置
+	err = apiClient.ReportPodTestResults([]models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.1", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}, Latency: models.Duration(time.Millisecond)},
+	})
+	require.NoError(t, err)
+	podResults, err := resultManager.GetPodTestResults()
+	require.NoError(t, err)
+	assert.Equal(t, "open", podResults["10.0.0.1"]["10.0.0.1"].PortStatus)
+
+	// 建立推送连接后创建任务，客户端通过推送收到
+	pending, err := apiClient.PollRuns("pod-1", 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	run, err := runManager.Create(nil, []string{models.TestTypePod})
+	require.NoError(t, err)
+	pending, err = apiClient.PollRuns("pod-1", 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, run.ID, pending[0].ID)
+	assert.Equal(t, run.Deadline.Unix(), pending[0].Deadline.Unix())
+
+	require.NoError(t, apiClient.CompleteRun(run.ID, "pod-1"))
+	assert.True(t, apiclient.IsPermanent(apiClient.CompleteRun("missing", "pod-1")))
+
+	// 探测配置变更实时推送给客户端
+	cfg := probeconfig.Default()
+	cfg.PodPorts = []int{6100, 8080}
+	cfg.Timeout = models.Duration(3 * time.Second)
+	updated, err := serverStore.Update(cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return clientStore.Get().Version == updated.Version
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{6100, 8080}, clientStore.Get().PodPorts)
+	assert.Equal(t, models.Duration(3*time.Second), clientStore.Get().Timeout)
+}
+
+// TestGRPCServerAuth 测试gRPC调用的签名认证、客户端标识与源IP校验
+func TestGRPCServerAuth(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	runManager := runs.NewManager(clientManager, 0)
+	verifier, err := auth.NewServerVerifier(auth.ModeHMAC, "", "", "master")
+	require.NoError(t, err)
+
+	newClient := startGRPCServer(t, clientManager, resultManager,
+		WithAuth(verifier),
+		WithSourceIPVerification(),
+		WithRunManager(runManager),
+	)
+	pod1 := auth.NewHMACSigner("pod-1", auth.DeriveKey("master", "pod-1"))
+	heartbeat1 := &models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+	results := []models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable"}}
+
+	// 未签名的调用被拒绝
+	var statusErr *apiclient.StatusError
+	_, err = newClient("10.0.0.1").SendHeartbeat(heartbeat1)
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 401, statusErr.StatusCode)
+
+	// 使用他人的标识发送心跳
+	signed := newClient("10.0.0.1", apiclient.WithSigner(pod1))
+	_, err = signed.SendHeartbeat(&models.NodeInfo{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"})
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 403, statusErr.StatusCode)
+
+	_, err = signed.SendHeartbeat(heartbeat1)
+	require.NoError(t, err)
+	require.NoError(t, signed.ReportPodTestResults(results))
+
+	// 源IP不属于签名客户端
+	forged := newClient("10.0.0.9", apiclient.WithSigner(pod1))
+	err = forged.ReportPodTestResults(results)
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 403, statusErr.StatusCode)
+
+	// 推送连接同样需要签名
+	run, err := runManager.Create([]string{"pod-1"}, nil)
+	require.NoError(t, err)
+	pending, err := signed.PollRuns("pod-1", 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, run.ID, pending[0].ID)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDecompressedBodySize 解压后请求体的大小上限，防止压缩炸弹
+const maxDecompressedBodySize = 64 << 20
+
+// gzipMiddleware 解压 Content-Encoding 为 gzip 的请求体，并在客户端接受 gzip 时压缩响应
+// 需放在认证中间件之后，签名基于传输的（压缩后的）请求体计算
+func gzipMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+			reader, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+					Code:    "INVALID_REQUEST",
+					Message: "解压请求体失败",
+					Details: err.Error(),
+				})
+				return
+			}
+			defer reader.Close()
+			c.Request.Body = http.MaxBytesReader(c.Writer, reader, maxDecompressedBodySize)
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		}
+
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		c.Header("Content-Encoding", "gzip")
+		c.Header("Vary", "Accept-Encoding")
+		writer := gzip.NewWriter(c.Writer)
+		defer writer.Close()
+		c.Writer = &gzipResponseWriter{ResponseWriter: c.Writer, writer: writer}
+		c.Next()
+	}
+}
+
+// gzipResponseWriter 将响应体写入 gzip 压缩流
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	writer *gzip.Writer
+}
+
+// Write 压缩写入响应体
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.writer.Write(data)
+}
+
+// WriteString 压缩写入字符串响应体
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.writer.Write([]byte(s))
+}
@@ -1,12 +1,27 @@
 package server
 
 import (
+	"crypto/tls"
+	"errors"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/alert"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/events"
+	"github.com/yezihack/k8snet-checker/pkg/latency"
 	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+	"github.com/yezihack/k8snet-checker/pkg/report"
 	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+	"github.com/yezihack/k8snet-checker/pkg/sharding"
+	"github.com/yezihack/k8snet-checker/pkg/slo"
+	"github.com/yezihack/k8snet-checker/pkg/targets"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +30,28 @@ import (
 type Handler struct {
 	clientManager client.ClientManager
 	resultManager result.TestResultManager
+	alertManager  alert.Manager // 可选，为nil时不注册告警接口
+
+	reportGenerator report.ReportGenerator // 可选，为nil时不注册实时报告接口
+	reportHistory   report.History         // 可选，为nil时不注册历史报告接口
+	eventBus        events.Bus             // 可选，为nil时不注册事件流接口
+	dashboard       bool                   // 是否提供内置仪表盘
+	runManager      runs.Manager           // 可选，为nil时不注册按需测试接口
+	probeConfig     probeconfig.Store      // 可选，为nil时不下发探测配置
+	planner         sharding.Planner       // 可选，为nil时每个客户端测试全部目标
+	targetTracker   targets.Tracker        // 可选，为nil时目标列表不带版本标识
+	sloTracker      slo.Tracker            // 可选，为nil时不注册可用性统计接口
+	metricsHandler  http.Handler           // 可选，为nil时不提供 /metrics
+	latencyDetector latency.Detector       // 可选，为nil时不注册延迟基线接口
+
+	heartbeatInterval time.Duration // 建议客户端使用的心跳间隔，为0时不建议
+
+	authVerifier   auth.Verifier // 可选，为nil时上报接口不要求认证
+	adminVerifier  auth.Verifier // 可选，为nil时管理接口与上报接口使用相同的认证
+	verifySourceIP bool          // 是否校验上报的源IP属于已注册客户端
+
+	tlsConfig         *tls.Config // 可选，为nil时使用HTTP
+	requireClientCert bool        // 上报接口是否要求客户端证书
 }
 
 // NewHandler 创建处理器实例
@@ -49,6 +86,10 @@ func (h *Handler) HandleHeartbeat(c *gin.Context) {
 		return
 	}
 
+	if !h.checkClientIdentity(c, nodeInfo.PodName) {
+		return
+	}
+
 	if err := h.clientManager.HandleHeartbeat(&nodeInfo); err != nil {
 		log.Printf("处理心跳失败: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -59,10 +100,25 @@ func (h *Handler) HandleHeartbeat(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "心跳接收成功",
-	})
+	c.JSON(http.StatusOK, h.heartbeatResponse(&nodeInfo, "心跳接收成功"))
+}
+
+// heartbeatResponse 构造心跳响应
+// 客户端的配置版本与服务器不一致时下发最新配置；服务器未设置配置（版本为0）而客户端仍在使用之前下发的配置时，
+// 下发版本为0的配置，客户端据此恢复各自的初始配置，例如服务器重启后丢失了通过接口设置的配置
+func (h *Handler) heartbeatResponse(nodeInfo *models.NodeInfo, message string) models.HeartbeatResponse {
+	response := models.HeartbeatResponse{
+		Status:  "success",
+		Message: message,
+
+		HeartbeatInterval: models.Duration(h.heartbeatInterval),
+	}
+	if h.probeConfig != nil {
+		if cfg := h.probeConfig.Get(); cfg.Version != nodeInfo.ProbeConfigVersion {
+			response.ProbeConfig = &cfg
+		}
+	}
+	return response
 }
 
 // HandleHostTestResults 处理宿主机测试结果上报
@@ -83,6 +139,10 @@ func (h *Handler) HandleHostTestResults(c *gin.Context) {
 		return
 	}
 
+	if !h.checkSourceIP(c, request.SourceIP) {
+		return
+	}
+
 	if err := h.resultManager.SaveHostTestResults(request.SourceIP, request.Results); err != nil {
 		log.Printf("保存宿主机测试结果失败: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -120,6 +180,10 @@ func (h *Handler) HandlePodTestResults(c *gin.Context) {
 		return
 	}
 
+	if !h.checkSourceIP(c, request.SourceIP) {
+		return
+	}
+
 	if err := h.resultManager.SavePodTestResults(request.SourceIP, request.Results); err != nil {
 		log.Printf("保存Pod测试结果失败: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -157,6 +221,10 @@ func (h *Handler) HandleServiceTestResults(c *gin.Context) {
 		return
 	}
 
+	if !h.checkSourceIP(c, request.SourceIP) {
+		return
+	}
+
 	if err := h.resultManager.SaveServiceTestResult(request.SourceIP, &request.Result); err != nil {
 		log.Printf("保存服务测试结果失败: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -179,6 +247,19 @@ func (h *Handler) HandleServiceTestResults(c *gin.Context) {
 // HandleGetHosts 获取所有宿主机IP列表
 // GET /api/v1/hosts
 func (h *Handler) HandleGetHosts(c *gin.Context) {
+	if targets, shard, ok := h.assignTargets(c.Query("pod_name"), models.TestTypeHost); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"host_ips": targets,
+			"count":    len(targets),
+			"shard":    shard,
+		})
+		return
+	}
+	if h.targetTracker != nil {
+		h.writeTargetList(c, models.TestTypeHost, "host_ips", "宿主机IP列表", false)
+		return
+	}
+
 	hostIPs, err := h.clientManager.GetAllHostIPs()
 	if err != nil {
 		log.Printf("获取宿主机IP列表失败: %v", err)
@@ -199,6 +280,19 @@ func (h *Handler) HandleGetHosts(c *gin.Context) {
 // HandleGetPods 获取所有Pod IP列表
 // GET /api/v1/pods
 func (h *Handler) HandleGetPods(c *gin.Context) {
+	if targets, shard, ok := h.assignTargets(c.Query("pod_name"), models.TestTypePod); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"pod_ips": targets,
+			"count":   len(targets),
+			"shard":   shard,
+		})
+		return
+	}
+	if h.targetTracker != nil {
+		h.writeTargetList(c, models.TestTypePod, "pod_ips", "Pod IP列表", false)
+		return
+	}
+
 	podIPs, err := h.clientManager.GetAllPodIPs()
 	if err != nil {
 		log.Printf("获取Pod IP列表失败: %v", err)
@@ -216,6 +310,151 @@ func (h *Handler) HandleGetPods(c *gin.Context) {
 	})
 }
 
+// HandleGetTargets 获取探测目标列表，目标包含IP、Pod名称、节点、可用区、探测端口与标签
+// GET /api/v1/targets/:type，type 取值为 host 或 pod；分片与版本标识的行为与 /hosts、/pods 相同
+func (h *Handler) HandleGetTargets(c *gin.Context) {
+	testType := c.Param("type")
+	if testType != models.TestTypeHost && testType != models.TestTypePod {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的目标类型: " + testType,
+		})
+		return
+	}
+
+	ips, shard, sharded := h.assignTargets(c.Query("pod_name"), testType)
+	if !sharded && h.targetTracker != nil {
+		h.writeTargetList(c, testType, "targets", "探测目标列表", true)
+		return
+	}
+
+	list, err := h.targetObjects(testType, ips)
+	if err != nil {
+		log.Printf("获取探测目标列表失败: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "获取探测目标列表失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"targets": list,
+		"count":   len(list),
+	}
+	if shard != nil {
+		response["shard"] = shard
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// targetObjects 返回指定IP的探测目标，ips 为nil时返回全部目标
+func (h *Handler) targetObjects(testType string, ips []string) ([]models.Target, error) {
+	records, err := h.clientManager.GetAllClients()
+	if err != nil {
+		return nil, err
+	}
+	all := targets.FromClients(records, testType)
+	if ips == nil {
+		return h.withPorts(testType, all), nil
+	}
+
+	byIP := make(map[string]models.Target, len(all))
+	for _, target := range all {
+		byIP[target.IP] = target
+	}
+	result := make([]models.Target, 0, len(ips))
+	for _, ip := range ips {
+		if target, ok := byIP[ip]; ok {
+			result = append(result, target)
+		} else {
+			result = append(result, models.Target{IP: ip})
+		}
+	}
+	return h.withPorts(testType, result), nil
+}
+
+// withPorts 服务器设置了探测配置时，为目标填写该测试类型的探测端口
+func (h *Handler) withPorts(testType string, list []models.Target) []models.Target {
+	if h.probeConfig == nil {
+		return list
+	}
+	cfg := h.probeConfig.Get()
+	if cfg.Version == 0 {
+		return list
+	}
+	ports := cfg.PodPorts
+	if testType == models.TestTypeHost {
+		ports = cfg.HostPorts
+	}
+	for i := range list {
+		list[i].Ports = ports
+	}
+	return list
+}
+
+// writeTargetList 返回带版本标识的目标列表，版本标识同时通过 ETag 响应头返回
+// If-None-Match 与当前版本相同时返回 304；携带 since 参数时返回自该版本以来的增量变更（added、removed），
+// 服务器已不保留该版本的变更记录时返回完整列表。structured 为 true 时返回目标对象，否则只返回IP
+func (h *Handler) writeTargetList(c *gin.Context, testType, field, name string, structured bool) {
+	list, err := h.targetTracker.Lookup(testType, c.Query("since"))
+	if err != nil {
+		log.Printf("获取%s失败: %v", name, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "获取" + name + "失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", `"`+list.ETag+`"`)
+	etag := strings.TrimPrefix(c.GetHeader("If-None-Match"), "W/")
+	if list.NotModified(strings.Trim(etag, `"`)) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response := gin.H{
+		"etag":    list.ETag,
+		"version": list.Version,
+		"count":   list.Count,
+	}
+	switch {
+	case list.Delta && structured:
+		response["delta"] = true
+		response["added"] = h.withPorts(testType, list.Added)
+		response["removed"] = list.Removed
+	case list.Delta:
+		response["delta"] = true
+		response["added"] = models.TargetIPs(list.Added)
+		response["removed"] = list.Removed
+	case structured:
+		response[field] = h.withPorts(testType, list.Targets)
+	default:
+		response[field] = models.TargetIPs(list.Targets)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// assignTargets 启用目标分片且请求携带Pod名称时，返回客户端本轮应测试的目标
+// 客户端尚未注册或分片失败时返回 false，调用方返回全部目标
+func (h *Handler) assignTargets(podName, testType string) ([]string, *models.TargetShard, bool) {
+	if h.planner == nil || podName == "" {
+		return nil, nil, false
+	}
+
+	targets, shard, err := h.planner.Assign(podName, testType)
+	if err != nil {
+		if !errors.Is(err, sharding.ErrUnknownClient) {
+			log.Printf("分配测试目标失败: pod=%s, type=%s, error=%v", podName, testType, err)
+		}
+		return nil, nil, false
+	}
+	return targets, shard, true
+}
+
 // HandleGetHostTestResults 获取宿主机互探结果
 // GET /api/v1/test-results/hosts
 func (h *Handler) HandleGetHostTestResults(c *gin.Context) {
@@ -292,6 +531,64 @@ func (h *Handler) HandleGetClientCount(c *gin.Context) {
 	})
 }
 
+// ClientStatus 客户端状态，用于客户端列表展示
+type ClientStatus struct {
+	PodName             string    `json:"pod_name"`
+	Namespace           string    `json:"namespace"`
+	NodeIP              string    `json:"node_ip"`
+	PodIP               string    `json:"pod_ip"`
+	Version             int64     `json:"version"`
+	VersionLag          int64     `json:"version_lag"`
+	LastHeartbeat       time.Time `json:"last_heartbeat"`
+	HeartbeatAgeSeconds float64   `json:"heartbeat_age_seconds"`
+}
+
+// HandleGetClients 获取所有已注册客户端及其心跳状态
+// GET /api/v1/clients
+// version_lag 为客户端版本号与最新版本号之差，越大表示越久未上报心跳
+func (h *Handler) HandleGetClients(c *gin.Context) {
+	records, err := h.clientManager.GetAllClients()
+	if err != nil {
+		log.Printf("获取客户端列表失败: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "获取客户端列表失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var latestVersion int64
+	for _, record := range records {
+		if record.Version > latestVersion {
+			latestVersion = record.Version
+		}
+	}
+
+	now := time.Now()
+	clients := make([]ClientStatus, 0, len(records))
+	for _, record := range records {
+		clients = append(clients, ClientStatus{
+			PodName:             record.NodeInfo.PodName,
+			Namespace:           record.NodeInfo.Namespace,
+			NodeIP:              record.NodeInfo.NodeIP,
+			PodIP:               record.NodeInfo.PodIP,
+			Version:             record.Version,
+			VersionLag:          latestVersion - record.Version,
+			LastHeartbeat:       record.LastHeartbeat,
+			HeartbeatAgeSeconds: now.Sub(record.LastHeartbeat).Seconds(),
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].PodName < clients[j].PodName
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"clients": clients,
+		"count":   len(clients),
+	})
+}
+
 // HandleGetAllResults 获取所有测试结果汇总
 // GET /api/v1/results
 func (h *Handler) HandleGetAllResults(c *gin.Context) {
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetLatency 获取各探测对的延迟基线与当前延迟异常
+// GET /api/v1/latency
+// anomalies=true 时只返回延迟异常，不返回基线明细
+func (h *Handler) HandleGetLatency(c *gin.Context) {
+	anomaliesOnly := false
+	if value := c.Query("anomalies"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的anomalies参数",
+				Details: value,
+			})
+			return
+		}
+		anomaliesOnly = parsed
+	}
+
+	report := models.LatencyReport{
+		Baselines: []models.PairLatency{},
+		Anomalies: h.latencyDetector.Anomalies(),
+	}
+	if !anomaliesOnly {
+		report.Baselines = h.latencyDetector.Baselines()
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetProbeConfig 获取当前探测配置
+// GET /api/v1/probe-config
+func (h *Handler) HandleGetProbeConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.probeConfig.Get())
+}
+
+// HandleUpdateProbeConfig 替换探测配置，版本号自动递增，客户端在下一次心跳时获取
+// PUT /api/v1/probe-config
+// 请求中未设置的字段使用默认值
+func (h *Handler) HandleUpdateProbeConfig(c *gin.Context) {
+	cfg := probeconfig.Default()
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的请求数据",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.probeConfig.Update(cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的探测配置",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/report"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetReport 实时生成网络连通性报告
+// GET /api/v1/report
+// 通过 ?format= 或 Accept 请求头选择格式，默认JSON
+func (h *Handler) HandleGetReport(c *gin.Context) {
+	networkReport, err := h.reportGenerator.GenerateReport()
+	if err != nil {
+		log.Printf("生成报告失败: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "生成报告失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	h.renderReport(c, networkReport)
+}
+
+// HandleListReports 获取历史报告列表
+// GET /api/v1/reports
+func (h *Handler) HandleListReports(c *gin.Context) {
+	entries := h.reportHistory.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports": entries,
+		"count":   len(entries),
+	})
+}
+
+// HandleGetHistoryReport 获取指定的历史报告
+// GET /api/v1/reports/:id
+// id 为 latest 时返回最新报告，格式选择与 /report 相同
+func (h *Handler) HandleGetHistoryReport(c *gin.Context) {
+	id := c.Param("id")
+
+	networkReport, ok := h.reportHistory.Get(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "报告不存在",
+			Details: id,
+		})
+		return
+	}
+
+	h.renderReport(c, networkReport)
+}
+
+// HandleDiffReports 对比两份报告
+// GET /api/v1/reports/diff?from=<id>&to=<id>
+// to 默认为 latest，from 默认为 to 之前的一份历史报告；id 为 current 时实时生成报告
+// 可通过 latency_threshold（如 "50ms"）和 latency_ratio 调整延迟劣化判定阈值
+func (h *Handler) HandleDiffReports(c *gin.Context) {
+	renderer, err := report.NegotiateDiffRenderer(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "不支持的报告格式",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	opts := report.DefaultDiffOptions
+	if threshold := c.Query("latency_threshold"); threshold != "" {
+		value, err := time.ParseDuration(threshold)
+		if err != nil || value < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的latency_threshold",
+				Details: threshold,
+			})
+			return
+		}
+		opts.LatencyThreshold = value
+	}
+	if ratio := c.Query("latency_ratio"); ratio != "" {
+		value, err := strconv.ParseFloat(ratio, 64)
+		if err != nil || value < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的latency_ratio",
+				Details: ratio,
+			})
+			return
+		}
+		opts.LatencyRatio = value
+	}
+
+	toID := c.DefaultQuery("to", "latest")
+	to, ok := h.lookupReport(toID)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "报告不存在",
+			Details: toID,
+		})
+		return
+	}
+
+	fromID := c.Query("from")
+	if fromID == "" {
+		fromID = h.previousReportID(to.ID)
+	}
+	from, ok := h.lookupReport(fromID)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "用于对比的报告不存在",
+			Details: fromID,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.RenderDiff(&buf, report.Diff(from, to, opts)); err != nil {
+		log.Printf("渲染%s报告差异失败: %v", renderer.Format(), err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "渲染报告差异失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Vary", "Accept")
+	c.Data(http.StatusOK, renderer.ContentType(), buf.Bytes())
+}
+
+// lookupReport 根据ID查找报告，current 表示实时生成
+func (h *Handler) lookupReport(id string) (*models.NetworkReport, bool) {
+	if id == "current" {
+		if h.reportGenerator == nil {
+			return nil, false
+		}
+		networkReport, err := h.reportGenerator.GenerateReport()
+		if err != nil {
+			log.Printf("生成报告失败: %v", err)
+			return nil, false
+		}
+		return networkReport, true
+	}
+	return h.reportHistory.Get(id)
+}
+
+// previousReportID 返回指定报告之前的一份历史报告ID
+// id 为空（实时报告）时返回最新的历史报告
+func (h *Handler) previousReportID(id string) string {
+	entries := h.reportHistory.List()
+	if id == "" {
+		if len(entries) > 0 {
+			return entries[0].ID
+		}
+		return ""
+	}
+	for i, entry := range entries {
+		if entry.ID == id && i+1 < len(entries) {
+			return entries[i+1].ID
+		}
+	}
+	return ""
+}
+
+// renderReport 按协商的格式输出报告
+func (h *Handler) renderReport(c *gin.Context, networkReport *models.NetworkReport) {
+	renderer, err := report.NegotiateRenderer(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "不支持的报告格式",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, networkReport); err != nil {
+		log.Printf("渲染%s报告失败: %v", renderer.Format(), err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "渲染报告失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("report-%s.%s", networkReport.Timestamp.Format("20060102-150405"), renderer.FileExtension())
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Header("Vary", "Accept")
+	c.Data(http.StatusOK, renderer.ContentType(), buf.Bytes())
+}
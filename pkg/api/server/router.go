@@ -1,6 +1,10 @@
 package server
 
 import (
+	"net/http"
+
+	"github.com/yezihack/k8snet-checker/pkg/dashboard"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -8,19 +12,108 @@ import (
 func RegisterRoutes(router *gin.Engine, handler *Handler) {
 	api := router.Group("/api/v1")
 
-	// 客户端上报接口
-	api.POST("/heartbeat", handler.HandleHeartbeat)
-	api.POST("/test-results/hosts", handler.HandleHostTestResults)
-	api.POST("/test-results/pods", handler.HandlePodTestResults)
-	api.POST("/test-results/service", handler.HandleServiceTestResults)
+	// 客户端上报接口，启用认证时需通过认证中间件
+	submit := api.Group("")
+	submit.Use(submitMiddlewares(handler)...)
+	submit.POST("/heartbeat", handler.HandleHeartbeat)
+	submit.POST("/test-results/hosts", handler.HandleHostTestResults)
+	submit.POST("/test-results/pods", handler.HandlePodTestResults)
+	submit.POST("/test-results/service", handler.HandleServiceTestResults)
+	submit.POST("/sync", gzipMiddleware(), handler.HandleSync)
+	if handler.runManager != nil {
+		submit.GET("/runs/pending", handler.HandlePollRuns)
+		submit.POST("/runs/:id/complete", handler.HandleCompleteRun)
+	}
+
+	// 管理接口，配置了管理员Token时使用该Token认证，否则与上报接口使用相同的认证
+	admin := api.Group("")
+	if handler.adminVerifier != nil {
+		admin.Use(authMiddleware(handler.adminVerifier))
+	} else {
+		admin.Use(submitMiddlewares(handler)...)
+	}
 
 	// 查询接口
 	api.GET("/hosts", handler.HandleGetHosts)
 	api.GET("/pods", handler.HandleGetPods)
+	api.GET("/targets/:type", handler.HandleGetTargets)
 	api.GET("/test-results/hosts", handler.HandleGetHostTestResults)
 	api.GET("/test-results/pods", handler.HandleGetPodTestResults)
 	api.GET("/test-results/service", handler.HandleGetServiceTestResults)
+	api.GET("/clients", handler.HandleGetClients)
 	api.GET("/clients/count", handler.HandleGetClientCount)
 	api.GET("/results", handler.HandleGetAllResults)
 	api.GET("/health", handler.HandleHealth)
+
+	// 告警接口
+	if handler.alertManager != nil {
+		api.GET("/alerts", handler.HandleGetAlerts)
+		api.GET("/silences", handler.HandleGetSilences)
+		admin.POST("/silences", handler.HandleCreateSilence)
+		admin.DELETE("/silences/:id", handler.HandleDeleteSilence)
+	}
+
+	// 报告接口
+	if handler.reportGenerator != nil {
+		api.GET("/report", handler.HandleGetReport)
+	}
+	if handler.reportHistory != nil {
+		api.GET("/reports", handler.HandleListReports)
+		api.GET("/reports/diff", handler.HandleDiffReports)
+		api.GET("/reports/:id", handler.HandleGetHistoryReport)
+	}
+
+	// 可用性统计接口
+	if handler.sloTracker != nil {
+		api.GET("/slo", handler.HandleGetSLO)
+	}
+
+	// 延迟基线接口
+	if handler.latencyDetector != nil {
+		api.GET("/latency", handler.HandleGetLatency)
+	}
+
+	// 按需测试接口
+	if handler.runManager != nil {
+		admin.POST("/runs", handler.HandleCreateRun)
+		api.GET("/runs", handler.HandleListRuns)
+		api.GET("/runs/:id", handler.HandleGetRun)
+		admin.POST("/runs/:id/cancel", handler.HandleCancelRun)
+	}
+
+	// 探测配置接口
+	if handler.probeConfig != nil {
+		api.GET("/probe-config", handler.HandleGetProbeConfig)
+		admin.PUT("/probe-config", handler.HandleUpdateProbeConfig)
+	}
+
+	// 实时事件流接口
+	if handler.eventBus != nil {
+		api.GET("/events", handler.HandleEventStream)
+	}
+
+	// Prometheus指标
+	if handler.metricsHandler != nil {
+		router.GET("/metrics", gin.WrapH(handler.metricsHandler))
+	}
+
+	// 内置仪表盘
+	if handler.dashboard {
+		router.GET("/", func(c *gin.Context) {
+			c.Redirect(http.StatusFound, "/dashboard/")
+		})
+		router.GET("/dashboard/*filepath", gin.WrapH(http.StripPrefix("/dashboard", dashboard.Handler())))
+	}
+}
+
+// submitMiddlewares 返回客户端上报接口使用的认证中间件
+func submitMiddlewares(handler *Handler) []gin.HandlerFunc {
+	var middlewares []gin.HandlerFunc
+	if handler.authVerifier != nil {
+		middlewares = append(middlewares, authMiddleware(handler.authVerifier))
+	}
+	if handler.requireClientCert {
+		middlewares = append(middlewares, clientCertMiddleware())
+	}
+	return middlewares
 }
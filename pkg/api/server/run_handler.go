@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 长轮询等待时间
+const (
+	defaultRunWait = 30 * time.Second
+	maxRunWait     = 60 * time.Second
+)
+
+// HandleCreateRun 创建按需测试任务
+// POST /api/v1/runs
+// clients 为空时下发给所有已注册客户端，test_types 为空时执行全部测试
+func (h *Handler) HandleCreateRun(c *gin.Context) {
+	var request struct {
+		Clients   []string `json:"clients"`
+		TestTypes []string `json:"test_types"`
+	}
+
+	// 允许不带请求体
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的请求数据",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	run, err := h.runManager.Create(request.Clients, request.TestTypes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "创建测试任务失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// HandleListRuns 获取所有保留的测试任务
+// GET /api/v1/runs
+func (h *Handler) HandleListRuns(c *gin.Context) {
+	list := h.runManager.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  list,
+		"count": len(list),
+	})
+}
+
+// HandleGetRun 获取测试任务状态与结果
+// GET /api/v1/runs/:id
+// 指定 wait（如 "30s"）时等待任务结束后返回，超过等待时间返回当前状态
+func (h *Handler) HandleGetRun(c *gin.Context) {
+	wait, ok := parseRunWait(c, 0)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+	defer cancel()
+
+	run, err := h.runManager.Wait(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, runs.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    "NOT_FOUND",
+				Message: "测试任务不存在",
+				Details: c.Param("id"),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "获取测试任务失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// HandleCancelRun 取消运行中的测试任务，执行中的客户端停止探测且不再上报结果
+// POST /api/v1/runs/:id/cancel
+// 任务已结束时返回当前状态
+func (h *Handler) HandleCancelRun(c *gin.Context) {
+	run, err := h.runManager.Cancel(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, runs.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    "NOT_FOUND",
+				Message: "测试任务不存在",
+				Details: c.Param("id"),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "取消测试任务失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// HandlePollRuns 客户端长轮询获取下发给自己的测试任务
+// GET /api/v1/runs/pending?pod_name=xxx&wait=30s
+func (h *Handler) HandlePollRuns(c *gin.Context) {
+	podName := c.Query("pod_name")
+	if podName == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "缺少必需参数",
+			Details: "pod_name不能为空",
+		})
+		return
+	}
+	if !h.checkClientIdentity(c, podName) {
+		return
+	}
+
+	wait, ok := parseRunWait(c, defaultRunWait)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+	defer cancel()
+
+	requests := h.runManager.Poll(ctx, podName)
+	if requests == nil {
+		requests = []models.RunRequest{}
+	}
+	if len(requests) > 0 {
+		log.Printf("下发按需测试任务: pod=%s, count=%d", podName, len(requests))
+	}
+
+	h.writeRuns(c, podName, requests, gin.H{
+		"runs":  requests,
+		"count": len(requests),
+	})
+}
+
+// writeRuns 写入包含测试任务的响应，客户端已断开或响应写入失败时任务未送达，重新放回待下发队列
+func (h *Handler) writeRuns(c *gin.Context, podName string, requests []models.RunRequest, response interface{}) {
+	if c.Request.Context().Err() == nil {
+		written := len(c.Errors)
+		c.JSON(http.StatusOK, response)
+		if len(c.Errors) == written {
+			return
+		}
+	}
+	if len(requests) > 0 {
+		log.Printf("测试任务未送达客户端，重新放回待下发队列: pod=%s, count=%d", podName, len(requests))
+		h.runManager.Requeue(podName, requests)
+	}
+}
+
+// HandleCompleteRun 客户端报告已完成测试任务
+// POST /api/v1/runs/:id/complete
+func (h *Handler) HandleCompleteRun(c *gin.Context) {
+	var request struct {
+		PodName string `json:"pod_name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的请求数据",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !h.checkClientIdentity(c, request.PodName) {
+		return
+	}
+
+	if err := h.runManager.Complete(c.Param("id"), request.PodName); err != nil {
+		status := http.StatusBadRequest
+		code := "INVALID_REQUEST"
+		if errors.Is(err, runs.ErrNotFound) {
+			status = http.StatusNotFound
+			code = "NOT_FOUND"
+		}
+		c.JSON(status, models.ErrorResponse{
+			Code:    code,
+			Message: "更新测试任务失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "测试任务已完成",
+	})
+}
+
+// parseRunWait 解析 wait 参数，超过上限时使用上限
+// 参数无效时返回400并返回false
+func parseRunWait(c *gin.Context, defaultWait time.Duration) (time.Duration, bool) {
+	value := c.Query("wait")
+	if value == "" {
+		return defaultWait, true
+	}
+
+	wait, err := time.ParseDuration(value)
+	if err != nil || wait < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的等待时间",
+			Details: value,
+		})
+		return 0, false
+	}
+	if wait > maxRunWait {
+		wait = maxRunWait
+	}
+	return wait, true
+}
@@ -1,11 +1,24 @@
 package server
 
 import (
+	"crypto/tls"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/alert"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/events"
+	"github.com/yezihack/k8snet-checker/pkg/latency"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+	"github.com/yezihack/k8snet-checker/pkg/report"
 	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+	"github.com/yezihack/k8snet-checker/pkg/sharding"
+	"github.com/yezihack/k8snet-checker/pkg/slo"
+	"github.com/yezihack/k8snet-checker/pkg/targets"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,8 +35,140 @@ type apiServerImpl struct {
 	handler *Handler
 }
 
+// Option APIServer的可选配置项
+type Option func(h *Handler)
+
+// WithAlertManager 启用告警查询与静默管理接口
+func WithAlertManager(alertManager alert.Manager) Option {
+	return func(h *Handler) {
+		h.alertManager = alertManager
+	}
+}
+
+// WithReportGenerator 启用实时报告接口
+func WithReportGenerator(reportGenerator report.ReportGenerator) Option {
+	return func(h *Handler) {
+		h.reportGenerator = reportGenerator
+	}
+}
+
+// WithReportHistory 启用历史报告接口
+func WithReportHistory(history report.History) Option {
+	return func(h *Handler) {
+		h.reportHistory = history
+	}
+}
+
+// WithEventBus 启用实时事件流接口
+func WithEventBus(bus events.Bus) Option {
+	return func(h *Handler) {
+		h.eventBus = bus
+	}
+}
+
+// WithDashboard 启用内置Web仪表盘，访问路径为 /dashboard/
+func WithDashboard() Option {
+	return func(h *Handler) {
+		h.dashboard = true
+	}
+}
+
+// WithRunManager 启用按需测试接口
+func WithRunManager(runManager runs.Manager) Option {
+	return func(h *Handler) {
+		h.runManager = runManager
+	}
+}
+
+// WithProbeConfig 启用探测配置接口，并通过心跳响应向客户端下发配置
+func WithProbeConfig(store probeconfig.Store) Option {
+	return func(h *Handler) {
+		h.probeConfig = store
+	}
+}
+
+// WithSharding 启用目标分片，携带 pod_name 查询 /hosts 与 /pods 时只返回本轮应测试的目标
+func WithSharding(planner sharding.Planner) Option {
+	return func(h *Handler) {
+		h.planner = planner
+	}
+}
+
+// WithTargetVersions 为 /hosts 与 /pods 返回的目标列表添加版本标识，支持 If-None-Match 与增量变更
+// 启用目标分片时，分片结果每轮不同，仍返回不带版本标识的完整分片
+func WithTargetVersions(tracker targets.Tracker) Option {
+	return func(h *Handler) {
+		h.targetTracker = tracker
+	}
+}
+
+// WithSLOTracker 启用可用性统计接口 /api/v1/slo
+func WithSLOTracker(tracker slo.Tracker) Option {
+	return func(h *Handler) {
+		h.sloTracker = tracker
+	}
+}
+
+// WithLatencyDetector 启用延迟基线与异常接口 /api/v1/latency
+func WithLatencyDetector(detector latency.Detector) Option {
+	return func(h *Handler) {
+		h.latencyDetector = detector
+	}
+}
+
+// WithMetrics 在 /metrics 提供Prometheus指标
+func WithMetrics(handler http.Handler) Option {
+	return func(h *Handler) {
+		h.metricsHandler = handler
+	}
+}
+
+// WithHeartbeatInterval 在心跳响应中建议客户端使用的心跳间隔
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(h *Handler) {
+		h.heartbeatInterval = interval
+	}
+}
+
+// WithAuth 要求客户端上报接口（心跳与测试结果）通过认证
+func WithAuth(verifier auth.Verifier) Option {
+	return func(h *Handler) {
+		h.authVerifier = verifier
+	}
+}
+
+// WithAdminAuth 要求管理接口（修改探测配置、创建与取消按需测试、静默告警）通过该校验器认证
+// 未设置时管理接口与上报接口使用相同的认证
+func WithAdminAuth(verifier auth.Verifier) Option {
+	return func(h *Handler) {
+		h.adminVerifier = verifier
+	}
+}
+
+// WithSourceIPVerification 校验上报测试结果的源IP属于已注册的客户端
+func WithSourceIPVerification() Option {
+	return func(h *Handler) {
+		h.verifySourceIP = true
+	}
+}
+
+// WithTLS 使用TLS提供服务，config 通常由 tlsutil.ServerConfig 创建
+func WithTLS(config *tls.Config) Option {
+	return func(h *Handler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithClientCertificates 要求客户端上报接口提供经CA校验的客户端证书（双向TLS）
+// 心跳中的Pod名称需与证书的 CN 或 SAN 一致
+func WithClientCertificates() Option {
+	return func(h *Handler) {
+		h.requireClientCert = true
+	}
+}
+
 // NewAPIServer 创建一个新的APIServer实例
-func NewAPIServer(clientManager client.ClientManager, resultManager result.TestResultManager) APIServer {
+func NewAPIServer(clientManager client.ClientManager, resultManager result.TestResultManager, opts ...Option) APIServer {
 	// 根据LOG_LEVEL设置Gin模式
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel == "debug" {
@@ -34,6 +179,9 @@ func NewAPIServer(clientManager client.ClientManager, resultManager result.TestR
 
 	router := gin.Default()
 	handler := NewHandler(clientManager, resultManager)
+	for _, opt := range opts {
+		opt(handler)
+	}
 
 	// 注册路由
 	RegisterRoutes(router, handler)
@@ -50,6 +198,17 @@ func (s *apiServerImpl) Start(port string) error {
 		port = "8080"
 	}
 
+	if s.handler.tlsConfig != nil {
+		log.Printf("HTTPS服务器启动在端口: %s", port)
+		httpServer := &http.Server{
+			Addr:      ":" + port,
+			Handler:   s.router,
+			TLSConfig: s.handler.tlsConfig,
+		}
+		// 证书由TLSConfig提供，无需指定文件
+		return httpServer.ListenAndServeTLS("", "")
+	}
+
 	log.Printf("HTTP服务器启动在端口: %s", port)
 	return s.router.Run(":" + port)
 }
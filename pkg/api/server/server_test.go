@@ -1,19 +1,38 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/alert"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/cache"
 	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/events"
+	"github.com/yezihack/k8snet-checker/pkg/latency"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
 	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
+	"github.com/yezihack/k8snet-checker/pkg/report"
 	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+	"github.com/yezihack/k8snet-checker/pkg/sharding"
+	"github.com/yezihack/k8snet-checker/pkg/slo"
+	"github.com/yezihack/k8snet-checker/pkg/targets"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // setupTestServer 创建测试用的服务器实例
@@ -67,6 +86,24 @@ func TestHeartbeatEndpoint(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "success", response["status"])
+	assert.NotContains(t, response, "heartbeat_interval")
+}
+
+// TestHeartbeatSuggestedInterval 测试心跳响应携带服务器建议的心跳间隔
+func TestHeartbeatSuggestedInterval(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	apiServer := NewAPIServer(client.NewClientManager(cacheManager), result.NewTestResultManager(cacheManager),
+		WithHeartbeatInterval(10*time.Second)).(*apiServerImpl)
+
+	body, _ := json.Marshal(models.NodeInfo{NodeIP: "192.168.1.1", PodIP: "10.0.0.1", PodName: "test-pod"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/heartbeat", bytes.NewBuffer(body))
+	apiServer.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.HeartbeatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.Duration(10*time.Second), response.HeartbeatInterval)
 }
 
 // TestHeartbeatEndpointInvalidData 测试心跳端点的无效数据处理
@@ -179,7 +216,7 @@ func TestHostTestResultsEndpoint(t *testing.T) {
 				TargetIP:   "192.168.1.2",
 				PingStatus: "reachable",
 				PortStatus: map[int]string{22: "open"},
-				Latency:    10 * time.Millisecond,
+				Latency:    models.Duration(10 * time.Millisecond),
 				Timestamp:  time.Now(),
 			},
 		},
@@ -223,7 +260,7 @@ func TestPodTestResultsEndpoint(t *testing.T) {
 				TargetIP:   "10.0.0.2",
 				PingStatus: "reachable",
 				PortStatus: map[int]string{6100: "open"},
-				Latency:    5 * time.Millisecond,
+				Latency:    models.Duration(5 * time.Millisecond),
 				Timestamp:  time.Now(),
 			},
 		},
@@ -266,7 +303,7 @@ func TestServiceTestResultsEndpoint(t *testing.T) {
 			TargetIP:   "kubernetes.default.svc.cluster.local",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{443: "open"},
-			Latency:    3 * time.Millisecond,
+			Latency:    models.Duration(3 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 	}
@@ -362,3 +399,1080 @@ func TestGetAllResultsEndpoint(t *testing.T) {
 	assert.NotNil(t, response["service_test_results"])
 	assert.NotNil(t, response["active_client_count"])
 }
+
+// TestAlertEndpoints 测试告警与静默接口
+func TestAlertEndpoints(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	alertManager := alert.NewManager(alert.Config{}, nil, clientManager, nil)
+
+	apiServer := NewAPIServer(clientManager, resultManager, WithAlertManager(alertManager)).(*apiServerImpl)
+
+	// 获取告警列表
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/alerts", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 创建静默规则
+	body, _ := json.Marshal(map[string]interface{}{
+		"matchers": map[string]string{"rule": alert.RuleClientMissing},
+		"duration": "2h",
+		"comment":  "节点维护",
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/silences", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var silence alert.Silence
+	err := json.Unmarshal(w.Body.Bytes(), &silence)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, silence.ID)
+
+	// 无效的持续时间
+	body, _ = json.Marshal(map[string]interface{}{
+		"matchers": map[string]string{"rule": alert.RuleClientMissing},
+		"duration": "abc",
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/silences", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 删除静默规则
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/silences/"+silence.ID, nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/silences/"+silence.ID, nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestAlertEndpointsDisabled 测试未启用告警时接口不存在
+func TestAlertEndpointsDisabled(t *testing.T) {
+	server := setupTestServer()
+	apiServer := server.(*apiServerImpl)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/alerts", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestReportEndpoints 测试实时报告与历史报告接口
+func TestReportEndpoints(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	history := report.NewHistory(5)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager, history)
+
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithReportGenerator(reportGenerator),
+		WithReportHistory(history),
+	).(*apiServerImpl)
+
+	// 默认返回JSON
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/report", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var networkReport models.NetworkReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &networkReport))
+
+	// 通过Accept请求头选择格式
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/report", nil)
+	req.Header.Set("Accept", "text/markdown")
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "# 网络连通性报告")
+
+	// ?format= 优先于Accept
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/report?format=junit", nil)
+	req.Header.Set("Accept", "text/markdown")
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".xml")
+
+	// 不支持的格式
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/report?format=pdf", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 历史报告尚不存在
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/latest", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// 写入一份历史报告
+	generated, err := reportGenerator.GenerateReport()
+	assert.NoError(t, err)
+	assert.NoError(t, history.Write(generated))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Reports []report.HistoryEntry `json:"reports"`
+		Count   int                   `json:"count"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, generated.ID, list.Reports[0].ID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/"+generated.ID+"?format=csv", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "timestamp,test_type,source_ip")
+}
+
+// TestReportDiffEndpoint 测试报告对比接口
+func TestReportDiffEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	history := report.NewHistory(5)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager, history)
+
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithReportGenerator(reportGenerator),
+		WithReportHistory(history),
+	).(*apiServerImpl)
+
+	// 没有历史报告
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/reports/diff", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// 第一份报告：Pod互探失败
+	err := resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}},
+	})
+	assert.NoError(t, err)
+	first, _ := reportGenerator.GenerateReport()
+	assert.NoError(t, history.Write(first))
+
+	// 第二份报告：恢复
+	err = resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	})
+	assert.NoError(t, err)
+	second, _ := reportGenerator.GenerateReport()
+	assert.NoError(t, history.Write(second))
+
+	// 默认对比最新两份报告
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/diff", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var diff report.ReportDiff
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, first.ID, diff.FromID)
+	assert.Equal(t, second.ID, diff.ToID)
+	assert.Len(t, diff.Recovered, 1)
+	assert.Empty(t, diff.NewlyFailing)
+
+	// 反向对比并输出Markdown
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/diff?from="+second.ID+"&to="+first.ID+"&format=markdown", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "## 新增失败 (共1个)")
+
+	// 与实时报告对比
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/diff?to=current", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 不支持差异输出的格式
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/diff?format=csv", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 无效阈值
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/reports/diff?latency_threshold=abc", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestEventStreamEndpoint 测试SSE事件流
+func TestEventStreamEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	bus := events.NewBus()
+	observer := events.NewObserver(bus)
+	clientManager.AddObserver(observer)
+	resultManager.AddObserver(observer)
+
+	apiServer := NewAPIServer(clientManager, resultManager, WithEventBus(bus)).(*apiServerImpl)
+	ts := httptest.NewServer(apiServer.router)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/v1/events?type=heartbeat,result&source=10.0.0.1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// 等待订阅建立
+	require.Eventually(t, func() bool { return bus.SubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// 不匹配过滤条件的事件不会推送
+	assert.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}))
+	assert.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}))
+	assert.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}))
+
+	reader := bufio.NewReader(resp.Body)
+	var received []events.Event
+	for len(received) < 2 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			var event events.Event
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			received = append(received, event)
+		}
+	}
+
+	assert.Equal(t, events.TypeHeartbeat, received[0].Type)
+	assert.Equal(t, "10.0.0.1", received[0].Source)
+	assert.Equal(t, events.TypeResult, received[1].Type)
+	assert.Equal(t, models.TestTypePod, received[1].TestType)
+	assert.Equal(t, "10.0.0.2", received[1].Target)
+
+	// 客户端断开后取消订阅
+	cancel()
+	require.Eventually(t, func() bool { return bus.SubscriberCount() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+// TestGetClientsEndpoint 测试客户端列表接口
+func TestGetClientsEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, resultManager).(*apiServerImpl)
+
+	assert.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-b", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}))
+	assert.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-a", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/clients", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Clients []ClientStatus `json:"clients"`
+		Count   int            `json:"count"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 2, response.Count)
+	assert.Equal(t, "pod-a", response.Clients[0].PodName)
+	assert.Equal(t, int64(0), response.Clients[0].VersionLag)
+	assert.Equal(t, int64(1), response.Clients[1].VersionLag)
+}
+
+// TestDashboardRoutes 测试仪表盘路由
+func TestDashboardRoutes(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, resultManager, WithDashboard()).(*apiServerImpl)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/dashboard/", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/dashboard/", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "K8s 网络连通性仪表盘")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/dashboard/app.js", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 未启用时不提供仪表盘
+	disabled := setupTestServer().(*apiServerImpl)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/dashboard/", nil)
+	disabled.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestSLOEndpoints 测试可用性统计接口、Prometheus指标与报告中的可用性
+func TestSLOEndpoints(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	tracker, err := slo.NewTracker(slo.Config{}, clientManager)
+	require.NoError(t, err)
+	resultManager.AddObserver(tracker)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager)
+	reportGenerator.AddEnricher(tracker)
+
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithSLOTracker(tracker),
+		WithReportGenerator(reportGenerator),
+		WithMetrics(metrics.NewServerMetrics(tracker).Handler()),
+	).(*apiServerImpl)
+
+	for _, info := range []*models.NodeInfo{
+		{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", NodeName: "node-1"},
+		{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2", NodeName: "node-2"},
+	} {
+		require.NoError(t, clientManager.HandleHeartbeat(info))
+	}
+	require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}))
+	require.NoError(t, resultManager.SavePodTestResults("10.0.0.2", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.2", TargetIP: "10.0.0.1", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}},
+	}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/slo", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var sloReport models.SLOReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sloReport))
+	assert.Equal(t, slo.DefaultObjective, sloReport.Objective)
+	assert.Equal(t, float64(50), sloReport.Cluster.Window(models.SLOWindowHour).Availability)
+	assert.Len(t, sloReport.Nodes, 2)
+	assert.Len(t, sloReport.Pairs, 2)
+
+	// 按节点过滤，不返回探测对明细
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/slo?node=node-1&pairs=false", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	sloReport = models.SLOReport{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sloReport))
+	require.Len(t, sloReport.Nodes, 1)
+	assert.Equal(t, float64(100), sloReport.Nodes[0].AsSource.Window(models.SLOWindowHour).Availability)
+	assert.Equal(t, float64(0), sloReport.Nodes[0].AsTarget.Window(models.SLOWindowHour).Availability)
+	assert.Empty(t, sloReport.Pairs)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/slo?pairs=abc", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `k8snet_checker_server_slo_availability_percent{node="",scope="cluster",window="1h"} 50`)
+	assert.Contains(t, w.Body.String(), `k8snet_checker_server_slo_error_budget_remaining_percent{node="node-2",scope="source"}`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/report?format=markdown", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "## 可用性 (目标 99.900%)")
+	assert.Contains(t, w.Body.String(), "| node-1 (目标) | 0.000% |")
+
+	// 未启用时接口不存在
+	disabled := setupTestServer().(*apiServerImpl)
+	for _, path := range []string{"/api/v1/slo", "/metrics"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", path, nil)
+		disabled.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+}
+
+// TestLatencyEndpoint 测试延迟基线接口与报告中的延迟异常
+func TestLatencyEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	detector := latency.NewDetector(latency.Config{MinSamples: 3, Consecutive: 1})
+	resultManager.AddObserver(detector)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager)
+	reportGenerator.AddEnricher(detector)
+
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithLatencyDetector(detector),
+		WithReportGenerator(reportGenerator),
+	).(*apiServerImpl)
+
+	for _, d := range []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond, 20 * time.Millisecond} {
+		require.NoError(t, resultManager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+			{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", Latency: models.Duration(d), PortStatus: map[int]string{6100: "open"}},
+		}))
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/latency", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var latencyReport models.LatencyReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &latencyReport))
+	require.Len(t, latencyReport.Baselines, 1)
+	assert.Equal(t, models.Duration(time.Millisecond), latencyReport.Baselines[0].Baseline.P50)
+	require.Len(t, latencyReport.Anomalies, 1)
+	assert.Equal(t, models.Duration(20*time.Millisecond), latencyReport.Anomalies[0].Latency)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/latency?anomalies=true", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	latencyReport = models.LatencyReport{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &latencyReport))
+	assert.Empty(t, latencyReport.Baselines)
+	assert.Len(t, latencyReport.Anomalies, 1)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/latency?anomalies=abc", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/report?format=markdown", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "## 延迟异常 (共1个)")
+
+	// 未启用时接口不存在
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/latency", nil)
+	setupTestServer().(*apiServerImpl).router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// postJSON 发送JSON请求，signer 不为nil时对请求签名
+func postJSON(router http.Handler, path string, payload interface{}, signer auth.Signer) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signer != nil {
+		signer.Sign(req, body)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestAuthMiddleware 测试上报接口认证与源IP校验
+func TestAuthMiddleware(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	verifier, err := auth.NewServerVerifier(auth.ModeHMAC, "", "", "master")
+	require.NoError(t, err)
+
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithAuth(verifier),
+		WithSourceIPVerification(),
+	).(*apiServerImpl)
+
+	pod1 := auth.NewHMACSigner("pod-1", auth.DeriveKey("master", "pod-1"))
+	pod2 := auth.NewHMACSigner("pod-2", auth.DeriveKey("master", "pod-2"))
+	heartbeat1 := models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+	heartbeat2 := models.NodeInfo{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}
+
+	// 未签名的请求被拒绝
+	w := postJSON(apiServer.router, "/api/v1/heartbeat", heartbeat1, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 使用他人的标识发送心跳
+	w = postJSON(apiServer.router, "/api/v1/heartbeat", heartbeat2, pod1)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = postJSON(apiServer.router, "/api/v1/heartbeat", heartbeat1, pod1)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = postJSON(apiServer.router, "/api/v1/heartbeat", heartbeat2, pod2)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	results := map[string]interface{}{
+		"source_ip": "10.0.0.1",
+		"results": []models.ConnectivityResult{
+			{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+		},
+	}
+
+	// 源IP属于签名客户端
+	w = postJSON(apiServer.router, "/api/v1/test-results/pods", results, pod1)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// pod-2 伪造 pod-1 的结果
+	w = postJSON(apiServer.router, "/api/v1/test-results/pods", results, pod2)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 未注册的源IP
+	results["source_ip"] = "10.9.9.9"
+	w = postJSON(apiServer.router, "/api/v1/test-results/hosts", results, pod1)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 超过大小上限的请求体不截断，直接拒绝
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/test-results/pods", bytes.NewReader(make([]byte, maxAuthBodySize+1)))
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "REQUEST_TOO_LARGE")
+
+	// 查询接口不需要认证
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/pods", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestSourceIPVerificationWithToken 测试Token认证下仅校验源IP已注册
+func TestSourceIPVerificationWithToken(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithAuth(auth.NewTokenVerifier("token")),
+		WithSourceIPVerification(),
+	).(*apiServerImpl)
+	signer := auth.NewTokenSigner("token")
+
+	w := postJSON(apiServer.router, "/api/v1/heartbeat", models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}, signer)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	service := map[string]interface{}{
+		"source_ip": "192.168.1.1",
+		"result":    models.ConnectivityResult{SourceIP: "192.168.1.1", TargetIP: "svc", PingStatus: "reachable"},
+	}
+	w = postJSON(apiServer.router, "/api/v1/test-results/service", service, signer)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	service["source_ip"] = "192.168.1.9"
+	w = postJSON(apiServer.router, "/api/v1/test-results/service", service, signer)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// TestClientCertificates 测试上报接口要求客户端证书，且心跳的Pod名称与证书身份一致
+func TestClientCertificates(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, resultManager, WithClientCertificates()).(*apiServerImpl)
+
+	// 模拟TLS握手后经CA校验的客户端证书
+	withCert := func(commonName string, dnsNames ...string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}, DNSNames: dnsNames}
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+	send := func(path string, payload interface{}, state *tls.ConnectionState) int {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.TLS = state
+		w := httptest.NewRecorder()
+		apiServer.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	heartbeat := models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+
+	// 没有客户端证书
+	assert.Equal(t, http.StatusUnauthorized, send("/api/v1/heartbeat", heartbeat, nil))
+	assert.Equal(t, http.StatusUnauthorized, send("/api/v1/heartbeat", heartbeat, &tls.ConnectionState{}))
+
+	// 证书身份与Pod名称不一致
+	assert.Equal(t, http.StatusForbidden, send("/api/v1/heartbeat", heartbeat, withCert("pod-2")))
+
+	// CN 或 SAN 与Pod名称一致
+	assert.Equal(t, http.StatusOK, send("/api/v1/heartbeat", heartbeat, withCert("pod-1")))
+	assert.Equal(t, http.StatusOK, send("/api/v1/heartbeat", heartbeat, withCert("k8snet-checker-client", "pod-1")))
+
+	results := map[string]interface{}{
+		"source_ip": "10.0.0.1",
+		"results":   []models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable"}},
+	}
+	assert.Equal(t, http.StatusUnauthorized, send("/api/v1/test-results/pods", results, nil))
+	assert.Equal(t, http.StatusOK, send("/api/v1/test-results/pods", results, withCert("pod-1")))
+
+	// 查询接口不需要客户端证书
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/pods", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestRunEndpoints 测试按需测试任务的创建、下发、结果收集与查询
+func TestRunEndpoints(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	runManager := runs.NewManager(clientManager, 0)
+	resultManager.AddObserver(runManager)
+	apiServer := NewAPIServer(clientManager, resultManager, WithRunManager(runManager)).(*apiServerImpl)
+
+	w := postJSON(apiServer.router, "/api/v1/heartbeat", models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 无效的测试类型
+	w = postJSON(apiServer.router, "/api/v1/runs", map[string]interface{}{"test_types": []string{"udp"}}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postJSON(apiServer.router, "/api/v1/runs", map[string]interface{}{"test_types": []string{"pod"}}, nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var run models.Run
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, []string{"pod-1"}, run.Clients)
+
+	// 客户端已断开时任务未送达，重新放回待下发队列
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/v1/runs/pending?pod_name=pod-1&wait=1s", nil)
+	apiServer.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// 客户端获取任务
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/runs/pending?pod_name=pod-1&wait=1s", nil)
+	apiServer.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var pending struct {
+		Runs []models.RunRequest `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+	require.Len(t, pending.Runs, 1)
+	assert.Equal(t, run.ID, pending.Runs[0].ID)
+	assert.Equal(t, run.Deadline.Unix(), pending.Runs[0].Deadline.Unix())
+
+	// 上报带有任务ID的结果并报告完成
+	results := map[string]interface{}{
+		"source_ip": "10.0.0.1",
+		"results": []models.ConnectivityResult{
+			{SourceIP: "10.0.0.1", TargetIP: "10.0.0.1", PingStatus: "reachable", RunID: run.ID},
+		},
+	}
+	w = postJSON(apiServer.router, "/api/v1/test-results/pods", results, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = postJSON(apiServer.router, "/api/v1/runs/"+run.ID+"/complete", map[string]string{"pod_name": "pod-1"}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/runs/"+run.ID+"?wait=1s", nil)
+	apiServer.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, runs.StatusCompleted, run.Status)
+	assert.Len(t, run.Results[models.TestTypePod], 1)
+
+	// 不存在的任务与无效参数
+	w = postJSON(apiServer.router, "/api/v1/runs/missing/complete", map[string]string{"pod_name": "pod-1"}, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = postJSON(apiServer.router, "/api/v1/runs/missing/cancel", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// 已完成的任务取消时返回当前状态
+	w = postJSON(apiServer.router, "/api/v1/runs/"+run.ID+"/cancel", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), runs.StatusCompleted)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/runs/missing", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/runs/pending?wait=1s", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/runs", nil)
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), run.ID)
+}
+
+// TestProbeConfigEndpoints 测试探测配置接口与心跳下发
+func TestProbeConfigEndpoints(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	store, err := probeconfig.NewStore(probeconfig.Default())
+	require.NoError(t, err)
+	apiServer := NewAPIServer(client.NewClientManager(cacheManager), result.NewTestResultManager(cacheManager), WithProbeConfig(store)).(*apiServerImpl)
+
+	heartbeat := func(version int64) models.HeartbeatResponse {
+		w := postJSON(apiServer.router, "/api/v1/heartbeat", models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", ProbeConfigVersion: version}, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.HeartbeatResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// 服务器未设置配置时不下发
+	assert.Nil(t, heartbeat(0).ProbeConfig)
+
+	// 服务器重启后未设置配置，客户端仍在使用之前下发的配置时下发版本为0的配置
+	response := heartbeat(100)
+	require.NotNil(t, response.ProbeConfig)
+	assert.Equal(t, int64(0), response.ProbeConfig.Version)
+
+	// 更新配置
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/probe-config", strings.NewReader(`{"pod_ports":[6100,8080],"test_interval":"30s"}`))
+	apiServer.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated models.ProbeConfig
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Greater(t, updated.Version, int64(0))
+	assert.Equal(t, []int{6100, 8080}, updated.PodPorts)
+	assert.Equal(t, []int{22}, updated.HostPorts)
+
+	// 版本落后的客户端收到配置，版本一致时不下发
+	response = heartbeat(0)
+	require.NotNil(t, response.ProbeConfig)
+	assert.Equal(t, updated.Version, response.ProbeConfig.Version)
+	assert.Nil(t, heartbeat(updated.Version).ProbeConfig)
+
+	// 无效配置返回400，当前配置不变
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/probe-config", strings.NewReader(`{"protocols":["udp"]}`))
+	apiServer.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/probe-config", nil)
+	apiServer.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var current models.ProbeConfig
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &current))
+	assert.Equal(t, updated.Version, current.Version)
+}
+
+// TestShardedTargets 测试启用目标分片后按Pod名称返回本轮目标
+func TestShardedTargets(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, result.NewTestResultManager(cacheManager), WithSharding(sharding.NewPlanner(clientManager, 2))).(*apiServerImpl)
+
+	for i, podIP := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"} {
+		nodeInfo := models.NodeInfo{PodName: "pod-" + string(rune('1'+i)), NodeIP: "192.168.1." + string(rune('1'+i)), PodIP: podIP}
+		require.Equal(t, http.StatusOK, postJSON(apiServer.router, "/api/v1/heartbeat", nodeInfo, nil).Code)
+	}
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		apiServer.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// 不带Pod名称或客户端未注册时返回全部目标
+	assert.Equal(t, float64(5), get("/api/v1/pods")["count"])
+	assert.Nil(t, get("/api/v1/pods")["shard"])
+	assert.Equal(t, float64(5), get("/api/v1/pods?pod_name=unknown")["count"])
+
+	response := get("/api/v1/pods?pod_name=pod-1")
+	assert.Equal(t, float64(2), response["count"])
+	shard, ok := response["shard"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(4), shard["total"])
+	assert.Equal(t, float64(2), shard["count"])
+
+	response = get("/api/v1/hosts?pod_name=pod-1")
+	assert.Equal(t, float64(2), response["count"])
+	assert.NotNil(t, response["shard"])
+}
+
+// TestTargetVersions 测试目标列表的版本标识、304 响应与增量变更
+func TestTargetVersions(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	apiServer := NewAPIServer(clientManager, result.NewTestResultManager(cacheManager), WithTargetVersions(targets.NewTracker(clientManager, cacheManager))).(*apiServerImpl)
+	heartbeat := func(i int) {
+		nodeInfo := models.NodeInfo{PodName: "pod-" + string(rune('0'+i)), NodeIP: "192.168.1." + string(rune('0'+i)), PodIP: "10.0.0." + string(rune('0'+i))}
+		require.Equal(t, http.StatusOK, postJSON(apiServer.router, "/api/v1/heartbeat", nodeInfo, nil).Code)
+	}
+	get := func(path, etag string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", `"`+etag+`"`)
+		}
+		apiServer.router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w, response
+	}
+	heartbeat(1)
+	heartbeat(2)
+
+	w, response := get("/api/v1/pods", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag, ok := response["etag"].(string)
+	require.True(t, ok)
+	assert.Equal(t, `"`+etag+`"`, w.Header().Get("ETag"))
+	assert.Equal(t, []interface{}{"10.0.0.1", "10.0.0.2"}, response["pod_ips"])
+
+	// 列表未变化时返回 304
+	heartbeat(1)
+	w, _ = get("/api/v1/pods?since="+etag, etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// 列表变化后返回增量
+	heartbeat(3)
+	w, response = get("/api/v1/pods?since="+etag, etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, response["delta"])
+	assert.Equal(t, []interface{}{"10.0.0.3"}, response["added"])
+	assert.Equal(t, []interface{}{}, response["removed"])
+	assert.Equal(t, float64(3), response["count"])
+	assert.Nil(t, response["pod_ips"])
+	assert.NotEqual(t, etag, response["etag"])
+
+	// 宿主机列表同样带版本标识
+	_, response = get("/api/v1/hosts", "")
+	assert.NotEmpty(t, response["etag"])
+	assert.Equal(t, []interface{}{"192.168.1.1", "192.168.1.2", "192.168.1.3"}, response["host_ips"])
+}
+
+// TestGetTargetsEndpoint 测试探测目标对象携带Pod与节点身份，设置探测配置后包含探测端口
+func TestGetTargetsEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	store, err := probeconfig.NewStore(probeconfig.Default())
+	require.NoError(t, err)
+	apiServer := NewAPIServer(clientManager, result.NewTestResultManager(cacheManager),
+		WithTargetVersions(targets.NewTracker(clientManager, cacheManager)),
+		WithProbeConfig(store),
+	).(*apiServerImpl)
+	get := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		apiServer.router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	nodeInfo := models.NodeInfo{PodName: "pod-1", NodeName: "node-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", Zone: "zone-a", Labels: map[string]string{"app": "checker"}}
+	require.Equal(t, http.StatusOK, postJSON(apiServer.router, "/api/v1/heartbeat", nodeInfo, nil).Code)
+
+	code, response := get("/api/v1/targets/pod")
+	require.Equal(t, http.StatusOK, code)
+	etag := response["etag"].(string)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"ip": "10.0.0.1", "pod_name": "pod-1", "node_name": "node-1", "node_ip": "192.168.1.1", "zone": "zone-a",
+		"labels": map[string]interface{}{"app": "checker"},
+	}}, response["targets"])
+
+	// 设置探测配置后目标包含该类型的探测端口
+	cfg := probeconfig.Default()
+	cfg.HostPorts = []int{22, 10250}
+	_, err = store.Update(cfg)
+	require.NoError(t, err)
+	_, response = get("/api/v1/targets/host")
+	hosts := response["targets"].([]interface{})
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "node-1", hosts[0].(map[string]interface{})["node_name"])
+	assert.Equal(t, []interface{}{float64(22), float64(10250)}, hosts[0].(map[string]interface{})["ports"])
+
+	// 增量中包含新增的目标对象
+	nodeInfo = models.NodeInfo{PodName: "pod-2", NodeName: "node-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}
+	require.Equal(t, http.StatusOK, postJSON(apiServer.router, "/api/v1/heartbeat", nodeInfo, nil).Code)
+	_, response = get("/api/v1/targets/pod?since=" + etag)
+	assert.Equal(t, true, response["delta"])
+	added := response["added"].([]interface{})
+	require.Len(t, added, 1)
+	assert.Equal(t, "pod-2", added[0].(map[string]interface{})["pod_name"])
+
+	code, response = get("/api/v1/targets/service")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "INVALID_REQUEST", response["code"])
+}
+
+// postSync 发送 gzip 压缩的同步请求，并接受 gzip 压缩的响应
+func postSync(t *testing.T, router http.Handler, request models.SyncRequest) (*httptest.ResponseRecorder, models.SyncResponse) {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(body)
+	require.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest("POST", "/api/v1/sync", &compressed)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.SyncResponse
+	if w.Code == http.StatusOK {
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(reader).Decode(&response))
+	}
+	return w, response
+}
+
+// TestSyncEndpoint 测试同步接口一次完成心跳、结果上报、目标列表与按需测试任务下发
+func TestSyncEndpoint(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	resultManager := result.NewTestResultManager(cacheManager)
+	runManager := runs.NewManager(clientManager, 0)
+	apiServer := NewAPIServer(clientManager, resultManager,
+		WithRunManager(runManager),
+		WithHeartbeatInterval(10*time.Second),
+		WithSourceIPVerification(),
+	).(*apiServerImpl)
+
+	heartbeat := models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+	for _, nodeInfo := range []models.NodeInfo{heartbeat, {PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}} {
+		require.Equal(t, http.StatusOK, postJSON(apiServer.router, "/api/v1/heartbeat", nodeInfo, nil).Code)
+	}
+
+	// 不支持的协议版本与无效的目标类型
+	w, _ := postSync(t, apiServer.router, models.SyncRequest{Version: models.SyncVersion + 1, Heartbeat: heartbeat})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = postSync(t, apiServer.router, models.SyncRequest{Version: models.SyncVersion, Heartbeat: heartbeat, Targets: []string{"service"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postJSON(apiServer.router, "/api/v1/runs", map[string]interface{}{"test_types": []string{"pod"}, "clients": []string{"pod-1"}}, nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	// 客户端已断开时任务未送达，下一次同步仍会下发
+	body, err := json.Marshal(models.SyncRequest{Version: models.SyncVersion, Heartbeat: heartbeat})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/api/v1/sync", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	apiServer.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	w, response := postSync(t, apiServer.router, models.SyncRequest{
+		Version:   models.SyncVersion,
+		Heartbeat: heartbeat,
+		SourceIP:  "10.0.0.1",
+		Results: []models.SyncResults{
+			{TestType: models.TestTypePod, Results: []models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable"}}},
+			{TestType: "udp", Results: []models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2"}}},
+		},
+		Targets: []string{models.TestTypeHost, models.TestTypePod},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 心跳已注册，结果按批返回处理结果
+	count, err := clientManager.GetActiveClientCount()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, models.SyncVersion, response.Version)
+	assert.Equal(t, models.Duration(10*time.Second), response.HeartbeatInterval)
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, http.StatusOK, response.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, response.Results[1].Status)
+	podResults, err := resultManager.GetPodTestResults()
+	require.NoError(t, err)
+	assert.Len(t, podResults, 1)
+
+	// 只返回请求的目标类型，并下发按需测试任务
+	assert.ElementsMatch(t, []string{"192.168.1.1", "192.168.1.2"}, response.Targets[models.TestTypeHost].IPs)
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2"}, response.Targets[models.TestTypePod].IPs)
+	require.Len(t, response.Runs, 1)
+	assert.Equal(t, []string{"pod"}, response.Runs[0].TestTypes)
+
+	// 任务只下发一次；源IP不属于同步的客户端时拒绝该批结果
+	w, response = postSync(t, apiServer.router, models.SyncRequest{
+		Version:   models.SyncVersion,
+		Heartbeat: heartbeat,
+		SourceIP:  "10.0.0.2",
+		Results: []models.SyncResults{
+			{TestType: models.TestTypeHost, Results: []models.ConnectivityResult{{SourceIP: "10.0.0.2", TargetIP: "192.168.1.1"}}},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, response.Runs)
+	assert.Empty(t, response.Targets)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, http.StatusForbidden, response.Results[0].Status)
+}
+
+// TestAdminEndpointsAuth 测试管理接口的认证
+func TestAdminEndpointsAuth(t *testing.T) {
+	newServer := func(opts ...Option) *apiServerImpl {
+		cacheManager := cache.NewCacheManager()
+		store, err := probeconfig.NewStore(probeconfig.Default())
+		require.NoError(t, err)
+		clientManager := client.NewClientManager(cacheManager)
+		opts = append(opts,
+			WithProbeConfig(store),
+			WithRunManager(runs.NewManager(clientManager, 0)),
+			WithAlertManager(alert.NewManager(alert.Config{}, nil, clientManager, nil)),
+		)
+		return NewAPIServer(clientManager, result.NewTestResultManager(cacheManager), opts...).(*apiServerImpl)
+	}
+	send := func(apiServer *apiServerImpl, method, path, body string, signer auth.Signer) int {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if signer != nil {
+			signer.Sign(req, []byte(body))
+		}
+		w := httptest.NewRecorder()
+		apiServer.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"PUT", "/api/v1/probe-config", `{"pod_ports":[6100]}`},
+		{"POST", "/api/v1/runs", `{"test_types":["pod"]}`},
+		{"POST", "/api/v1/runs/missing/cancel", ``},
+		{"POST", "/api/v1/silences", `{"matchers":{"rule":"pair_failing"},"duration":"1h"}`},
+		{"DELETE", "/api/v1/silences/missing", ``},
+	}
+
+	// 未配置管理员Token时与上报接口使用相同的认证
+	apiServer := newServer(WithAuth(auth.NewTokenVerifier("client")))
+	for _, r := range requests {
+		assert.Equal(t, http.StatusUnauthorized, send(apiServer, r.method, r.path, r.body, nil), r.path)
+		assert.NotEqual(t, http.StatusUnauthorized, send(apiServer, r.method, r.path, r.body, auth.NewTokenSigner("client")), r.path)
+	}
+
+	// 配置管理员Token后客户端凭据不能访问管理接口
+	apiServer = newServer(WithAuth(auth.NewTokenVerifier("client")), WithAdminAuth(auth.NewTokenVerifier("admin")))
+	for _, r := range requests {
+		assert.Equal(t, http.StatusUnauthorized, send(apiServer, r.method, r.path, r.body, auth.NewTokenSigner("client")), r.path)
+		assert.NotEqual(t, http.StatusUnauthorized, send(apiServer, r.method, r.path, r.body, auth.NewTokenSigner("admin")), r.path)
+	}
+
+	// 查询接口不需要认证
+	assert.Equal(t, http.StatusOK, send(apiServer, "GET", "/api/v1/probe-config", "", nil))
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetSLO 获取探测对、节点与集群在 1h、24h、30d 窗口的可用性与错误预算
+// GET /api/v1/slo
+// pairs=false 时不返回探测对明细；node 指定节点时只返回该节点，以及以其为源或目标的探测对
+func (h *Handler) HandleGetSLO(c *gin.Context) {
+	includePairs := true
+	if value := c.Query("pairs"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的pairs参数",
+				Details: value,
+			})
+			return
+		}
+		includePairs = parsed
+	}
+
+	report := h.sloTracker.Snapshot(includePairs)
+	if node := c.Query("node"); node != "" {
+		nodes := make([]models.NodeSLO, 0, 1)
+		for _, n := range report.Nodes {
+			if n.Node == node {
+				nodes = append(nodes, n)
+			}
+		}
+		report.Nodes = nodes
+
+		var pairs []models.PairSLO
+		for _, pair := range report.Pairs {
+			if pair.SourceNode == node || pair.TargetNode == node {
+				pairs = append(pairs, pair)
+			}
+		}
+		report.Pairs = pairs
+	}
+
+	c.JSON(http.StatusOK, report)
+}
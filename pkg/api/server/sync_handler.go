@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleSync 处理客户端同步请求
+// POST /api/v1/sync
+// 一次请求完成心跳、测试结果上报、目标列表获取与按需测试任务下发，替代每轮分别调用的多个接口。
+// 心跳无效时整个请求失败；单批测试结果无效或保存失败只影响该批，在响应的 results 中按顺序返回处理结果
+func (h *Handler) HandleSync(c *gin.Context) {
+	var request models.SyncRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("解析同步请求失败: %v", err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "无效的请求数据",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if request.Version <= 0 || request.Version > models.SyncVersion {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "UNSUPPORTED_VERSION",
+			Message: "不支持的同步协议版本",
+			Details: fmt.Sprintf("version=%d, supported=%d", request.Version, models.SyncVersion),
+		})
+		return
+	}
+
+	nodeInfo := request.Heartbeat
+	if nodeInfo.PodName == "" || nodeInfo.NodeIP == "" || nodeInfo.PodIP == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "缺少必需字段",
+			Details: "PodName, NodeIP, PodIP不能为空",
+		})
+		return
+	}
+	for _, testType := range request.Targets {
+		if testType != models.TestTypeHost && testType != models.TestTypePod {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "无效的目标类型",
+				Details: testType,
+			})
+			return
+		}
+	}
+
+	if !h.checkClientIdentity(c, nodeInfo.PodName) {
+		return
+	}
+
+	if err := h.clientManager.HandleHeartbeat(&nodeInfo); err != nil {
+		log.Printf("处理心跳失败: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "处理心跳失败",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := models.SyncResponse{
+		HeartbeatResponse: h.heartbeatResponse(&nodeInfo, "同步成功"),
+		Version:           models.SyncVersion,
+	}
+
+	for _, batch := range request.Results {
+		response.Results = append(response.Results, h.saveSyncResults(&request, &nodeInfo, batch))
+	}
+
+	if len(request.Targets) > 0 {
+		response.Targets = make(map[string]models.SyncTargets, len(request.Targets))
+		for _, testType := range request.Targets {
+			targets, err := h.syncTargets(nodeInfo.PodName, testType)
+			if err != nil {
+				log.Printf("获取目标列表失败: type=%s, error=%v", testType, err)
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+					Code:    "INTERNAL_ERROR",
+					Message: "获取目标列表失败",
+					Details: err.Error(),
+				})
+				return
+			}
+			response.Targets[testType] = targets
+		}
+	}
+
+	if h.runManager != nil {
+		// 不等待新任务，只取出已有的任务，等待时长由客户端的同步间隔决定
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		cancel()
+		response.Runs = h.runManager.Poll(ctx, nodeInfo.PodName)
+		if len(response.Runs) > 0 {
+			log.Printf("下发按需测试任务: pod=%s, count=%d", nodeInfo.PodName, len(response.Runs))
+		}
+	}
+
+	log.Printf("同步成功: pod=%s, result_batches=%d, targets=%v", nodeInfo.PodName, len(request.Results), request.Targets)
+	if h.runManager == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	h.writeRuns(c, nodeInfo.PodName, response.Runs, response)
+}
+
+// saveSyncResults 保存同步请求中的一批测试结果
+// 启用源IP校验时，源IP需属于本次同步的客户端
+func (h *Handler) saveSyncResults(request *models.SyncRequest, nodeInfo *models.NodeInfo, batch models.SyncResults) models.SyncResultStatus {
+	if request.SourceIP == "" || len(batch.Results) == 0 {
+		return models.SyncResultStatus{Status: http.StatusBadRequest, Code: "INVALID_REQUEST", Message: "缺少源IP或测试结果"}
+	}
+	if h.verifySourceIP && request.SourceIP != nodeInfo.PodIP && request.SourceIP != nodeInfo.NodeIP {
+		log.Printf("拒绝测试结果: 源IP %s 不属于同步的客户端 %s", request.SourceIP, nodeInfo.PodName)
+		return models.SyncResultStatus{Status: http.StatusForbidden, Code: "FORBIDDEN", Message: "源IP不属于已注册的客户端"}
+	}
+
+	if !validResultType(batch.TestType) {
+		return models.SyncResultStatus{Status: http.StatusBadRequest, Code: "INVALID_REQUEST", Message: "无效的测试类型: " + batch.TestType}
+	}
+	if err := h.saveResults(batch.TestType, request.SourceIP, batch.Results); err != nil {
+		log.Printf("保存测试结果失败: type=%s, error=%v", batch.TestType, err)
+		return models.SyncResultStatus{Status: http.StatusInternalServerError, Code: "CACHE_ERROR", Message: err.Error()}
+	}
+
+	log.Printf("测试结果保存成功: type=%s, source_ip=%s, results_count=%d",
+		batch.TestType, request.SourceIP, len(batch.Results))
+	return models.SyncResultStatus{Status: http.StatusOK}
+}
+
+// validResultType 判断是否为可上报的测试类型
+func validResultType(testType string) bool {
+	return testType == models.TestTypeHost || testType == models.TestTypePod || testType == models.TestTypeService
+}
+
+// saveResults 按测试类型保存一批测试结果，自定义服务的结果逐条保存
+func (h *Handler) saveResults(testType, sourceIP string, results []models.ConnectivityResult) error {
+	switch testType {
+	case models.TestTypeHost:
+		return h.resultManager.SaveHostTestResults(sourceIP, results)
+	case models.TestTypePod:
+		return h.resultManager.SavePodTestResults(sourceIP, results)
+	default:
+		for i := range results {
+			if err := h.resultManager.SaveServiceTestResult(sourceIP, &results[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// syncTargets 返回客户端本轮应测试的目标，未启用分片时返回全部目标
+func (h *Handler) syncTargets(podName, testType string) (models.SyncTargets, error) {
+	ips, shard, _ := h.assignTargets(podName, testType)
+	targets, err := h.targetObjects(testType, ips)
+	if err != nil {
+		return models.SyncTargets{}, err
+	}
+	return models.SyncTargets{IPs: models.TargetIPs(targets), Targets: targets, Shard: shard}, nil
+}
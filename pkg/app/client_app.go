@@ -2,19 +2,27 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/api/client"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/clientserver"
 	"github.com/yezihack/k8snet-checker/pkg/collector"
 	"github.com/yezihack/k8snet-checker/pkg/config"
 	"github.com/yezihack/k8snet-checker/pkg/heartbeat"
 	"github.com/yezihack/k8snet-checker/pkg/logger"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
 	"github.com/yezihack/k8snet-checker/pkg/network"
+	"github.com/yezihack/k8snet-checker/pkg/outbox"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
 	"github.com/yezihack/k8snet-checker/pkg/scheduler"
+	"github.com/yezihack/k8snet-checker/pkg/tlsutil"
 
 	"go.uber.org/zap"
 )
@@ -26,6 +34,7 @@ type ClientApp struct {
 	heartbeatReporter heartbeat.HeartbeatReporter
 	clientServer      clientserver.ClientServer
 	testScheduler     *scheduler.TestScheduler
+	probeExecutor     network.Executor
 	config            *config.ClientConfig
 	logger            *zap.Logger
 }
@@ -54,11 +63,14 @@ func NewClientApp() (*ClientApp, error) {
 func initializeComponents(cfg *config.ClientConfig, log *zap.Logger) (*ClientApp, error) {
 	log.Info("配置加载成功",
 		zap.String("server_url", cfg.ServerURL),
+		zap.String("transport", cfg.Transport),
 		zap.Duration("heartbeat_interval", cfg.HeartbeatInterval),
 		zap.Int("test_port", cfg.TestPort),
 		zap.Int("service_port", cfg.ServicePort),
 		zap.Int("client_port", cfg.ClientPort),
 		zap.String("custom_service_name", cfg.CustomServiceName),
+		zap.Duration("test_interval", cfg.TestInterval),
+		zap.Duration("test_jitter", cfg.TestJitter),
 	)
 
 	// 初始化信息收集器
@@ -77,27 +89,112 @@ func initializeComponents(cfg *config.ClientConfig, log *zap.Logger) (*ClientApp
 		zap.String("namespace", nodeInfo.Namespace),
 	)
 
+	// 初始化探测配置，版本0表示使用环境变量配置，服务器下发配置后替换
+	probeStore, err := probeconfig.NewStore(initialProbeConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
 	// 初始化API客户端
-	apiClient := client.NewAPIClient(cfg.ServerURL, nodeInfo.PodIP)
+	signer, err := auth.NewClientSigner(cfg.AuthMode, cfg.AuthToken, nodeInfo.PodName, cfg.AuthHMACKey)
+	if err != nil {
+		return nil, err
+	}
+	clientOptions := []client.Option{
+		client.WithPodName(nodeInfo.PodName),
+		client.WithRetryPolicy(cfg.RetryMaxAttempts, cfg.RetryBaseDelay, cfg.RetryMaxDelay),
+	}
+	if signer != nil {
+		log.Info("已启用请求认证", zap.String("auth_mode", cfg.AuthMode))
+		clientOptions = append(clientOptions, client.WithSigner(signer))
+	}
+	if cfg.TLSCertFile != "" || cfg.TLSCAFile != "" {
+		reloader, err := tlsutil.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile, 0)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("已启用TLS", zap.Bool("client_certificate", cfg.TLSCertFile != ""))
+		clientOptions = append(clientOptions, client.WithTLSConfig(func() *tls.Config {
+			return tlsutil.ClientConfig(reloader, cfg.TLSServerName)
+		}))
+	}
+	var apiClient client.APIClient
+	switch cfg.Transport {
+	case "http":
+		if cfg.SyncEnabled {
+			log.Info("已启用同步模式，测试结果随心跳上报")
+			clientOptions = append(clientOptions, client.WithSync())
+		}
+		apiClient = client.NewAPIClient(cfg.ServerURL, nodeInfo.PodIP, clientOptions...)
+	case "grpc":
+		if cfg.SyncEnabled {
+			log.Warn("gRPC方式不支持同步模式，忽略SYNC_ENABLED")
+		}
+		log.Info("使用gRPC与服务器通信", zap.String("grpc_address", cfg.GRPCAddress))
+		clientOptions = append(clientOptions, client.WithProbeConfig(probeStore))
+		apiClient, err = client.NewGRPCClient(cfg.GRPCAddress, nodeInfo.PodIP, clientOptions...)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("无效的TRANSPORT: %s，可选值为 http、grpc", cfg.Transport)
+	}
+
+	// 初始化探测执行器，宿主机、Pod与服务测试共享并发与速率预算
+	probeExecutor := network.NewExecutor(cfg.MaxConcurrency, cfg.ProbeRateLimit)
 
 	// 初始化网络测试器
+	testerOptions := []network.Option{network.WithExecutor(probeExecutor)}
+	if cfg.SkipSameNode {
+		log.Info("跳过同节点的Pod目标", zap.String("node_ip", nodeInfo.NodeIP))
+		testerOptions = append(testerOptions, network.WithSameNodeSkip(nodeInfo.NodeIP))
+	}
 	networkTester := network.NewNetworkTester(
 		nodeInfo.PodIP,
 		cfg.TestPort,
-		6100,            // Pod端口固定为6100
-		cfg.ServicePort, // 自定义服务端口
-		10,              // 最大并发数为10
+		6100,               // Pod端口固定为6100
+		cfg.ServicePort,    // 自定义服务端口
+		cfg.MaxConcurrency, // 最大并发数
 		log,
+		testerOptions...,
 	)
 
-	// 初始化心跳上报器
-	heartbeatReporter := heartbeat.NewHeartbeatReporter(infoCollector, apiClient)
+	// 初始化客户端指标
+	clientMetrics := metrics.NewClientMetrics()
+
+	// 初始化测试调度器
+	schedulerOptions := []scheduler.Option{scheduler.WithRemoteRuns(nodeInfo.PodName)}
+	if cfg.OutboxSize > 0 {
+		resultOutbox, err := outbox.New(cfg.OutboxSize, cfg.OutboxDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("已启用结果离线缓存",
+			zap.Int("capacity", cfg.OutboxSize),
+			zap.String("dir", cfg.OutboxDir),
+			zap.Int("pending", resultOutbox.Len()),
+		)
+		schedulerOptions = append(schedulerOptions, scheduler.WithOutbox(resultOutbox))
+	}
+	testScheduler := scheduler.NewTestScheduler(apiClient, networkTester, cfg.CustomServiceName, clientMetrics, log,
+		schedulerOptions...,
+	)
+
+	// 初始化心跳上报器，服务器恢复后通知测试调度器立即重放离线期间的结果
+	heartbeatReporter := heartbeat.NewHeartbeatReporter(infoCollector, apiClient, clientMetrics,
+		heartbeat.WithProbeConfig(probeStore),
+		heartbeat.WithMaxBackoff(cfg.HeartbeatBackoff),
+		heartbeat.WithRecoveryObserver(testScheduler),
+	)
 
 	// 初始化客户端HTTP服务器
-	clientServer := clientserver.NewClientServer()
+	clientServer := clientserver.NewClientServer(clientMetrics, clientserver.WithServerStatus(heartbeatReporter))
 
-	// 初始化测试调度器
-	testScheduler := scheduler.NewTestScheduler(apiClient, networkTester, cfg.CustomServiceName, log)
+	// 应用初始探测配置，之后探测配置变更时实时更新网络测试器与测试调度器
+	for _, observer := range []probeconfig.Observer{networkTester, testScheduler} {
+		observer.OnProbeConfig(probeStore.Get())
+		probeStore.AddObserver(observer)
+	}
 
 	// 创建主上下文
 	ctx, cancel := context.WithCancel(context.Background())
@@ -108,11 +205,39 @@ func initializeComponents(cfg *config.ClientConfig, log *zap.Logger) (*ClientApp
 		heartbeatReporter: heartbeatReporter,
 		clientServer:      clientServer,
 		testScheduler:     testScheduler,
+		probeExecutor:     probeExecutor,
 		config:            cfg,
 		logger:            log,
 	}, nil
 }
 
+// initialProbeConfig 根据环境变量构建初始探测配置
+func initialProbeConfig(cfg *config.ClientConfig) models.ProbeConfig {
+	probeCfg := probeconfig.Default()
+	if cfg.TestPort > 0 {
+		probeCfg.HostPorts = []int{cfg.TestPort}
+	}
+	if cfg.ServicePort > 0 {
+		probeCfg.ServicePorts = []int{cfg.ServicePort}
+	}
+	probeCfg.ServiceName = cfg.CustomServiceName
+	probeCfg.TestInterval = models.Duration(cfg.TestInterval)
+	probeCfg.HostInterval = models.Duration(cfg.HostTestInterval)
+	probeCfg.PodInterval = models.Duration(cfg.PodTestInterval)
+	probeCfg.ServiceInterval = models.Duration(cfg.ServiceTestInterval)
+	probeCfg.PingInterval = models.Duration(cfg.PingInterval)
+	probeCfg.InitialDelay = models.Duration(cfg.TestInitialDelay)
+	probeCfg.Jitter = models.Duration(cfg.TestJitter)
+	if cfg.MaxConcurrency > 0 {
+		probeCfg.MaxConcurrency = cfg.MaxConcurrency
+	}
+	probeCfg.RateLimit = cfg.ProbeRateLimit
+	probeCfg.PingTimeout = models.Duration(cfg.PingTimeout)
+	probeCfg.Timeout = models.Duration(cfg.PortTimeout)
+	probeCfg.DNSTimeout = models.Duration(cfg.DNSTimeout)
+	return probeCfg
+}
+
 // Run 运行应用程序
 func (a *ClientApp) Run() error {
 	// 启动所有服务
@@ -166,6 +291,9 @@ func (a *ClientApp) shutdown() {
 	// 取消上下文，停止所有goroutine
 	a.cancel()
 
+	// 关闭探测执行器，尚未开始的探测不再执行
+	a.probeExecutor.Close()
+
 	// 等待一小段时间，确保所有goroutine完成
 	time.Sleep(1 * time.Second)
 }
@@ -10,12 +10,23 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/alert"
 	"github.com/yezihack/k8snet-checker/pkg/api/server"
+	"github.com/yezihack/k8snet-checker/pkg/auth"
 	"github.com/yezihack/k8snet-checker/pkg/cache"
 	"github.com/yezihack/k8snet-checker/pkg/client"
 	"github.com/yezihack/k8snet-checker/pkg/config"
+	"github.com/yezihack/k8snet-checker/pkg/events"
+	"github.com/yezihack/k8snet-checker/pkg/latency"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
 	"github.com/yezihack/k8snet-checker/pkg/report"
 	"github.com/yezihack/k8snet-checker/pkg/result"
+	"github.com/yezihack/k8snet-checker/pkg/runs"
+	"github.com/yezihack/k8snet-checker/pkg/sharding"
+	"github.com/yezihack/k8snet-checker/pkg/slo"
+	"github.com/yezihack/k8snet-checker/pkg/targets"
+	"github.com/yezihack/k8snet-checker/pkg/tlsutil"
 )
 
 // ServerApp 服务器应用程序
@@ -23,7 +34,10 @@ type ServerApp struct {
 	ctx             context.Context
 	cancel          context.CancelFunc
 	apiServer       server.APIServer
+	grpcServer      server.GRPCServer // 可选，未配置GRPC_PORT时为nil
 	reportGenerator report.ReportGenerator
+	alertManager    alert.Manager
+	sloTracker      slo.Tracker
 	config          *config.ServerConfig
 }
 
@@ -65,15 +79,149 @@ func initializeServerComponents(cfg *config.ServerConfig) (*ServerApp, error) {
 
 	// 初始化测试结果管理器
 	log.Println("初始化测试结果管理器...")
-	resultManager := result.NewTestResultManager(cacheManager)
+	resultOptions := []result.Option{result.WithHealthConfig(result.HealthConfig{
+		FailureThreshold:  cfg.PairFailureThreshold,
+		RecoveryThreshold: cfg.PairRecoveryThreshold,
+		FlapThreshold:     cfg.PairFlapThreshold,
+		FlapWindow:        cfg.PairFlapWindow,
+		RecoveredHold:     cfg.PairRecoveredHold,
+	})}
+	if cfg.TargetsPerCycle > 0 {
+		// 启用分片时每次上报只包含部分目标，合并保留其他目标的最近结果
+		resultOptions = append(resultOptions, result.WithRetention(cfg.ShardResultMaxAge))
+	}
+	resultManager := result.NewTestResultManager(cacheManager, resultOptions...)
+	clientManager.AddObserver(resultManager)
+
+	// 初始化事件总线，将心跳与测试结果转换为实时事件
+	log.Println("初始化事件总线...")
+	eventBus := events.NewBus()
+	eventObserver := events.NewObserver(eventBus)
+	clientManager.AddObserver(eventObserver)
+	resultManager.AddObserver(eventObserver)
+	resultManager.AddHealthObserver(eventObserver)
+
+	// 初始化按需测试任务管理器，收集带有任务ID的测试结果
+	log.Println("初始化按需测试任务管理器...")
+	runManager := runs.NewManager(clientManager, cfg.RunTimeout)
+	resultManager.AddObserver(runManager)
+
+	// 初始化探测配置，未配置文件时版本为0，客户端使用各自的环境变量配置，直到通过接口设置配置
+	log.Println("初始化探测配置...")
+	probeConfig, err := loadProbeConfig(cfg.ProbeConfigFile)
+	if err != nil {
+		return nil, err
+	}
 
 	// 初始化报告生成器
 	log.Println("初始化报告生成器...")
-	reportGenerator := report.NewReportGenerator(clientManager, resultManager)
+	reportHistory := report.NewHistory(cfg.ReportHistorySize)
+	reportOutputs, err := buildReportOutputs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 历史记录放在最前，为报告分配ID后再写入其他输出
+	reportOutputs = append([]report.Output{reportHistory}, reportOutputs...)
+	reportGenerator := report.NewReportGenerator(clientManager, resultManager, reportOutputs...)
+
+	// 初始化可用性统计，记录每次探测结果，在报告中加入集群与节点的可用性
+	log.Println("初始化可用性统计...")
+	sloTracker, err := slo.NewTracker(slo.Config{
+		Objective: cfg.SLOObjective,
+		StateFile: cfg.SLOStateFile,
+	}, clientManager)
+	if err != nil {
+		return nil, fmt.Errorf("初始化可用性统计失败: %w", err)
+	}
+	resultManager.AddObserver(sloTracker)
+	reportGenerator.AddEnricher(sloTracker)
+
+	// 初始化延迟基线，在报告与告警中加入延迟异常
+	log.Println("初始化延迟基线...")
+	latencyDetector := latency.NewDetector(latency.Config{
+		Alpha:       cfg.LatencyEWMAAlpha,
+		Ratio:       cfg.LatencyAnomalyRatio,
+		MinDelta:    cfg.LatencyAnomalyMinDelta,
+		MinSamples:  cfg.LatencyBaselineMinSamples,
+		Consecutive: cfg.LatencyAnomalyConsecutive,
+		Rebaseline:  cfg.LatencyRebaseline,
+	})
+	resultManager.AddObserver(latencyDetector)
+	reportGenerator.AddEnricher(latencyDetector)
+
+	// 初始化告警管理器
+	log.Println("初始化告警管理器...")
+	notifiers, err := alert.ParseNotifiers(cfg.AlertWebhooks)
+	if err != nil {
+		return nil, fmt.Errorf("解析ALERT_WEBHOOKS失败: %w", err)
+	}
+	alertManager := alert.NewManager(alert.Config{
+		SuccessRateThreshold: cfg.AlertSuccessRateThreshold,
+		RepeatInterval:       cfg.AlertRepeatInterval,
+		MissingClientTTL:     cfg.AlertMissingClientTTL,
+	}, reportGenerator, clientManager, notifiers)
 
 	// 初始化HTTP服务器
 	log.Println("初始化HTTP服务器...")
-	apiServer := server.NewAPIServer(clientManager, resultManager)
+	serverOptions := []server.Option{
+		server.WithAlertManager(alertManager),
+		server.WithReportGenerator(reportGenerator),
+		server.WithReportHistory(reportHistory),
+		server.WithEventBus(eventBus),
+		server.WithRunManager(runManager),
+		server.WithProbeConfig(probeConfig),
+		server.WithHeartbeatInterval(cfg.HeartbeatInterval),
+		server.WithTargetVersions(targets.NewTracker(clientManager, cacheManager)),
+		server.WithSLOTracker(sloTracker),
+		server.WithLatencyDetector(latencyDetector),
+		server.WithMetrics(metrics.NewServerMetrics(sloTracker).Handler()),
+	}
+	if cfg.DashboardEnabled {
+		serverOptions = append(serverOptions, server.WithDashboard())
+	}
+	if cfg.TargetsPerCycle > 0 {
+		log.Printf("已启用目标分片: targets_per_cycle=%d, result_max_age=%s", cfg.TargetsPerCycle, cfg.ShardResultMaxAge)
+		planner := sharding.NewPlanner(clientManager, cfg.TargetsPerCycle)
+		resultManager.AddObserver(planner)
+		serverOptions = append(serverOptions, server.WithSharding(planner))
+	}
+	verifier, err := auth.NewServerVerifier(cfg.AuthMode, cfg.AuthToken, cfg.AuthHMACKeys, cfg.AuthHMACSecret)
+	if err != nil {
+		return nil, fmt.Errorf("初始化认证失败: %w", err)
+	}
+	if verifier != nil {
+		log.Printf("上报接口已启用认证: mode=%s", cfg.AuthMode)
+		serverOptions = append(serverOptions, server.WithAuth(verifier))
+	}
+	if cfg.AdminToken != "" {
+		log.Println("管理接口已启用管理员Token认证")
+		serverOptions = append(serverOptions, server.WithAdminAuth(auth.NewTokenVerifier(cfg.AdminToken)))
+	}
+	if cfg.AuthVerifySourceIP {
+		log.Println("已启用源IP校验")
+		serverOptions = append(serverOptions, server.WithSourceIPVerification())
+	}
+	if cfg.TLSCertFile != "" {
+		reloader, err := tlsutil.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile, 0)
+		if err != nil {
+			return nil, fmt.Errorf("加载TLS证书失败: %w", err)
+		}
+		serverOptions = append(serverOptions, server.WithTLS(tlsutil.ServerConfig(reloader)))
+		if cfg.TLSClientCAFile != "" {
+			log.Println("已启用双向TLS，上报接口要求客户端证书")
+			serverOptions = append(serverOptions, server.WithClientCertificates())
+		}
+	} else if cfg.TLSClientCAFile != "" {
+		return nil, fmt.Errorf("配置TLS_CLIENT_CA_FILE时需同时配置TLS_CERT_FILE和TLS_KEY_FILE")
+	}
+	apiServer := server.NewAPIServer(clientManager, resultManager, serverOptions...)
+
+	// 初始化gRPC服务器，与HTTP服务器使用相同的配置
+	var grpcServer server.GRPCServer
+	if cfg.GRPCPort != "" {
+		log.Println("初始化gRPC服务器...")
+		grpcServer = server.NewGRPCServer(clientManager, resultManager, serverOptions...)
+	}
 
 	// 创建主上下文
 	ctx, cancel := context.WithCancel(context.Background())
@@ -82,7 +230,10 @@ func initializeServerComponents(cfg *config.ServerConfig) (*ServerApp, error) {
 		ctx:             ctx,
 		cancel:          cancel,
 		apiServer:       apiServer,
+		grpcServer:      grpcServer,
 		reportGenerator: reportGenerator,
+		alertManager:    alertManager,
+		sloTracker:      sloTracker,
 		config:          cfg,
 	}, nil
 }
@@ -113,6 +264,18 @@ func (a *ServerApp) start(serverErrors chan error) error {
 		return fmt.Errorf("启动报告生成器失败: %w", err)
 	}
 
+	// 启动告警管理器
+	log.Printf("启动告警管理器，评估间隔: %v", a.config.AlertEvalInterval)
+	if err := a.alertManager.Start(a.ctx, a.config.AlertEvalInterval); err != nil {
+		return fmt.Errorf("启动告警管理器失败: %w", err)
+	}
+
+	// 启动可用性统计
+	log.Printf("启动可用性统计，保存间隔: %v", a.config.SLOSaveInterval)
+	if err := a.sloTracker.Start(a.ctx, a.config.SLOSaveInterval); err != nil {
+		return fmt.Errorf("启动可用性统计失败: %w", err)
+	}
+
 	// 在独立goroutine中启动HTTP服务器
 	go func() {
 		log.Printf("HTTP服务器启动在端口: %s", a.config.HTTPPort)
@@ -121,6 +284,15 @@ func (a *ServerApp) start(serverErrors chan error) error {
 		}
 	}()
 
+	// 在独立goroutine中启动gRPC服务器
+	if a.grpcServer != nil {
+		go func() {
+			if err := a.grpcServer.Start(a.config.GRPCPort); err != nil {
+				serverErrors <- fmt.Errorf("gRPC服务器启动失败: %w", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -131,6 +303,11 @@ func (a *ServerApp) shutdown() {
 	// 取消context，停止报告生成器
 	a.cancel()
 
+	// 关闭gRPC服务器，断开客户端的推送连接
+	if a.grpcServer != nil {
+		a.grpcServer.Stop()
+	}
+
 	// 给服务器一些时间来完成正在处理的请求
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
@@ -176,3 +353,49 @@ func setupLogging(logLevel string) {
 		log.Printf("未知的日志级别: %s，使用默认级别INFO", logLevel)
 	}
 }
+
+// loadProbeConfig 创建探测配置存储，从文件加载时以当前 Unix 时间作为版本号
+func loadProbeConfig(path string) (probeconfig.Store, error) {
+	if path == "" {
+		return probeconfig.NewStore(probeconfig.Default())
+	}
+
+	probeCfg, err := probeconfig.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("加载PROBE_CONFIG_FILE失败: %w", err)
+	}
+	probeCfg.Version = time.Now().Unix()
+	probeCfg.UpdatedAt = time.Now()
+	log.Printf("已加载探测配置: file=%s, version=%d", path, probeCfg.Version)
+	return probeconfig.NewStore(probeCfg)
+}
+
+// buildReportOutputs 根据配置构建报告输出目标
+func buildReportOutputs(cfg *config.ServerConfig) ([]report.Output, error) {
+	var outputs []report.Output
+
+	if cfg.ReportFormat != "none" {
+		renderer, err := report.NewRenderer(cfg.ReportFormat)
+		if err != nil {
+			return nil, fmt.Errorf("解析REPORT_FORMAT失败: %w", err)
+		}
+		outputs = append(outputs, report.NewWriterOutput(os.Stdout, renderer))
+	}
+
+	if cfg.ReportOutputDir != "" {
+		renderers, err := report.ParseRenderers(cfg.ReportFileFormats)
+		if err != nil {
+			return nil, fmt.Errorf("解析REPORT_FILE_FORMATS失败: %w", err)
+		}
+		for _, renderer := range renderers {
+			output, err := report.NewFileOutput(cfg.ReportOutputDir, renderer, cfg.ReportMaxFiles)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+		log.Printf("报告文件输出目录: %s, 格式: %s, 保留数量: %d", cfg.ReportOutputDir, cfg.ReportFileFormats, cfg.ReportMaxFiles)
+	}
+
+	return outputs, nil
+}
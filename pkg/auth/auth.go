@@ -0,0 +1,220 @@
+// Package auth 实现客户端与服务器之间的请求认证
+// 支持两种方式：共享 Bearer Token，以及按客户端区分密钥的 HMAC-SHA256 请求签名
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 认证方式
+const (
+	ModeNone  = "none"
+	ModeToken = "token"
+	ModeHMAC  = "hmac"
+)
+
+// HMAC签名使用的请求头
+const (
+	HeaderClientID  = "X-K8snet-Client-ID"
+	HeaderTimestamp = "X-K8snet-Timestamp"
+	HeaderSignature = "X-K8snet-Signature"
+)
+
+// DefaultMaxClockSkew 默认允许的客户端与服务器时钟偏差，超出视为重放请求
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// ErrUnauthorized 认证失败
+var ErrUnauthorized = errors.New("认证失败")
+
+// Signer 在客户端为请求添加认证信息
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// Verifier 在服务器端校验请求的认证信息
+// 校验成功时返回客户端标识，Token 方式没有客户端标识，返回空字符串
+type Verifier interface {
+	Verify(req *http.Request, body []byte) (clientID string, err error)
+}
+
+// tokenAuth 共享 Bearer Token 认证，同时实现 Signer 和 Verifier
+type tokenAuth struct {
+	token string
+}
+
+// NewTokenSigner 创建 Bearer Token 签名器
+func NewTokenSigner(token string) Signer {
+	return &tokenAuth{token: token}
+}
+
+// NewTokenVerifier 创建 Bearer Token 校验器
+func NewTokenVerifier(token string) Verifier {
+	return &tokenAuth{token: token}
+}
+
+// Sign 添加 Authorization 请求头
+func (a *tokenAuth) Sign(req *http.Request, body []byte) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// Verify 校验 Authorization 请求头
+func (a *tokenAuth) Verify(req *http.Request, body []byte) (string, error) {
+	header := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if header == "" || token == header {
+		return "", fmt.Errorf("%w: 缺少Bearer Token", ErrUnauthorized)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		return "", fmt.Errorf("%w: Token无效", ErrUnauthorized)
+	}
+	return "", nil
+}
+
+// hmacSigner 使用客户端自己的密钥签名请求
+type hmacSigner struct {
+	clientID string
+	key      []byte
+	now      func() time.Time
+}
+
+// NewHMACSigner 创建 HMAC 签名器，clientID 为客户端标识（Pod名称）
+func NewHMACSigner(clientID string, key []byte) Signer {
+	return &hmacSigner{
+		clientID: clientID,
+		key:      key,
+		now:      time.Now,
+	}
+}
+
+// Sign 添加客户端标识、时间戳和签名请求头
+func (s *hmacSigner) Sign(req *http.Request, body []byte) error {
+	timestamp := s.now().Unix()
+	req.Header.Set(HeaderClientID, s.clientID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, ComputeSignature(s.key, req.Method, req.URL.Path, req.URL.RawQuery, s.clientID, timestamp, body))
+	return nil
+}
+
+// hmacVerifier 根据客户端标识查找密钥并校验签名
+type hmacVerifier struct {
+	keys    KeyStore
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewHMACVerifier 创建 HMAC 校验器，maxSkew <= 0 时使用 DefaultMaxClockSkew
+func NewHMACVerifier(keys KeyStore, maxSkew time.Duration) Verifier {
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxClockSkew
+	}
+	return &hmacVerifier{
+		keys:    keys,
+		maxSkew: maxSkew,
+		now:     time.Now,
+	}
+}
+
+// Verify 校验签名与时间戳
+func (v *hmacVerifier) Verify(req *http.Request, body []byte) (string, error) {
+	clientID := req.Header.Get(HeaderClientID)
+	signature := req.Header.Get(HeaderSignature)
+	if clientID == "" || signature == "" {
+		return "", fmt.Errorf("%w: 缺少签名请求头", ErrUnauthorized)
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: 时间戳无效", ErrUnauthorized)
+	}
+	skew := v.now().Sub(time.Unix(timestamp, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return "", fmt.Errorf("%w: 时间戳超出允许范围", ErrUnauthorized)
+	}
+
+	key, ok := v.keys.Key(clientID)
+	if !ok {
+		return "", fmt.Errorf("%w: 未知客户端 %s", ErrUnauthorized, clientID)
+	}
+
+	expected := ComputeSignature(key, req.Method, req.URL.Path, req.URL.RawQuery, clientID, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", fmt.Errorf("%w: 签名不匹配", ErrUnauthorized)
+	}
+	return clientID, nil
+}
+
+// ComputeSignature 计算请求签名
+// 签名内容为 方法、路径、查询参数、客户端标识、时间戳和请求体SHA256摘要，以换行分隔
+func ComputeSignature(key []byte, method, path, query, clientID string, timestamp int64, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%d\n%s", method, path, query, clientID, timestamp, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewClientSigner 根据认证方式创建客户端签名器，mode 为空或 none 时返回 nil
+// HMAC 方式使用为当前客户端单独分发的密钥，客户端不持有主密钥
+func NewClientSigner(mode, token, clientID, hmacKey string) (Signer, error) {
+	switch mode {
+	case "", ModeNone:
+		return nil, nil
+	case ModeToken:
+		if token == "" {
+			return nil, fmt.Errorf("token认证方式需要配置Token")
+		}
+		return NewTokenSigner(token), nil
+	case ModeHMAC:
+		if clientID == "" {
+			return nil, fmt.Errorf("hmac认证方式需要客户端标识")
+		}
+		if hmacKey == "" {
+			return nil, fmt.Errorf("hmac认证方式需要配置客户端密钥")
+		}
+		return NewHMACSigner(clientID, []byte(hmacKey)), nil
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", mode)
+	}
+}
+
+// NewServerVerifier 根据认证方式创建服务器校验器，mode 为空或 none 时返回 nil
+// HMAC 方式先查找 hmacKeys 中静态配置的客户端密钥，再使用 hmacSecret 派生
+func NewServerVerifier(mode, token, hmacKeys, hmacSecret string) (Verifier, error) {
+	switch mode {
+	case "", ModeNone:
+		return nil, nil
+	case ModeToken:
+		if token == "" {
+			return nil, fmt.Errorf("token认证方式需要配置Token")
+		}
+		return NewTokenVerifier(token), nil
+	case ModeHMAC:
+		var stores []KeyStore
+		if hmacKeys != "" {
+			store, err := ParseKeys(hmacKeys)
+			if err != nil {
+				return nil, err
+			}
+			stores = append(stores, store)
+		}
+		if hmacSecret != "" {
+			stores = append(stores, NewDerivedKeyStore(hmacSecret))
+		}
+		if len(stores) == 0 {
+			return nil, fmt.Errorf("hmac认证方式需要配置客户端密钥或主密钥")
+		}
+		return NewHMACVerifier(NewChainKeyStore(stores...), 0), nil
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", mode)
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRequest 构造测试请求
+func newRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/api/v1/test-results/pods", bytes.NewBufferString(body))
+}
+
+// TestTokenAuth 测试Bearer Token认证
+func TestTokenAuth(t *testing.T) {
+	verifier := NewTokenVerifier("secret-token")
+
+	req := newRequest("{}")
+	require.NoError(t, NewTokenSigner("secret-token").Sign(req, []byte("{}")))
+	clientID, err := verifier.Verify(req, []byte("{}"))
+	assert.NoError(t, err)
+	assert.Empty(t, clientID)
+
+	req = newRequest("{}")
+	require.NoError(t, NewTokenSigner("wrong").Sign(req, []byte("{}")))
+	_, err = verifier.Verify(req, []byte("{}"))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = verifier.Verify(newRequest("{}"), []byte("{}"))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+// TestHMACAuth 测试HMAC签名认证
+func TestHMACAuth(t *testing.T) {
+	keys, err := ParseKeys("pod-1=key-1")
+	require.NoError(t, err)
+	verifier := NewHMACVerifier(NewChainKeyStore(keys, NewDerivedKeyStore("master")), time.Minute)
+
+	body := []byte(`{"source_ip":"10.0.0.1"}`)
+
+	// 静态密钥
+	req := newRequest(string(body))
+	require.NoError(t, NewHMACSigner("pod-1", []byte("key-1")).Sign(req, body))
+	clientID, err := verifier.Verify(req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-1", clientID)
+
+	// 派生密钥
+	req = newRequest(string(body))
+	require.NoError(t, NewHMACSigner("pod-2", DeriveKey("master", "pod-2")).Sign(req, body))
+	clientID, err = verifier.Verify(req, body)
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-2", clientID)
+
+	// 请求体被篡改
+	req = newRequest(string(body))
+	require.NoError(t, NewHMACSigner("pod-1", []byte("key-1")).Sign(req, body))
+	_, err = verifier.Verify(req, []byte(`{"source_ip":"10.0.0.9"}`))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// 查询参数被篡改
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/runs/pending?pod_name=pod-1", nil)
+	require.NoError(t, NewHMACSigner("pod-1", []byte("key-1")).Sign(req, nil))
+	_, err = verifier.Verify(req, nil)
+	assert.NoError(t, err)
+	req.URL.RawQuery = "pod_name=pod-2"
+	_, err = verifier.Verify(req, nil)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// 冒用其他客户端标识
+	req = newRequest(string(body))
+	require.NoError(t, NewHMACSigner("pod-1", []byte("key-1")).Sign(req, body))
+	req.Header.Set(HeaderClientID, "pod-2")
+	_, err = verifier.Verify(req, body)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// 时间戳过旧
+	signer := NewHMACSigner("pod-1", []byte("key-1")).(*hmacSigner)
+	signer.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	req = newRequest(string(body))
+	require.NoError(t, signer.Sign(req, body))
+	_, err = verifier.Verify(req, body)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// 缺少签名
+	_, err = verifier.Verify(newRequest(string(body)), body)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+// TestParseKeys 测试解析客户端密钥配置
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys(" pod-1 = a , pod-2=b,")
+	require.NoError(t, err)
+
+	key, ok := keys.Key("pod-1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), key)
+	_, ok = keys.Key("pod-3")
+	assert.False(t, ok)
+
+	_, err = ParseKeys("pod-1")
+	assert.Error(t, err)
+}
+
+// TestNewFromConfig 测试根据配置创建签名器与校验器
+func TestNewFromConfig(t *testing.T) {
+	signer, err := NewClientSigner(ModeNone, "", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, signer)
+
+	_, err = NewClientSigner(ModeToken, "", "", "")
+	assert.Error(t, err)
+	_, err = NewClientSigner(ModeHMAC, "", "pod-1", "")
+	assert.Error(t, err)
+	_, err = NewClientSigner("basic", "", "", "")
+	assert.Error(t, err)
+
+	// 客户端使用由主密钥派生的密钥签名，可被服务器校验
+	signer, err = NewClientSigner(ModeHMAC, "", "pod-1", string(DeriveKey("master", "pod-1")))
+	require.NoError(t, err)
+	verifier, err := NewServerVerifier(ModeHMAC, "", "", "master")
+	require.NoError(t, err)
+
+	req := newRequest("{}")
+	require.NoError(t, signer.Sign(req, []byte("{}")))
+	clientID, err := verifier.Verify(req, []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-1", clientID)
+
+	_, err = NewServerVerifier(ModeHMAC, "", "", "")
+	assert.Error(t, err)
+}
+
+// TestDeriveKey 测试派生密钥为可直接分发的十六进制字符串
+func TestDeriveKey(t *testing.T) {
+	// echo -n pod-1 | openssl dgst -sha256 -hmac master
+	assert.Equal(t, "00f04479979bec68ab1fccf90e9400080148ab12294a6024f98ae521502cafeb", string(DeriveKey("master", "pod-1")))
+	assert.NotEqual(t, DeriveKey("master", "pod-1"), DeriveKey("master", "pod-2"))
+}
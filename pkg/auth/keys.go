@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// KeyStore 根据客户端标识查找 HMAC 密钥
+type KeyStore interface {
+	Key(clientID string) ([]byte, bool)
+}
+
+// staticKeyStore 静态配置的客户端密钥
+type staticKeyStore map[string][]byte
+
+// Key 查找客户端密钥
+func (s staticKeyStore) Key(clientID string) ([]byte, bool) {
+	key, ok := s[clientID]
+	return key, ok
+}
+
+// ParseKeys 解析客户端密钥配置
+// 格式: "客户端标识=密钥,客户端标识=密钥"
+func ParseKeys(spec string) (KeyStore, error) {
+	store := make(staticKeyStore)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("无效的客户端密钥配置: %s", item)
+		}
+		store[strings.TrimSpace(parts[0])] = []byte(strings.TrimSpace(parts[1]))
+	}
+	return store, nil
+}
+
+// derivedKeyStore 由主密钥派生每个客户端的密钥
+type derivedKeyStore struct {
+	secret string
+}
+
+// NewDerivedKeyStore 创建派生密钥存储，客户端密钥为 DeriveKey(secret, clientID)
+// 服务器只需保存主密钥，每个客户端只分发自己的派生密钥
+func NewDerivedKeyStore(secret string) KeyStore {
+	return &derivedKeyStore{secret: secret}
+}
+
+// Key 派生客户端密钥
+func (s *derivedKeyStore) Key(clientID string) ([]byte, bool) {
+	return DeriveKey(s.secret, clientID), true
+}
+
+// chainKeyStore 依次查找多个密钥存储
+type chainKeyStore []KeyStore
+
+// NewChainKeyStore 组合多个密钥存储，按顺序返回第一个找到的密钥
+func NewChainKeyStore(stores ...KeyStore) KeyStore {
+	return chainKeyStore(stores)
+}
+
+// Key 依次查找客户端密钥
+func (c chainKeyStore) Key(clientID string) ([]byte, bool) {
+	for _, store := range c {
+		if key, ok := store.Key(clientID); ok {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// DeriveKey 由主密钥和客户端标识派生客户端密钥
+// 密钥为 HMAC-SHA256(secret, clientID) 的十六进制字符串，可直接作为客户端的 AUTH_HMAC_KEY，
+// 等价于 echo -n <clientID> | openssl dgst -sha256 -hmac <secret>
+func DeriveKey(secret, clientID string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(clientID))
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"strconv"
 	"sync"
@@ -37,19 +38,23 @@ type CacheManager interface {
 	GetCurrentVersion() (int64, error)
 	UpdateVersion(version int64) error
 
-	// 测试结果管理
+	// 测试结果管理，宿主机与Pod结果的键由调用方决定（TestResultManager 使用源与目标的身份键）
 	SaveHostTestResults(sourceIP string, results map[string]models.TestStatus) error
 	GetHostTestResults() (models.HostTestResults, error)
+	DeleteHostTestResults(sourceIP string) error
 	SavePodTestResults(sourceIP string, results map[string]models.TestStatus) error
 	GetPodTestResults() (models.PodTestResults, error)
+	DeletePodTestResults(sourceIP string) error
 	SaveServiceTestResults(sourceIP string, result *models.ConnectivityResult) error
 	GetServiceTestResults() (models.ServiceTestResults, error)
+	DeleteServiceTestResults(sourceIP string) error
 }
 
 // cacheManagerImpl 是CacheManager的实现
 type cacheManagerImpl struct {
 	cache      *gocache.Cache
 	mu         sync.RWMutex // 保护版本号更新的互斥锁
+	resultsMu  sync.Mutex   // 保护测试结果读取、复制与写回的互斥锁
 	expiration time.Duration
 }
 
@@ -186,6 +191,9 @@ func (cm *cacheManagerImpl) UpdateVersion(version int64) error {
 
 // SaveHostTestResults 保存宿主机测试结果
 func (cm *cacheManagerImpl) SaveHostTestResults(sourceIP string, results map[string]models.TestStatus) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
 	// 获取现有的测试结果
 	allResults, err := cm.GetHostTestResults()
 	if err != nil {
@@ -193,7 +201,8 @@ func (cm *cacheManagerImpl) SaveHostTestResults(sourceIP string, results map[str
 		allResults = make(models.HostTestResults)
 	}
 
-	// 更新源IP的测试结果
+	// 复制后更新源IP的测试结果，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
 	allResults[sourceIP] = results
 
 	// 保存回缓存
@@ -217,8 +226,31 @@ func (cm *cacheManagerImpl) GetHostTestResults() (models.HostTestResults, error)
 	return results, nil
 }
 
+// DeleteHostTestResults 删除指定源的宿主机测试结果
+func (cm *cacheManagerImpl) DeleteHostTestResults(sourceIP string) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
+	allResults, err := cm.GetHostTestResults()
+	if err != nil {
+		return err
+	}
+	if _, ok := allResults[sourceIP]; !ok {
+		return nil
+	}
+
+	// 复制后修改，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
+	delete(allResults, sourceIP)
+	cm.cache.Set(hostTestResultsKey, allResults, gocache.NoExpiration)
+	return nil
+}
+
 // SavePodTestResults 保存Pod测试结果
 func (cm *cacheManagerImpl) SavePodTestResults(sourceIP string, results map[string]models.TestStatus) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
 	// 获取现有的测试结果
 	allResults, err := cm.GetPodTestResults()
 	if err != nil {
@@ -226,7 +258,8 @@ func (cm *cacheManagerImpl) SavePodTestResults(sourceIP string, results map[stri
 		allResults = make(models.PodTestResults)
 	}
 
-	// 更新源IP的测试结果
+	// 复制后更新源IP的测试结果，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
 	allResults[sourceIP] = results
 
 	// 保存回缓存
@@ -250,8 +283,31 @@ func (cm *cacheManagerImpl) GetPodTestResults() (models.PodTestResults, error) {
 	return results, nil
 }
 
+// DeletePodTestResults 删除指定源的Pod测试结果
+func (cm *cacheManagerImpl) DeletePodTestResults(sourceIP string) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
+	allResults, err := cm.GetPodTestResults()
+	if err != nil {
+		return err
+	}
+	if _, ok := allResults[sourceIP]; !ok {
+		return nil
+	}
+
+	// 复制后修改，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
+	delete(allResults, sourceIP)
+	cm.cache.Set(podTestResultsKey, allResults, gocache.NoExpiration)
+	return nil
+}
+
 // SaveServiceTestResults 保存自定义服务测试结果
 func (cm *cacheManagerImpl) SaveServiceTestResults(sourceIP string, result *models.ConnectivityResult) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
 	// 获取现有的测试结果
 	allResults, err := cm.GetServiceTestResults()
 	if err != nil {
@@ -259,7 +315,8 @@ func (cm *cacheManagerImpl) SaveServiceTestResults(sourceIP string, result *mode
 		allResults = make(models.ServiceTestResults)
 	}
 
-	// 更新源IP的测试结果
+	// 复制后更新源IP的测试结果，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
 	allResults[sourceIP] = result
 
 	// 保存回缓存
@@ -282,3 +339,23 @@ func (cm *cacheManagerImpl) GetServiceTestResults() (models.ServiceTestResults,
 
 	return results, nil
 }
+
+// DeleteServiceTestResults 删除指定源的自定义服务测试结果
+func (cm *cacheManagerImpl) DeleteServiceTestResults(sourceIP string) error {
+	cm.resultsMu.Lock()
+	defer cm.resultsMu.Unlock()
+
+	allResults, err := cm.GetServiceTestResults()
+	if err != nil {
+		return err
+	}
+	if _, ok := allResults[sourceIP]; !ok {
+		return nil
+	}
+
+	// 复制后修改，不改动读取方可能正在遍历的结果集
+	allResults = maps.Clone(allResults)
+	delete(allResults, sourceIP)
+	cm.cache.Set(serviceTestResultsKey, allResults, gocache.NoExpiration)
+	return nil
+}
@@ -308,6 +308,18 @@ func TestSaveAndGetPodTestResults(t *testing.T) {
 	if len(allResults) != 1 {
 		t.Errorf("期望1个源IP的测试结果，实际为%d", len(allResults))
 	}
+
+	// 删除后不再返回该源的测试结果，之前读取的结果集不受影响
+	if err := cm.DeletePodTestResults("10.0.0.1"); err != nil {
+		t.Fatalf("DeletePodTestResults失败: %v", err)
+	}
+	if len(allResults) != 1 {
+		t.Errorf("删除不应修改已读取的结果集，实际为%d", len(allResults))
+	}
+	allResults, _ = cm.GetPodTestResults()
+	if len(allResults) != 0 {
+		t.Errorf("删除后期望0个源IP的测试结果，实际为%d", len(allResults))
+	}
 }
 
 // TestSaveAndGetServiceTestResults 测试保存和获取服务测试结果
@@ -319,7 +331,7 @@ func TestSaveAndGetServiceTestResults(t *testing.T) {
 		TargetIP:   "kubernetes.default.svc.cluster.local",
 		PingStatus: "reachable",
 		PortStatus: map[int]string{443: "open"},
-		Latency:    10 * time.Millisecond,
+		Latency:    models.Duration(10 * time.Millisecond),
 		Timestamp:  time.Now(),
 	}
 
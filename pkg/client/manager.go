@@ -3,6 +3,7 @@ package client
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/yezihack/k8snet-checker/pkg/cache"
 	"github.com/yezihack/k8snet-checker/pkg/models"
@@ -21,11 +22,24 @@ type ClientManager interface {
 
 	// GetAllPodIPs 获取所有Pod IP列表
 	GetAllPodIPs() ([]string, error)
+
+	// GetAllClients 获取所有已注册客户端记录，key为PodName
+	GetAllClients() (map[string]*models.ClientRecord, error)
+
+	// AddObserver 注册心跳观察者，心跳处理成功后会被通知
+	AddObserver(observer HeartbeatObserver)
+}
+
+// HeartbeatObserver 定义心跳观察者接口
+type HeartbeatObserver interface {
+	OnHeartbeat(info *models.NodeInfo)
 }
 
 // clientManagerImpl 是ClientManager的实现
 type clientManagerImpl struct {
 	cacheManager cache.CacheManager
+	mu           sync.RWMutex
+	observers    []HeartbeatObserver
 }
 
 // NewClientManager 创建一个新的ClientManager实例
@@ -63,9 +77,33 @@ func (cm *clientManagerImpl) HandleHeartbeat(info *models.NodeInfo) error {
 	log.Printf("心跳处理成功: pod=%s, node_ip=%s, pod_ip=%s, version=%d",
 		info.PodName, info.NodeIP, info.PodIP, version)
 
+	cm.notifyObservers(info)
 	return nil
 }
 
+// AddObserver 注册心跳观察者
+func (cm *clientManagerImpl) AddObserver(observer HeartbeatObserver) {
+	if observer == nil {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.observers = append(cm.observers, observer)
+}
+
+// notifyObservers 通知所有观察者
+func (cm *clientManagerImpl) notifyObservers(info *models.NodeInfo) {
+	cm.mu.RLock()
+	observers := make([]HeartbeatObserver, len(cm.observers))
+	copy(observers, cm.observers)
+	cm.mu.RUnlock()
+
+	for _, observer := range observers {
+		observer.OnHeartbeat(info)
+	}
+}
+
 // GetActiveClientCount 获取活跃客户端数量
 // 活跃客户端统计逻辑：
 // 1. 获取当前全局版本号 currentVersion
@@ -178,3 +216,13 @@ func (cm *clientManagerImpl) GetAllPodIPs() ([]string, error) {
 	log.Printf("获取Pod IP列表: 数量=%d", len(podIPs))
 	return podIPs, nil
 }
+
+// GetAllClients 获取所有已注册客户端记录
+func (cm *clientManagerImpl) GetAllClients() (map[string]*models.ClientRecord, error) {
+	allClients, err := cm.cacheManager.GetAllClients()
+	if err != nil {
+		return nil, fmt.Errorf("获取所有客户端失败: %w", err)
+	}
+
+	return allClients, nil
+}
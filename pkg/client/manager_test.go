@@ -261,3 +261,60 @@ func TestGetAllPodIPsEmpty(t *testing.T) {
 		t.Errorf("Pod IP列表应为空: 实际长度=%d", len(podIPs))
 	}
 }
+
+// TestGetAllClients 测试获取所有客户端记录
+func TestGetAllClients(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := NewClientManager(cacheManager)
+
+	for _, info := range []*models.NodeInfo{
+		{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", Namespace: "default"},
+		{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2", Namespace: "default"},
+	} {
+		if err := clientManager.HandleHeartbeat(info); err != nil {
+			t.Fatalf("HandleHeartbeat失败: %v", err)
+		}
+	}
+
+	clients, err := clientManager.GetAllClients()
+	if err != nil {
+		t.Fatalf("GetAllClients失败: %v", err)
+	}
+	if len(clients) != 2 {
+		t.Errorf("客户端数量不匹配: 期望=%d, 实际=%d", 2, len(clients))
+	}
+	if record, ok := clients["pod-1"]; !ok || record.NodeInfo.PodIP != "10.0.0.1" {
+		t.Errorf("客户端记录不正确: %+v", record)
+	}
+}
+
+// recordingObserver 记录收到的心跳通知
+type recordingObserver struct {
+	podNames []string
+}
+
+func (o *recordingObserver) OnHeartbeat(info *models.NodeInfo) {
+	o.podNames = append(o.podNames, info.PodName)
+}
+
+// TestHeartbeatObserverNotified 测试心跳处理成功后通知观察者
+func TestHeartbeatObserverNotified(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := NewClientManager(cacheManager)
+
+	observer := &recordingObserver{}
+	clientManager.AddObserver(observer)
+
+	if err := clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}); err != nil {
+		t.Fatalf("HandleHeartbeat失败: %v", err)
+	}
+
+	// 处理失败时不应通知
+	if err := clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-2"}); err == nil {
+		t.Fatal("缺少字段的心跳应返回错误")
+	}
+
+	if len(observer.podNames) != 1 || observer.podNames[0] != "pod-1" {
+		t.Errorf("观察者通知不正确: %v", observer.podNames)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,15 +22,39 @@ type ClientServer interface {
 	Stop() error
 }
 
+// ServerStatusProvider 提供与服务器的连接状态，heartbeat.HeartbeatReporter 实现了该接口
+type ServerStatusProvider interface {
+	ServerStatus() models.ServerStatus
+}
+
 // clientServerImpl 是ClientServer的实现
 type clientServerImpl struct {
-	server *http.Server
-	port   int
+	server       *http.Server
+	port         int
+	metrics      *metrics.ClientMetrics
+	serverStatus ServerStatusProvider // 可选，为nil时健康检查不包含服务器连接状态
+}
+
+// Option ClientServer的可选配置项
+type Option func(cs *clientServerImpl)
+
+// WithServerStatus 在健康检查响应中包含与服务器的连接状态
+func WithServerStatus(provider ServerStatusProvider) Option {
+	return func(cs *clientServerImpl) {
+		cs.serverStatus = provider
+	}
 }
 
 // NewClientServer 创建一个新的ClientServer实例
-func NewClientServer() ClientServer {
-	return &clientServerImpl{}
+// clientMetrics 为 nil 时不暴露 /metrics 端点
+func NewClientServer(clientMetrics *metrics.ClientMetrics, opts ...Option) ClientServer {
+	cs := &clientServerImpl{
+		metrics: clientMetrics,
+	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+	return cs
 }
 
 // Start 启动HTTP服务器
@@ -64,6 +91,11 @@ func (cs *clientServerImpl) Start(port int) error {
 	
 	// 注册健康检查端点
 	router.GET("/health", cs.healthHandler)
+
+	// 注册指标端点
+	if cs.metrics != nil {
+		router.GET("/metrics", gin.WrapH(cs.metrics.Handler()))
+	}
 	
 	// 创建HTTP服务器
 	cs.server = &http.Server{
@@ -110,8 +142,13 @@ func (cs *clientServerImpl) Stop() error {
 
 // healthHandler 处理健康检查请求
 // 返回200 OK表示服务器正常运行
+// 服务器不可达不影响客户端自身的健康状态，避免服务器故障时所有客户端被重启，仅在 server 字段中体现
 func (cs *clientServerImpl) healthHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"status": "healthy",
-	})
+	}
+	if cs.serverStatus != nil {
+		response["server"] = cs.serverStatus.ServerStatus()
+	}
+	c.JSON(http.StatusOK, response)
 }
@@ -1,23 +1,27 @@
 package clientserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
 	"github.com/stretchr/testify/assert"
 )
 
 // TestNewClientServer 测试创建ClientServer实例
 func TestNewClientServer(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	assert.NotNil(t, server, "ClientServer实例不应为nil")
 }
 
 // TestClientServer_Start_Success 测试成功启动服务器
 func TestClientServer_Start_Success(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	
 	// 使用一个随机端口
 	port := 16100
@@ -52,7 +56,7 @@ func TestClientServer_Start_InvalidPort(t *testing.T) {
 	
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := NewClientServer()
+			server := NewClientServer(nil)
 			err := server.Start(tt.port)
 			assert.Error(t, err, "使用无效端口应该返回错误")
 		})
@@ -61,7 +65,7 @@ func TestClientServer_Start_InvalidPort(t *testing.T) {
 
 // TestClientServer_HealthEndpoint 测试健康检查端点
 func TestClientServer_HealthEndpoint(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	port := 16101
 	
 	err := server.Start(port)
@@ -82,14 +86,14 @@ func TestClientServer_HealthEndpoint(t *testing.T) {
 
 // TestClientServer_Stop_WithoutStart 测试在未启动的情况下停止服务器
 func TestClientServer_Stop_WithoutStart(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	err := server.Stop()
 	assert.Error(t, err, "停止未启动的服务器应该返回错误")
 }
 
 // TestClientServer_MultipleStarts 测试多次启动服务器
 func TestClientServer_MultipleStarts(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	port := 16102
 	
 	// 第一次启动
@@ -115,7 +119,7 @@ func TestClientServer_MultipleStarts(t *testing.T) {
 
 // TestClientServer_GracefulShutdown 测试优雅关闭
 func TestClientServer_GracefulShutdown(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	port := 16103
 	
 	err := server.Start(port)
@@ -141,7 +145,7 @@ func TestClientServer_GracefulShutdown(t *testing.T) {
 
 // TestClientServer_DefaultPort 测试使用默认端口6100
 func TestClientServer_DefaultPort(t *testing.T) {
-	server := NewClientServer()
+	server := NewClientServer(nil)
 	
 	// 使用默认端口6100（如果端口未被占用）
 	port := 6100
@@ -163,3 +167,80 @@ func TestClientServer_DefaultPort(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	resp.Body.Close()
 }
+
+// TestClientServer_MetricsEndpoint 测试指标端点
+func TestClientServer_MetricsEndpoint(t *testing.T) {
+	server := NewClientServer(metrics.NewClientMetrics())
+	port := 16104
+
+	err := server.Start(port)
+	assert.NoError(t, err, "启动服务器应该成功")
+	defer server.Stop()
+
+	// 等待服务器完全启动
+	time.Sleep(200 * time.Millisecond)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	assert.NoError(t, err, "指标请求应该成功")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "指标端点应该返回200")
+}
+
+// TestClientServer_MetricsDisabled 测试未启用指标时不暴露端点
+func TestClientServer_MetricsDisabled(t *testing.T) {
+	server := NewClientServer(nil)
+	port := 16105
+
+	err := server.Start(port)
+	assert.NoError(t, err, "启动服务器应该成功")
+	defer server.Stop()
+
+	// 等待服务器完全启动
+	time.Sleep(200 * time.Millisecond)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	assert.NoError(t, err, "请求应该成功")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "未启用指标时应该返回404")
+}
+
+// staticServerStatus 返回固定的服务器连接状态
+type staticServerStatus models.ServerStatus
+
+func (s staticServerStatus) ServerStatus() models.ServerStatus {
+	return models.ServerStatus(s)
+}
+
+// TestClientServer_HealthServerStatus 测试健康检查包含服务器连接状态，服务器不可达时仍返回200
+func TestClientServer_HealthServerStatus(t *testing.T) {
+	server := NewClientServer(nil, WithServerStatus(staticServerStatus{
+		Reachable:           false,
+		ConsecutiveFailures: 3,
+		LastError:           "connection refused",
+	}))
+	port := 16106
+
+	err := server.Start(port)
+	assert.NoError(t, err, "启动服务器应该成功")
+	defer server.Stop()
+
+	// 等待服务器完全启动
+	time.Sleep(200 * time.Millisecond)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/health", port))
+	assert.NoError(t, err, "健康检查请求应该成功")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "服务器不可达不影响客户端健康状态")
+	var body struct {
+		Status string              `json:"status"`
+		Server models.ServerStatus `json:"server"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "healthy", body.Status)
+	assert.False(t, body.Server.Reachable)
+	assert.Equal(t, 3, body.Server.ConsecutiveFailures)
+	assert.Equal(t, "connection refused", body.Server.LastError)
+}
@@ -3,6 +3,7 @@ package collector
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/models"
@@ -57,7 +58,28 @@ func (c *EnvInfoCollector) CollectNodeInfo() (*models.NodeInfo, error) {
 		PodIP:     podIP,
 		PodName:   podName,
 		Timestamp: time.Now(),
+		Zone:      os.Getenv("NODE_ZONE"), // 可选
+		NodeName:  os.Getenv("NODE_NAME"), // 可选
+		Labels:    parseLabels(os.Getenv("POD_LABELS")),
+		PodUID:    os.Getenv("POD_UID"), // 可选
 	}
 
 	return nodeInfo, nil
 }
+
+// parseLabels 解析 key=value 形式、逗号分隔的标签，忽略格式错误的项
+func parseLabels(value string) map[string]string {
+	var labels map[string]string
+	for _, item := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = strings.TrimSpace(val)
+	}
+	return labels
+}
@@ -38,6 +38,21 @@ func TestEnvInfoCollector_CollectNodeInfo_Success(t *testing.T) {
 	assert.WithinDuration(t, time.Now(), nodeInfo.Timestamp, time.Second)
 }
 
+// TestEnvInfoCollector_CollectNodeInfo_Identity 测试读取可选的节点名称与Pod标签
+func TestEnvInfoCollector_CollectNodeInfo_Identity(t *testing.T) {
+	t.Setenv("NODE_IP", "192.168.1.100")
+	t.Setenv("POD_IP", "10.244.0.5")
+	t.Setenv("POD_NAME", "test-pod-abc")
+	t.Setenv("NAMESPACE", "default")
+	t.Setenv("NODE_NAME", "node-1")
+	t.Setenv("POD_LABELS", "app=checker, tier = net,invalid,=empty")
+
+	nodeInfo, err := NewEnvInfoCollector().CollectNodeInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "node-1", nodeInfo.NodeName)
+	assert.Equal(t, map[string]string{"app": "checker", "tier": "net"}, nodeInfo.Labels)
+}
+
 func TestEnvInfoCollector_CollectNodeInfo_MissingNodeIP(t *testing.T) {
 	// 只设置部分环境变量，缺少NODE_IP
 	os.Setenv("POD_IP", "10.244.0.5")
@@ -11,11 +11,54 @@ import (
 type ClientConfig struct {
 	ServerURL         string
 	HeartbeatInterval time.Duration
+	HeartbeatBackoff  time.Duration // 服务器不可达时心跳退避的上限
+	SyncEnabled       bool          // 是否通过同步接口随心跳上报测试结果并获取目标列表
 	TestPort          int
 	CustomServiceName string
 	ServicePort       int
 	ClientPort        int
 	LogLevel          string
+
+	// 传输配置
+	Transport   string // 与服务器通信的方式: http、grpc
+	GRPCAddress string // grpc方式使用的服务器地址（host:port）
+
+	// 调度配置，服务器下发探测配置后以服务器配置为准
+	TestInterval        time.Duration // 定期测试间隔
+	HostTestInterval    time.Duration // 宿主机测试间隔，为0时使用 TestInterval
+	PodTestInterval     time.Duration // Pod测试间隔，为0时使用 TestInterval
+	ServiceTestInterval time.Duration // 自定义服务测试间隔，为0时使用 TestInterval
+	PingInterval        time.Duration // ping 的最小执行间隔，为0时每轮都执行
+	TestInitialDelay    time.Duration // 启动后首轮测试前的等待时间
+	TestJitter          time.Duration // 每轮测试随机增加的最大延迟
+
+	// 探测执行配置，所有测试类型共享
+	MaxConcurrency int     // 最大并发探测数
+	ProbeRateLimit float64 // 每秒最多发起的探测数，为0时不限制
+	SkipSameNode   bool    // 是否跳过与本客户端位于同一节点的Pod目标
+
+	// 各类探测的超时时间
+	PingTimeout time.Duration // 一次 ping 测试的超时时间
+	PortTimeout time.Duration // TCP 端口探测的超时时间
+	DNSTimeout  time.Duration // 自定义服务 DNS 解析的超时时间
+
+	// 结果上报配置
+	RetryMaxAttempts int           // 单次请求的最大尝试次数
+	RetryBaseDelay   time.Duration // 首次重试前的等待时间，之后按指数增长
+	RetryMaxDelay    time.Duration // 重试等待时间的上限
+	OutboxSize       int           // 服务器不可达时最多缓存的结果批次数，为0时不缓存
+	OutboxDir        string        // 缓存结果的持久化目录，为空时只保存在内存中
+
+	// 认证配置
+	AuthMode    string // 认证方式: none、token、hmac
+	AuthToken   string // token方式使用的共享Token
+	AuthHMACKey string // hmac方式使用的客户端密钥，由服务器主密钥派生时为 auth.DeriveKey 的结果
+
+	// TLS配置，SERVER_URL 需为 https 地址，证书文件变更后自动重新加载
+	TLSCertFile   string // 客户端证书文件，CN或SAN需包含Pod名称
+	TLSKeyFile    string // 客户端私钥文件
+	TLSCAFile     string // 校验服务器证书的CA文件，为空时使用系统根证书
+	TLSServerName string // 校验服务器证书时使用的名称，为空时使用SERVER_URL中的主机名
 }
 
 // LoadClientConfig 从环境变量加载客户端配置
@@ -23,11 +66,44 @@ func LoadClientConfig() *ClientConfig {
 	return &ClientConfig{
 		ServerURL:         getEnv("SERVER_URL", "http://k8snet-checker-server.kube-system.svc.cluster.local:8080"),
 		HeartbeatInterval: getDurationEnv("HEARTBEAT_INTERVAL", 5) * time.Second,
+		HeartbeatBackoff:  getDurationEnv("HEARTBEAT_MAX_BACKOFF", 60) * time.Second,
+		SyncEnabled:       getBoolEnv("SYNC_ENABLED", false),
 		TestPort:          getIntEnv("TEST_PORT", 22),
 		CustomServiceName: getEnv("CUSTOM_SERVICE_NAME", ""),
 		ServicePort:       getIntEnv("CUSTOM_SERVICE_PORT", 80),
 		ClientPort:        getIntEnv("CLIENT_PORT", 6100),
 		LogLevel:          getEnv("LOG_LEVEL", "info"),
+		Transport:         getEnv("TRANSPORT", "http"),
+		GRPCAddress:       getEnv("GRPC_SERVER_ADDRESS", "k8snet-checker-server.kube-system.svc.cluster.local:9090"),
+		AuthMode:          getEnv("AUTH_MODE", "none"),
+		AuthToken:         getEnv("AUTH_TOKEN", ""),
+		AuthHMACKey:       getEnv("AUTH_HMAC_KEY", ""),
+		TLSCertFile:       getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:        getEnv("TLS_KEY_FILE", ""),
+		TLSCAFile:         getEnv("TLS_CA_FILE", ""),
+		TLSServerName:     getEnv("TLS_SERVER_NAME", ""),
+
+		TestInterval:        getDurationEnv("TEST_INTERVAL", 60) * time.Second,
+		HostTestInterval:    getOptionalDurationEnv("HOST_TEST_INTERVAL", 0) * time.Second,
+		PodTestInterval:     getOptionalDurationEnv("POD_TEST_INTERVAL", 0) * time.Second,
+		ServiceTestInterval: getOptionalDurationEnv("SERVICE_TEST_INTERVAL", 0) * time.Second,
+		PingInterval:        getOptionalDurationEnv("PING_INTERVAL", 0) * time.Second,
+		TestInitialDelay:    getOptionalDurationEnv("TEST_INITIAL_DELAY", 0) * time.Second,
+		TestJitter:          getOptionalDurationEnv("TEST_JITTER", 5) * time.Second,
+
+		MaxConcurrency: getIntEnv("MAX_CONCURRENCY", 10),
+		ProbeRateLimit: getFloatEnv("PROBE_RATE_LIMIT", 0),
+		SkipSameNode:   getBoolEnv("SKIP_SAME_NODE", false),
+
+		PingTimeout: getDurationEnv("PING_TIMEOUT", 10) * time.Second,
+		PortTimeout: getDurationEnv("PORT_TIMEOUT", 5) * time.Second,
+		DNSTimeout:  getDurationEnv("DNS_TIMEOUT", 5) * time.Second,
+
+		RetryMaxAttempts: getIntEnv("RETRY_MAX_ATTEMPTS", 5),
+		RetryBaseDelay:   getDurationEnv("RETRY_BASE_DELAY", 1) * time.Second,
+		RetryMaxDelay:    getDurationEnv("RETRY_MAX_DELAY", 30) * time.Second,
+		OutboxSize:       getIntEnv("OUTBOX_SIZE", 100),
+		OutboxDir:        getEnv("OUTBOX_DIR", ""),
 	}
 }
 
@@ -57,6 +133,39 @@ func getIntEnv(key string, defaultValue int) int {
 	return value
 }
 
+// getBoolEnv 获取布尔类型的环境变量
+func getBoolEnv(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("警告: 无法解析环境变量 %s='%s'，使用默认值 %t: %v",
+			key, valueStr, defaultValue, err)
+		return defaultValue
+	}
+
+	return value
+}
+
+// getFloatEnv 获取非负浮点数类型的环境变量
+func getFloatEnv(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || value < 0 {
+		log.Printf("警告: 环境变量 %s 值无效 (%s)，使用默认值 %g", key, valueStr, defaultValue)
+		return defaultValue
+	}
+
+	return value
+}
+
 // getDurationEnv 获取时间间隔类型的环境变量（秒）
 func getDurationEnv(key string, defaultValue int) time.Duration {
 	valueStr := os.Getenv(key)
@@ -79,3 +188,19 @@ func getDurationEnv(key string, defaultValue int) time.Duration {
 
 	return time.Duration(value)
 }
+
+// getOptionalDurationEnv 获取时间间隔类型的环境变量（秒），允许设置为0表示关闭
+func getOptionalDurationEnv(key string, defaultValue int) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return time.Duration(defaultValue)
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		log.Printf("警告: 环境变量 %s 值无效 (%s)，使用默认值 %d秒", key, valueStr, defaultValue)
+		return time.Duration(defaultValue)
+	}
+
+	return time.Duration(value)
+}
@@ -12,7 +12,67 @@ type ServerConfig struct {
 	CacheKeySecond int           // 缓存过期时间（秒）
 	LogLevel       string        // 日志级别
 	HTTPPort       string        // HTTP服务端口
+	GRPCPort       string        // gRPC服务端口，为空表示不启用
 	ReportInterval time.Duration // 报告生成间隔
+
+	HeartbeatInterval time.Duration // 建议客户端使用的心跳间隔，为0时客户端使用本地配置
+
+	// 报告输出配置
+	ReportFormat      string // 控制台报告格式，none表示不输出到控制台
+	ReportOutputDir   string // 报告文件输出目录，为空表示不写文件
+	ReportFileFormats string // 报告文件格式，逗号分隔
+	ReportMaxFiles    int    // 每种格式保留的报告文件数量
+	ReportHistorySize int    // 内存中保留的历史报告数量
+
+	DashboardEnabled bool // 是否提供内置Web仪表盘
+
+	RunTimeout time.Duration // 按需测试任务超时时间
+
+	ProbeConfigFile string // 探测配置文件（YAML/JSON），配置后通过心跳下发给客户端
+
+	// 目标分片配置
+	TargetsPerCycle   int           // 每个客户端每轮测试的目标数量，0表示不分片
+	ShardResultMaxAge time.Duration // 启用分片时，未在本轮重新测试的结果保留时间
+
+	// 探测对状态配置
+	PairFailureThreshold  int           // 连续失败达到该次数时探测对进入down
+	PairRecoveryThreshold int           // 故障探测对连续成功达到该次数时恢复
+	PairFlapThreshold     int           // 抖动窗口内结果切换达到该次数时进入flapping
+	PairFlapWindow        time.Duration // 统计结果切换次数的时间窗口
+	PairRecoveredHold     time.Duration // 恢复后保持recovered状态的时长
+
+	// 认证配置
+	AuthMode           string // 上报接口认证方式: none、token、hmac
+	AuthToken          string // token方式使用的共享Token
+	AuthHMACKeys       string // hmac方式的客户端密钥，格式: Pod名称=密钥,Pod名称=密钥
+	AuthHMACSecret     string // hmac方式的主密钥，用于派生未单独配置的客户端密钥
+	AuthVerifySourceIP bool   // 是否校验上报的源IP属于已注册客户端
+	AdminToken         string // 管理接口使用的Bearer Token，为空时与上报接口使用相同的认证
+
+	// TLS配置，证书文件变更后自动重新加载
+	TLSCertFile     string // 服务器证书文件，为空表示使用HTTP
+	TLSKeyFile      string // 服务器私钥文件
+	TLSClientCAFile string // 校验客户端证书的CA文件，配置后上报接口要求客户端证书
+
+	// 告警配置
+	AlertWebhooks             string        // 告警通知渠道，格式: 类型=URL,类型=URL
+	AlertEvalInterval         time.Duration // 告警规则评估间隔
+	AlertSuccessRateThreshold float64       // 成功率告警阈值（百分比）
+	AlertRepeatInterval       time.Duration // 告警重复通知间隔
+	AlertMissingClientTTL     time.Duration // 客户端失联超过该时间后不再告警
+
+	// 可用性统计配置
+	SLOObjective    float64       // 可用性目标（百分比）
+	SLOStateFile    string        // 可用性历史的状态文件，为空表示只保存在内存中
+	SLOSaveInterval time.Duration // 写入状态文件的间隔
+
+	// 延迟基线配置
+	LatencyEWMAAlpha          float64       // 延迟基线EWMA平滑系数
+	LatencyAnomalyRatio       float64       // 延迟达到基线的该倍数时视为异常
+	LatencyAnomalyMinDelta    time.Duration // 异常延迟高出基线的最小值
+	LatencyBaselineMinSamples int           // 基线样本数达到该值后才开始检测
+	LatencyAnomalyConsecutive int           // 连续异常样本数达到该值时报告异常
+	LatencyRebaseline         time.Duration // 异常持续该时长后重新学习基线
 }
 
 // LoadServerConfig 从环境变量加载服务器配置
@@ -22,6 +82,40 @@ func LoadServerConfig() *ServerConfig {
 		LogLevel:       "info",            // 默认info级别
 		HTTPPort:       "8080",            // 默认8080端口
 		ReportInterval: 300 * time.Second, // 默认300秒（5分钟）
+
+		ReportFormat:      "text", // 默认文本格式
+		ReportFileFormats: "json", // 默认JSON格式
+		ReportMaxFiles:    100,    // 默认保留100个
+		ReportHistorySize: 20,     // 默认保留20份
+
+		DashboardEnabled: true, // 默认启用
+
+		RunTimeout: 300 * time.Second, // 默认300秒
+
+		ShardResultMaxAge: 900 * time.Second, // 默认900秒
+
+		PairFailureThreshold:  3,                 // 默认连续失败3次
+		PairRecoveryThreshold: 2,                 // 默认连续成功2次
+		PairFlapThreshold:     4,                 // 默认切换4次
+		PairFlapWindow:        600 * time.Second, // 默认600秒
+		PairRecoveredHold:     600 * time.Second, // 默认600秒
+
+		AuthMode: "none", // 默认不认证
+
+		AlertEvalInterval:         30 * time.Second, // 默认30秒
+		AlertSuccessRateThreshold: 95,               // 默认95%
+		AlertRepeatInterval:       time.Hour,        // 默认1小时
+		AlertMissingClientTTL:     time.Hour,        // 默认1小时
+
+		SLOObjective:    99.9,             // 默认99.9%
+		SLOSaveInterval: 60 * time.Second, // 默认60秒
+
+		LatencyEWMAAlpha:          0.1,              // 默认0.1
+		LatencyAnomalyRatio:       3,                // 默认3倍
+		LatencyAnomalyMinDelta:    time.Millisecond, // 默认1毫秒
+		LatencyBaselineMinSamples: 10,               // 默认10个样本
+		LatencyAnomalyConsecutive: 3,                // 默认连续3次
+		LatencyRebaseline:         time.Hour,        // 默认1小时
 	}
 
 	// 读取CACHE_KEY_SECOND
@@ -33,6 +127,19 @@ func LoadServerConfig() *ServerConfig {
 		}
 	}
 
+	// 读取SUGGESTED_HEARTBEAT_INTERVAL
+	if heartbeatInterval := os.Getenv("SUGGESTED_HEARTBEAT_INTERVAL"); heartbeatInterval != "" {
+		if val, err := strconv.Atoi(heartbeatInterval); err == nil && val >= 0 {
+			config.HeartbeatInterval = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: SUGGESTED_HEARTBEAT_INTERVAL值无效(%s)，使用默认值0", heartbeatInterval)
+		}
+	}
+	if config.HeartbeatInterval >= time.Duration(config.CacheKeySecond)*time.Second {
+		log.Printf("警告: SUGGESTED_HEARTBEAT_INTERVAL(%v)不小于客户端缓存过期时间(%d秒)，客户端可能被误判为离线",
+			config.HeartbeatInterval, config.CacheKeySecond)
+	}
+
 	// 读取LOG_LEVEL
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.LogLevel = logLevel
@@ -43,6 +150,9 @@ func LoadServerConfig() *ServerConfig {
 		config.HTTPPort = httpPort
 	}
 
+	// 读取GRPC_PORT
+	config.GRPCPort = os.Getenv("GRPC_PORT")
+
 	// 读取REPORT_INTERVAL
 	if reportInterval := os.Getenv("REPORT_INTERVAL"); reportInterval != "" {
 		if val, err := strconv.Atoi(reportInterval); err == nil && val > 0 {
@@ -52,5 +162,246 @@ func LoadServerConfig() *ServerConfig {
 		}
 	}
 
+	// 读取报告输出配置
+	if reportFormat := os.Getenv("REPORT_FORMAT"); reportFormat != "" {
+		config.ReportFormat = reportFormat
+	}
+	config.ReportOutputDir = os.Getenv("REPORT_OUTPUT_DIR")
+	if fileFormats := os.Getenv("REPORT_FILE_FORMATS"); fileFormats != "" {
+		config.ReportFileFormats = fileFormats
+	}
+	if maxFiles := os.Getenv("REPORT_MAX_FILES"); maxFiles != "" {
+		if val, err := strconv.Atoi(maxFiles); err == nil && val >= 0 {
+			config.ReportMaxFiles = val
+		} else {
+			log.Printf("警告: REPORT_MAX_FILES值无效(%s)，使用默认值100", maxFiles)
+		}
+	}
+
+	if historySize := os.Getenv("REPORT_HISTORY_SIZE"); historySize != "" {
+		if val, err := strconv.Atoi(historySize); err == nil && val > 0 {
+			config.ReportHistorySize = val
+		} else {
+			log.Printf("警告: REPORT_HISTORY_SIZE值无效(%s)，使用默认值20", historySize)
+		}
+	}
+
+	// 读取DASHBOARD_ENABLED
+	if dashboardEnabled := os.Getenv("DASHBOARD_ENABLED"); dashboardEnabled != "" {
+		if val, err := strconv.ParseBool(dashboardEnabled); err == nil {
+			config.DashboardEnabled = val
+		} else {
+			log.Printf("警告: DASHBOARD_ENABLED值无效(%s)，使用默认值true", dashboardEnabled)
+		}
+	}
+
+	// 读取RUN_TIMEOUT
+	if runTimeout := os.Getenv("RUN_TIMEOUT"); runTimeout != "" {
+		if val, err := strconv.Atoi(runTimeout); err == nil && val > 0 {
+			config.RunTimeout = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: RUN_TIMEOUT值无效(%s)，使用默认值300秒", runTimeout)
+		}
+	}
+
+	// 读取PROBE_CONFIG_FILE
+	config.ProbeConfigFile = os.Getenv("PROBE_CONFIG_FILE")
+
+	// 读取目标分片配置
+	if targetsPerCycle := os.Getenv("TARGETS_PER_CYCLE"); targetsPerCycle != "" {
+		if val, err := strconv.Atoi(targetsPerCycle); err == nil && val >= 0 {
+			config.TargetsPerCycle = val
+		} else {
+			log.Printf("警告: TARGETS_PER_CYCLE值无效(%s)，使用默认值0", targetsPerCycle)
+		}
+	}
+	if maxAge := os.Getenv("SHARD_RESULT_MAX_AGE"); maxAge != "" {
+		if val, err := strconv.Atoi(maxAge); err == nil && val > 0 {
+			config.ShardResultMaxAge = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: SHARD_RESULT_MAX_AGE值无效(%s)，使用默认值900秒", maxAge)
+		}
+	}
+
+	// 读取认证配置
+	if authMode := os.Getenv("AUTH_MODE"); authMode != "" {
+		config.AuthMode = authMode
+	}
+	config.AuthToken = os.Getenv("AUTH_TOKEN")
+	config.AuthHMACKeys = os.Getenv("AUTH_HMAC_KEYS")
+	config.AuthHMACSecret = os.Getenv("AUTH_HMAC_SECRET")
+	config.AdminToken = os.Getenv("ADMIN_TOKEN")
+	if verifySourceIP := os.Getenv("AUTH_VERIFY_SOURCE_IP"); verifySourceIP != "" {
+		if val, err := strconv.ParseBool(verifySourceIP); err == nil {
+			config.AuthVerifySourceIP = val
+		} else {
+			log.Printf("警告: AUTH_VERIFY_SOURCE_IP值无效(%s)，使用默认值false", verifySourceIP)
+		}
+	}
+
+	// 读取TLS配置
+	config.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	config.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	config.TLSClientCAFile = os.Getenv("TLS_CLIENT_CA_FILE")
+
+	// 读取PAIR_FAILURE_THRESHOLD
+	if threshold := os.Getenv("PAIR_FAILURE_THRESHOLD"); threshold != "" {
+		if val, err := strconv.Atoi(threshold); err == nil && val > 0 {
+			config.PairFailureThreshold = val
+		} else {
+			log.Printf("警告: PAIR_FAILURE_THRESHOLD值无效(%s)，使用默认值3", threshold)
+		}
+	}
+
+	// 读取PAIR_RECOVERY_THRESHOLD
+	if threshold := os.Getenv("PAIR_RECOVERY_THRESHOLD"); threshold != "" {
+		if val, err := strconv.Atoi(threshold); err == nil && val > 0 {
+			config.PairRecoveryThreshold = val
+		} else {
+			log.Printf("警告: PAIR_RECOVERY_THRESHOLD值无效(%s)，使用默认值2", threshold)
+		}
+	}
+
+	// 读取PAIR_FLAP_THRESHOLD
+	if threshold := os.Getenv("PAIR_FLAP_THRESHOLD"); threshold != "" {
+		if val, err := strconv.Atoi(threshold); err == nil && val > 0 {
+			config.PairFlapThreshold = val
+		} else {
+			log.Printf("警告: PAIR_FLAP_THRESHOLD值无效(%s)，使用默认值4", threshold)
+		}
+	}
+
+	// 读取PAIR_FLAP_WINDOW
+	if flapWindow := os.Getenv("PAIR_FLAP_WINDOW"); flapWindow != "" {
+		if val, err := strconv.Atoi(flapWindow); err == nil && val > 0 {
+			config.PairFlapWindow = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: PAIR_FLAP_WINDOW值无效(%s)，使用默认值600秒", flapWindow)
+		}
+	}
+
+	// 读取PAIR_RECOVERED_HOLD
+	if recoveredHold := os.Getenv("PAIR_RECOVERED_HOLD"); recoveredHold != "" {
+		if val, err := strconv.Atoi(recoveredHold); err == nil && val > 0 {
+			config.PairRecoveredHold = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: PAIR_RECOVERED_HOLD值无效(%s)，使用默认值600秒", recoveredHold)
+		}
+	}
+
+	// 读取ALERT_WEBHOOKS
+	config.AlertWebhooks = os.Getenv("ALERT_WEBHOOKS")
+
+	// 读取ALERT_EVAL_INTERVAL
+	if evalInterval := os.Getenv("ALERT_EVAL_INTERVAL"); evalInterval != "" {
+		if val, err := strconv.Atoi(evalInterval); err == nil && val > 0 {
+			config.AlertEvalInterval = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: ALERT_EVAL_INTERVAL值无效(%s)，使用默认值30秒", evalInterval)
+		}
+	}
+
+	// 读取ALERT_SUCCESS_RATE_THRESHOLD
+	if threshold := os.Getenv("ALERT_SUCCESS_RATE_THRESHOLD"); threshold != "" {
+		if val, err := strconv.ParseFloat(threshold, 64); err == nil && val >= 0 && val <= 100 {
+			config.AlertSuccessRateThreshold = val
+		} else {
+			log.Printf("警告: ALERT_SUCCESS_RATE_THRESHOLD值无效(%s)，使用默认值95", threshold)
+		}
+	}
+
+	// 读取ALERT_REPEAT_INTERVAL
+	if repeatInterval := os.Getenv("ALERT_REPEAT_INTERVAL"); repeatInterval != "" {
+		if val, err := strconv.Atoi(repeatInterval); err == nil && val > 0 {
+			config.AlertRepeatInterval = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: ALERT_REPEAT_INTERVAL值无效(%s)，使用默认值3600秒", repeatInterval)
+		}
+	}
+
+	// 读取ALERT_MISSING_CLIENT_TTL
+	if missingClientTTL := os.Getenv("ALERT_MISSING_CLIENT_TTL"); missingClientTTL != "" {
+		if val, err := strconv.Atoi(missingClientTTL); err == nil && val > 0 {
+			config.AlertMissingClientTTL = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: ALERT_MISSING_CLIENT_TTL值无效(%s)，使用默认值3600秒", missingClientTTL)
+		}
+	}
+
+	// 读取SLO_OBJECTIVE
+	if objective := os.Getenv("SLO_OBJECTIVE"); objective != "" {
+		if val, err := strconv.ParseFloat(objective, 64); err == nil && val > 0 && val < 100 {
+			config.SLOObjective = val
+		} else {
+			log.Printf("警告: SLO_OBJECTIVE值无效(%s)，使用默认值99.9", objective)
+		}
+	}
+
+	// 读取SLO_STATE_FILE
+	config.SLOStateFile = os.Getenv("SLO_STATE_FILE")
+
+	// 读取SLO_SAVE_INTERVAL
+	if saveInterval := os.Getenv("SLO_SAVE_INTERVAL"); saveInterval != "" {
+		if val, err := strconv.Atoi(saveInterval); err == nil && val > 0 {
+			config.SLOSaveInterval = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: SLO_SAVE_INTERVAL值无效(%s)，使用默认值60秒", saveInterval)
+		}
+	}
+
+	// 读取LATENCY_EWMA_ALPHA
+	if alpha := os.Getenv("LATENCY_EWMA_ALPHA"); alpha != "" {
+		if val, err := strconv.ParseFloat(alpha, 64); err == nil && val > 0 && val <= 1 {
+			config.LatencyEWMAAlpha = val
+		} else {
+			log.Printf("警告: LATENCY_EWMA_ALPHA值无效(%s)，使用默认值0.1", alpha)
+		}
+	}
+
+	// 读取LATENCY_ANOMALY_RATIO
+	if ratio := os.Getenv("LATENCY_ANOMALY_RATIO"); ratio != "" {
+		if val, err := strconv.ParseFloat(ratio, 64); err == nil && val > 1 {
+			config.LatencyAnomalyRatio = val
+		} else {
+			log.Printf("警告: LATENCY_ANOMALY_RATIO值无效(%s)，使用默认值3", ratio)
+		}
+	}
+
+	// 读取LATENCY_ANOMALY_MIN_DELTA
+	if minDelta := os.Getenv("LATENCY_ANOMALY_MIN_DELTA"); minDelta != "" {
+		if val, err := strconv.ParseFloat(minDelta, 64); err == nil && val > 0 {
+			config.LatencyAnomalyMinDelta = time.Duration(val * float64(time.Millisecond))
+		} else {
+			log.Printf("警告: LATENCY_ANOMALY_MIN_DELTA值无效(%s)，使用默认值1毫秒", minDelta)
+		}
+	}
+
+	// 读取LATENCY_BASELINE_MIN_SAMPLES
+	if minSamples := os.Getenv("LATENCY_BASELINE_MIN_SAMPLES"); minSamples != "" {
+		if val, err := strconv.Atoi(minSamples); err == nil && val > 0 {
+			config.LatencyBaselineMinSamples = val
+		} else {
+			log.Printf("警告: LATENCY_BASELINE_MIN_SAMPLES值无效(%s)，使用默认值10", minSamples)
+		}
+	}
+
+	// 读取LATENCY_ANOMALY_CONSECUTIVE
+	if consecutive := os.Getenv("LATENCY_ANOMALY_CONSECUTIVE"); consecutive != "" {
+		if val, err := strconv.Atoi(consecutive); err == nil && val > 0 {
+			config.LatencyAnomalyConsecutive = val
+		} else {
+			log.Printf("警告: LATENCY_ANOMALY_CONSECUTIVE值无效(%s)，使用默认值3", consecutive)
+		}
+	}
+
+	// 读取LATENCY_REBASELINE_AFTER
+	if rebaseline := os.Getenv("LATENCY_REBASELINE_AFTER"); rebaseline != "" {
+		if val, err := strconv.Atoi(rebaseline); err == nil && val > 0 {
+			config.LatencyRebaseline = time.Duration(val) * time.Second
+		} else {
+			log.Printf("警告: LATENCY_REBASELINE_AFTER值无效(%s)，使用默认值3600秒", rebaseline)
+		}
+	}
+
 	return config
 }
@@ -0,0 +1,22 @@
+// Package dashboard 提供内置的Web仪表盘，静态资源通过 go:embed 打包进二进制文件
+// 仪表盘只调用 /api/v1 下的现有接口，不依赖任何外部CDN
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Handler 返回仪表盘静态资源处理器，挂载时需去除路由前缀
+func Handler() http.Handler {
+	sub, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// static 目录在编译期嵌入，不会出现该错误
+		panic(err)
+	}
+	return http.FileServer(http.FS(sub))
+}
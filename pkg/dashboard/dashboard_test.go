@@ -0,0 +1,49 @@
+package dashboard
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandlerServesIndex 测试仪表盘首页及静态资源
+func TestHandlerServesIndex(t *testing.T) {
+	handler := Handler()
+
+	for path, contentType := range map[string]string{
+		"/":          "text/html",
+		"/app.js":    "javascript",
+		"/style.css": "text/css",
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Header().Get("Content-Type"), contentType, path)
+	}
+}
+
+// TestNoExternalResources 测试静态资源不引用外部地址
+// SVG命名空间只是标识符，不会产生网络请求
+func TestNoExternalResources(t *testing.T) {
+	external := regexp.MustCompile(`(?:src|href)\s*=\s*["']?(?:https?:)?//|https?://`)
+	allowed := "http://www.w3.org/2000/svg"
+
+	err := fs.WalkDir(staticFiles, "static", func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		if d.IsDir() {
+			return nil
+		}
+		data, err := staticFiles.ReadFile(path)
+		require.NoError(t, err)
+
+		content := regexp.MustCompile(regexp.QuoteMeta(allowed)).ReplaceAllString(string(data), "")
+		assert.False(t, external.MatchString(content), "%s 引用了外部资源", path)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,278 @@
+// K8s 网络连通性仪表盘
+// 只使用 /api/v1 下的接口，资源均内嵌在服务器中
+(function () {
+  'use strict';
+
+  var API = '../api/v1';
+  var POLL_INTERVAL = 15000;   // 定时刷新间隔
+  var EVENT_DEBOUNCE = 1000;   // 收到事件后延迟刷新，合并短时间内的多条事件
+  var HEARTBEAT_WARN = 15;     // 心跳超过该秒数显示为延迟
+
+  var state = { heatmapType: 'pod', report: null };
+  var refreshTimer = null;
+
+  function $(id) { return document.getElementById(id); }
+
+  function el(tag, attrs, text) {
+    var node = document.createElement(tag);
+    if (attrs) {
+      Object.keys(attrs).forEach(function (key) { node.setAttribute(key, attrs[key]); });
+    }
+    if (text !== undefined && text !== null) { node.textContent = String(text); }
+    return node;
+  }
+
+  function fetchJSON(path) {
+    return fetch(API + path, { headers: { Accept: 'application/json' } }).then(function (resp) {
+      if (!resp.ok) { throw new Error(path + ' 返回 ' + resp.status); }
+      return resp.json();
+    });
+  }
+
+  function percent(value) { return value.toFixed(2) + '%'; }
+
+  function emptyRow(tbody, colspan, text) {
+    var tr = el('tr');
+    tr.appendChild(el('td', { colspan: colspan, class: 'empty' }, text));
+    tbody.appendChild(tr);
+  }
+
+  // 汇总卡片
+  function renderCards(report) {
+    var pairs = report.pairs || [];
+    var failing = pairs.filter(function (p) { return !p.success; }).length;
+    $('card-clients').textContent = report.active_client_count;
+    $('card-host-rate').textContent = report.host_test_summary.total_tests ? percent(report.host_test_summary.success_rate) : '-';
+    $('card-pod-rate').textContent = report.pod_test_summary.total_tests ? percent(report.pod_test_summary.success_rate) : '-';
+    $('card-service-rate').textContent = report.service_test_summary.total_tests ? percent(report.service_test_summary.success_rate) : '-';
+    $('card-failing').textContent = failing;
+  }
+
+  // 热力图：行为源，列为目标
+  function renderHeatmap(report) {
+    var table = $('heatmap');
+    table.textContent = '';
+
+    var type = state.heatmapType;
+    var nodes = (type === 'host' ? report.host_ips : report.pod_ips) || [];
+    var index = {};
+    (report.pairs || []).forEach(function (p) {
+      if (p.test_type !== type) { return; }
+      index[p.source_ip + '|' + p.target_ip] = p;
+      if (nodes.indexOf(p.source_ip) < 0) { nodes.push(p.source_ip); }
+      if (nodes.indexOf(p.target_ip) < 0) { nodes.push(p.target_ip); }
+    });
+    nodes = nodes.slice().sort();
+
+    if (nodes.length === 0) {
+      var row = el('tr');
+      row.appendChild(el('td', { class: 'empty' }, '暂无数据'));
+      table.appendChild(row);
+      return;
+    }
+
+    var head = el('tr');
+    head.appendChild(el('th', null, '源 \\ 目标'));
+    nodes.forEach(function (ip) { head.appendChild(el('th', { class: 'col' }, ip)); });
+    table.appendChild(head);
+
+    nodes.forEach(function (source) {
+      var tr = el('tr');
+      tr.appendChild(el('th', { class: 'row' }, source));
+      nodes.forEach(function (target) {
+        var pair = index[source + '|' + target];
+        var cls = 'none';
+        var title = source + ' → ' + target + '\n暂无结果';
+        if (source === target && !pair) {
+          cls = 'self';
+          title = source;
+        } else if (pair) {
+          cls = pair.success ? 'ok' : 'fail';
+          title = source + ' → ' + target + '\nping: ' + pair.ping + '\n端口: ' + pair.port_status + '\n耗时: ' + pair.test_duration;
+        }
+        tr.appendChild(el('td', { class: 'cell ' + cls, title: title }));
+      });
+      table.appendChild(tr);
+    });
+  }
+
+  function renderFailing(report) {
+    var tbody = $('failing').querySelector('tbody');
+    tbody.textContent = '';
+    var failing = (report.pairs || []).filter(function (p) { return !p.success; });
+    if (failing.length === 0) { emptyRow(tbody, 6, '全部连通'); return; }
+    failing.forEach(function (p) {
+      var tr = el('tr');
+      [p.test_type, p.source_ip, p.target_ip, p.ping, p.port_status, p.test_duration].forEach(function (v) {
+        tr.appendChild(el('td', null, v));
+      });
+      tbody.appendChild(tr);
+    });
+  }
+
+  function renderServices(report) {
+    var tbody = $('services').querySelector('tbody');
+    tbody.textContent = '';
+    var services = (report.pairs || []).filter(function (p) { return p.test_type === 'service'; });
+    if (services.length === 0) { emptyRow(tbody, 5, '未配置自定义服务探测'); return; }
+    services.forEach(function (p) {
+      var tr = el('tr');
+      [p.source_ip, p.target_ip, p.ping, p.test_duration].forEach(function (v) {
+        tr.appendChild(el('td', null, v));
+      });
+      var td = el('td');
+      td.appendChild(el('span', { class: 'badge ' + (p.success ? 'badge-ok' : 'badge-fail') }, p.success ? '正常' : '失败'));
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+    });
+  }
+
+  function renderClients(data) {
+    var tbody = $('clients').querySelector('tbody');
+    tbody.textContent = '';
+    var clients = data.clients || [];
+    if (clients.length === 0) { emptyRow(tbody, 6, '暂无客户端'); return; }
+    clients.forEach(function (c) {
+      var tr = el('tr');
+      [c.pod_name, c.namespace, c.node_ip, c.pod_ip].forEach(function (v) {
+        tr.appendChild(el('td', null, v));
+      });
+      tr.appendChild(el('td', { title: c.last_heartbeat }, Math.round(c.heartbeat_age_seconds) + ' 秒前'));
+      var td = el('td');
+      var live = c.heartbeat_age_seconds <= HEARTBEAT_WARN;
+      td.appendChild(el('span', { class: 'badge ' + (live ? 'badge-ok' : 'badge-warn') }, live ? '在线' : '心跳延迟'));
+      tr.appendChild(td);
+      tbody.appendChild(tr);
+    });
+  }
+
+  // 折线图，entries 为按时间升序排列的历史报告摘要
+  function renderChart(svg, entries, hostKey, podKey, fixedMax) {
+    var ns = 'http://www.w3.org/2000/svg';
+    var width = 600, height = 200, pad = 28;
+    svg.textContent = '';
+
+    if (entries.length < 2) {
+      var msg = document.createElementNS(ns, 'text');
+      msg.setAttribute('x', width / 2);
+      msg.setAttribute('y', height / 2);
+      msg.setAttribute('text-anchor', 'middle');
+      msg.setAttribute('class', 'axis');
+      msg.textContent = '历史报告不足，至少需要两份';
+      svg.appendChild(msg);
+      return;
+    }
+
+    var max = fixedMax || 0;
+    if (!fixedMax) {
+      entries.forEach(function (e) { max = Math.max(max, e[hostKey], e[podKey]); });
+      max = max > 0 ? max * 1.1 : 1;
+    }
+
+    function x(i) { return pad + (width - 2 * pad) * i / (entries.length - 1); }
+    function y(v) { return height - pad - (height - 2 * pad) * v / max; }
+
+    [[hostKey, 'host'], [podKey, 'pod']].forEach(function (series) {
+      var points = entries.map(function (e, i) { return x(i).toFixed(1) + ',' + y(e[series[0]]).toFixed(1); });
+      var line = document.createElementNS(ns, 'polyline');
+      line.setAttribute('points', points.join(' '));
+      line.setAttribute('fill', 'none');
+      line.setAttribute('stroke-width', '2');
+      line.setAttribute('class', series[1]);
+      svg.appendChild(line);
+    });
+
+    [[0, '0'], [max, max.toFixed(max < 10 ? 1 : 0)]].forEach(function (tick) {
+      var label = document.createElementNS(ns, 'text');
+      label.setAttribute('x', 2);
+      label.setAttribute('y', y(tick[0]) + 4);
+      label.setAttribute('class', 'axis');
+      label.textContent = tick[1];
+      svg.appendChild(label);
+    });
+
+    [[0, 'start'], [entries.length - 1, 'end']].forEach(function (tick) {
+      var label = document.createElementNS(ns, 'text');
+      label.setAttribute('x', x(tick[0]));
+      label.setAttribute('y', height - 6);
+      label.setAttribute('text-anchor', tick[1]);
+      label.setAttribute('class', 'axis');
+      label.textContent = new Date(entries[tick[0]].timestamp).toLocaleTimeString();
+      svg.appendChild(label);
+    });
+  }
+
+  function renderTrends(data) {
+    var entries = (data.reports || []).slice().reverse();
+    renderChart($('trend-duration'), entries, 'host_avg_duration_ms', 'pod_avg_duration_ms');
+    renderChart($('trend-rate'), entries, 'host_success_rate', 'pod_success_rate', 100);
+  }
+
+  function refresh() {
+    clearTimeout(refreshTimer);
+    refreshTimer = null;
+
+    var tasks = [
+      fetchJSON('/report').then(function (report) {
+        state.report = report;
+        renderCards(report);
+        renderHeatmap(report);
+        renderFailing(report);
+        renderServices(report);
+      }),
+      fetchJSON('/clients').then(renderClients),
+      fetchJSON('/reports').then(renderTrends).catch(function () {
+        // 未启用历史报告时忽略趋势图
+      })
+    ];
+
+    Promise.all(tasks).then(function () {
+      $('updated-at').textContent = new Date().toLocaleTimeString();
+    }).catch(function (err) {
+      $('updated-at').textContent = '刷新失败: ' + err.message;
+    });
+  }
+
+  function scheduleRefresh() {
+    if (refreshTimer === null) {
+      refreshTimer = setTimeout(refresh, EVENT_DEBOUNCE);
+    }
+  }
+
+  // 订阅实时事件，测试结果或状态变化时刷新；不可用时退回定时刷新
+  function connectStream() {
+    var badge = $('stream-status');
+    if (!window.EventSource) {
+      badge.textContent = '定时刷新';
+      return;
+    }
+
+    var source = new EventSource(API + '/events?type=result,state_change');
+    source.onopen = function () {
+      badge.textContent = '实时';
+      badge.className = 'badge badge-ok';
+    };
+    source.onerror = function () {
+      badge.textContent = '实时连接断开，重连中';
+      badge.className = 'badge badge-warn';
+    };
+    source.addEventListener('result', scheduleRefresh);
+    source.addEventListener('state_change', scheduleRefresh);
+    source.addEventListener('dropped', scheduleRefresh);
+  }
+
+  $('refresh').addEventListener('click', refresh);
+  Array.prototype.forEach.call($('heatmap-tabs').querySelectorAll('button'), function (button) {
+    button.addEventListener('click', function () {
+      Array.prototype.forEach.call($('heatmap-tabs').querySelectorAll('button'), function (b) {
+        b.classList.toggle('active', b === button);
+      });
+      state.heatmapType = button.getAttribute('data-type');
+      if (state.report) { renderHeatmap(state.report); }
+    });
+  });
+
+  refresh();
+  setInterval(refresh, POLL_INTERVAL);
+  connectStream();
+})();
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBufferSize 订阅者默认缓冲区大小
+const DefaultBufferSize = 256
+
+// Bus 定义事件总线接口
+type Bus interface {
+	// Publish 发布事件，不会因订阅者消费缓慢而阻塞
+	Publish(event Event)
+
+	// Subscribe 按过滤条件订阅事件，bufferSize <= 0 时使用 DefaultBufferSize
+	Subscribe(filter Filter, bufferSize int) Subscription
+
+	// SubscriberCount 返回当前订阅者数量
+	SubscriberCount() int
+}
+
+// Subscription 定义事件订阅接口
+type Subscription interface {
+	// Events 返回事件通道，订阅关闭后通道会被关闭
+	Events() <-chan Event
+
+	// Dropped 返回因缓冲区已满而丢弃的事件数量
+	Dropped() uint64
+
+	// Close 取消订阅
+	Close()
+}
+
+// busImpl 是Bus的实现
+type busImpl struct {
+	mu          sync.RWMutex
+	subscribers map[*subscription]struct{}
+	nextID      uint64
+}
+
+// NewBus 创建事件总线
+func NewBus() Bus {
+	return &busImpl{
+		subscribers: make(map[*subscription]struct{}),
+	}
+}
+
+// Publish 发布事件
+// 订阅者缓冲区已满时丢弃该事件并计数，由订阅者自行决定如何处理积压
+func (b *busImpl) Publish(event Event) {
+	event.ID = atomic.AddUint64(&b.nextID, 1)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(&event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+}
+
+// Subscribe 订阅事件
+func (b *busImpl) Subscribe(filter Filter, bufferSize int) Subscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	sub := &subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan Event, bufferSize),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// SubscriberCount 返回当前订阅者数量
+func (b *busImpl) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// unsubscribe 移除订阅者并关闭其事件通道
+// 持有写锁时关闭通道，保证不会与Publish并发写入已关闭的通道
+func (b *busImpl) unsubscribe(sub *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// subscription 是Subscription的实现
+type subscription struct {
+	bus     *busImpl
+	filter  Filter
+	events  chan Event
+	dropped uint64
+}
+
+// Events 返回事件通道
+func (s *subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped 返回丢弃的事件数量
+func (s *subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close 取消订阅，可重复调用
+func (s *subscription) Close() {
+	s.bus.unsubscribe(s)
+}
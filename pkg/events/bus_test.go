@@ -0,0 +1,111 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBusPublishSubscribe 测试发布订阅与过滤
+func TestBusPublishSubscribe(t *testing.T) {
+	bus := NewBus()
+
+	all := bus.Subscribe(Filter{}, 10)
+	filtered := bus.Subscribe(Filter{Types: []string{TypeResult}, Sources: []string{"10.0.0.1"}}, 10)
+	assert.Equal(t, 2, bus.SubscriberCount())
+
+	bus.Publish(Event{Type: TypeHeartbeat, Source: "10.0.0.1"})
+	bus.Publish(Event{Type: TypeResult, Source: "10.0.0.2"})
+	bus.Publish(Event{Type: TypeResult, Source: "10.0.0.1", Target: "10.0.0.2"})
+
+	assert.Len(t, all.Events(), 3)
+	require.Len(t, filtered.Events(), 1)
+
+	event := <-filtered.Events()
+	assert.Equal(t, uint64(3), event.ID)
+	assert.Equal(t, "10.0.0.2", event.Target)
+	assert.False(t, event.Timestamp.IsZero())
+
+	// 关闭后通道被关闭，重复关闭无影响
+	filtered.Close()
+	filtered.Close()
+	_, ok := <-filtered.Events()
+	assert.False(t, ok)
+	assert.Equal(t, 1, bus.SubscriberCount())
+}
+
+// TestBusSlowSubscriber 测试慢消费者不阻塞发布并统计丢弃数量
+func TestBusSlowSubscriber(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe(Filter{}, 2)
+	fast := bus.Subscribe(Filter{}, 10)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: TypeHeartbeat})
+	}
+
+	assert.Len(t, slow.Events(), 2)
+	assert.Equal(t, uint64(3), slow.Dropped())
+	assert.Len(t, fast.Events(), 5)
+	assert.Equal(t, uint64(0), fast.Dropped())
+}
+
+// TestObserver 测试心跳、测试结果与探测对有效状态变化转换为事件
+func TestObserver(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(Filter{}, 20)
+	observer := NewObserver(bus)
+
+	info := &models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}
+	observer.OnHeartbeat(info)
+	observer.OnHeartbeat(info)
+
+	up := models.ConnectivityResult{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}}
+	down := models.ConnectivityResult{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}}
+	observer.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{up})
+	observer.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{down})
+	observer.OnHealthChange(models.TestTypePod, "10.0.0.1", "10.0.0.2", models.PairStateUp, models.PairStateFlapping)
+
+	var types []string
+	var changes []StateChange
+	for len(sub.Events()) > 0 {
+		event := <-sub.Events()
+		types = append(types, event.Type)
+		if change, ok := event.Data.(StateChange); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	// 单次结果的成功与失败切换不产生状态变化事件
+	assert.Equal(t, []string{
+		TypeStateChange, TypeHeartbeat, TypeHeartbeat,
+		TypeResult, TypeResult, TypeStateChange,
+	}, types)
+	assert.Equal(t, []StateChange{{To: StateRegistered}, {From: StateUp, To: StateFlapping}}, changes)
+}
+
+// TestObserverPrunesState 测试长时间没有心跳的客户端被删除
+func TestObserverPrunesState(t *testing.T) {
+	bus := NewBus()
+	observer := NewObserver(bus).(*observerImpl)
+	now := time.Now()
+	observer.now = func() time.Time { return now }
+
+	observer.OnHeartbeat(&models.NodeInfo{PodName: "pod-1", PodIP: "10.0.0.1"})
+	assert.Len(t, observer.clients, 1)
+
+	now = now.Add(clientTTL + time.Minute)
+	observer.OnHeartbeat(&models.NodeInfo{PodName: "pod-2", PodIP: "10.0.0.3"})
+	assert.Len(t, observer.clients, 1)
+	assert.Contains(t, observer.clients, "pod-2")
+
+	// 再次出现的客户端重新发布注册事件
+	sub := bus.Subscribe(Filter{Types: []string{TypeStateChange}}, 10)
+	observer.OnHeartbeat(&models.NodeInfo{PodName: "pod-1", PodIP: "10.0.0.1"})
+	require.Len(t, sub.Events(), 1)
+	assert.Equal(t, StateChange{To: StateRegistered}, (<-sub.Events()).Data)
+}
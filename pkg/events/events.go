@@ -0,0 +1,69 @@
+package events
+
+import (
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// 事件类型
+const (
+	TypeHeartbeat   = "heartbeat"
+	TypeResult      = "result"
+	TypeStateChange = "state_change"
+)
+
+// 状态变化，探测对的状态为经过滞后阈值平滑的有效状态
+const (
+	StateUp         = models.PairStateUp
+	StateDown       = models.PairStateDown
+	StateFlapping   = models.PairStateFlapping
+	StateRecovered  = models.PairStateRecovered
+	StateRegistered = "registered"
+)
+
+// Event 表示一条实时事件
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	TestType  string      `json:"test_type,omitempty"`
+	Source    string      `json:"source,omitempty"`
+	Target    string      `json:"target,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// StateChange 状态变化事件的数据
+type StateChange struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+}
+
+// Filter 事件过滤条件，各字段为空表示不限制，多个取值之间为"或"关系
+type Filter struct {
+	Types     []string
+	TestTypes []string
+	Sources   []string
+	Targets   []string
+}
+
+// Match 判断事件是否满足过滤条件
+func (f Filter) Match(event *Event) bool {
+	return matchAny(f.Types, event.Type) &&
+		matchAny(f.TestTypes, event.TestType) &&
+		matchAny(f.Sources, event.Source) &&
+		matchAny(f.Targets, event.Target)
+}
+
+// matchAny 判断value是否在values中，values为空时视为匹配
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+const (
+	// clientTTL 客户端超过该时长没有新的心跳时删除其记录，例如Pod已删除
+	// 之后再次出现的客户端重新发布注册事件
+	clientTTL = time.Hour
+
+	// pruneInterval 清理过期客户端的最小间隔
+	pruneInterval = time.Minute
+)
+
+// Observer 将心跳、测试结果与探测对有效状态变化转换为事件发布到总线
+// 同时实现 client.HeartbeatObserver、result.ResultObserver 和 result.HealthObserver
+type Observer interface {
+	OnHeartbeat(info *models.NodeInfo)
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+	OnHealthChange(testType string, sourceIP string, targetIP string, from string, to string)
+}
+
+// observerImpl 是Observer的实现
+type observerImpl struct {
+	bus Bus
+
+	mu         sync.Mutex
+	clients    map[string]time.Time // 已注册的客户端最后一次心跳的时间，key为PodName
+	lastPruned time.Time
+	now        func() time.Time
+}
+
+// NewObserver 创建事件观察者
+func NewObserver(bus Bus) Observer {
+	return &observerImpl{
+		bus:     bus,
+		clients: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// OnHeartbeat 发布心跳事件，首次出现的客户端额外发布注册事件
+func (o *observerImpl) OnHeartbeat(info *models.NodeInfo) {
+	o.mu.Lock()
+	now := o.now()
+	o.pruneLocked(now)
+	_, registered := o.clients[info.PodName]
+	o.clients[info.PodName] = now
+	o.mu.Unlock()
+
+	if !registered {
+		o.bus.Publish(Event{
+			Type:   TypeStateChange,
+			Source: info.PodIP,
+			Data:   StateChange{To: StateRegistered},
+		})
+	}
+
+	o.bus.Publish(Event{
+		Type:   TypeHeartbeat,
+		Source: info.PodIP,
+		Data:   info,
+	})
+}
+
+// OnTestResults 发布测试结果事件
+func (o *observerImpl) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	for i := range results {
+		o.bus.Publish(Event{
+			Type:     TypeResult,
+			TestType: testType,
+			Source:   sourceIP,
+			Target:   results[i].TargetIP,
+			Data:     results[i],
+		})
+	}
+}
+
+// OnHealthChange 探测对的有效状态变化时发布状态变化事件
+// 有效状态经过滞后阈值平滑，偶发的单次失败不会产生事件
+func (o *observerImpl) OnHealthChange(testType string, sourceIP string, targetIP string, from string, to string) {
+	o.bus.Publish(Event{
+		Type:     TypeStateChange,
+		TestType: testType,
+		Source:   sourceIP,
+		Target:   targetIP,
+		Data:     StateChange{From: from, To: to},
+	})
+}
+
+// pruneLocked 删除长时间没有心跳的客户端（调用者需持有锁）
+func (o *observerImpl) pruneLocked(now time.Time) {
+	if now.Sub(o.lastPruned) < pruneInterval {
+		return
+	}
+	o.lastPruned = now
+
+	for podName, lastSeen := range o.clients {
+		if now.Sub(lastSeen) > clientTTL {
+			delete(o.clients, podName)
+		}
+	}
+}
@@ -3,12 +3,17 @@ package heartbeat
 import (
 	"context"
 	"log"
+	"math/rand"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/api/client"
 	"github.com/yezihack/k8snet-checker/pkg/collector"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
 )
 
 // HeartbeatReporter 定义了心跳上报的接口
@@ -18,23 +23,84 @@ type HeartbeatReporter interface {
 
 	// Stop 停止心跳上报
 	Stop() error
+
+	// ServerStatus 返回与服务器的连接状态
+	ServerStatus() models.ServerStatus
+}
+
+// RecoveryObserver 服务器从不可达恢复时收到通知
+type RecoveryObserver interface {
+	// OnServerRecovered 在心跳重新成功后调用，实现方不应阻塞
+	OnServerRecovered()
 }
 
+// 心跳间隔的取值范围
+const (
+	// DefaultMaxBackoff 服务器不可达时心跳退避的默认上限
+	DefaultMaxBackoff = 60 * time.Second
+
+	// minSuggestedInterval 服务器建议的心跳间隔下限，避免错误配置导致心跳风暴
+	minSuggestedInterval = time.Second
+)
+
 // heartbeatReporterImpl 是HeartbeatReporter的实现
 type heartbeatReporterImpl struct {
 	collector  collector.InfoCollector
 	apiClient  client.APIClient
+	metrics    *metrics.ClientMetrics
+	probeStore probeconfig.Store // 可选，为nil时不同步探测配置
+	maxBackoff time.Duration
+	observers  []RecoveryObserver
 	cancelFunc context.CancelFunc
 	done       chan struct{}
+
+	mu        sync.Mutex
+	interval  time.Duration       // 本地配置的心跳间隔
+	suggested time.Duration       // 服务器建议的心跳间隔，为0时使用本地配置
+	status    models.ServerStatus // 与服务器的连接状态
+}
+
+// Option 心跳上报器配置选项
+type Option func(r *heartbeatReporterImpl)
+
+// WithProbeConfig 心跳携带当前探测配置版本，并应用服务器返回的新配置
+func WithProbeConfig(store probeconfig.Store) Option {
+	return func(r *heartbeatReporterImpl) {
+		r.probeStore = store
+	}
+}
+
+// WithMaxBackoff 设置服务器不可达时心跳退避的上限
+// 上限越小，服务器恢复后客户端越快重新注册
+func WithMaxBackoff(d time.Duration) Option {
+	return func(r *heartbeatReporterImpl) {
+		if d > 0 {
+			r.maxBackoff = d
+		}
+	}
+}
+
+// WithRecoveryObserver 服务器从不可达恢复时通知 observer
+func WithRecoveryObserver(observer RecoveryObserver) Option {
+	return func(r *heartbeatReporterImpl) {
+		r.observers = append(r.observers, observer)
+	}
 }
 
 // NewHeartbeatReporter 创建一个新的HeartbeatReporter实例
-func NewHeartbeatReporter(collector collector.InfoCollector, apiClient client.APIClient) HeartbeatReporter {
-	return &heartbeatReporterImpl{
-		collector: collector,
-		apiClient: apiClient,
-		done:      make(chan struct{}),
+// clientMetrics 可为 nil
+func NewHeartbeatReporter(collector collector.InfoCollector, apiClient client.APIClient, clientMetrics *metrics.ClientMetrics, opts ...Option) HeartbeatReporter {
+	r := &heartbeatReporterImpl{
+		collector:  collector,
+		apiClient:  apiClient,
+		metrics:    clientMetrics,
+		maxBackoff: DefaultMaxBackoff,
+		done:       make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Start 启动心跳上报goroutine
@@ -44,10 +110,14 @@ func (r *heartbeatReporterImpl) Start(ctx context.Context, interval time.Duratio
 	ctx, cancel := context.WithCancel(ctx)
 	r.cancelFunc = cancel
 
+	r.mu.Lock()
+	r.interval = interval
+	r.mu.Unlock()
+
 	log.Printf("启动心跳上报goroutine，间隔: %v", interval)
 
 	// 启动独立的goroutine进行心跳上报
-	go r.heartbeatLoop(ctx, interval)
+	go r.heartbeatLoop(ctx)
 
 	return nil
 }
@@ -65,17 +135,25 @@ func (r *heartbeatReporterImpl) Stop() error {
 	return nil
 }
 
+// ServerStatus 返回与服务器的连接状态
+func (r *heartbeatReporterImpl) ServerStatus() models.ServerStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
 // heartbeatLoop 心跳上报循环
-func (r *heartbeatReporterImpl) heartbeatLoop(ctx context.Context, interval time.Duration) {
+// 每次心跳后根据结果决定下一次的等待时间：成功时使用服务器建议或本地配置的间隔，
+// 服务器返回5xx或不可达时按指数退避加随机抖动延长，不超过退避上限
+func (r *heartbeatReporterImpl) heartbeatLoop(ctx context.Context) {
 	defer close(r.done)
 
-	// 创建定时器
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
 	// 立即发送第一次心跳
 	r.sendHeartbeat()
 
+	timer := time.NewTimer(r.nextDelay())
+	defer timer.Stop()
+
 	// 定期发送心跳
 	for {
 		select {
@@ -84,11 +162,35 @@ func (r *heartbeatReporterImpl) heartbeatLoop(ctx context.Context, interval time
 			log.Println("心跳上报goroutine收到停止信号")
 			return
 
-		case <-ticker.C:
+		case <-timer.C:
 			// 定时器触发，发送心跳
 			r.sendHeartbeat()
+			timer.Reset(r.nextDelay())
+		}
+	}
+}
+
+// nextDelay 计算下一次心跳的等待时间，并记录到连接状态中
+func (r *heartbeatReporterImpl) nextDelay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delay := r.interval
+	if r.suggested > 0 {
+		delay = r.suggested
+	}
+	if failures := r.status.ConsecutiveFailures; failures > 0 && delay < r.maxBackoff {
+		// 第n次连续失败后等待 间隔*2^(n-1)，抖动范围为其一半，避免服务器恢复时所有客户端同时重连
+		backoff := r.maxBackoff
+		if failures <= 16 {
+			backoff = min(delay<<(failures-1), r.maxBackoff)
 		}
+		delay = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
 	}
+
+	r.status.HeartbeatInterval = models.Duration(delay)
+	r.metrics.ObserveServerStatus(r.status)
+	return delay
 }
 
 // sendHeartbeat 发送单次心跳
@@ -100,15 +202,92 @@ func (r *heartbeatReporterImpl) sendHeartbeat() {
 		return
 	}
 
+	if r.probeStore != nil {
+		nodeInfo.ProbeConfigVersion = r.probeStore.Get().Version
+	}
+
 	// 发送心跳到服务器
-	err = r.apiClient.SendHeartbeat(nodeInfo)
+	response, err := r.apiClient.SendHeartbeat(nodeInfo)
+	r.metrics.ObserveHeartbeat(err)
 	if err != nil {
 		log.Printf("错误: 发送心跳失败: %v", err)
 		// 注意：这里不返回，下一个心跳周期会继续尝试
+		r.recordFailure(err)
 		return
 	}
 
 	log.Printf("心跳发送成功: pod=%s", nodeInfo.PodName)
+	r.recordSuccess(response)
+
+	if r.probeStore != nil && response != nil && response.ProbeConfig != nil {
+		r.applyProbeConfig(*response.ProbeConfig)
+	}
+}
+
+// recordFailure 记录心跳失败
+// 服务器返回4xx说明服务器可达但拒绝了请求（如认证失败），退避无助于恢复，按正常间隔继续尝试
+func (r *heartbeatReporterImpl) recordFailure(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.LastError = err.Error()
+	if client.IsPermanent(err) {
+		r.status.Reachable = true
+		r.status.ConsecutiveFailures = 0
+		return
+	}
+	if r.status.Reachable || r.status.ConsecutiveFailures == 0 {
+		log.Printf("警告: 服务器不可达，心跳开始退避: %v", err)
+	}
+	r.status.Reachable = false
+	r.status.ConsecutiveFailures++
+}
+
+// recordSuccess 记录心跳成功，应用服务器建议的心跳间隔
+// 从不可达恢复时通知观察者，以便立即重放离线期间缓存的数据
+func (r *heartbeatReporterImpl) recordSuccess(response *models.HeartbeatResponse) {
+	r.mu.Lock()
+	recovered := !r.status.Reachable && r.status.ConsecutiveFailures > 0
+	failures := r.status.ConsecutiveFailures
+	r.status.Reachable = true
+	r.status.ConsecutiveFailures = 0
+	r.status.LastSuccess = time.Now()
+	r.status.LastError = ""
+
+	if response != nil {
+		suggested := time.Duration(response.HeartbeatInterval)
+		if suggested > 0 && suggested < minSuggestedInterval {
+			suggested = minSuggestedInterval
+		}
+		if suggested != r.suggested {
+			if suggested > 0 {
+				log.Printf("心跳间隔调整为服务器建议值: %v", suggested)
+			} else {
+				log.Printf("服务器未建议心跳间隔，恢复使用本地配置: %v", r.interval)
+			}
+			r.suggested = suggested
+		}
+	}
+	r.mu.Unlock()
+
+	if recovered {
+		log.Printf("服务器已恢复，连续失败%d次后心跳成功", failures)
+		for _, observer := range r.observers {
+			observer.OnServerRecovered()
+		}
+	}
+}
+
+// applyProbeConfig 应用服务器下发的探测配置，配置无效时保留当前配置
+func (r *heartbeatReporterImpl) applyProbeConfig(cfg models.ProbeConfig) {
+	changed, err := r.probeStore.Apply(cfg)
+	if err != nil {
+		log.Printf("错误: 服务器下发的探测配置无效，保留当前配置: version=%d, error=%v", cfg.Version, err)
+		return
+	}
+	if changed {
+		log.Printf("已应用服务器下发的探测配置: version=%d", cfg.Version)
+	}
 }
 
 // GetHeartbeatIntervalFromEnv 从环境变量获取心跳间隔配置
@@ -147,7 +326,7 @@ func NewHeartbeatReporterFromEnv(apiClient client.APIClient) (HeartbeatReporter,
 	}
 
 	// 创建心跳上报器
-	reporter := NewHeartbeatReporter(infoCollector, apiClient)
+	reporter := NewHeartbeatReporter(infoCollector, apiClient, nil)
 
 	return reporter, nil
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/api/client"
 	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/probeconfig"
 )
 
 // mockInfoCollector 是InfoCollector的mock实现
@@ -28,12 +30,16 @@ type mockAPIClient struct {
 	heartbeatCalls int
 	heartbeatErr   error
 	lastNodeInfo   *models.NodeInfo
+	response       *models.HeartbeatResponse
 }
 
-func (m *mockAPIClient) SendHeartbeat(info *models.NodeInfo) error {
+func (m *mockAPIClient) SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error) {
 	m.heartbeatCalls++
 	m.lastNodeInfo = info
-	return m.heartbeatErr
+	if m.heartbeatErr != nil {
+		return nil, m.heartbeatErr
+	}
+	return m.response, nil
 }
 
 func (m *mockAPIClient) GetHostIPs() ([]string, error) {
@@ -44,6 +50,14 @@ func (m *mockAPIClient) GetPodIPs() ([]string, error) {
 	return nil, nil
 }
 
+func (m *mockAPIClient) GetHostTargets() ([]models.Target, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) GetPodTargets() ([]models.Target, error) {
+	return nil, nil
+}
+
 func (m *mockAPIClient) ReportHostTestResults(results []models.ConnectivityResult) error {
 	return nil
 }
@@ -56,6 +70,14 @@ func (m *mockAPIClient) ReportServiceTestResults(result *models.ConnectivityResu
 	return nil
 }
 
+func (m *mockAPIClient) PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) CompleteRun(runID string, podName string) error {
+	return nil
+}
+
 // TestNewHeartbeatReporter 测试创建HeartbeatReporter
 func TestNewHeartbeatReporter(t *testing.T) {
 	collector := &mockInfoCollector{
@@ -68,7 +90,7 @@ func TestNewHeartbeatReporter(t *testing.T) {
 	}
 	apiClient := &mockAPIClient{}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	if reporter == nil {
 		t.Fatal("NewHeartbeatReporter返回nil")
@@ -88,7 +110,7 @@ func TestHeartbeatReporter_Start_Success(t *testing.T) {
 	}
 	apiClient := &mockAPIClient{}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	ctx := context.Background()
 	interval := 100 * time.Millisecond
@@ -136,7 +158,7 @@ func TestHeartbeatReporter_SendHeartbeatError(t *testing.T) {
 		heartbeatErr: errors.New("网络错误"),
 	}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	ctx := context.Background()
 	interval := 100 * time.Millisecond
@@ -168,7 +190,7 @@ func TestHeartbeatReporter_CollectInfoError(t *testing.T) {
 	}
 	apiClient := &mockAPIClient{}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	ctx := context.Background()
 	interval := 100 * time.Millisecond
@@ -206,7 +228,7 @@ func TestHeartbeatReporter_ContextCancellation(t *testing.T) {
 	}
 	apiClient := &mockAPIClient{}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	interval := 100 * time.Millisecond
@@ -304,7 +326,7 @@ func TestHeartbeatReporter_MultipleStops(t *testing.T) {
 	}
 	apiClient := &mockAPIClient{}
 
-	reporter := NewHeartbeatReporter(collector, apiClient)
+	reporter := NewHeartbeatReporter(collector, apiClient, nil)
 
 	ctx := context.Background()
 	interval := 100 * time.Millisecond
@@ -326,3 +348,126 @@ func TestHeartbeatReporter_MultipleStops(t *testing.T) {
 		t.Fatalf("第二次Stop失败: %v", err)
 	}
 }
+
+// TestHeartbeatReporter_ProbeConfig 测试心跳携带配置版本并应用服务器下发的新配置
+func TestHeartbeatReporter_ProbeConfig(t *testing.T) {
+	collector := &mockInfoCollector{
+		nodeInfo: &models.NodeInfo{
+			Namespace: "default",
+			NodeIP:    "192.168.1.1",
+			PodIP:     "10.0.0.1",
+			PodName:   "test-pod",
+		},
+	}
+
+	store, err := probeconfig.NewStore(probeconfig.Default())
+	if err != nil {
+		t.Fatalf("创建探测配置存储失败: %v", err)
+	}
+
+	pushed := probeconfig.Default()
+	pushed.Version = 3
+	pushed.PodPorts = []int{6100, 8080}
+	apiClient := &mockAPIClient{
+		response: &models.HeartbeatResponse{Status: "success", ProbeConfig: &pushed},
+	}
+
+	reporter := NewHeartbeatReporter(collector, apiClient, nil, WithProbeConfig(store)).(*heartbeatReporterImpl)
+
+	reporter.sendHeartbeat()
+	if apiClient.lastNodeInfo.ProbeConfigVersion != 0 {
+		t.Errorf("期望首次心跳携带版本0，实际为%d", apiClient.lastNodeInfo.ProbeConfigVersion)
+	}
+	if store.Get().Version != 3 {
+		t.Fatalf("期望应用版本3，实际为%d", store.Get().Version)
+	}
+
+	// 后续心跳携带新版本，无效配置不会覆盖当前配置
+	apiClient.response = &models.HeartbeatResponse{Status: "success", ProbeConfig: &models.ProbeConfig{Version: 4, TestTypes: []string{"udp"}}}
+	reporter.sendHeartbeat()
+	if apiClient.lastNodeInfo.ProbeConfigVersion != 3 {
+		t.Errorf("期望心跳携带版本3，实际为%d", apiClient.lastNodeInfo.ProbeConfigVersion)
+	}
+	if store.Get().Version != 3 {
+		t.Errorf("无效配置不应被应用，当前版本为%d", store.Get().Version)
+	}
+}
+
+// recoveryCounter 记录服务器恢复通知次数
+type recoveryCounter struct {
+	count int
+}
+
+func (r *recoveryCounter) OnServerRecovered() {
+	r.count++
+}
+
+// TestHeartbeatReporter_AdaptiveInterval 测试服务器不可达时退避、恢复后通知观察者并使用服务器建议的间隔
+func TestHeartbeatReporter_AdaptiveInterval(t *testing.T) {
+	collector := &mockInfoCollector{
+		nodeInfo: &models.NodeInfo{
+			Namespace: "default",
+			NodeIP:    "192.168.1.1",
+			PodIP:     "10.0.0.1",
+			PodName:   "test-pod",
+		},
+	}
+	apiClient := &mockAPIClient{
+		heartbeatErr: &client.StatusError{StatusCode: 503, Message: "SERVICE_UNAVAILABLE"},
+	}
+	observer := &recoveryCounter{}
+	reporter := NewHeartbeatReporter(collector, apiClient, nil,
+		WithMaxBackoff(8*time.Second),
+		WithRecoveryObserver(observer),
+	).(*heartbeatReporterImpl)
+	reporter.interval = time.Second
+
+	// 连续失败时等待时间按指数增长，抖动范围为其一半，不超过退避上限
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		reporter.sendHeartbeat()
+		delay := reporter.nextDelay()
+		if delay < want/2 || delay > want {
+			t.Errorf("期望等待时间在[%v, %v]之间，实际为%v", want/2, want, delay)
+		}
+	}
+	status := reporter.ServerStatus()
+	if status.Reachable || status.ConsecutiveFailures != 5 || status.LastError == "" {
+		t.Errorf("期望服务器不可达且连续失败5次，实际为%+v", status)
+	}
+
+	// 恢复后通知观察者，并使用服务器建议的间隔
+	apiClient.heartbeatErr = nil
+	apiClient.response = &models.HeartbeatResponse{Status: "success", HeartbeatInterval: models.Duration(3 * time.Second)}
+	reporter.sendHeartbeat()
+	if delay := reporter.nextDelay(); delay != 3*time.Second {
+		t.Errorf("期望使用服务器建议的间隔3s，实际为%v", delay)
+	}
+	if observer.count != 1 {
+		t.Errorf("期望通知1次服务器恢复，实际为%d次", observer.count)
+	}
+	status = reporter.ServerStatus()
+	if !status.Reachable || status.ConsecutiveFailures != 0 || status.LastSuccess.IsZero() {
+		t.Errorf("期望服务器可达，实际为%+v", status)
+	}
+
+	// 服务器拒绝请求时不退避，也不视为不可达
+	apiClient.heartbeatErr = &client.StatusError{StatusCode: 401, Message: "UNAUTHORIZED"}
+	reporter.sendHeartbeat()
+	if delay := reporter.nextDelay(); delay != 3*time.Second {
+		t.Errorf("4xx错误不应退避，实际等待%v", delay)
+	}
+	if !reporter.ServerStatus().Reachable {
+		t.Error("4xx错误不应将服务器视为不可达")
+	}
+
+	// 服务器不再建议间隔时恢复使用本地配置
+	apiClient.heartbeatErr = nil
+	apiClient.response = &models.HeartbeatResponse{Status: "success"}
+	reporter.sendHeartbeat()
+	if delay := reporter.nextDelay(); delay != time.Second {
+		t.Errorf("期望恢复使用本地间隔1s，实际为%v", delay)
+	}
+	if observer.count != 1 {
+		t.Errorf("服务器一直可达时不应重复通知，实际通知%d次", observer.count)
+	}
+}
@@ -0,0 +1,338 @@
+// Package latency 为每个探测对学习延迟基线（EWMA与最近样本的分位数），
+// 延迟连续明显高于基线时标记为异常，异常通过报告与告警提供。
+// 异常期间的样本不计入基线，避免持续劣化被基线吸收；延迟恢复后继续学习，
+// 异常持续超过 Rebaseline 时视为新的延迟水平（例如迁移到其他可用区），重新学习基线
+package latency
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// pairTTL 探测对超过该时长没有新样本时删除其基线，例如Pod已删除
+const pairTTL = time.Hour
+
+// Config 延迟基线与异常检测配置
+type Config struct {
+	Alpha       float64       // EWMA平滑系数，取值 (0, 1]，越大越偏重最近的样本
+	Ratio       float64       // 延迟达到基线EWMA的该倍数时视为异常样本
+	MinDelta    time.Duration // 异常样本还需高出基线EWMA该值，避免亚毫秒级延迟的小幅波动被误判
+	MinSamples  int           // 基线样本数达到该值后才开始检测
+	Consecutive int           // 连续异常样本数达到该值时报告异常
+	Window      int           // 计算分位数保留的最近样本数
+	Rebaseline  time.Duration // 异常持续该时长后接受新的延迟水平，丢弃原基线重新学习
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() Config {
+	return Config{
+		Alpha:       0.1,
+		Ratio:       3,
+		MinDelta:    time.Millisecond,
+		MinSamples:  10,
+		Consecutive: 3,
+		Window:      100,
+		Rebaseline:  time.Hour,
+	}
+}
+
+// withDefaults 将未设置的配置替换为默认值
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if c.Alpha <= 0 || c.Alpha > 1 {
+		c.Alpha = defaults.Alpha
+	}
+	if c.Ratio <= 1 {
+		c.Ratio = defaults.Ratio
+	}
+	if c.MinDelta <= 0 {
+		c.MinDelta = defaults.MinDelta
+	}
+	if c.MinSamples <= 0 {
+		c.MinSamples = defaults.MinSamples
+	}
+	if c.Consecutive <= 0 {
+		c.Consecutive = defaults.Consecutive
+	}
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.Rebaseline <= 0 {
+		c.Rebaseline = defaults.Rebaseline
+	}
+	return c
+}
+
+// Detector 定义延迟基线与异常检测接口
+type Detector interface {
+	// OnTestResults 实现 result.ResultObserver，使用宿主机与Pod探测中ping可达的延迟
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+
+	// Baselines 返回所有探测对的最近延迟与基线，按类型、源IP、目标IP排序
+	Baselines() []models.PairLatency
+
+	// Anomalies 返回当前延迟异常的探测对，按类型、源IP、目标IP排序
+	Anomalies() []models.LatencyAnomaly
+
+	// Enrich 实现 report.Enricher，在报告中加入延迟异常
+	Enrich(report *models.NetworkReport)
+}
+
+// pairKey 探测对标识，目标按身份区分，IP被新的Pod或节点复用时重新学习基线
+type pairKey struct {
+	testType string
+	sourceIP string
+	targetIP string
+	target   string // 客户端标注的目标身份（Pod UID或名称、节点名称），未标注时为空
+}
+
+// pairState 探测对的基线与异常状态
+type pairState struct {
+	latest    time.Duration
+	updatedAt time.Time
+
+	ewma    float64         // 纳秒
+	samples int             // 计入基线的样本总数
+	recent  []time.Duration // 最近计入基线的样本，环形缓冲
+	next    int             // 环形缓冲的下一个写入位置
+
+	consecutive int       // 连续异常的样本数
+	since       time.Time // 本次连续异常的开始时间
+}
+
+// detectorImpl 是Detector的实现
+type detectorImpl struct {
+	config Config
+
+	mu    sync.Mutex
+	pairs map[pairKey]*pairState
+	now   func() time.Time
+}
+
+// NewDetector 创建延迟异常检测器，未设置的配置使用默认值
+func NewDetector(cfg Config) Detector {
+	return &detectorImpl{
+		config: cfg.withDefaults(),
+		pairs:  make(map[pairKey]*pairState),
+		now:    time.Now,
+	}
+}
+
+// OnTestResults 记录探测延迟，ping不可达或未启用ICMP的结果没有延迟，不计入
+func (d *detectorImpl) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	if testType != models.TestTypeHost && testType != models.TestTypePod {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for _, result := range results {
+		latency := time.Duration(result.Latency)
+		if result.TargetIP == "" || result.PingStatus != "reachable" || latency <= 0 {
+			continue
+		}
+		key := pairKey{testType: testType, sourceIP: sourceIP, targetIP: result.TargetIP, target: targetIdentity(testType, result.Target)}
+		state, ok := d.pairs[key]
+		if !ok {
+			d.replace(key)
+			state = &pairState{recent: make([]time.Duration, 0, d.config.Window)}
+			d.pairs[key] = state
+		}
+		state.latest = latency
+		state.updatedAt = now
+
+		if d.anomalous(state, latency) {
+			state.consecutive++
+			if state.consecutive == 1 {
+				state.since = now
+			}
+			if state.consecutive == d.config.Consecutive {
+				log.Printf("探测对延迟异常: %s %s -> %s, 延迟=%v, 基线=%v",
+					testType, sourceIP, result.TargetIP, latency, time.Duration(state.ewma))
+			}
+			if state.consecutive < d.config.Consecutive || now.Sub(state.since) < d.config.Rebaseline {
+				continue
+			}
+			// 异常持续过久，接受新的延迟水平
+			log.Printf("探测对延迟异常持续 %v，重新学习基线: %s %s -> %s, 延迟=%v",
+				now.Sub(state.since), testType, sourceIP, result.TargetIP, latency)
+			*state = pairState{latest: latency, updatedAt: now, recent: state.recent[:0]}
+			d.learn(state, latency)
+			continue
+		}
+
+		if state.consecutive >= d.config.Consecutive {
+			log.Printf("探测对延迟恢复: %s %s -> %s, 延迟=%v", testType, sourceIP, result.TargetIP, latency)
+		}
+		state.consecutive = 0
+		state.since = time.Time{}
+		d.learn(state, latency)
+	}
+}
+
+// replace 删除同一源与目标IP下其他目标身份的基线（调用者需持有锁）
+// 目标IP已属于新的Pod或节点，原身份的延迟不再代表该探测对
+func (d *detectorImpl) replace(key pairKey) {
+	for existing := range d.pairs {
+		if existing.testType == key.testType && existing.sourceIP == key.sourceIP && existing.targetIP == key.targetIP {
+			delete(d.pairs, existing)
+		}
+	}
+}
+
+// targetIdentity 返回测试结果中标注的目标身份，与结果管理器保存结果使用的身份一致：
+// Pod优先使用UID，宿主机使用节点名称
+func targetIdentity(testType string, target *models.Target) string {
+	if target == nil {
+		return ""
+	}
+	if testType == models.TestTypeHost {
+		return target.NodeName
+	}
+	if target.PodUID != "" {
+		return target.PodUID
+	}
+	return target.PodName
+}
+
+// anomalous 判断样本是否明显高于基线，基线样本不足时不判断
+func (d *detectorImpl) anomalous(state *pairState, latency time.Duration) bool {
+	if state.samples < d.config.MinSamples {
+		return false
+	}
+	value := float64(latency)
+	return value >= state.ewma*d.config.Ratio && value-state.ewma >= float64(d.config.MinDelta)
+}
+
+// learn 将样本计入基线
+func (d *detectorImpl) learn(state *pairState, latency time.Duration) {
+	if state.samples == 0 {
+		state.ewma = float64(latency)
+	} else {
+		state.ewma = d.config.Alpha*float64(latency) + (1-d.config.Alpha)*state.ewma
+	}
+	state.samples++
+
+	if len(state.recent) < d.config.Window {
+		state.recent = append(state.recent, latency)
+	} else {
+		state.recent[state.next] = latency
+	}
+	state.next = (state.next + 1) % d.config.Window
+}
+
+// Baselines 返回所有探测对的基线
+func (d *detectorImpl) Baselines() []models.PairLatency {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune()
+	baselines := make([]models.PairLatency, 0, len(d.pairs))
+	for _, key := range d.sortedKeys() {
+		state := d.pairs[key]
+		baselines = append(baselines, models.PairLatency{
+			TestType:  key.testType,
+			SourceIP:  key.sourceIP,
+			TargetIP:  key.targetIP,
+			Latency:   models.Duration(state.latest),
+			Baseline:  state.baseline(),
+			UpdatedAt: state.updatedAt,
+		})
+	}
+	return baselines
+}
+
+// Anomalies 返回当前延迟异常的探测对
+func (d *detectorImpl) Anomalies() []models.LatencyAnomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune()
+	anomalies := make([]models.LatencyAnomaly, 0)
+	for _, key := range d.sortedKeys() {
+		state := d.pairs[key]
+		if state.consecutive < d.config.Consecutive {
+			continue
+		}
+		anomalies = append(anomalies, models.LatencyAnomaly{
+			TestType:    key.testType,
+			SourceIP:    key.sourceIP,
+			TargetIP:    key.targetIP,
+			Latency:     models.Duration(state.latest),
+			Baseline:    state.baseline(),
+			Ratio:       float64(state.latest) / state.ewma,
+			Consecutive: state.consecutive,
+			Since:       state.since,
+		})
+	}
+	return anomalies
+}
+
+// Enrich 在报告中加入延迟异常
+func (d *detectorImpl) Enrich(report *models.NetworkReport) {
+	report.LatencyAnomalies = d.Anomalies()
+}
+
+// prune 删除长时间没有新样本的探测对（调用者需持有锁）
+func (d *detectorImpl) prune() {
+	now := d.now()
+	for key, state := range d.pairs {
+		if now.Sub(state.updatedAt) > pairTTL {
+			delete(d.pairs, key)
+		}
+	}
+}
+
+// sortedKeys 返回按类型、源IP、目标IP排序的探测对（调用者需持有锁）
+func (d *detectorImpl) sortedKeys() []pairKey {
+	keys := make([]pairKey, 0, len(d.pairs))
+	for key := range d.pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.testType != b.testType {
+			return a.testType < b.testType
+		}
+		if a.sourceIP != b.sourceIP {
+			return a.sourceIP < b.sourceIP
+		}
+		if a.targetIP != b.targetIP {
+			return a.targetIP < b.targetIP
+		}
+		return a.target < b.target
+	})
+	return keys
+}
+
+// baseline 返回基线的EWMA与最近样本的分位数
+func (s *pairState) baseline() models.LatencyBaseline {
+	sorted := append([]time.Duration(nil), s.recent...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return models.LatencyBaseline{
+		EWMA:    models.Duration(time.Duration(s.ewma)),
+		P50:     models.Duration(percentile(sorted, 50)),
+		P95:     models.Duration(percentile(sorted, 95)),
+		P99:     models.Duration(percentile(sorted, 99)),
+		Samples: s.samples,
+	}
+}
+
+// percentile 按最近秩法计算已排序样本的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,116 @@
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample 返回一次ping可达的Pod探测结果
+func sample(targetIP string, latency time.Duration) []models.ConnectivityResult {
+	return []models.ConnectivityResult{{TargetIP: targetIP, PingStatus: "reachable", Latency: models.Duration(latency)}}
+}
+
+// TestDetector 测试基线学习、连续异常后报告以及恢复后清除
+func TestDetector(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	detector := NewDetector(Config{}).(*detectorImpl)
+	detector.now = func() time.Time { return now }
+
+	for i := 0; i < 20; i++ {
+		detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 300*time.Microsecond))
+	}
+	// 不可达与自定义服务探测不计入
+	detector.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{{TargetIP: "10.0.0.2", PingStatus: "unreachable"}})
+	detector.OnTestResults(models.TestTypeService, "10.0.0.1", sample("10.96.0.1", 8*time.Millisecond))
+
+	baselines := detector.Baselines()
+	require.Len(t, baselines, 1)
+	assert.Equal(t, 20, baselines[0].Baseline.Samples)
+	assert.Equal(t, models.Duration(300*time.Microsecond), baselines[0].Baseline.EWMA)
+	assert.Equal(t, models.Duration(300*time.Microsecond), baselines[0].Baseline.P99)
+
+	// 未达到最小差值的倍数增长不视为异常
+	detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", time.Millisecond))
+	assert.Empty(t, detector.Anomalies())
+	assert.Equal(t, 21, detector.Baselines()[0].Baseline.Samples)
+
+	start := now
+	for i := 0; i < 3; i++ {
+		assert.Empty(t, detector.Anomalies())
+		detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 8*time.Millisecond))
+		now = now.Add(time.Minute)
+	}
+	anomalies := detector.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, models.Duration(8*time.Millisecond), anomalies[0].Latency)
+	assert.Equal(t, 3, anomalies[0].Consecutive)
+	assert.Equal(t, start, anomalies[0].Since)
+	assert.Greater(t, anomalies[0].Ratio, 3.0)
+	// 异常样本不计入基线
+	assert.Equal(t, 21, anomalies[0].Baseline.Samples)
+
+	report := &models.NetworkReport{}
+	detector.Enrich(report)
+	assert.Len(t, report.LatencyAnomalies, 1)
+
+	// 延迟恢复后清除异常
+	detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 300*time.Microsecond))
+	assert.Empty(t, detector.Anomalies())
+
+	// 长时间没有新样本的探测对被删除
+	now = now.Add(2 * time.Hour)
+	assert.Empty(t, detector.Baselines())
+}
+
+// TestDetectorRebaseline 测试异常持续超过 Rebaseline 后接受新的延迟水平
+func TestDetectorRebaseline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	detector := NewDetector(Config{MinSamples: 5, Rebaseline: 10 * time.Minute}).(*detectorImpl)
+	detector.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 300*time.Microsecond))
+	}
+	for i := 0; i < 10; i++ {
+		detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 8*time.Millisecond))
+		now = now.Add(time.Minute)
+	}
+	require.Len(t, detector.Anomalies(), 1)
+
+	// 异常持续10分钟后重新学习基线
+	detector.OnTestResults(models.TestTypePod, "10.0.0.1", sample("10.0.0.2", 8*time.Millisecond))
+	assert.Empty(t, detector.Anomalies())
+	baselines := detector.Baselines()
+	require.Len(t, baselines, 1)
+	assert.Equal(t, 1, baselines[0].Baseline.Samples)
+	assert.Equal(t, models.Duration(8*time.Millisecond), baselines[0].Baseline.EWMA)
+}
+
+// TestDetectorTargetIdentity 测试目标IP被新的Pod复用时丢弃原基线
+func TestDetectorTargetIdentity(t *testing.T) {
+	detector := NewDetector(Config{MinSamples: 5})
+
+	observe := func(podUID string, latency time.Duration) {
+		results := sample("10.0.0.2", latency)
+		results[0].Target = &models.Target{IP: "10.0.0.2", PodUID: podUID}
+		detector.OnTestResults(models.TestTypePod, "10.0.0.1", results)
+	}
+	for i := 0; i < 5; i++ {
+		observe("uid-a", 300*time.Microsecond)
+	}
+
+	// 新Pod的延迟不与旧Pod的基线比较
+	for i := 0; i < 3; i++ {
+		observe("uid-b", 8*time.Millisecond)
+	}
+	assert.Empty(t, detector.Anomalies())
+	baselines := detector.Baselines()
+	require.Len(t, baselines, 1)
+	assert.Equal(t, 3, baselines[0].Baseline.Samples)
+	assert.Equal(t, models.Duration(8*time.Millisecond), baselines[0].Baseline.EWMA)
+}
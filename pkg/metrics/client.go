@@ -0,0 +1,300 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// 客户端指标命名空间与子系统
+	namespace       = "k8snet_checker"
+	clientSubsystem = "client"
+)
+
+// ClientMetrics 客户端本地指标
+// 记录本客户端最近一轮探测结果、探测耗时、心跳和上报情况，
+// 使 DaemonSet 在服务端不可用时仍可被独立抓取。
+// 所有方法对 nil 接收者安全，未启用指标时可直接传入 nil。
+type ClientMetrics struct {
+	registry *prometheus.Registry
+
+	probeSuccess       *prometheus.GaugeVec
+	probePingReachable *prometheus.GaugeVec
+	probePortOpen      *prometheus.GaugeVec
+	probeLatency       *prometheus.GaugeVec
+	probeDuration      *prometheus.HistogramVec
+	lastRunTimestamp   *prometheus.GaugeVec
+	lastRunTargets     *prometheus.GaugeVec
+	heartbeatsTotal    *prometheus.CounterVec
+	uploadErrorsTotal  *prometheus.CounterVec
+	missedCycles       *prometheus.CounterVec
+	overrunCycles      *prometheus.CounterVec
+	outboxDepth        prometheus.Gauge
+	outboxDropped      *prometheus.CounterVec
+	outboxReplayed     *prometheus.CounterVec
+	serverReachable    prometheus.Gauge
+	heartbeatFailures  prometheus.Gauge
+	heartbeatInterval  prometheus.Gauge
+}
+
+// NewClientMetrics 创建客户端指标实例，使用独立的 Registry
+func NewClientMetrics() *ClientMetrics {
+	m := &ClientMetrics{
+		registry: prometheus.NewRegistry(),
+		probeSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "probe_success",
+			Help:      "最近一轮探测是否成功（ping 可达且端口开放为 1）",
+		}, []string{"type", "target"}),
+		probePingReachable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "probe_ping_reachable",
+			Help:      "最近一轮 ping 是否可达",
+		}, []string{"type", "target"}),
+		probePortOpen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "probe_port_open",
+			Help:      "最近一轮端口是否开放",
+		}, []string{"type", "target", "port"}),
+		probeLatency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "probe_latency_seconds",
+			Help:      "最近一轮 ping 平均延迟（秒）",
+		}, []string{"type", "target"}),
+		probeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "probe_duration_seconds",
+			Help:      "单个目标探测耗时（秒）",
+			Buckets:   []float64{0.005, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 15},
+		}, []string{"type"}),
+		lastRunTimestamp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "last_run_timestamp_seconds",
+			Help:      "最近一轮探测完成的 Unix 时间戳",
+		}, []string{"type"}),
+		lastRunTargets: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "last_run_targets",
+			Help:      "最近一轮探测的目标数量",
+		}, []string{"type"}),
+		heartbeatsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "heartbeats_total",
+			Help:      "心跳发送次数，按结果（success/failure）区分",
+		}, []string{"result"}),
+		uploadErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "report_upload_errors_total",
+			Help:      "测试结果上报失败次数",
+		}, []string{"type"}),
+		missedCycles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "scheduler_missed_cycles_total",
+			Help:      "因上一轮测试尚未完成而跳过的调度周期数",
+		}, []string{"type"}),
+		overrunCycles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "scheduler_overrun_cycles_total",
+			Help:      "耗时超过测试间隔的测试轮次数",
+		}, []string{"type"}),
+		outboxDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "outbox_batches",
+			Help:      "等待重新上报的测试结果批次数",
+		}),
+		outboxDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "outbox_dropped_batches_total",
+			Help:      "未能上报而丢弃的测试结果批次数，reason 为 full（队列已满）、rejected（服务器拒绝）或 error（写入队列失败）",
+		}, []string{"reason"}),
+		outboxReplayed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "outbox_replayed_batches_total",
+			Help:      "服务器恢复后重新上报成功的测试结果批次数",
+		}, []string{"type"}),
+		serverReachable: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "server_reachable",
+			Help:      "最近一次心跳是否到达服务器",
+		}),
+		heartbeatFailures: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "heartbeat_consecutive_failures",
+			Help:      "连续心跳失败次数",
+		}),
+		heartbeatInterval: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: clientSubsystem,
+			Name:      "heartbeat_interval_seconds",
+			Help:      "下一次心跳的等待时间，服务器不可达时包含退避",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.probeSuccess,
+		m.probePingReachable,
+		m.probePortOpen,
+		m.probeLatency,
+		m.probeDuration,
+		m.lastRunTimestamp,
+		m.lastRunTargets,
+		m.heartbeatsTotal,
+		m.uploadErrorsTotal,
+		m.missedCycles,
+		m.overrunCycles,
+		m.outboxDepth,
+		m.outboxDropped,
+		m.outboxReplayed,
+		m.serverReachable,
+		m.heartbeatFailures,
+		m.heartbeatInterval,
+	)
+
+	return m
+}
+
+// Registry 返回底层 Registry，便于其他组件注册额外指标
+func (m *ClientMetrics) Registry() *prometheus.Registry {
+	if m == nil {
+		return nil
+	}
+	return m.registry
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器
+func (m *ClientMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveProbeResults 记录一轮探测结果
+// 同一类型上一轮的目标会被清除，避免已下线目标残留
+func (m *ClientMetrics) ObserveProbeResults(testType string, results []models.ConnectivityResult) {
+	if m == nil {
+		return
+	}
+
+	typeLabel := prometheus.Labels{"type": testType}
+	m.probeSuccess.DeletePartialMatch(typeLabel)
+	m.probePingReachable.DeletePartialMatch(typeLabel)
+	m.probePortOpen.DeletePartialMatch(typeLabel)
+	m.probeLatency.DeletePartialMatch(typeLabel)
+
+	for _, result := range results {
+		reachable := result.PingStatus == "reachable"
+		for port, status := range result.PortStatus {
+			open := status == "open"
+			m.probePortOpen.WithLabelValues(testType, result.TargetIP, strconv.Itoa(port)).Set(boolToFloat(open))
+		}
+
+		m.probeSuccess.WithLabelValues(testType, result.TargetIP).Set(boolToFloat(result.Succeeded(testType)))
+		m.probePingReachable.WithLabelValues(testType, result.TargetIP).Set(boolToFloat(reachable))
+		m.probeLatency.WithLabelValues(testType, result.TargetIP).Set(time.Duration(result.Latency).Seconds())
+		m.probeDuration.WithLabelValues(testType).Observe(time.Duration(result.TestDuration).Seconds())
+	}
+
+	m.lastRunTargets.WithLabelValues(testType).Set(float64(len(results)))
+	m.lastRunTimestamp.WithLabelValues(testType).SetToCurrentTime()
+}
+
+// ObserveHeartbeat 记录一次心跳发送结果
+func (m *ClientMetrics) ObserveHeartbeat(err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.heartbeatsTotal.WithLabelValues("failure").Inc()
+		return
+	}
+	m.heartbeatsTotal.WithLabelValues("success").Inc()
+}
+
+// ObserveReportUploadError 记录一次测试结果上报失败
+func (m *ClientMetrics) ObserveReportUploadError(testType string) {
+	if m == nil {
+		return
+	}
+	m.uploadErrorsTotal.WithLabelValues(testType).Inc()
+}
+
+// ObserveMissedCycle 记录一次因上一轮测试未完成而跳过的调度周期
+func (m *ClientMetrics) ObserveMissedCycle(testType string) {
+	if m == nil {
+		return
+	}
+	m.missedCycles.WithLabelValues(testType).Inc()
+}
+
+// ObserveOverrunCycle 记录一次耗时超过测试间隔的测试轮次
+func (m *ClientMetrics) ObserveOverrunCycle(testType string) {
+	if m == nil {
+		return
+	}
+	m.overrunCycles.WithLabelValues(testType).Inc()
+}
+
+// ObserveOutboxDepth 记录等待重新上报的批次数
+func (m *ClientMetrics) ObserveOutboxDepth(depth int) {
+	if m == nil {
+		return
+	}
+	m.outboxDepth.Set(float64(depth))
+}
+
+// ObserveOutboxDropped 记录丢弃的批次数
+func (m *ClientMetrics) ObserveOutboxDropped(reason string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.outboxDropped.WithLabelValues(reason).Add(float64(count))
+}
+
+// ObserveOutboxReplayed 记录一次重新上报成功的批次
+func (m *ClientMetrics) ObserveOutboxReplayed(testType string) {
+	if m == nil {
+		return
+	}
+	m.outboxReplayed.WithLabelValues(testType).Inc()
+}
+
+// ObserveServerStatus 记录与服务器的连接状态
+func (m *ClientMetrics) ObserveServerStatus(status models.ServerStatus) {
+	if m == nil {
+		return
+	}
+	m.serverReachable.Set(boolToFloat(status.Reachable))
+	m.heartbeatFailures.Set(float64(status.ConsecutiveFailures))
+	m.heartbeatInterval.Set(time.Duration(status.HeartbeatInterval).Seconds())
+}
+
+// boolToFloat 将布尔值转换为指标值
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
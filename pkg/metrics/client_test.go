@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClientMetrics_ObserveProbeResults 测试探测结果指标
+func TestClientMetrics_ObserveProbeResults(t *testing.T) {
+	m := NewClientMetrics()
+
+	m.ObserveProbeResults(models.TestTypeHost, []models.ConnectivityResult{
+		{
+			TargetIP:     "192.168.1.2",
+			PingStatus:   "reachable",
+			PortStatus:   map[int]string{22: "open"},
+			Latency:      models.Duration(2 * time.Millisecond),
+			TestDuration: models.Duration(10 * time.Millisecond),
+		},
+		{
+			TargetIP:     "192.168.1.3",
+			PingStatus:   "unreachable",
+			PortStatus:   map[int]string{22: "closed"},
+			TestDuration: models.Duration(5 * time.Second),
+		},
+	})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.probeSuccess.WithLabelValues("host", "192.168.1.2")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.probeSuccess.WithLabelValues("host", "192.168.1.3")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.probePortOpen.WithLabelValues("host", "192.168.1.3", "22")))
+	assert.Equal(t, 0.002, testutil.ToFloat64(m.probeLatency.WithLabelValues("host", "192.168.1.2")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.lastRunTargets.WithLabelValues("host")))
+
+	// 新一轮结果应清除已下线的目标
+	m.ObserveProbeResults(models.TestTypeHost, []models.ConnectivityResult{
+		{
+			TargetIP:   "192.168.1.2",
+			PingStatus: "reachable",
+			PortStatus: map[int]string{22: "open"},
+		},
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(m.probeSuccess))
+}
+
+// TestClientMetrics_Counters 测试心跳与上报失败计数
+func TestClientMetrics_Counters(t *testing.T) {
+	m := NewClientMetrics()
+
+	m.ObserveHeartbeat(nil)
+	m.ObserveHeartbeat(nil)
+	m.ObserveHeartbeat(errors.New("连接失败"))
+	m.ObserveReportUploadError(models.TestTypePod)
+	m.ObserveMissedCycle(models.TestTypeHost)
+	m.ObserveOverrunCycle(models.TestTypeHost)
+	m.ObserveOverrunCycle(models.TestTypeHost)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.heartbeatsTotal.WithLabelValues("success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.heartbeatsTotal.WithLabelValues("failure")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.uploadErrorsTotal.WithLabelValues("pod")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.missedCycles.WithLabelValues("host")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.overrunCycles.WithLabelValues("host")))
+}
+
+// TestClientMetrics_NilSafe 测试 nil 接收者不会 panic
+func TestClientMetrics_NilSafe(t *testing.T) {
+	var m *ClientMetrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveHeartbeat(nil)
+		m.ObserveReportUploadError(models.TestTypeHost)
+		m.ObserveProbeResults(models.TestTypeHost, nil)
+		m.ObserveMissedCycle(models.TestTypeHost)
+		m.ObserveOverrunCycle(models.TestTypeHost)
+	})
+}
+
+// TestClientMetrics_Handler 测试 /metrics 输出
+func TestClientMetrics_Handler(t *testing.T) {
+	m := NewClientMetrics()
+	m.ObserveHeartbeat(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	m.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "k8snet_checker_client_heartbeats_total"))
+}
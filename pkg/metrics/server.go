@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// serverSubsystem 服务器指标子系统
+const serverSubsystem = "server"
+
+// SLOSource 提供可用性统计，每次抓取指标时读取
+type SLOSource interface {
+	Snapshot(includePairs bool) *models.SLOReport
+}
+
+// ServerMetrics 服务器指标
+// 导出集群与节点在各滚动窗口的可用性、错误预算消耗速率和剩余错误预算；
+// 探测对数量随节点数平方增长，探测对的可用性只通过SLO接口提供
+type ServerMetrics struct {
+	registry *prometheus.Registry
+	slo      SLOSource
+
+	mu                 sync.Mutex // 抓取时先清空再写入，避免并发抓取交错
+	sloObjective       prometheus.Gauge
+	sloAvailability    *prometheus.GaugeVec
+	sloBurnRate        *prometheus.GaugeVec
+	sloBudgetRemaining *prometheus.GaugeVec
+	sloProbes          *prometheus.GaugeVec
+	sloProbesGood      *prometheus.GaugeVec
+}
+
+// NewServerMetrics 创建服务器指标实例，使用独立的 Registry
+func NewServerMetrics(slo SLOSource) *ServerMetrics {
+	scopeLabels := []string{"scope", "node", "window"}
+	m := &ServerMetrics{
+		registry: prometheus.NewRegistry(),
+		slo:      slo,
+		sloObjective: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_objective_percent",
+			Help:      "可用性目标（百分比）",
+		}),
+		sloAvailability: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_availability_percent",
+			Help:      "滚动窗口内的可用性（百分比），scope 为 cluster、source（节点发起的探测）或 target（探测该节点）",
+		}, scopeLabels),
+		sloBurnRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_burn_rate",
+			Help:      "滚动窗口内的错误预算消耗速率，1表示恰好在30d窗口结束时耗尽预算",
+		}, scopeLabels),
+		sloBudgetRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_error_budget_remaining_percent",
+			Help:      "30d窗口剩余的错误预算（百分比），超支时为负数",
+		}, []string{"scope", "node"}),
+		sloProbes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_probes",
+			Help:      "滚动窗口内的探测次数",
+		}, scopeLabels),
+		sloProbesGood: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: serverSubsystem,
+			Name:      "slo_probes_good",
+			Help:      "滚动窗口内成功的探测次数",
+		}, scopeLabels),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.sloObjective,
+		m.sloAvailability,
+		m.sloBurnRate,
+		m.sloBudgetRemaining,
+		m.sloProbes,
+		m.sloProbesGood,
+	)
+
+	return m
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器，每次抓取前读取最新的可用性统计
+func (m *ServerMetrics) Handler() http.Handler {
+	handler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.refresh()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// refresh 用当前的可用性统计替换SLO指标，已没有历史的节点不再导出（调用者需持有锁）
+func (m *ServerMetrics) refresh() {
+	if m.slo == nil {
+		return
+	}
+	report := m.slo.Snapshot(false)
+
+	m.sloAvailability.Reset()
+	m.sloBurnRate.Reset()
+	m.sloBudgetRemaining.Reset()
+	m.sloProbes.Reset()
+	m.sloProbesGood.Reset()
+
+	m.sloObjective.Set(report.Objective)
+	m.observeSLO("cluster", "", report.Cluster)
+	for _, node := range report.Nodes {
+		m.observeSLO("source", node.Node, node.AsSource)
+		m.observeSLO("target", node.Node, node.AsTarget)
+	}
+}
+
+// observeSLO 记录一个统计对象的可用性
+func (m *ServerMetrics) observeSLO(scope, node string, status models.SLOStatus) {
+	for _, w := range status.Windows {
+		m.sloAvailability.WithLabelValues(scope, node, w.Window).Set(w.Availability)
+		m.sloBurnRate.WithLabelValues(scope, node, w.Window).Set(w.BurnRate)
+		m.sloProbes.WithLabelValues(scope, node, w.Window).Set(float64(w.Total))
+		m.sloProbesGood.WithLabelValues(scope, node, w.Window).Set(float64(w.Good))
+	}
+	m.sloBudgetRemaining.WithLabelValues(scope, node).Set(status.ErrorBudgetRemaining)
+}
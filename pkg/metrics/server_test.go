@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSLOSource 返回固定的可用性统计
+type fakeSLOSource struct {
+	report *models.SLOReport
+}
+
+func (f *fakeSLOSource) Snapshot(includePairs bool) *models.SLOReport {
+	return f.report
+}
+
+// sloStatus 返回各窗口可用性相同的统计
+func sloStatus(availability, burnRate float64) models.SLOStatus {
+	status := models.SLOStatus{ErrorBudgetRemaining: (1 - burnRate) * 100}
+	for _, window := range []string{models.SLOWindowHour, models.SLOWindowDay, models.SLOWindowMonth} {
+		status.Windows = append(status.Windows, models.SLOWindow{Window: window, Total: 10, Good: 10, Availability: availability, BurnRate: burnRate})
+	}
+	return status
+}
+
+// TestServerMetrics_SLO 测试每次抓取时按当前统计导出SLO指标
+func TestServerMetrics_SLO(t *testing.T) {
+	source := &fakeSLOSource{report: &models.SLOReport{
+		Objective: 99.9,
+		Cluster:   sloStatus(99.5, 5),
+		Nodes: []models.NodeSLO{
+			{Node: "node-1", AsSource: sloStatus(100, 0), AsTarget: sloStatus(99, 10)},
+			{Node: "node-2", AsSource: sloStatus(99, 10), AsTarget: sloStatus(100, 0)},
+		},
+	}}
+	m := NewServerMetrics(source)
+
+	scrape := func() {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	scrape()
+	assert.Equal(t, 99.9, testutil.ToFloat64(m.sloObjective))
+	assert.Equal(t, 99.5, testutil.ToFloat64(m.sloAvailability.WithLabelValues("cluster", "", models.SLOWindowMonth)))
+	assert.Equal(t, float64(10), testutil.ToFloat64(m.sloBurnRate.WithLabelValues("target", "node-1", models.SLOWindowHour)))
+	assert.Equal(t, float64(-900), testutil.ToFloat64(m.sloBudgetRemaining.WithLabelValues("source", "node-2")))
+	assert.Equal(t, 15, testutil.CollectAndCount(m.sloAvailability))
+
+	// 节点没有历史后不再导出
+	source.report.Nodes = source.report.Nodes[:1]
+	scrape()
+	assert.Equal(t, 9, testutil.CollectAndCount(m.sloAvailability))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.sloBudgetRemaining))
+}
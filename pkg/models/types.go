@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -36,6 +37,11 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
+// MarshalYAML 实现 YAML 序列化，与 JSON 保持一致
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
 // String 返回人性化的时间字符串
 func (d Duration) String() string {
 	duration := time.Duration(d)
@@ -64,6 +70,13 @@ func (d Duration) String() string {
 	return duration.String()
 }
 
+// 测试类型常量
+const (
+	TestTypeHost    = "host"
+	TestTypePod     = "pod"
+	TestTypeService = "service"
+)
+
 // NodeInfo represents the information about a Kubernetes node and pod
 type NodeInfo struct {
 	Namespace string    `json:"namespace"`
@@ -71,6 +84,197 @@ type NodeInfo struct {
 	PodIP     string    `json:"pod_ip"`
 	PodName   string    `json:"pod_name"`
 	Timestamp time.Time `json:"timestamp"`
+
+	ProbeConfigVersion int64             `json:"probe_config_version,omitempty"` // 客户端当前使用的探测配置版本
+	Zone               string            `json:"zone,omitempty"`                 // 节点所在可用区，用于目标分片时选择跨可用区的探测对象
+	NodeName           string            `json:"node_name,omitempty"`            // 节点名称，随目标列表下发给其他客户端
+	Labels             map[string]string `json:"labels,omitempty"`               // Pod标签，随目标列表下发，用于标注测试结果
+	PodUID             string            `json:"pod_uid,omitempty"`              // Pod UID，IP被新Pod复用时据此区分测试结果
+}
+
+// Target 探测目标，目标列表接口返回，客户端据此探测并在测试结果中标注目标身份
+type Target struct {
+	IP       string            `json:"ip"`
+	PodName  string            `json:"pod_name,omitempty"`  // 宿主机目标为空
+	PodUID   string            `json:"pod_uid,omitempty"`   // 宿主机目标为空
+	NodeName string            `json:"node_name,omitempty"` // 目标所在节点名称
+	NodeIP   string            `json:"node_ip,omitempty"`   // 目标所在节点IP
+	Zone     string            `json:"zone,omitempty"`      // 目标所在可用区
+	Ports    []int             `json:"ports,omitempty"`     // 需要探测的端口，为空时使用客户端的探测配置
+	Labels   map[string]string `json:"labels,omitempty"`    // 目标Pod的标签
+}
+
+// Identity 返回用于标注测试结果的目标身份（不含探测端口），只有IP时返回nil
+func (t Target) Identity() *Target {
+	if t.PodName == "" && t.PodUID == "" && t.NodeName == "" && t.NodeIP == "" && t.Zone == "" && len(t.Labels) == 0 {
+		return nil
+	}
+	t.Ports = nil
+	return &t
+}
+
+// TargetsFromIPs 将IP列表转换为只包含IP的探测目标，用于不支持目标对象的服务器
+func TargetsFromIPs(ips []string) []Target {
+	targets := make([]Target, 0, len(ips))
+	for _, ip := range ips {
+		targets = append(targets, Target{IP: ip})
+	}
+	return targets
+}
+
+// TargetIPs 返回探测目标的IP列表
+func TargetIPs(targets []Target) []string {
+	ips := make([]string, 0, len(targets))
+	for _, target := range targets {
+		ips = append(ips, target.IP)
+	}
+	return ips
+}
+
+// TargetShard 描述目标分片时客户端本轮分到的目标范围
+type TargetShard struct {
+	Cycle  int64 `json:"cycle"`  // 该客户端此测试类型的轮次
+	Index  int   `json:"index"`  // 本轮使用的分片序号
+	Count  int   `json:"count"`  // 分片数，即覆盖全部目标所需的轮数
+	Total  int   `json:"total"`  // 候选目标总数
+	Pinned int   `json:"pinned"` // 每轮固定测试的同节点与跨可用区目标数
+}
+
+// HeartbeatResponse 心跳响应，客户端的探测配置版本与服务器不一致时携带服务器的配置，
+// 配置版本为0表示服务器未设置配置，客户端恢复初始配置
+type HeartbeatResponse struct {
+	Status      string       `json:"status"`
+	Message     string       `json:"message"`
+	ProbeConfig *ProbeConfig `json:"probe_config,omitempty"`
+
+	HeartbeatInterval Duration `json:"heartbeat_interval,omitempty"` // 服务器建议的心跳间隔，为0时客户端使用本地配置
+}
+
+// SyncVersion 当前同步协议版本，协议不兼容地变更时递增
+const SyncVersion = 1
+
+// SyncRequest 客户端同步请求，一次请求完成心跳、测试结果上报与目标列表获取
+type SyncRequest struct {
+	Version   int           `json:"version"`             // 同步协议版本
+	Heartbeat NodeInfo      `json:"heartbeat"`           // 心跳携带的节点信息
+	SourceIP  string        `json:"source_ip,omitempty"` // 测试结果的源IP
+	Results   []SyncResults `json:"results,omitempty"`   // 上次同步后产生的测试结果
+	Targets   []string      `json:"targets,omitempty"`   // 需要获取目标列表的测试类型，取值为 host 或 pod
+}
+
+// SyncResults 同步请求中的一批测试结果
+type SyncResults struct {
+	TestType string               `json:"test_type"`
+	Results  []ConnectivityResult `json:"results"`
+}
+
+// SyncResultStatus 同步请求中一批测试结果的处理结果
+type SyncResultStatus struct {
+	Status  int    `json:"status"`            // 与单独上报接口一致的HTTP状态码，200表示已保存
+	Code    string `json:"code,omitempty"`    // 失败时的错误码
+	Message string `json:"message,omitempty"` // 失败原因
+}
+
+// SyncTargets 同步响应中一种测试类型的目标列表
+type SyncTargets struct {
+	IPs     []string     `json:"ips"`
+	Targets []Target     `json:"targets,omitempty"` // 与 IPs 一一对应的目标对象
+	Shard   *TargetShard `json:"shard,omitempty"`   // 启用目标分片时本轮分到的范围
+}
+
+// SyncResponse 同步响应，在心跳响应的基础上携带测试结果的处理结果、目标列表与待执行的按需测试任务
+type SyncResponse struct {
+	HeartbeatResponse
+	Version int                    `json:"version"`
+	Results []SyncResultStatus     `json:"results,omitempty"` // 与请求中的测试结果按顺序一一对应
+	Targets map[string]SyncTargets `json:"targets,omitempty"` // 按测试类型索引，只包含请求的类型
+	Runs    []RunRequest           `json:"runs,omitempty"`    // 下发给该客户端的按需测试任务
+}
+
+// ServerStatus 客户端视角的服务器连接状态
+type ServerStatus struct {
+	Reachable           bool      `json:"reachable"`             // 最近一次心跳是否到达服务器
+	ConsecutiveFailures int       `json:"consecutive_failures"`  // 连续心跳失败次数
+	LastSuccess         time.Time `json:"last_success,omitzero"` // 最近一次心跳成功的时间
+	LastError           string    `json:"last_error,omitempty"`  // 最近一次心跳失败的原因
+	HeartbeatInterval   Duration  `json:"heartbeat_interval"`    // 下一次心跳的等待时间，服务器不可达时包含退避
+}
+
+// 探测协议
+const (
+	ProtocolICMP = "icmp"
+	ProtocolTCP  = "tcp"
+)
+
+// PingSkipped 探测配置未启用ICMP时的ping状态
+const PingSkipped = "skipped"
+
+// ProbeConfig 由服务器统一管理的探测配置，客户端通过心跳获取并实时生效
+type ProbeConfig struct {
+	Version        int64     `json:"version"`
+	TestInterval   Duration  `json:"test_interval"`   // 定期测试间隔
+	TestTypes      []string  `json:"test_types"`      // 启用的测试类型: host、pod、service
+	Protocols      []string  `json:"protocols"`       // 启用的探测协议: icmp、tcp
+	HostPorts      []int     `json:"host_ports"`      // 宿主机探测端口
+	PodPorts       []int     `json:"pod_ports"`       // Pod探测端口
+	ServiceName    string    `json:"service_name"`    // 自定义服务名称，为空时不测试
+	ServicePorts   []int     `json:"service_ports"`   // 自定义服务探测端口
+	MaxConcurrency int       `json:"max_concurrency"` // 最大并发探测数
+	PingCount      int       `json:"ping_count"`      // 每次ping的次数
+	Timeout        Duration  `json:"timeout"`         // 端口探测超时时间
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// 调度配置，各测试类型的间隔为0时使用 TestInterval
+	HostInterval    Duration `json:"host_interval,omitempty"`    // 宿主机测试间隔
+	PodInterval     Duration `json:"pod_interval,omitempty"`     // Pod测试间隔
+	ServiceInterval Duration `json:"service_interval,omitempty"` // 自定义服务测试间隔
+	PingInterval    Duration `json:"ping_interval,omitempty"`    // ping 的最小执行间隔，期间只执行端口测试，为0时每轮都执行
+	InitialDelay    Duration `json:"initial_delay,omitempty"`    // 启动后首轮测试前的等待时间
+	Jitter          Duration `json:"jitter,omitempty"`           // 每轮测试随机增加的最大延迟，避免客户端同时探测
+
+	// 所有测试类型共享 MaxConcurrency 个并发探测，一轮探测需在该类型的测试间隔内开始
+	RateLimit float64 `json:"rate_limit,omitempty"` // 每秒最多发起的探测数，为0时不限制
+
+	// 各类探测的超时时间，端口探测使用 Timeout
+	PingTimeout Duration `json:"ping_timeout,omitempty"` // 一次 ping 测试（全部次数）的超时时间
+	DNSTimeout  Duration `json:"dns_timeout,omitempty"`  // 自定义服务 DNS 解析的超时时间
+}
+
+// IntervalFor 返回指定测试类型的测试间隔
+func (c *ProbeConfig) IntervalFor(testType string) time.Duration {
+	var interval Duration
+	switch testType {
+	case TestTypeHost:
+		interval = c.HostInterval
+	case TestTypePod:
+		interval = c.PodInterval
+	case TestTypeService:
+		interval = c.ServiceInterval
+	}
+	if interval <= 0 {
+		interval = c.TestInterval
+	}
+	return time.Duration(interval)
+}
+
+// HasProtocol 判断是否启用了指定协议
+func (c *ProbeConfig) HasProtocol(protocol string) bool {
+	for _, p := range c.Protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTestType 判断是否启用了指定测试类型
+func (c *ProbeConfig) HasTestType(testType string) bool {
+	for _, t := range c.TestTypes {
+		if t == testType {
+			return true
+		}
+	}
+	return false
 }
 
 // ConnectivityResult represents the result of a network connectivity test
@@ -82,6 +286,45 @@ type ConnectivityResult struct {
 	Latency      Duration       `json:"latency"`       // ping 延迟
 	TestDuration Duration       `json:"test_duration"` // 整个测试耗时
 	Timestamp    time.Time      `json:"timestamp"`
+	RunID        string         `json:"run_id,omitempty"` // 按需测试任务ID，定期测试为空
+
+	ConfigVersion int64   `json:"config_version,omitempty"` // 执行测试时使用的探测配置版本
+	Target        *Target `json:"target,omitempty"`         // 目标身份（Pod、节点、可用区与标签），不含探测端口
+}
+
+// Succeeded 判断单次探测是否成功，与报告统计口径一致：
+// 宿主机和Pod要求ping可达且端口开放，自定义服务仅要求ping可达；
+// 探测配置未启用ICMP时只看端口
+func (r *ConnectivityResult) Succeeded(testType string) bool {
+	switch r.PingStatus {
+	case "reachable":
+		if testType == TestTypeService {
+			return true
+		}
+	case PingSkipped:
+	default:
+		return false
+	}
+	for _, status := range r.PortStatus {
+		if status != "open" {
+			return false
+		}
+	}
+	return true
+}
+
+// PortSummary 汇总多个端口的探测状态：任一端口关闭为 closed，全部开放为 open，
+// 未探测端口（未启用TCP）为 skipped
+func (r *ConnectivityResult) PortSummary() string {
+	if len(r.PortStatus) == 0 {
+		return PingSkipped
+	}
+	for _, status := range r.PortStatus {
+		if status != "open" {
+			return status
+		}
+	}
+	return "open"
 }
 
 // ClientRecord represents a client's registration record in the server cache
@@ -99,17 +342,74 @@ type VersionInfo struct {
 
 // TestStatus represents the status of a connectivity test
 type TestStatus struct {
-	Ping         string   `json:"ping"`          // "reachable" or "unreachable"
-	PortStatus   string   `json:"port_status"`   // "open" or "closed"
-	TestDuration Duration `json:"test_duration"` // 测试耗时
+	Ping         string   `json:"ping"`              // "reachable" or "unreachable"
+	PortStatus   string   `json:"port_status"`       // "open" or "closed"
+	TestDuration Duration `json:"test_duration"`     // 测试耗时
+	Latency      Duration `json:"latency,omitempty"` // ping 延迟，未执行 ping 或不可达时为0
+
+	UpdatedAt time.Time `json:"updated_at,omitzero"` // 客户端采集该结果的时间，重放的结果保留原采集时间
+
+	Source *Endpoint `json:"source,omitempty"` // 上报结果的源Pod身份
+	Target *Endpoint `json:"target,omitempty"` // 被探测目标的身份
+
+	Health *PairHealth `json:"health,omitempty"` // 探测对在多次测试中的有效状态
+}
+
+// 探测对的有效状态
+const (
+	PairStateUp        = "up"        // 正常，偶发失败未达到阈值时仍为 up
+	PairStateDown      = "down"      // 连续失败达到阈值
+	PairStateFlapping  = "flapping"  // 窗口内结果频繁在成功与失败之间切换
+	PairStateRecovered = "recovered" // 从 down 或 flapping 恢复后的一段时间内
+)
+
+// PairHealth 探测对在多次测试中的状态，服务器按滞后阈值判断有效状态：
+// 偶发的单次失败不会使探测对进入 down，恢复也需要连续成功
+type PairHealth struct {
+	State                string    `json:"state"`
+	ConsecutiveFailures  int       `json:"consecutive_failures"`
+	ConsecutiveSuccesses int       `json:"consecutive_successes"`
+	FlapCount            int       `json:"flap_count"`               // 抖动窗口内结果在成功与失败之间切换的次数
+	FirstFailedAt        time.Time `json:"first_failed_at,omitzero"` // 当前连续失败中第一次失败的时间
+	DownSince            time.Time `json:"down_since,omitzero"`      // 处于 down 或 flapping 时本次故障开始的时间
+	RecoveredAt          time.Time `json:"recovered_at,omitzero"`    // 最近一次从故障恢复的时间
+
+	Flips []time.Time `json:"-"` // 抖动窗口内结果切换的时间
+}
+
+// Failing 判断探测对的有效状态是否为故障（down 或 flapping）
+func (h *PairHealth) Failing() bool {
+	return h.State == PairStateDown || h.State == PairStateFlapping
+}
+
+// Endpoint 测试结果中源或目标的身份，服务器按 (Name, IP, Generation) 保存测试结果
+// 同一IP先后属于不同的Pod（或节点）时 Generation 递增，旧身份的结果随之失效
+type Endpoint struct {
+	IP         string `json:"ip"`
+	Name       string `json:"name,omitempty"` // Pod为UID（客户端未上报时为Pod名称），宿主机为节点名称
+	Generation int64  `json:"generation"`     // 该IP上身份的序号，为0时服务器尚未见过使用该IP的客户端
+}
+
+// Key 返回测试结果存储使用的身份键
+func (e Endpoint) Key() string {
+	return e.IP + "/" + e.Name + "/" + strconv.FormatInt(e.Generation, 10)
+}
+
+// Succeeded 判断宿主机或Pod探测是否成功，与 ConnectivityResult.Succeeded 口径一致
+func (s TestStatus) Succeeded() bool {
+	pingOK := s.Ping == "reachable" || s.Ping == PingSkipped
+	portOK := s.PortStatus == "open" || s.PortStatus == PingSkipped
+	return pingOK && portOK
 }
 
 // HostTestResults stores host-to-host connectivity test results
-// Structure: map[sourceIP]map[targetIP]TestStatus
+// Cache structure: map[source Endpoint.Key()]map[target Endpoint.Key()]TestStatus
+// TestResultManager returns the current identities keyed as map[sourceIP]map[targetIP]TestStatus
 type HostTestResults map[string]map[string]TestStatus
 
 // PodTestResults stores pod-to-pod connectivity test results
-// Structure: map[sourcePodIP]map[targetPodIP]TestStatus
+// Cache structure: map[source Endpoint.Key()]map[target Endpoint.Key()]TestStatus
+// TestResultManager returns the current identities keyed as map[sourcePodIP]map[targetPodIP]TestStatus
 type PodTestResults map[string]map[string]TestStatus
 
 // ServiceTestResults stores custom service connectivity test results
@@ -118,6 +418,7 @@ type ServiceTestResults map[string]*ConnectivityResult
 
 // NetworkReport represents a comprehensive network connectivity report
 type NetworkReport struct {
+	ID                 string             `json:"id,omitempty"`
 	Timestamp          time.Time          `json:"timestamp"`
 	ActiveClientCount  int                `json:"active_client_count"`
 	HostIPs            []string           `json:"host_ips"`
@@ -125,6 +426,36 @@ type NetworkReport struct {
 	HostTestSummary    TestSummary        `json:"host_test_summary"`
 	PodTestSummary     TestSummary        `json:"pod_test_summary"`
 	ServiceTestSummary ServiceTestSummary `json:"service_test_summary"`
+	Pairs              []PairResult       `json:"pairs"`
+	SLO                *SLOReport         `json:"slo,omitempty"`               // 集群与节点的可用性统计，不含探测对明细
+	LatencyAnomalies   []LatencyAnomaly   `json:"latency_anomalies,omitempty"` // 延迟明显高于基线的探测对
+}
+
+// PairResult represents the latest test outcome of a single source -> target pair
+type PairResult struct {
+	TestType     string   `json:"test_type"` // "host", "pod" or "service"
+	SourceIP     string   `json:"source_ip"`
+	TargetIP     string   `json:"target_ip"`
+	Ping         string   `json:"ping"`
+	PortStatus   string   `json:"port_status"`
+	TestDuration Duration `json:"test_duration"`
+	Latency      Duration `json:"latency,omitempty"` // 最近一次 ping 延迟，未执行 ping 或不可达时为0
+	Success      bool     `json:"success"`           // 最近一次测试是否成功
+
+	State               string    `json:"state,omitempty"`                // 有效状态: up、down、flapping、recovered
+	DownSince           time.Time `json:"down_since,omitzero"`            // 处于 down 或 flapping 时本次故障开始的时间
+	RecoveredAt         time.Time `json:"recovered_at,omitzero"`          // 处于 recovered 时恢复的时间
+	ConsecutiveFailures int       `json:"consecutive_failures,omitempty"` // 连续失败次数
+	FlapCount           int       `json:"flap_count,omitempty"`           // 抖动窗口内结果切换的次数
+}
+
+// Failing 判断探测对是否处于故障，有效状态为 down 或 flapping 时为故障；
+// 没有状态记录（例如自定义服务探测）时按最近一次测试结果判断
+func (p PairResult) Failing() bool {
+	if p.State != "" {
+		return p.State == PairStateDown || p.State == PairStateFlapping
+	}
+	return !p.Success
 }
 
 // TestSummary provides statistics about connectivity tests
@@ -135,6 +466,10 @@ type TestSummary struct {
 	SuccessRate       float64  `json:"success_rate"`
 	AvgTestDuration   Duration `json:"avg_test_duration"`   // 平均测试耗时
 	TotalTestDuration Duration `json:"total_test_duration"` // 总测试耗时
+
+	DownPairs      int `json:"down_pairs"`      // 处于 down 状态的探测对数量
+	FlappingPairs  int `json:"flapping_pairs"`  // 处于 flapping 状态的探测对数量
+	RecoveredPairs int `json:"recovered_pairs"` // 处于 recovered 状态的探测对数量
 }
 
 // ServiceTestSummary provides statistics about custom service tests
@@ -146,6 +481,124 @@ type ServiceTestSummary struct {
 	SuccessRate     float64 `json:"success_rate"`
 }
 
+// SLO 统计的滚动窗口
+const (
+	SLOWindowHour  = "1h"
+	SLOWindowDay   = "24h"
+	SLOWindowMonth = "30d" // 错误预算按该窗口计算
+)
+
+// SLOWindow 一个滚动窗口内的可用性
+type SLOWindow struct {
+	Window       string  `json:"window"`
+	Total        int64   `json:"total"`        // 窗口内的探测次数
+	Good         int64   `json:"good"`         // 窗口内成功的探测次数
+	Availability float64 `json:"availability"` // 可用性百分比，窗口内没有探测时为100
+	BurnRate     float64 `json:"burn_rate"`    // 错误预算消耗速率，1表示恰好在预算窗口结束时耗尽
+}
+
+// SLOStatus 一个统计对象在各滚动窗口的可用性
+type SLOStatus struct {
+	Windows              []SLOWindow `json:"windows"`
+	ErrorBudgetRemaining float64     `json:"error_budget_remaining"` // 最长窗口（节点与集群为30d，探测对为24h）剩余的错误预算百分比，超支时为负数
+}
+
+// Window 返回指定窗口的可用性，不存在时返回零值
+func (s SLOStatus) Window(window string) SLOWindow {
+	for _, w := range s.Windows {
+		if w.Window == window {
+			return w
+		}
+	}
+	return SLOWindow{Window: window}
+}
+
+// NodeSLO 节点作为探测源与探测目标的可用性
+type NodeSLO struct {
+	Node     string    `json:"node"`      // 节点名称，客户端未上报时为节点IP
+	AsSource SLOStatus `json:"as_source"` // 从该节点发起的探测
+	AsTarget SLOStatus `json:"as_target"` // 探测该节点（宿主机或其上的Pod）
+}
+
+// PairSLO 探测对的可用性
+type PairSLO struct {
+	TestType   string `json:"test_type"` // "host" or "pod"
+	SourceIP   string `json:"source_ip"`
+	TargetIP   string `json:"target_ip"`
+	SourceNode string `json:"source_node,omitempty"`
+	TargetNode string `json:"target_node,omitempty"`
+	SLOStatus
+}
+
+// SLOReport 按滚动窗口统计的探测对、节点与集群可用性
+type SLOReport struct {
+	Timestamp time.Time `json:"timestamp"`
+	Objective float64   `json:"objective"` // 可用性目标百分比，如 99.9
+	Cluster   SLOStatus `json:"cluster"`
+	Nodes     []NodeSLO `json:"nodes"`
+	Pairs     []PairSLO `json:"pairs,omitempty"`
+}
+
+// LatencyBaseline 探测对根据历史延迟学习到的基线
+type LatencyBaseline struct {
+	EWMA    Duration `json:"ewma"` // 指数加权移动平均
+	P50     Duration `json:"p50"`  // 最近样本的延迟分位数
+	P95     Duration `json:"p95"`
+	P99     Duration `json:"p99"`
+	Samples int      `json:"samples"` // 参与基线计算的样本总数
+}
+
+// PairLatency 探测对的最近延迟与基线
+type PairLatency struct {
+	TestType  string          `json:"test_type"` // "host" or "pod"
+	SourceIP  string          `json:"source_ip"`
+	TargetIP  string          `json:"target_ip"`
+	Latency   Duration        `json:"latency"` // 最近一次 ping 延迟
+	Baseline  LatencyBaseline `json:"baseline"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// LatencyAnomaly 延迟连续明显高于基线的探测对
+type LatencyAnomaly struct {
+	TestType    string          `json:"test_type"`
+	SourceIP    string          `json:"source_ip"`
+	TargetIP    string          `json:"target_ip"`
+	Latency     Duration        `json:"latency"`     // 最近一次 ping 延迟
+	Baseline    LatencyBaseline `json:"baseline"`    // 异常开始前的基线，异常期间的样本不计入基线
+	Ratio       float64         `json:"ratio"`       // 最近一次延迟与基线EWMA之比
+	Consecutive int             `json:"consecutive"` // 连续异常的样本数
+	Since       time.Time       `json:"since"`       // 本次连续异常的开始时间
+}
+
+// LatencyReport 延迟基线与异常查询结果
+type LatencyReport struct {
+	Baselines []PairLatency    `json:"baselines"`
+	Anomalies []LatencyAnomaly `json:"anomalies"`
+}
+
+// RunRequest 服务器下发给客户端的按需测试任务
+type RunRequest struct {
+	ID        string    `json:"id"`
+	TestTypes []string  `json:"test_types"`
+	CreatedAt time.Time `json:"created_at"`
+	Deadline  time.Time `json:"deadline,omitzero"` // 任务截止时间，客户端到期后停止探测
+	Cancel    bool      `json:"cancel,omitempty"`  // 为true时表示任务已取消，客户端停止执行中的探测
+}
+
+// Run 按需测试任务的执行状态与结果
+type Run struct {
+	ID         string                          `json:"id"`
+	Status     string                          `json:"status"` // "running", "completed", "timeout" or "cancelled"
+	TestTypes  []string                        `json:"test_types"`
+	Clients    []string                        `json:"clients"`   // 需要执行测试的客户端Pod名称
+	Completed  []string                        `json:"completed"` // 已完成测试的客户端
+	Pending    []string                        `json:"pending"`   // 尚未完成测试的客户端
+	CreatedAt  time.Time                       `json:"created_at"`
+	Deadline   time.Time                       `json:"deadline"`
+	FinishedAt *time.Time                      `json:"finished_at,omitempty"`
+	Results    map[string][]ConnectivityResult `json:"results"` // key为测试类型
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Code    string `json:"code"`
@@ -1,8 +1,11 @@
 package network
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
 	"go.uber.org/zap"
 )
 
@@ -15,8 +18,11 @@ func ExampleNetworkTester() {
 	// 参数：源IP, 宿主机端口, Pod端口, 服务端口, 最大并发数, 日志记录器
 	tester := NewNetworkTester("192.168.1.100", 22, 6100, 80, 10, logger)
 
+	// 取消 ctx 即可中止进行中的探测，例如收到退出信号时
+	ctx := context.Background()
+
 	// 1. 执行 ping 测试
-	success, latency, err := tester.PingTest("192.168.1.1", 3)
+	success, latency, err := tester.PingTest(ctx, "192.168.1.1", 3)
 	if err != nil {
 		fmt.Printf("Ping 测试错误: %v\n", err)
 	} else if success {
@@ -26,7 +32,7 @@ func ExampleNetworkTester() {
 	}
 
 	// 2. 执行端口测试
-	portOpen, err := tester.PortTest("192.168.1.1", 22, 0)
+	portOpen, err := tester.PortTest(ctx, "192.168.1.1", 22, 0)
 	if err != nil {
 		fmt.Printf("端口测试错误: %v\n", err)
 	} else if portOpen {
@@ -36,8 +42,12 @@ func ExampleNetworkTester() {
 	}
 
 	// 3. 测试宿主机连通性
-	hostIPs := []string{"192.168.1.1", "192.168.1.2", "192.168.1.3"}
-	hostResults, err := tester.TestHostConnectivity(hostIPs)
+	hosts := []models.Target{
+		{IP: "192.168.1.1", NodeName: "node-1"},
+		{IP: "192.168.1.2", NodeName: "node-2"},
+		{IP: "192.168.1.3", NodeName: "node-3", Ports: []int{22, 10250}}, // 目标指定的端口优先
+	}
+	hostResults, err := tester.TestHostConnectivity(ctx, hosts)
 	if err != nil {
 		fmt.Printf("宿主机测试错误: %v\n", err)
 	} else {
@@ -45,8 +55,8 @@ func ExampleNetworkTester() {
 	}
 
 	// 4. 测试 Pod 连通性
-	podIPs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
-	podResults, err := tester.TestPodConnectivity(podIPs)
+	pods := models.TargetsFromIPs([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	podResults, err := tester.TestPodConnectivity(ctx, pods)
 	if err != nil {
 		fmt.Printf("Pod 测试错误: %v\n", err)
 	} else {
@@ -54,7 +64,7 @@ func ExampleNetworkTester() {
 	}
 
 	// 5. 测试自定义服务
-	serviceResult, err := tester.TestServiceConnectivity("kubernetes.default.svc.cluster.local")
+	serviceResult, err := tester.TestServiceConnectivity(ctx, "kubernetes.default.svc.cluster.local")
 	if err != nil {
 		fmt.Printf("服务测试错误: %v\n", err)
 	} else {
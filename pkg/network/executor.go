@@ -0,0 +1,219 @@
+package network
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 探测优先级，数值越大越先获得执行槽位
+// 单个服务探测开销最小且最先反映业务影响，宿主机扫描通常耗时最长，排在最后
+const (
+	PriorityHost    = 0
+	PriorityPod     = 1
+	PriorityService = 2
+)
+
+// ErrExecutorClosed 执行器已关闭
+var ErrExecutorClosed = errors.New("探测执行器已关闭")
+
+// Executor 全局探测执行器，所有测试类型的探测共享并发与速率预算
+type Executor interface {
+	// Run 等待执行槽位与速率令牌后同步执行 fn
+	// 等待期间 ctx 结束或执行器关闭时返回错误且不执行 fn；槽位按优先级分配，同优先级先到先得
+	Run(ctx context.Context, priority int, fn func()) error
+
+	// SetLimits 调整最大并发数与每秒最多发起的探测数，rate <= 0 表示不限制速率
+	SetLimits(concurrency int, rate float64)
+
+	// Close 关闭执行器，等待中的探测立即返回 ErrExecutorClosed，执行中的探测不受影响
+	Close()
+}
+
+// waiter 等待执行槽位的探测
+type waiter struct {
+	priority int
+	seq      int64
+	index    int           // 在队列中的位置，-1 表示已出队
+	ready    chan struct{} // 分配到槽位时关闭
+}
+
+// waitQueue 按优先级与到达顺序排列的等待队列，实现 heap.Interface
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// executorImpl 是Executor的实现
+type executorImpl struct {
+	mu          sync.Mutex
+	concurrency int
+	interval    time.Duration // 相邻两次探测的最小间隔，为0时不限制速率
+	active      int
+	queue       waitQueue
+	seq         int64
+	next        time.Time // 下一次探测最早的开始时间
+	closed      bool
+	done        chan struct{}
+}
+
+// NewExecutor 创建探测执行器，concurrency <= 0 时使用默认并发数 10
+func NewExecutor(concurrency int, rate float64) Executor {
+	e := &executorImpl{done: make(chan struct{})}
+	e.SetLimits(concurrency, rate)
+	return e
+}
+
+// SetLimits 调整并发数与速率
+func (e *executorImpl) SetLimits(concurrency int, rate float64) {
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.concurrency = concurrency
+	e.interval = 0
+	if rate > 0 {
+		e.interval = time.Duration(float64(time.Second) / rate)
+	}
+	e.dispatch()
+}
+
+// Run 等待槽位与速率令牌后执行 fn
+func (e *executorImpl) Run(ctx context.Context, priority int, fn func()) error {
+	if err := e.acquire(ctx, priority); err != nil {
+		return err
+	}
+	defer e.release()
+
+	if err := e.throttle(ctx); err != nil {
+		return err
+	}
+	fn()
+	return nil
+}
+
+// Close 关闭执行器
+func (e *executorImpl) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.closed {
+		e.closed = true
+		close(e.done)
+	}
+}
+
+// acquire 进入等待队列，直到分配到槽位
+func (e *executorImpl) acquire(ctx context.Context, priority int) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return ErrExecutorClosed
+	}
+	e.seq++
+	w := &waiter{priority: priority, seq: e.seq, ready: make(chan struct{})}
+	heap.Push(&e.queue, w)
+	e.dispatch()
+	e.mu.Unlock()
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-e.done:
+		err = ErrExecutorClosed
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if w.index < 0 {
+		// 放弃等待前已分配到槽位，归还给下一个等待者
+		e.active--
+		e.dispatch()
+	} else {
+		heap.Remove(&e.queue, w.index)
+	}
+	return err
+}
+
+// release 归还槽位
+func (e *executorImpl) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.active--
+	e.dispatch()
+}
+
+// dispatch 按优先级将空闲槽位分配给等待者，调用方需持有锁
+func (e *executorImpl) dispatch() {
+	for e.active < e.concurrency && e.queue.Len() > 0 {
+		w := heap.Pop(&e.queue).(*waiter)
+		e.active++
+		close(w.ready)
+	}
+}
+
+// throttle 按速率限制预约开始时间并等待
+func (e *executorImpl) throttle(ctx context.Context) error {
+	e.mu.Lock()
+	if e.interval <= 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	start := e.next
+	if start.Before(now) {
+		start = now
+	}
+	e.next = start.Add(e.interval)
+	e.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.done:
+		return ErrExecutorClosed
+	}
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutorConcurrency 测试并发探测数不超过限制
+func TestExecutorConcurrency(t *testing.T) {
+	executor := NewExecutor(2, 0)
+
+	var active, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := executor.Run(context.Background(), PriorityPod, func() {
+				current := atomic.AddInt32(&active, 1)
+				for {
+					old := atomic.LoadInt32(&peak)
+					if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak)
+}
+
+// TestExecutorPriority 测试空闲槽位优先分配给高优先级的探测
+func TestExecutorPriority(t *testing.T) {
+	executor := NewExecutor(1, 0)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go executor.Run(context.Background(), PriorityHost, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for _, priority := range []int{PriorityHost, PriorityPod, PriorityService} {
+		wg.Add(1)
+		go func(priority int) {
+			defer wg.Done()
+			executor.Run(context.Background(), priority, func() {
+				mu.Lock()
+				order = append(order, priority)
+				mu.Unlock()
+			})
+		}(priority)
+		// 保证按顺序进入等待队列
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{PriorityService, PriorityPod, PriorityHost}, order)
+}
+
+// TestExecutorCancel 测试等待中的探测在 ctx 结束或执行器关闭后不再执行
+func TestExecutorCancel(t *testing.T) {
+	executor := NewExecutor(1, 0)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go executor.Run(context.Background(), PriorityPod, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	executed := false
+	err := executor.Run(ctx, PriorityService, func() { executed = true })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, executed)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Run(context.Background(), PriorityPod, func() { executed = true })
+	}()
+	time.Sleep(10 * time.Millisecond)
+	executor.Close()
+	assert.ErrorIs(t, <-done, ErrExecutorClosed)
+	assert.False(t, executed)
+
+	close(release)
+	assert.ErrorIs(t, executor.Run(context.Background(), PriorityPod, func() {}), ErrExecutorClosed)
+}
+
+// TestExecutorRateLimit 测试每秒发起的探测数不超过速率限制
+func TestExecutorRateLimit(t *testing.T) {
+	executor := NewExecutor(10, 100) // 每 10ms 一次
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, executor.Run(context.Background(), PriorityPod, func() {}))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// 取消速率限制后不再等待
+	executor.SetLimits(10, 0)
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, executor.Run(context.Background(), PriorityPod, func() {}))
+	}
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+}
@@ -16,41 +16,88 @@ import (
 )
 
 // NetworkTester defines the interface for performing network connectivity tests
+// All methods stop promptly when ctx is cancelled; results of probes interrupted
+// by cancellation are discarded rather than reported as failures
 type NetworkTester interface {
 	// PingTest performs a ping test to the target IP
-	// count: number of ping attempts
-	// Returns: success status, average latency, error
-	PingTest(targetIP string, count int) (bool, time.Duration, error)
+	// count: number of ping attempts, bounded by the configured ping timeout
+	// Returns: success status, average latency, error (ctx.Err() when cancelled)
+	PingTest(ctx context.Context, targetIP string, count int) (bool, time.Duration, error)
 
 	// PortTest performs a TCP port connectivity test
-	// Returns: true if port is open, false otherwise
-	PortTest(targetIP string, port int, timeout time.Duration) (bool, error)
+	// Returns: true if port is open, false otherwise (error is ctx.Err() when cancelled)
+	PortTest(ctx context.Context, targetIP string, port int, timeout time.Duration) (bool, error)
 
-	// TestHostConnectivity tests connectivity to all host IPs
-	// Tests both ping and port 22 (or configured port)
-	TestHostConnectivity(hostIPs []string) ([]models.ConnectivityResult, error)
+	// TestHostConnectivity tests connectivity to all host targets
+	// Tests both ping and port 22 (or configured port); a target's own ports take precedence
+	// Results carry the target identity
+	TestHostConnectivity(ctx context.Context, hosts []models.Target) ([]models.ConnectivityResult, error)
 
-	// TestPodConnectivity tests connectivity to all pod IPs
-	// Tests both ping and port 6100
-	TestPodConnectivity(podIPs []string) ([]models.ConnectivityResult, error)
+	// TestPodConnectivity tests connectivity to all pod targets
+	// Tests both ping and port 6100; a target's own ports take precedence
+	// Results carry the target identity
+	TestPodConnectivity(ctx context.Context, pods []models.Target) ([]models.ConnectivityResult, error)
 
 	// TestServiceConnectivity tests connectivity to a custom service
 	// Performs DNS resolution and connectivity test
-	TestServiceConnectivity(serviceName string) (*models.ConnectivityResult, error)
+	TestServiceConnectivity(ctx context.Context, serviceName string) (*models.ConnectivityResult, error)
+
+	// OnProbeConfig applies a probe configuration pushed by the server;
+	// the next test uses the new ports, protocols, timeout and concurrency
+	OnProbeConfig(cfg models.ProbeConfig)
+}
+
+// probeSettings 探测参数，可由服务器下发的探测配置实时替换
+type probeSettings struct {
+	hostPorts    []int         // 宿主机测试端口（默认 22）
+	podPorts     []int         // Pod 测试端口（默认 6100）
+	servicePorts []int         // 自定义服务测试端口（默认 80）
+	maxWorkers   int           // 最大并发探测数，由所有测试类型共享
+	rateLimit    float64       // 每秒最多发起的探测数，为0时不限制
+	pingCount    int           // 每次 ping 的次数
+	timeout      time.Duration // 端口测试超时
+	pingTimeout  time.Duration // 一次 ping 测试的超时
+	dnsTimeout   time.Duration // DNS 解析超时
+	icmp         bool          // 是否执行 ping 测试
+	tcp          bool          // 是否执行端口测试
+	pingInterval time.Duration // ping 的最小执行间隔，为0时每轮都执行
+
+	// 各测试类型一轮探测的截止时间，超过后尚未开始的探测不再执行，为0时不限制
+	deadlines map[string]time.Duration
 }
 
 // networkTester 是 NetworkTester 接口的实现
 type networkTester struct {
-	sourceIP    string      // 源 IP 地址
-	hostPort    int         // 宿主机测试端口（默认 22）
-	podPort     int         // Pod 测试端口（默认 6100）
-	servicePort int         // 自定义服务测试端口（默认 80）
-	maxWorkers  int         // 最大并发 goroutine 数量
-	logger      *zap.Logger // 日志记录器
+	sourceIP     string      // 源 IP 地址
+	logger       *zap.Logger // 日志记录器
+	executor     Executor    // 探测执行器，所有测试类型共享并发与速率预算
+	sameNodeSkip string      // 非空时跳过位于该节点IP上的Pod目标
+
+	mu       sync.RWMutex
+	settings probeSettings
+	lastPing map[string]time.Time // 各测试类型最近一次执行 ping 的时间
+}
+
+// Option NetworkTester 的可选配置项
+type Option func(nt *networkTester)
+
+// WithExecutor 使用指定的探测执行器，未指定时按 maxWorkers 创建
+func WithExecutor(executor Executor) Option {
+	return func(nt *networkTester) {
+		nt.executor = executor
+	}
+}
+
+// WithSameNodeSkip Pod测试跳过与当前客户端位于同一节点（nodeIP）的目标，只测试跨节点的连通性
+// 只对携带节点信息的目标生效
+func WithSameNodeSkip(nodeIP string) Option {
+	return func(nt *networkTester) {
+		nt.sameNodeSkip = nodeIP
+	}
 }
 
 // NewNetworkTester 创建一个新的 NetworkTester 实例
-func NewNetworkTester(sourceIP string, hostPort, podPort, servicePort, maxWorkers int, logger *zap.Logger) NetworkTester {
+func NewNetworkTester(sourceIP string, hostPort, podPort, servicePort, maxWorkers int, logger *zap.Logger, opts ...Option) NetworkTester {
 	if maxWorkers <= 0 {
 		maxWorkers = 10 // 默认最大并发数为 10
 	}
@@ -64,40 +111,134 @@ func NewNetworkTester(sourceIP string, hostPort, podPort, servicePort, maxWorker
 		servicePort = 80 // 默认服务端口为 80
 	}
 
-	return &networkTester{
-		sourceIP:    sourceIP,
-		hostPort:    hostPort,
-		podPort:     podPort,
-		servicePort: servicePort,
-		maxWorkers:  maxWorkers,
-		logger:      logger,
+	nt := &networkTester{
+		sourceIP: sourceIP,
+		logger:   logger,
+		lastPing: make(map[string]time.Time),
+		settings: probeSettings{
+			hostPorts:    []int{hostPort},
+			podPorts:     []int{podPort},
+			servicePorts: []int{servicePort},
+			maxWorkers:   maxWorkers,
+			pingCount:    3,
+			timeout:      5 * time.Second,
+			pingTimeout:  10 * time.Second,
+			dnsTimeout:   5 * time.Second,
+			icmp:         true,
+			tcp:          true,
+		},
 	}
+	for _, opt := range opts {
+		opt(nt)
+	}
+	if nt.executor == nil {
+		nt.executor = NewExecutor(maxWorkers, 0)
+	}
+	return nt
+}
+
+// OnProbeConfig 应用服务器下发的探测配置
+func (nt *networkTester) OnProbeConfig(cfg models.ProbeConfig) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+
+	nt.settings = probeSettings{
+		hostPorts:    cfg.HostPorts,
+		podPorts:     cfg.PodPorts,
+		servicePorts: cfg.ServicePorts,
+		maxWorkers:   cfg.MaxConcurrency,
+		rateLimit:    cfg.RateLimit,
+		pingCount:    cfg.PingCount,
+		timeout:      time.Duration(cfg.Timeout),
+		pingTimeout:  time.Duration(cfg.PingTimeout),
+		dnsTimeout:   time.Duration(cfg.DNSTimeout),
+		icmp:         cfg.HasProtocol(models.ProtocolICMP),
+		tcp:          cfg.HasProtocol(models.ProtocolTCP),
+		pingInterval: time.Duration(cfg.PingInterval),
+		deadlines: map[string]time.Duration{
+			models.TestTypeHost:    cfg.IntervalFor(models.TestTypeHost),
+			models.TestTypePod:     cfg.IntervalFor(models.TestTypePod),
+			models.TestTypeService: cfg.IntervalFor(models.TestTypeService),
+		},
+	}
+	if nt.settings.maxWorkers <= 0 {
+		nt.settings.maxWorkers = 10
+	}
+	if nt.settings.pingTimeout <= 0 {
+		nt.settings.pingTimeout = 10 * time.Second
+	}
+	if nt.settings.dnsTimeout <= 0 {
+		nt.settings.dnsTimeout = 5 * time.Second
+	}
+	nt.executor.SetLimits(nt.settings.maxWorkers, nt.settings.rateLimit)
+
+	nt.logger.Info("已应用探测配置",
+		zap.Int64("version", cfg.Version),
+		zap.Ints("host_ports", cfg.HostPorts),
+		zap.Ints("pod_ports", cfg.PodPorts),
+		zap.Ints("service_ports", cfg.ServicePorts),
+		zap.Strings("protocols", cfg.Protocols),
+		zap.Int("max_concurrency", nt.settings.maxWorkers),
+		zap.Float64("rate_limit", nt.settings.rateLimit),
+	)
+}
+
+// currentSettings 返回指定测试类型本轮使用的探测参数，单次测试内使用同一份参数
+// 同时启用端口测试时，距上次 ping 未达到 ping 间隔的轮次不执行 ping
+func (nt *networkTester) currentSettings(testType string) probeSettings {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+
+	settings := nt.settings
+	if settings.icmp && settings.tcp && settings.pingInterval > 0 {
+		now := time.Now()
+		if last, ok := nt.lastPing[testType]; ok && now.Sub(last) < settings.pingInterval {
+			settings.icmp = false
+		} else {
+			nt.lastPing[testType] = now
+		}
+	}
+	return settings
+}
+
+// sweepContext 返回一轮探测等待执行槽位使用的上下文，截止时间为该测试类型的测试间隔
+// 截止后尚未开始的探测不再执行，避免慢速扫描挤占下一轮及其他测试类型的预算；
+// 已开始的探测只受 ctx 控制
+func (nt *networkTester) sweepContext(ctx context.Context, settings probeSettings, testType string) (context.Context, context.CancelFunc) {
+	if deadline := settings.deadlines[testType]; deadline > 0 {
+		return context.WithTimeout(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
 }
 
 // PingTest 执行 ping 测试
-func (nt *networkTester) PingTest(targetIP string, count int) (bool, time.Duration, error) {
+func (nt *networkTester) PingTest(ctx context.Context, targetIP string, count int) (bool, time.Duration, error) {
 	if count <= 0 {
 		count = 3 // 默认 ping 3 次
 	}
 
+	// 设置超时上下文，ctx 取消时终止 ping 进程
+	nt.mu.RLock()
+	timeout := nt.settings.pingTimeout
+	nt.mu.RUnlock()
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// 根据操作系统选择 ping 命令参数
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("ping", "-n", strconv.Itoa(count), "-w", "5000", targetIP)
+		cmd = exec.CommandContext(pingCtx, "ping", "-n", strconv.Itoa(count), "-w", "5000", targetIP)
 	} else {
-		cmd = exec.Command("ping", "-c", strconv.Itoa(count), "-W", "5", targetIP)
+		cmd = exec.CommandContext(pingCtx, "ping", "-c", strconv.Itoa(count), "-W", "5", targetIP)
 	}
 
-	// 设置超时上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
-
 	start := time.Now()
 	output, err := cmd.CombinedOutput()
 	latency := time.Since(start)
 
+	if ctx.Err() != nil {
+		return false, 0, ctx.Err()
+	}
 	if err != nil {
 		nt.logger.Debug("ping 测试失败",
 			zap.String("target_ip", targetIP),
@@ -119,14 +260,21 @@ func (nt *networkTester) PingTest(targetIP string, count int) (bool, time.Durati
 }
 
 // PortTest 执行 TCP 端口连接测试
-func (nt *networkTester) PortTest(targetIP string, port int, timeout time.Duration) (bool, error) {
+func (nt *networkTester) PortTest(ctx context.Context, targetIP string, port int, timeout time.Duration) (bool, error) {
 	if timeout <= 0 {
 		timeout = 5 * time.Second // 默认超时 5 秒
 	}
 
 	address := net.JoinHostPort(targetIP, strconv.Itoa(port))
 
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if ctx.Err() != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return false, ctx.Err()
+	}
 	if err != nil {
 		nt.logger.Debug("端口测试失败",
 			zap.String("target_ip", targetIP),
@@ -146,65 +294,110 @@ func (nt *networkTester) PortTest(targetIP string, port int, timeout time.Durati
 	return true, nil
 }
 
-// TestHostConnectivity 测试所有宿主机 IP 的连通性
-func (nt *networkTester) TestHostConnectivity(hostIPs []string) ([]models.ConnectivityResult, error) {
-	return nt.testConnectivity(hostIPs, nt.hostPort, "宿主机")
+// TestHostConnectivity 测试所有宿主机目标的连通性
+func (nt *networkTester) TestHostConnectivity(ctx context.Context, hosts []models.Target) ([]models.ConnectivityResult, error) {
+	settings := nt.currentSettings(models.TestTypeHost)
+	return nt.testConnectivity(ctx, hosts, settings.hostPorts, settings, "宿主机", models.TestTypeHost, PriorityHost)
 }
 
-// TestPodConnectivity 测试所有 Pod IP 的连通性
-func (nt *networkTester) TestPodConnectivity(podIPs []string) ([]models.ConnectivityResult, error) {
-	return nt.testConnectivity(podIPs, nt.podPort, "Pod")
+// TestPodConnectivity 测试所有 Pod 目标的连通性
+func (nt *networkTester) TestPodConnectivity(ctx context.Context, pods []models.Target) ([]models.ConnectivityResult, error) {
+	settings := nt.currentSettings(models.TestTypePod)
+	if nt.sameNodeSkip != "" {
+		remote := make([]models.Target, 0, len(pods))
+		for _, pod := range pods {
+			if pod.NodeIP != nt.sameNodeSkip {
+				remote = append(remote, pod)
+			}
+		}
+		if skipped := len(pods) - len(remote); skipped > 0 {
+			nt.logger.Debug("跳过同节点的 Pod 目标", zap.Int("skipped_count", skipped))
+		}
+		pods = remote
+	}
+	return nt.testConnectivity(ctx, pods, settings.podPorts, settings, "Pod", models.TestTypePod, PriorityPod)
 }
 
-// testConnectivity 是通用的连通性测试方法，支持并发测试
-func (nt *networkTester) testConnectivity(targetIPs []string, port int, testType string) ([]models.ConnectivityResult, error) {
-	if len(targetIPs) == 0 {
+// testConnectivity 是通用的连通性测试方法，各目标的探测提交到共享的探测执行器并发执行
+// 目标指定了端口时使用目标的端口，否则使用 ports；
+// 超过本轮截止时间尚未开始的探测不再执行；ctx 取消时执行中的探测立即停止，结果中不包含这些目标
+func (nt *networkTester) testConnectivity(ctx context.Context, targets []models.Target, ports []int, settings probeSettings, testType, kind string, priority int) ([]models.ConnectivityResult, error) {
+	if len(targets) == 0 {
 		nt.logger.Info("没有目标 IP 需要测试", zap.String("test_type", testType))
 		return []models.ConnectivityResult{}, nil
 	}
 
 	nt.logger.Info("开始连通性测试",
 		zap.String("test_type", testType),
-		zap.Int("target_count", len(targetIPs)),
-		zap.Int("port", port),
+		zap.Int("target_count", len(targets)),
+		zap.Ints("ports", ports),
 	)
 
 	// 创建结果切片和互斥锁
-	results := make([]models.ConnectivityResult, 0, len(targetIPs))
+	results := make([]models.ConnectivityResult, 0, len(targets))
 	var resultsMutex sync.Mutex
 
-	// 创建工作池，限制并发数
-	semaphore := make(chan struct{}, nt.maxWorkers)
+	sweepCtx, cancel := nt.sweepContext(ctx, settings, kind)
+	defer cancel()
+
 	var wg sync.WaitGroup
+	skipped := 0
 
-	// 并发测试每个目标 IP
-	for _, targetIP := range targetIPs {
+	// 并发测试每个目标
+	for _, target := range targets {
 		// 跳过自己
-		if targetIP == nt.sourceIP {
+		if target.IP == nt.sourceIP {
 			continue
 		}
 
 		wg.Add(1)
-		go func(target string) {
+		go func(target models.Target) {
 			defer wg.Done()
 
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// 执行测试
-			result := nt.testSingleTarget(target, port)
-
-			// 将结果添加到切片
-			resultsMutex.Lock()
-			results = append(results, result)
-			resultsMutex.Unlock()
-		}(targetIP)
+			targetPorts := ports
+			if len(target.Ports) > 0 {
+				targetPorts = target.Ports
+			}
+
+			// 等待执行器分配槽位后执行测试
+			err := nt.executor.Run(sweepCtx, priority, func() {
+				result := nt.testSingleTarget(ctx, target.IP, targetPorts, settings)
+				result.Target = target.Identity()
+				if ctx.Err() != nil {
+					return // 探测被取消，结果不可信
+				}
+
+				// 将结果添加到切片
+				resultsMutex.Lock()
+				results = append(results, result)
+				resultsMutex.Unlock()
+			})
+			if err != nil {
+				resultsMutex.Lock()
+				skipped++
+				resultsMutex.Unlock()
+			}
+		}(target)
 	}
 
 	// 等待所有测试完成
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		nt.logger.Info("连通性测试已取消",
+			zap.String("test_type", testType),
+			zap.Int("tested_count", len(results)),
+		)
+		return nil, err
+	}
+	if skipped > 0 {
+		nt.logger.Warn("部分目标未在截止时间内开始探测",
+			zap.String("test_type", testType),
+			zap.Int("skipped_count", skipped),
+			zap.Error(sweepCtx.Err()),
+		)
+	}
+
 	nt.logger.Info("连通性测试完成",
 		zap.String("test_type", testType),
 		zap.Int("tested_count", len(results)),
@@ -214,7 +407,7 @@ func (nt *networkTester) testConnectivity(targetIPs []string, port int, testType
 }
 
 // testSingleTarget 测试单个目标的连通性
-func (nt *networkTester) testSingleTarget(targetIP string, port int) models.ConnectivityResult {
+func (nt *networkTester) testSingleTarget(ctx context.Context, targetIP string, ports []int, settings probeSettings) models.ConnectivityResult {
 	startTime := time.Now()
 
 	result := models.ConnectivityResult{
@@ -224,23 +417,7 @@ func (nt *networkTester) testSingleTarget(targetIP string, port int) models.Conn
 		Timestamp:  startTime,
 	}
 
-	// 执行 ping 测试
-	pingSuccess, latency, _ := nt.PingTest(targetIP, 3)
-	if pingSuccess {
-		result.PingStatus = "reachable"
-		result.Latency = models.Duration(latency)
-	} else {
-		result.PingStatus = "unreachable"
-		result.Latency = 0
-	}
-
-	// 执行端口测试
-	portOpen, _ := nt.PortTest(targetIP, port, 5*time.Second)
-	if portOpen {
-		result.PortStatus[port] = "open"
-	} else {
-		result.PortStatus[port] = "closed"
-	}
+	nt.probe(ctx, &result, targetIP, ports, settings)
 
 	// 记录测试耗时
 	result.TestDuration = models.Duration(time.Since(startTime))
@@ -248,20 +425,53 @@ func (nt *networkTester) testSingleTarget(targetIP string, port int) models.Conn
 	nt.logger.Debug("单个目标测试完成",
 		zap.String("target_ip", targetIP),
 		zap.String("ping_status", result.PingStatus),
-		zap.String("port_status", result.PortStatus[port]),
+		zap.String("port_status", result.PortSummary()),
 		zap.String("test_duration", result.TestDuration.String()),
 	)
 
 	return result
 }
 
+// probe 按探测参数对目标执行 ping 和端口测试，未启用的协议不执行
+func (nt *networkTester) probe(ctx context.Context, result *models.ConnectivityResult, targetIP string, ports []int, settings probeSettings) {
+	// 执行 ping 测试
+	if settings.icmp {
+		pingSuccess, latency, _ := nt.PingTest(ctx, targetIP, settings.pingCount)
+		if pingSuccess {
+			result.PingStatus = "reachable"
+			result.Latency = models.Duration(latency)
+		} else {
+			result.PingStatus = "unreachable"
+			result.Latency = 0
+		}
+	} else {
+		result.PingStatus = models.PingSkipped
+	}
+
+	// 执行端口测试
+	if settings.tcp {
+		for _, port := range ports {
+			if ctx.Err() != nil {
+				return
+			}
+			portOpen, _ := nt.PortTest(ctx, targetIP, port, settings.timeout)
+			if portOpen {
+				result.PortStatus[port] = "open"
+			} else {
+				result.PortStatus[port] = "closed"
+			}
+		}
+	}
+}
+
 // TestServiceConnectivity 测试自定义服务的连通性
-func (nt *networkTester) TestServiceConnectivity(serviceName string) (*models.ConnectivityResult, error) {
+func (nt *networkTester) TestServiceConnectivity(ctx context.Context, serviceName string) (*models.ConnectivityResult, error) {
 	if serviceName == "" {
 		return nil, fmt.Errorf("服务名称不能为空")
 	}
 
 	startTime := time.Now()
+	settings := nt.currentSettings(models.TestTypeService)
 	nt.logger.Info("开始自定义服务测试", zap.String("service_name", serviceName))
 
 	result := &models.ConnectivityResult{
@@ -271,10 +481,13 @@ func (nt *networkTester) TestServiceConnectivity(serviceName string) (*models.Co
 	}
 
 	// 执行 DNS 解析
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	dnsCtx, cancel := context.WithTimeout(ctx, settings.dnsTimeout)
 	defer cancel()
 
-	ips, err := net.DefaultResolver.LookupHost(ctx, serviceName)
+	ips, err := net.DefaultResolver.LookupHost(dnsCtx, serviceName)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		nt.logger.Warn("DNS 解析失败",
 			zap.String("service_name", serviceName),
@@ -306,22 +519,17 @@ func (nt *networkTester) TestServiceConnectivity(serviceName string) (*models.Co
 		zap.Strings("all_ips", ips),
 	)
 
-	// 执行 ping 测试
-	pingSuccess, latency, _ := nt.PingTest(targetIP, 3)
-	if pingSuccess {
-		result.PingStatus = "reachable"
-		result.Latency = models.Duration(latency)
-	} else {
-		result.PingStatus = "unreachable"
-		result.Latency = 0
-	}
-
 	// 测试配置的服务端口
-	portOpen, _ := nt.PortTest(targetIP, nt.servicePort, 5*time.Second)
-	if portOpen {
-		result.PortStatus[nt.servicePort] = "open"
-	} else {
-		result.PortStatus[nt.servicePort] = "closed"
+	probeCtx, probeCancel := nt.sweepContext(ctx, settings, models.TestTypeService)
+	defer probeCancel()
+	err = nt.executor.Run(probeCtx, PriorityService, func() {
+		nt.probe(ctx, result, targetIP, settings.servicePorts, settings)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("自定义服务探测未执行: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	// 记录测试耗时
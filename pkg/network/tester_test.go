@@ -1,10 +1,15 @@
 package network
 
 import (
+	"context"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -57,10 +62,10 @@ func TestNewNetworkTester(t *testing.T) {
 
 			nt := tester.(*networkTester)
 			assert.Equal(t, tt.sourceIP, nt.sourceIP)
-			assert.Equal(t, tt.wantHost, nt.hostPort)
-			assert.Equal(t, tt.wantPod, nt.podPort)
-			assert.Equal(t, tt.wantService, nt.servicePort)
-			assert.Equal(t, tt.wantWorker, nt.maxWorkers)
+			assert.Equal(t, []int{tt.wantHost}, nt.settings.hostPorts)
+			assert.Equal(t, []int{tt.wantPod}, nt.settings.podPorts)
+			assert.Equal(t, []int{tt.wantService}, nt.settings.servicePorts)
+			assert.Equal(t, tt.wantWorker, nt.settings.maxWorkers)
 		})
 	}
 }
@@ -92,7 +97,7 @@ func TestPingTest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			success, latency, err := tester.PingTest(tt.targetIP, tt.count)
+			success, latency, err := tester.PingTest(context.Background(), tt.targetIP, tt.count)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -131,7 +136,7 @@ func TestPortTest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			open, err := tester.PortTest(tt.targetIP, tt.port, tt.timeout)
+			open, err := tester.PortTest(context.Background(), tt.targetIP, tt.port, tt.timeout)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -176,7 +181,7 @@ func TestTestHostConnectivity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := tester.TestHostConnectivity(tt.hostIPs)
+			results, err := tester.TestHostConnectivity(context.Background(), models.TargetsFromIPs(tt.hostIPs))
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -225,7 +230,7 @@ func TestTestPodConnectivity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := tester.TestPodConnectivity(tt.podIPs)
+			results, err := tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs(tt.podIPs))
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -274,7 +279,7 @@ func TestTestServiceConnectivity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tester.TestServiceConnectivity(tt.serviceName)
+			result, err := tester.TestServiceConnectivity(context.Background(), tt.serviceName)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -298,7 +303,7 @@ func TestConcurrentTesting(t *testing.T) {
 	}
 
 	start := time.Now()
-	results, err := tester.TestPodConnectivity(podIPs)
+	results, err := tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs(podIPs))
 	duration := time.Since(start)
 
 	assert.NoError(t, err)
@@ -306,3 +311,144 @@ func TestConcurrentTesting(t *testing.T) {
 
 	t.Logf("测试 %d 个目标耗时: %v", len(podIPs), duration)
 }
+
+// TestOnProbeConfig 测试探测配置下发后使用新的端口和协议
+func TestOnProbeConfig(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tester := NewNetworkTester("10.0.0.1", 22, 6100, 80, 10, logger)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	// 关闭 ICMP 后不执行 ping，多个端口逐一测试
+	tester.OnProbeConfig(models.ProbeConfig{
+		Version:        2,
+		Protocols:      []string{models.ProtocolTCP},
+		PodPorts:       []int{openPort, 9999},
+		MaxConcurrency: 2,
+		Timeout:        models.Duration(time.Second),
+	})
+
+	results, err := tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs([]string{"127.0.0.1"}))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, models.PingSkipped, results[0].PingStatus)
+	assert.Equal(t, "open", results[0].PortStatus[openPort])
+	assert.Equal(t, "closed", results[0].PortStatus[9999])
+	assert.False(t, results[0].Succeeded(models.TestTypePod))
+
+	// 只探测开放端口时测试成功
+	tester.OnProbeConfig(models.ProbeConfig{
+		Version:   3,
+		Protocols: []string{models.ProtocolTCP},
+		PodPorts:  []int{openPort},
+	})
+	results, err = tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs([]string{"127.0.0.1"}))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Succeeded(models.TestTypePod))
+}
+
+// TestPingInterval 测试 ping 间隔内只执行端口测试
+func TestPingInterval(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tester := NewNetworkTester("10.0.0.1", 22, 6100, 80, 10, logger).(*networkTester)
+
+	tester.OnProbeConfig(models.ProbeConfig{
+		Protocols:    []string{models.ProtocolICMP, models.ProtocolTCP},
+		PodPorts:     []int{6100},
+		PingInterval: models.Duration(time.Hour),
+	})
+
+	assert.True(t, tester.currentSettings(models.TestTypePod).icmp)
+	assert.False(t, tester.currentSettings(models.TestTypePod).icmp)
+	// 各测试类型独立计算 ping 间隔
+	assert.True(t, tester.currentSettings(models.TestTypeHost).icmp)
+
+	// 仅启用 ICMP 时每轮都执行 ping
+	tester.OnProbeConfig(models.ProbeConfig{
+		Protocols:    []string{models.ProtocolICMP},
+		PingInterval: models.Duration(time.Hour),
+	})
+	assert.True(t, tester.currentSettings(models.TestTypePod).icmp)
+}
+
+// TestTargetIdentity 测试目标指定的端口优先于探测配置，结果携带目标身份，并跳过同节点目标
+func TestTargetIdentity(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	logger, _ := zap.NewDevelopment()
+	tester := NewNetworkTester("10.0.0.1", 22, 6100, 80, 10, logger, WithSameNodeSkip("192.168.1.1"))
+	tester.OnProbeConfig(models.ProbeConfig{Protocols: []string{models.ProtocolTCP}, PodPorts: []int{1}})
+
+	results, err := tester.TestPodConnectivity(context.Background(), []models.Target{
+		{IP: "127.0.0.1", PodName: "pod-2", NodeName: "node-2", NodeIP: "192.168.1.2", Ports: []int{port}, Labels: map[string]string{"app": "checker"}},
+		{IP: "127.0.0.2", PodName: "pod-3", NodeName: "node-1", NodeIP: "192.168.1.1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, map[int]string{port: "open"}, results[0].PortStatus)
+	assert.Equal(t, &models.Target{
+		IP: "127.0.0.1", PodName: "pod-2", NodeName: "node-2", NodeIP: "192.168.1.2", Labels: map[string]string{"app": "checker"},
+	}, results[0].Target)
+
+	// 只有IP的目标不携带身份
+	results, err = tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs([]string{"127.0.0.1"}))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Nil(t, results[0].Target)
+}
+
+// TestSweepDeadline 测试截止时间内未分配到执行槽位的目标不再探测
+func TestSweepDeadline(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	executor := NewExecutor(1, 0)
+	tester := NewNetworkTester("10.0.0.1", 22, 6100, 80, 1, logger, WithExecutor(executor))
+	tester.OnProbeConfig(models.ProbeConfig{
+		TestInterval:   models.Duration(50 * time.Millisecond),
+		Protocols:      []string{models.ProtocolTCP},
+		PodPorts:       []int{6100},
+		MaxConcurrency: 1,
+	})
+
+	// 其他测试类型占用唯一的执行槽位
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go executor.Run(context.Background(), PriorityHost, func() {
+		close(started)
+		<-release
+	})
+	<-started
+	defer close(release)
+
+	start := time.Now()
+	results, err := tester.TestPodConnectivity(context.Background(), models.TargetsFromIPs([]string{"127.0.0.1"}))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestCancelledContext 测试 ctx 取消后探测立即返回且不产生结果
+func TestCancelledContext(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tester := NewNetworkTester("10.0.0.1", 22, 6100, 80, 10, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	open, err := tester.PortTest(ctx, "127.0.0.1", 6100, time.Second)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, open)
+
+	results, err := tester.TestPodConnectivity(ctx, models.TargetsFromIPs([]string{"127.0.0.1", "127.0.0.2"}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+
+	_, err = tester.TestServiceConnectivity(ctx, "localhost")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,227 @@
+// Package outbox 在服务器不可达时缓存待上报的测试结果
+// 结果按批次先进先出保存，服务器恢复后按原顺序重放；队列有容量上限，满时丢弃最早的批次。
+// 配置目录后每个批次同时写入磁盘，客户端重启后仍可继续重放
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/fileutil"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// Batch 一批待上报的测试结果
+type Batch struct {
+	ID        string                      `json:"id"`
+	TestType  string                      `json:"test_type"`
+	RunID     string                      `json:"run_id,omitempty"`
+	Results   []models.ConnectivityResult `json:"results"`
+	CreatedAt time.Time                   `json:"created_at"` // 结果的采集时间，重放时服务器按该时间保存结果
+}
+
+// Outbox 定义待上报结果队列接口
+type Outbox interface {
+	// Enqueue 将批次加入队列末尾，返回因队列已满被丢弃的批次数量
+	// 同一按需测试任务的同类型结果只保留最新的一批，替换原有批次且保持其位置
+	Enqueue(batch Batch) (dropped int, err error)
+
+	// Peek 返回队列中最早的批次
+	Peek() (Batch, bool)
+
+	// Remove 删除指定批次
+	Remove(id string) error
+
+	// Len 返回队列中的批次数量
+	Len() int
+}
+
+// outboxImpl 是Outbox的实现
+type outboxImpl struct {
+	capacity int
+	dir      string // 为空时只保存在内存中
+
+	mu      sync.Mutex
+	batches []Batch
+	seq     int64
+}
+
+// New 创建结果队列，capacity 为最多保存的批次数量
+// dir 非空时批次持久化到该目录，并加载目录中已有的批次
+func New(capacity int, dir string) (Outbox, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("队列容量必须大于0: %d", capacity)
+	}
+
+	o := &outboxImpl{capacity: capacity, dir: dir}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("创建队列目录失败: %w", err)
+		}
+		if err := o.load(); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Enqueue 将批次加入队列
+func (o *outboxImpl) Enqueue(batch Batch) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = collectedAt(batch.Results)
+	}
+	// 未标注采集时间的结果使用批次的采集时间，避免重放时被服务器视为最新结果
+	results := make([]models.ConnectivityResult, len(batch.Results))
+	for i, result := range batch.Results {
+		if result.Timestamp.IsZero() {
+			result.Timestamp = batch.CreatedAt
+		}
+		results[i] = result
+	}
+	batch.Results = results
+
+	// 同一任务的重复结果替换原有批次
+	if batch.RunID != "" {
+		for i, existing := range o.batches {
+			if existing.RunID == batch.RunID && existing.TestType == batch.TestType {
+				batch.ID = existing.ID
+				if err := o.write(batch); err != nil {
+					return 0, err
+				}
+				o.batches[i] = batch
+				return 0, nil
+			}
+		}
+	}
+
+	o.seq++
+	batch.ID = fmt.Sprintf("%019d-%04d-%s", batch.CreatedAt.UnixNano(), o.seq%10000, batch.TestType)
+	if err := o.write(batch); err != nil {
+		return 0, err
+	}
+	o.batches = append(o.batches, batch)
+
+	dropped := 0
+	for len(o.batches) > o.capacity {
+		o.delete(o.batches[0].ID)
+		o.batches = o.batches[1:]
+		dropped++
+	}
+	return dropped, nil
+}
+
+// Peek 返回最早的批次
+func (o *outboxImpl) Peek() (Batch, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.batches) == 0 {
+		return Batch{}, false
+	}
+	return o.batches[0], true
+}
+
+// Remove 删除指定批次
+func (o *outboxImpl) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, batch := range o.batches {
+		if batch.ID == id {
+			o.batches = append(o.batches[:i], o.batches[i+1:]...)
+			return o.delete(id)
+		}
+	}
+	return nil
+}
+
+// Len 返回批次数量
+func (o *outboxImpl) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.batches)
+}
+
+// collectedAt 返回结果中最早的采集时间，结果均未标注时间时返回当前时间
+func collectedAt(results []models.ConnectivityResult) time.Time {
+	var earliest time.Time
+	for _, result := range results {
+		if !result.Timestamp.IsZero() && (earliest.IsZero() || result.Timestamp.Before(earliest)) {
+			earliest = result.Timestamp
+		}
+	}
+	if earliest.IsZero() {
+		return time.Now()
+	}
+	return earliest
+}
+
+// load 加载目录中的批次，按ID（即创建顺序）排序，超出容量时删除最早的批次
+func (o *outboxImpl) load() error {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return fmt.Errorf("读取队列目录失败: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("读取队列文件失败: %w", err)
+		}
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil || batch.ID == "" {
+			// 损坏的文件无法重放，直接删除
+			os.Remove(filepath.Join(o.dir, entry.Name()))
+			continue
+		}
+		o.batches = append(o.batches, batch)
+	}
+
+	sort.Slice(o.batches, func(i, j int) bool {
+		return o.batches[i].ID < o.batches[j].ID
+	})
+	for len(o.batches) > o.capacity {
+		o.delete(o.batches[0].ID)
+		o.batches = o.batches[1:]
+	}
+	return nil
+}
+
+// write 将批次写入磁盘
+func (o *outboxImpl) write(batch Batch) error {
+	if o.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("序列化批次失败: %w", err)
+	}
+	if err := fileutil.WriteFile(filepath.Join(o.dir, batch.ID+".json"), data, 0o644); err != nil {
+		return fmt.Errorf("写入队列文件失败: %w", err)
+	}
+	return nil
+}
+
+// delete 删除批次文件
+func (o *outboxImpl) delete(id string) error {
+	if o.dir == "" {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(o.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除队列文件失败: %w", err)
+	}
+	return nil
+}
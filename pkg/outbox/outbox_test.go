@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// results 构造单个目标的测试结果
+func results(target string) []models.ConnectivityResult {
+	return []models.ConnectivityResult{{SourceIP: "10.0.0.1", TargetIP: target, PingStatus: "reachable"}}
+}
+
+// TestEnqueueOrderAndCapacity 测试先进先出与容量上限
+func TestEnqueueOrderAndCapacity(t *testing.T) {
+	o, err := New(2, "")
+	require.NoError(t, err)
+
+	for _, target := range []string{"10.0.0.2", "10.0.0.3", "10.0.0.4"} {
+		_, err := o.Enqueue(Batch{TestType: models.TestTypePod, Results: results(target)})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, o.Len())
+
+	// 最早的批次被丢弃
+	batch, ok := o.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.3", batch.Results[0].TargetIP)
+
+	require.NoError(t, o.Remove(batch.ID))
+	batch, ok = o.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.4", batch.Results[0].TargetIP)
+
+	require.NoError(t, o.Remove(batch.ID))
+	_, ok = o.Peek()
+	assert.False(t, ok)
+
+	_, err = New(0, "")
+	assert.Error(t, err)
+}
+
+// TestEnqueueCollectedAt 测试批次保留结果的采集时间
+func TestEnqueueCollectedAt(t *testing.T) {
+	o, err := New(2, "")
+	require.NoError(t, err)
+
+	collected := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	batchResults := append(results("10.0.0.2"), results("10.0.0.3")...)
+	batchResults[0].Timestamp = collected.Add(time.Second)
+	batchResults[1].Timestamp = collected
+	_, err = o.Enqueue(Batch{TestType: models.TestTypePod, Results: batchResults})
+	require.NoError(t, err)
+
+	batch, ok := o.Peek()
+	require.True(t, ok)
+	assert.Equal(t, collected, batch.CreatedAt)
+
+	// 未标注采集时间的结果使用批次的采集时间
+	_, err = o.Enqueue(Batch{TestType: models.TestTypeHost, Results: results("192.168.1.2"), CreatedAt: collected})
+	require.NoError(t, err)
+	require.NoError(t, o.Remove(batch.ID))
+	batch, ok = o.Peek()
+	require.True(t, ok)
+	assert.Equal(t, collected, batch.Results[0].Timestamp)
+}
+
+// TestEnqueueDeduplicatesRuns 测试同一任务的同类型结果只保留最新一批
+func TestEnqueueDeduplicatesRuns(t *testing.T) {
+	o, err := New(10, "")
+	require.NoError(t, err)
+
+	o.Enqueue(Batch{TestType: models.TestTypePod, RunID: "run-1", Results: results("10.0.0.2")})
+	o.Enqueue(Batch{TestType: models.TestTypeHost, RunID: "run-1", Results: results("192.168.1.2")})
+	o.Enqueue(Batch{TestType: models.TestTypePod, RunID: "run-1", Results: results("10.0.0.3")})
+	assert.Equal(t, 2, o.Len())
+
+	batch, _ := o.Peek()
+	assert.Equal(t, models.TestTypePod, batch.TestType)
+	assert.Equal(t, "10.0.0.3", batch.Results[0].TargetIP)
+}
+
+// TestPersistence 测试批次写入磁盘并在重启后加载
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	o, err := New(2, dir)
+	require.NoError(t, err)
+
+	o.Enqueue(Batch{TestType: models.TestTypePod, Results: results("10.0.0.2")})
+	o.Enqueue(Batch{TestType: models.TestTypeHost, Results: results("192.168.1.2")})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+
+	reloaded, err := New(2, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reloaded.Len())
+	batch, _ := reloaded.Peek()
+	assert.Equal(t, "10.0.0.2", batch.Results[0].TargetIP)
+	assert.NoFileExists(t, filepath.Join(dir, "broken.json"))
+
+	// 删除后文件同步删除
+	require.NoError(t, reloaded.Remove(batch.ID))
+	assert.NoFileExists(t, filepath.Join(dir, batch.ID+".json"))
+
+	// 容量缩小时只保留最新的批次
+	reloaded, err = New(1, dir)
+	require.NoError(t, err)
+	batch, _ = reloaded.Peek()
+	assert.Equal(t, models.TestTypeHost, batch.TestType)
+}
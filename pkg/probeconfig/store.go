@@ -0,0 +1,264 @@
+// Package probeconfig 管理由服务器统一下发的探测配置
+// 服务器持有带版本号的配置，客户端通过心跳获取新版本并通知探测组件实时生效
+package probeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Observer 探测配置变更观察者
+type Observer interface {
+	OnProbeConfig(cfg models.ProbeConfig)
+}
+
+// Store 定义探测配置存储接口
+type Store interface {
+	// Get 返回当前配置
+	Get() models.ProbeConfig
+
+	// Update 校验并替换配置，版本号递增，供服务器使用
+	// 版本号不小于当前 Unix 时间（秒），服务器重启后版本号仍然递增
+	Update(cfg models.ProbeConfig) (models.ProbeConfig, error)
+
+	// Apply 应用服务器下发的配置，版本号不高于当前版本时忽略，供客户端使用
+	// 版本号为0表示服务器未设置配置（例如重启后丢失了通过接口设置的配置），恢复为创建存储时的初始配置
+	// 返回配置是否发生变化
+	Apply(cfg models.ProbeConfig) (bool, error)
+
+	// AddObserver 注册配置变更观察者
+	AddObserver(observer Observer)
+}
+
+// storeImpl 是Store的实现
+type storeImpl struct {
+	mu        sync.RWMutex
+	initial   models.ProbeConfig
+	config    models.ProbeConfig
+	observers []Observer
+}
+
+// NewStore 使用初始配置创建存储，初始配置需通过校验
+func NewStore(initial models.ProbeConfig) (Store, error) {
+	if err := Validate(&initial); err != nil {
+		return nil, err
+	}
+	return &storeImpl{initial: clone(initial), config: initial}, nil
+}
+
+// Get 返回当前配置
+func (s *storeImpl) Get() models.ProbeConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return clone(s.config)
+}
+
+// Update 校验并替换配置
+func (s *storeImpl) Update(cfg models.ProbeConfig) (models.ProbeConfig, error) {
+	if err := Validate(&cfg); err != nil {
+		return models.ProbeConfig{}, err
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	cfg.Version = s.config.Version + 1
+	if cfg.Version < now.Unix() {
+		cfg.Version = now.Unix()
+	}
+	cfg.UpdatedAt = now
+	s.config = cfg
+	s.mu.Unlock()
+
+	log.Printf("探测配置已更新: version=%d", cfg.Version)
+	s.notifyObservers(cfg)
+	return clone(cfg), nil
+}
+
+// Apply 应用服务器下发的配置
+func (s *storeImpl) Apply(cfg models.ProbeConfig) (bool, error) {
+	if err := Validate(&cfg); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	switch {
+	case cfg.Version == 0 && s.config.Version != 0:
+		cfg = clone(s.initial)
+	case cfg.Version <= s.config.Version:
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.config = cfg
+	s.mu.Unlock()
+
+	s.notifyObservers(cfg)
+	return true, nil
+}
+
+// AddObserver 注册配置变更观察者
+func (s *storeImpl) AddObserver(observer Observer) {
+	if observer == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+// notifyObservers 通知所有观察者
+func (s *storeImpl) notifyObservers(cfg models.ProbeConfig) {
+	s.mu.RLock()
+	observers := make([]Observer, len(s.observers))
+	copy(observers, s.observers)
+	s.mu.RUnlock()
+
+	for _, observer := range observers {
+		observer.OnProbeConfig(clone(cfg))
+	}
+}
+
+// Default 返回默认探测配置，与客户端环境变量的默认值一致
+func Default() models.ProbeConfig {
+	return models.ProbeConfig{
+		TestInterval:   models.Duration(60 * time.Second),
+		TestTypes:      []string{models.TestTypeHost, models.TestTypePod, models.TestTypeService},
+		Protocols:      []string{models.ProtocolICMP, models.ProtocolTCP},
+		HostPorts:      []int{22},
+		PodPorts:       []int{6100},
+		ServicePorts:   []int{80},
+		MaxConcurrency: 10,
+		PingCount:      3,
+		Timeout:        models.Duration(5 * time.Second),
+		Jitter:         models.Duration(5 * time.Second),
+		PingTimeout:    models.Duration(10 * time.Second),
+		DNSTimeout:     models.Duration(5 * time.Second),
+	}
+}
+
+// Validate 校验配置，并为未设置的数值字段填充默认值
+func Validate(cfg *models.ProbeConfig) error {
+	defaults := Default()
+	if cfg.TestInterval <= 0 {
+		cfg.TestInterval = defaults.TestInterval
+	}
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaults.MaxConcurrency
+	}
+	if cfg.PingCount <= 0 {
+		cfg.PingCount = defaults.PingCount
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.PingTimeout <= 0 {
+		cfg.PingTimeout = defaults.PingTimeout
+	}
+	if cfg.DNSTimeout <= 0 {
+		cfg.DNSTimeout = defaults.DNSTimeout
+	}
+	if len(cfg.TestTypes) == 0 {
+		cfg.TestTypes = defaults.TestTypes
+	}
+	if len(cfg.Protocols) == 0 {
+		cfg.Protocols = defaults.Protocols
+	}
+
+	if time.Duration(cfg.TestInterval) < time.Second {
+		return fmt.Errorf("测试间隔不能小于1秒: %s", cfg.TestInterval)
+	}
+	for name, interval := range map[string]models.Duration{"host_interval": cfg.HostInterval, "pod_interval": cfg.PodInterval, "service_interval": cfg.ServiceInterval} {
+		if interval != 0 && time.Duration(interval) < time.Second {
+			return fmt.Errorf("%s 不能小于1秒: %s", name, interval)
+		}
+	}
+	if cfg.PingInterval < 0 || cfg.InitialDelay < 0 || cfg.Jitter < 0 {
+		return fmt.Errorf("ping_interval、initial_delay、jitter 不能为负数")
+	}
+	if cfg.RateLimit < 0 {
+		return fmt.Errorf("rate_limit 不能为负数: %g", cfg.RateLimit)
+	}
+	for _, testType := range cfg.TestTypes {
+		switch testType {
+		case models.TestTypeHost, models.TestTypePod, models.TestTypeService:
+		default:
+			return fmt.Errorf("不支持的测试类型: %s", testType)
+		}
+	}
+	for _, protocol := range cfg.Protocols {
+		switch protocol {
+		case models.ProtocolICMP, models.ProtocolTCP:
+		default:
+			return fmt.Errorf("不支持的探测协议: %s", protocol)
+		}
+	}
+
+	for name, ports := range map[string][]int{"host_ports": cfg.HostPorts, "pod_ports": cfg.PodPorts, "service_ports": cfg.ServicePorts} {
+		for _, port := range ports {
+			if port <= 0 || port > 65535 {
+				return fmt.Errorf("%s 中的端口无效: %d", name, port)
+			}
+		}
+	}
+
+	// 启用TCP时每种测试类型都需要探测端口
+	if cfg.HasProtocol(models.ProtocolTCP) {
+		if cfg.HasTestType(models.TestTypeHost) && len(cfg.HostPorts) == 0 {
+			return fmt.Errorf("启用tcp探测时host_ports不能为空")
+		}
+		if cfg.HasTestType(models.TestTypePod) && len(cfg.PodPorts) == 0 {
+			return fmt.Errorf("启用tcp探测时pod_ports不能为空")
+		}
+		if cfg.HasTestType(models.TestTypeService) && cfg.ServiceName != "" && len(cfg.ServicePorts) == 0 {
+			return fmt.Errorf("启用tcp探测时service_ports不能为空")
+		}
+	}
+	return nil
+}
+
+// LoadFile 从 YAML 或 JSON 文件加载配置，文件中未设置的字段使用默认值
+// 时间间隔使用 "60s"、"5s" 这样的格式
+func LoadFile(path string) (models.ProbeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.ProbeConfig{}, fmt.Errorf("读取探测配置文件失败: %w", err)
+	}
+
+	// YAML 是 JSON 的超集，先解析为通用结构，再按 JSON 字段名解码
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return models.ProbeConfig{}, fmt.Errorf("解析探测配置文件失败: %w", err)
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return models.ProbeConfig{}, fmt.Errorf("解析探测配置文件失败: %w", err)
+	}
+
+	cfg := Default()
+	if err := json.Unmarshal(encoded, &cfg); err != nil {
+		return models.ProbeConfig{}, fmt.Errorf("解析探测配置文件失败: %w", err)
+	}
+	if err := Validate(&cfg); err != nil {
+		return models.ProbeConfig{}, err
+	}
+	return cfg, nil
+}
+
+// clone 复制配置中的切片，避免调用方修改共享数据
+func clone(cfg models.ProbeConfig) models.ProbeConfig {
+	cfg.TestTypes = append([]string(nil), cfg.TestTypes...)
+	cfg.Protocols = append([]string(nil), cfg.Protocols...)
+	cfg.HostPorts = append([]int(nil), cfg.HostPorts...)
+	cfg.PodPorts = append([]int(nil), cfg.PodPorts...)
+	cfg.ServicePorts = append([]int(nil), cfg.ServicePorts...)
+	return cfg
+}
@@ -0,0 +1,127 @@
+package probeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver 记录收到的配置
+type recordingObserver struct {
+	configs []models.ProbeConfig
+}
+
+func (o *recordingObserver) OnProbeConfig(cfg models.ProbeConfig) {
+	o.configs = append(o.configs, cfg)
+}
+
+// TestValidate 测试配置校验与默认值填充
+func TestValidate(t *testing.T) {
+	cfg := models.ProbeConfig{HostPorts: []int{22}, PodPorts: []int{6100}}
+	require.NoError(t, Validate(&cfg))
+	assert.Equal(t, models.Duration(60*time.Second), cfg.TestInterval)
+	assert.Equal(t, 10, cfg.MaxConcurrency)
+	assert.Equal(t, []string{models.ProtocolICMP, models.ProtocolTCP}, cfg.Protocols)
+
+	invalid := []models.ProbeConfig{
+		{TestInterval: models.Duration(time.Millisecond), HostPorts: []int{22}, PodPorts: []int{6100}},
+		{TestTypes: []string{"udp"}, HostPorts: []int{22}, PodPorts: []int{6100}},
+		{Protocols: []string{"udp"}, HostPorts: []int{22}, PodPorts: []int{6100}},
+		{HostPorts: []int{70000}, PodPorts: []int{6100}},
+		{HostPorts: []int{22}},
+	}
+	for _, cfg := range invalid {
+		cfg := cfg
+		assert.Error(t, Validate(&cfg))
+	}
+
+	// 仅启用 ICMP 时不需要端口
+	icmpOnly := models.ProbeConfig{Protocols: []string{models.ProtocolICMP}}
+	assert.NoError(t, Validate(&icmpOnly))
+}
+
+// TestUpdateAndApply 测试服务器端更新递增版本，客户端只应用更新的版本
+func TestUpdateAndApply(t *testing.T) {
+	server, err := NewStore(Default())
+	require.NoError(t, err)
+
+	cfg := Default()
+	cfg.PodPorts = []int{6100, 8080}
+	updated, err := server.Update(cfg)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, updated.Version, time.Now().Unix()-1)
+	assert.False(t, updated.UpdatedAt.IsZero())
+
+	// 连续更新时版本号严格递增
+	second, err := server.Update(cfg)
+	require.NoError(t, err)
+	assert.Greater(t, second.Version, updated.Version)
+
+	_, err = server.Update(models.ProbeConfig{TestTypes: []string{"udp"}})
+	assert.Error(t, err)
+	assert.Equal(t, second.Version, server.Get().Version)
+
+	client, err := NewStore(Default())
+	require.NoError(t, err)
+	observer := &recordingObserver{}
+	client.AddObserver(observer)
+
+	changed, err := client.Apply(server.Get())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []int{6100, 8080}, client.Get().PodPorts)
+
+	// 相同或更旧的版本被忽略
+	changed, err = client.Apply(server.Get())
+	require.NoError(t, err)
+	assert.False(t, changed)
+	require.Len(t, observer.configs, 1)
+	assert.Equal(t, second.Version, observer.configs[0].Version)
+
+	// 修改返回值不影响存储中的配置
+	current := client.Get()
+	current.PodPorts[0] = 1
+	assert.Equal(t, 6100, client.Get().PodPorts[0])
+
+	// 服务器未设置配置时恢复初始配置
+	restarted, err := NewStore(Default())
+	require.NoError(t, err)
+	changed, err = client.Apply(restarted.Get())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, int64(0), client.Get().Version)
+	assert.Equal(t, Default().PodPorts, client.Get().PodPorts)
+	require.Len(t, observer.configs, 2)
+
+	changed, err = client.Apply(restarted.Get())
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+// TestLoadFile 测试从 YAML 文件加载配置
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probe.yaml")
+	content := `test_interval: 30s
+protocols: [tcp]
+pod_ports: [6100, 9090]
+timeout: 2s
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, models.Duration(30*time.Second), cfg.TestInterval)
+	assert.Equal(t, []string{models.ProtocolTCP}, cfg.Protocols)
+	assert.Equal(t, []int{6100, 9090}, cfg.PodPorts)
+	assert.Equal(t, []int{22}, cfg.HostPorts)
+	assert.Equal(t, models.Duration(2*time.Second), cfg.Timeout)
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
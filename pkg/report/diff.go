@@ -0,0 +1,196 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// DiffOptions 报告对比选项
+type DiffOptions struct {
+	// LatencyThreshold 延迟增加超过该值才视为延迟劣化
+	LatencyThreshold time.Duration
+
+	// LatencyRatio 延迟变为原来的该倍数及以上才视为延迟劣化，<= 1 时不检查倍数
+	LatencyRatio float64
+}
+
+// DefaultDiffOptions 默认对比选项
+var DefaultDiffOptions = DiffOptions{
+	LatencyThreshold: 100 * time.Millisecond,
+	LatencyRatio:     1.5,
+}
+
+// 延迟劣化比较的指标
+const (
+	MetricLatency      = "latency"       // ping 延迟
+	MetricTestDuration = "test_duration" // 探测耗时，前后任一报告没有 ping 延迟时使用
+)
+
+// LatencyChange 探测对延迟变化
+type LatencyChange struct {
+	TestType string          `json:"test_type"`
+	SourceIP string          `json:"source_ip"`
+	TargetIP string          `json:"target_ip"`
+	Metric   string          `json:"metric"` // 比较的指标: latency 或 test_duration
+	Before   models.Duration `json:"before"`
+	After    models.Duration `json:"after"`
+	Increase models.Duration `json:"increase"`
+}
+
+// ReportDiff 两份报告之间的差异
+type ReportDiff struct {
+	FromID        string    `json:"from_id,omitempty"`
+	ToID          string    `json:"to_id,omitempty"`
+	FromTimestamp time.Time `json:"from_timestamp"`
+	ToTimestamp   time.Time `json:"to_timestamp"`
+
+	NewlyFailing []models.PairResult `json:"newly_failing"` // 之前成功（或不存在）现在失败的探测对
+	Recovered    []models.PairResult `json:"recovered"`     // 之前失败现在成功的探测对
+	RemovedPairs []models.PairResult `json:"removed_pairs"` // 之前存在现在不存在的探测对
+
+	AddedHosts   []string `json:"added_hosts"`
+	RemovedHosts []string `json:"removed_hosts"`
+	AddedPods    []string `json:"added_pods"`
+	RemovedPods  []string `json:"removed_pods"`
+
+	LatencyRegressions []LatencyChange `json:"latency_regressions"`
+
+	HostSuccessRateDelta float64 `json:"host_success_rate_delta"`
+	PodSuccessRateDelta  float64 `json:"pod_success_rate_delta"`
+}
+
+// HasChanges 判断是否存在需要关注的变化
+func (d *ReportDiff) HasChanges() bool {
+	return len(d.NewlyFailing) > 0 || len(d.Recovered) > 0 || len(d.RemovedPairs) > 0 ||
+		len(d.AddedHosts) > 0 || len(d.RemovedHosts) > 0 ||
+		len(d.AddedPods) > 0 || len(d.RemovedPods) > 0 ||
+		len(d.LatencyRegressions) > 0
+}
+
+// pairKey 探测对的唯一标识
+type pairKey struct {
+	testType string
+	sourceIP string
+	targetIP string
+}
+
+// Diff 对比两份报告，from 为较早的报告，to 为较新的报告
+func Diff(from, to *models.NetworkReport, opts DiffOptions) *ReportDiff {
+	diff := &ReportDiff{
+		FromID:             from.ID,
+		ToID:               to.ID,
+		FromTimestamp:      from.Timestamp,
+		ToTimestamp:        to.Timestamp,
+		NewlyFailing:       []models.PairResult{},
+		Recovered:          []models.PairResult{},
+		RemovedPairs:       []models.PairResult{},
+		LatencyRegressions: []LatencyChange{},
+
+		HostSuccessRateDelta: to.HostTestSummary.SuccessRate - from.HostTestSummary.SuccessRate,
+		PodSuccessRateDelta:  to.PodTestSummary.SuccessRate - from.PodTestSummary.SuccessRate,
+	}
+
+	before := make(map[pairKey]models.PairResult, len(from.Pairs))
+	for _, pair := range from.Pairs {
+		before[pairKey{pair.TestType, pair.SourceIP, pair.TargetIP}] = pair
+	}
+
+	seen := make(map[pairKey]bool, len(to.Pairs))
+	for _, pair := range to.Pairs {
+		key := pairKey{pair.TestType, pair.SourceIP, pair.TargetIP}
+		seen[key] = true
+
+		old, existed := before[key]
+		switch {
+		case !pair.Success && (!existed || old.Success):
+			diff.NewlyFailing = append(diff.NewlyFailing, pair)
+		case pair.Success && existed && !old.Success:
+			diff.Recovered = append(diff.Recovered, pair)
+		}
+
+		// 仅对前后都成功的探测对比较延迟，失败探测的耗时受超时影响没有参考意义
+		if !existed || !old.Success || !pair.Success {
+			continue
+		}
+		metric, before, after := latencyOf(old, pair)
+		if isLatencyRegression(before, after, opts) {
+			diff.LatencyRegressions = append(diff.LatencyRegressions, LatencyChange{
+				TestType: pair.TestType,
+				SourceIP: pair.SourceIP,
+				TargetIP: pair.TargetIP,
+				Metric:   metric,
+				Before:   before,
+				After:    after,
+				Increase: after - before,
+			})
+		}
+	}
+
+	for _, pair := range from.Pairs {
+		if !seen[pairKey{pair.TestType, pair.SourceIP, pair.TargetIP}] {
+			diff.RemovedPairs = append(diff.RemovedPairs, pair)
+		}
+	}
+	sortPairs(diff.RemovedPairs)
+
+	sort.Slice(diff.LatencyRegressions, func(i, j int) bool {
+		return diff.LatencyRegressions[i].Increase > diff.LatencyRegressions[j].Increase
+	})
+
+	diff.AddedHosts, diff.RemovedHosts = diffStrings(from.HostIPs, to.HostIPs)
+	diff.AddedPods, diff.RemovedPods = diffStrings(from.PodIPs, to.PodIPs)
+
+	return diff
+}
+
+// latencyOf 返回比较延迟使用的指标与前后的值
+// 优先使用 ping 延迟；探测耗时包含端口探测与超时等待，只在前后任一报告没有 ping 延迟时使用
+func latencyOf(before, after models.PairResult) (string, models.Duration, models.Duration) {
+	if before.Latency > 0 && after.Latency > 0 {
+		return MetricLatency, before.Latency, after.Latency
+	}
+	return MetricTestDuration, before.TestDuration, after.TestDuration
+}
+
+// isLatencyRegression 判断延迟是否劣化
+func isLatencyRegression(before, after models.Duration, opts DiffOptions) bool {
+	increase := time.Duration(after - before)
+	if increase <= 0 || increase < opts.LatencyThreshold {
+		return false
+	}
+	if opts.LatencyRatio > 1 && float64(after) < float64(before)*opts.LatencyRatio {
+		return false
+	}
+	return true
+}
+
+// diffStrings 返回新增和移除的元素，结果已排序
+func diffStrings(before, after []string) (added, removed []string) {
+	beforeSet := make(map[string]bool, len(before))
+	for _, s := range before {
+		beforeSet[s] = true
+	}
+	afterSet := make(map[string]bool, len(after))
+	for _, s := range after {
+		afterSet[s] = true
+	}
+
+	added = []string{}
+	for _, s := range after {
+		if !beforeSet[s] {
+			added = append(added, s)
+		}
+	}
+	removed = []string{}
+	for _, s := range before {
+		if !afterSet[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DiffRenderer 定义报告差异渲染器接口
+// 并非所有报告格式都支持差异输出，目前支持 text、json、yaml、markdown
+type DiffRenderer interface {
+	Renderer
+
+	// RenderDiff 将报告差异渲染到w
+	RenderDiff(w io.Writer, diff *ReportDiff) error
+}
+
+// NewDiffRenderer 根据格式名称获取差异渲染器
+func NewDiffRenderer(format string) (DiffRenderer, error) {
+	renderer, err := NewRenderer(format)
+	if err != nil {
+		return nil, err
+	}
+	return asDiffRenderer(renderer)
+}
+
+// NegotiateDiffRenderer 与 NegotiateRenderer 相同的规则选择差异渲染器
+func NegotiateDiffRenderer(format, accept string) (DiffRenderer, error) {
+	renderer, err := NegotiateRenderer(format, accept)
+	if err != nil {
+		return nil, err
+	}
+	return asDiffRenderer(renderer)
+}
+
+// asDiffRenderer 检查渲染器是否支持差异输出
+func asDiffRenderer(renderer Renderer) (DiffRenderer, error) {
+	diffRenderer, ok := renderer.(DiffRenderer)
+	if !ok {
+		return nil, fmt.Errorf("报告格式 %s 不支持差异输出", renderer.Format())
+	}
+	return diffRenderer, nil
+}
+
+// RenderDiff 输出缩进的JSON
+func (r *jsonRenderer) RenderDiff(w io.Writer, diff *ReportDiff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diff)
+}
+
+// RenderDiff 输出YAML
+func (r *yamlRenderer) RenderDiff(w io.Writer, diff *ReportDiff) error {
+	return renderYAML(w, diff)
+}
+
+// RenderDiff 输出Markdown
+func (r *markdownRenderer) RenderDiff(w io.Writer, diff *ReportDiff) error {
+	var b strings.Builder
+
+	b.WriteString("# 网络连通性报告对比\n\n")
+	fmt.Fprintf(&b, "- 对比区间: %s → %s\n", diff.FromTimestamp.Format("2006-01-02 15:04:05"), diff.ToTimestamp.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 宿主机成功率变化: %+.2f%%\n", diff.HostSuccessRateDelta)
+	fmt.Fprintf(&b, "- Pod成功率变化: %+.2f%%\n\n", diff.PodSuccessRateDelta)
+
+	fmt.Fprintf(&b, "## 新增失败 (共%d个)\n\n", len(diff.NewlyFailing))
+	if len(diff.NewlyFailing) == 0 {
+		b.WriteString("无\n\n")
+	} else {
+		writeMarkdownPairs(&b, diff.NewlyFailing)
+	}
+
+	fmt.Fprintf(&b, "## 已恢复 (共%d个)\n\n", len(diff.Recovered))
+	if len(diff.Recovered) == 0 {
+		b.WriteString("无\n\n")
+	} else {
+		writeMarkdownPairs(&b, diff.Recovered)
+	}
+
+	fmt.Fprintf(&b, "## 延迟劣化 (共%d个)\n\n", len(diff.LatencyRegressions))
+	if len(diff.LatencyRegressions) == 0 {
+		b.WriteString("无\n\n")
+	} else {
+		b.WriteString("| 类型 | 源 | 目标 | 指标 | 之前 | 之后 | 增加 |\n")
+		b.WriteString("|------|----|------|------|------|------|------|\n")
+		for _, change := range diff.LatencyRegressions {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+				change.TestType, change.SourceIP, change.TargetIP, change.Metric, change.Before, change.After, change.Increase)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## 客户端变化\n\n")
+	fmt.Fprintf(&b, "- 新增宿主机: %s\n", joinOrNone(diff.AddedHosts))
+	fmt.Fprintf(&b, "- 移除宿主机: %s\n", joinOrNone(diff.RemovedHosts))
+	fmt.Fprintf(&b, "- 新增Pod: %s\n", joinOrNone(diff.AddedPods))
+	fmt.Fprintf(&b, "- 移除Pod: %s\n", joinOrNone(diff.RemovedPods))
+	fmt.Fprintf(&b, "- 不再探测的探测对: %d个\n", len(diff.RemovedPairs))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderDiff 输出控制台文本
+func (r *textRenderer) RenderDiff(w io.Writer, diff *ReportDiff) error {
+	var b strings.Builder
+
+	b.WriteString("\n" + strings.Repeat("=", 80) + "\n")
+	b.WriteString("网络连通性报告对比\n")
+	b.WriteString(strings.Repeat("=", 80) + "\n")
+	fmt.Fprintf(&b, "对比区间: %s -> %s\n", diff.FromTimestamp.Format("2006-01-02 15:04:05"), diff.ToTimestamp.Format("2006-01-02 15:04:05"))
+	b.WriteString(strings.Repeat("-", 80) + "\n")
+	fmt.Fprintf(&b, "宿主机成功率变化: %+.2f%%\n", diff.HostSuccessRateDelta)
+	fmt.Fprintf(&b, "Pod成功率变化: %+.2f%%\n\n", diff.PodSuccessRateDelta)
+
+	fmt.Fprintf(&b, "新增失败 (共%d个):\n", len(diff.NewlyFailing))
+	for _, pair := range diff.NewlyFailing {
+		fmt.Fprintf(&b, "  [%s] %s -> %s (ping=%s, port=%s)\n", pair.TestType, pair.SourceIP, pair.TargetIP, pair.Ping, pair.PortStatus)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "已恢复 (共%d个):\n", len(diff.Recovered))
+	for _, pair := range diff.Recovered {
+		fmt.Fprintf(&b, "  [%s] %s -> %s\n", pair.TestType, pair.SourceIP, pair.TargetIP)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "延迟劣化 (共%d个):\n", len(diff.LatencyRegressions))
+	for _, change := range diff.LatencyRegressions {
+		fmt.Fprintf(&b, "  [%s] %s -> %s: %s %s -> %s (+%s)\n",
+			change.TestType, change.SourceIP, change.TargetIP, change.Metric, change.Before, change.After, change.Increase)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "新增宿主机: %s\n", joinOrNone(diff.AddedHosts))
+	fmt.Fprintf(&b, "移除宿主机: %s\n", joinOrNone(diff.RemovedHosts))
+	fmt.Fprintf(&b, "新增Pod: %s\n", joinOrNone(diff.AddedPods))
+	fmt.Fprintf(&b, "移除Pod: %s\n", joinOrNone(diff.RemovedPods))
+	b.WriteString(strings.Repeat("=", 80) + "\n\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// joinOrNone 拼接列表，为空时返回"无"
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "无"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pair 构造测试用探测对
+func pair(testType, source, target string, success bool, duration time.Duration) models.PairResult {
+	p := models.PairResult{
+		TestType:     testType,
+		SourceIP:     source,
+		TargetIP:     target,
+		Ping:         "reachable",
+		PortStatus:   "open",
+		TestDuration: models.Duration(duration),
+		Success:      success,
+	}
+	if !success {
+		p.Ping = "unreachable"
+	}
+	return p
+}
+
+// TestDiff 测试报告对比
+func TestDiff(t *testing.T) {
+	from := &models.NetworkReport{
+		ID:              "a",
+		HostIPs:         []string{"192.168.1.1", "192.168.1.2"},
+		PodIPs:          []string{"10.0.0.1", "10.0.0.2"},
+		HostTestSummary: models.TestSummary{SuccessRate: 100},
+		Pairs: []models.PairResult{
+			pair(models.TestTypeHost, "192.168.1.1", "192.168.1.2", true, 10*time.Millisecond),
+			pair(models.TestTypePod, "10.0.0.1", "10.0.0.2", false, time.Second),
+			pair(models.TestTypePod, "10.0.0.2", "10.0.0.1", true, 10*time.Millisecond),
+			pair(models.TestTypePod, "10.0.0.2", "10.0.0.9", true, 10*time.Millisecond),
+		},
+	}
+	to := &models.NetworkReport{
+		ID:              "b",
+		HostIPs:         []string{"192.168.1.1", "192.168.1.3"},
+		PodIPs:          []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		HostTestSummary: models.TestSummary{SuccessRate: 50},
+		Pairs: []models.PairResult{
+			pair(models.TestTypeHost, "192.168.1.1", "192.168.1.2", true, 500*time.Millisecond),
+			pair(models.TestTypePod, "10.0.0.1", "10.0.0.2", true, 10*time.Millisecond),
+			pair(models.TestTypePod, "10.0.0.2", "10.0.0.1", false, time.Second),
+			pair(models.TestTypePod, "10.0.0.3", "10.0.0.1", false, time.Second),
+		},
+	}
+
+	diff := Diff(from, to, DefaultDiffOptions)
+
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, "a", diff.FromID)
+	assert.Equal(t, -50.0, diff.HostSuccessRateDelta)
+
+	require.Len(t, diff.NewlyFailing, 2)
+	assert.Equal(t, "10.0.0.2", diff.NewlyFailing[0].SourceIP)
+	assert.Equal(t, "10.0.0.3", diff.NewlyFailing[1].SourceIP)
+
+	require.Len(t, diff.Recovered, 1)
+	assert.Equal(t, "10.0.0.1", diff.Recovered[0].SourceIP)
+
+	require.Len(t, diff.RemovedPairs, 1)
+	assert.Equal(t, "10.0.0.9", diff.RemovedPairs[0].TargetIP)
+
+	require.Len(t, diff.LatencyRegressions, 1)
+	assert.Equal(t, MetricTestDuration, diff.LatencyRegressions[0].Metric)
+	assert.Equal(t, models.Duration(490*time.Millisecond), diff.LatencyRegressions[0].Increase)
+
+	assert.Equal(t, []string{"192.168.1.3"}, diff.AddedHosts)
+	assert.Equal(t, []string{"192.168.1.2"}, diff.RemovedHosts)
+	assert.Equal(t, []string{"10.0.0.3"}, diff.AddedPods)
+	assert.Empty(t, diff.RemovedPods)
+}
+
+// TestDiffLatencyThreshold 测试延迟劣化阈值
+func TestDiffLatencyThreshold(t *testing.T) {
+	from := &models.NetworkReport{Pairs: []models.PairResult{
+		pair(models.TestTypeHost, "a", "b", true, 100*time.Millisecond),
+	}}
+	to := &models.NetworkReport{Pairs: []models.PairResult{
+		pair(models.TestTypeHost, "a", "b", true, 220*time.Millisecond),
+	}}
+
+	// 增加120ms超过阈值且达到1.5倍
+	assert.Len(t, Diff(from, to, DefaultDiffOptions).LatencyRegressions, 1)
+
+	// 阈值提高后不视为劣化
+	assert.Empty(t, Diff(from, to, DiffOptions{LatencyThreshold: 200 * time.Millisecond}).LatencyRegressions)
+
+	// 倍数不足
+	assert.Empty(t, Diff(from, to, DiffOptions{LatencyThreshold: 50 * time.Millisecond, LatencyRatio: 3}).LatencyRegressions)
+
+	// 无变化
+	assert.False(t, Diff(from, from, DefaultDiffOptions).HasChanges())
+}
+
+// TestDiffPingLatency 测试优先比较 ping 延迟，探测耗时只在没有 ping 延迟时使用
+func TestDiffPingLatency(t *testing.T) {
+	withLatency := func(duration, latency time.Duration) models.PairResult {
+		p := pair(models.TestTypePod, "a", "b", true, duration)
+		p.Latency = models.Duration(latency)
+		return p
+	}
+
+	// 探测耗时因端口探测增加，ping 延迟不变时不视为劣化
+	from := &models.NetworkReport{Pairs: []models.PairResult{withLatency(100*time.Millisecond, time.Millisecond)}}
+	to := &models.NetworkReport{Pairs: []models.PairResult{withLatency(500*time.Millisecond, time.Millisecond)}}
+	assert.Empty(t, Diff(from, to, DefaultDiffOptions).LatencyRegressions)
+
+	// ping 延迟劣化
+	to.Pairs[0] = withLatency(100*time.Millisecond, 200*time.Millisecond)
+	regressions := Diff(from, to, DefaultDiffOptions).LatencyRegressions
+	require.Len(t, regressions, 1)
+	assert.Equal(t, MetricLatency, regressions[0].Metric)
+	assert.Equal(t, models.Duration(199*time.Millisecond), regressions[0].Increase)
+
+	// 之前的报告没有 ping 延迟时比较探测耗时
+	from.Pairs[0].Latency = 0
+	to.Pairs[0] = withLatency(500*time.Millisecond, time.Millisecond)
+	regressions = Diff(from, to, DefaultDiffOptions).LatencyRegressions
+	require.Len(t, regressions, 1)
+	assert.Equal(t, MetricTestDuration, regressions[0].Metric)
+}
+
+// TestDiffRenderers 测试差异渲染
+func TestDiffRenderers(t *testing.T) {
+	from := newTestReport()
+	to := newTestReport()
+	to.Pairs[1].Success = true
+	to.Pairs[1].Ping = "reachable"
+	diff := Diff(from, to, DefaultDiffOptions)
+
+	for _, format := range []string{FormatText, FormatJSON, FormatYAML, FormatMarkdown} {
+		renderer, err := NewDiffRenderer(format)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, renderer.RenderDiff(&buf, diff))
+		assert.Contains(t, buf.String(), "10.0.0.2", format)
+	}
+
+	_, err := NewDiffRenderer(FormatCSV)
+	assert.Error(t, err)
+}
@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/client"
@@ -24,21 +25,38 @@ type ReportGenerator interface {
 
 	// GenerateReport 生成网络连通性报告
 	GenerateReport() (*models.NetworkReport, error)
+
+	// AddEnricher 添加报告补充内容的来源，每次生成报告时依次调用
+	AddEnricher(enricher Enricher)
+}
+
+// Enricher 定义在报告生成后补充内容的接口，例如可用性统计
+type Enricher interface {
+	Enrich(report *models.NetworkReport)
 }
 
 // reportGeneratorImpl 是ReportGenerator的实现
 type reportGeneratorImpl struct {
 	clientManager client.ClientManager
 	resultManager result.TestResultManager
+	outputs       []Output
+	enrichers     []Enricher
+	mu            sync.RWMutex
 	stopChan      chan struct{}
 	running       bool
 }
 
 // NewReportGenerator 创建一个新的ReportGenerator实例
-func NewReportGenerator(clientManager client.ClientManager, resultManager result.TestResultManager) ReportGenerator {
+// outputs 为定期报告的输出目标，未指定时以文本格式输出到控制台
+func NewReportGenerator(clientManager client.ClientManager, resultManager result.TestResultManager, outputs ...Output) ReportGenerator {
+	if len(outputs) == 0 {
+		outputs = []Output{NewWriterOutput(os.Stdout, &textRenderer{})}
+	}
+
 	return &reportGeneratorImpl{
 		clientManager: clientManager,
 		resultManager: resultManager,
+		outputs:       outputs,
 		stopChan:      make(chan struct{}),
 		running:       false,
 	}
@@ -78,8 +96,8 @@ func (rg *reportGeneratorImpl) Start(ctx context.Context, interval time.Duration
 					continue
 				}
 
-				// 格式化输出到控制台
-				rg.printReport(report)
+				// 输出到所有配置的目标
+				rg.writeOutputs(report)
 			}
 		}
 	}()
@@ -102,6 +120,7 @@ func (rg *reportGeneratorImpl) Stop() error {
 func (rg *reportGeneratorImpl) GenerateReport() (*models.NetworkReport, error) {
 	report := &models.NetworkReport{
 		Timestamp: time.Now(),
+		Pairs:     []models.PairResult{},
 	}
 
 	// 获取活跃客户端数量
@@ -135,6 +154,7 @@ func (rg *reportGeneratorImpl) GenerateReport() (*models.NetworkReport, error) {
 		hostTestResults = make(models.HostTestResults)
 	}
 	report.HostTestSummary = rg.calculateTestSummary(hostTestResults)
+	report.Pairs = append(report.Pairs, rg.collectPairs(models.TestTypeHost, hostTestResults)...)
 
 	// 获取Pod测试结果并生成统计
 	podTestResults, err := rg.resultManager.GetPodTestResults()
@@ -143,6 +163,7 @@ func (rg *reportGeneratorImpl) GenerateReport() (*models.NetworkReport, error) {
 		podTestResults = make(models.PodTestResults)
 	}
 	report.PodTestSummary = rg.calculateTestSummary(podTestResults)
+	report.Pairs = append(report.Pairs, rg.collectPairs(models.TestTypePod, podTestResults)...)
 
 	// 获取自定义服务测试结果并生成统计
 	serviceTestResults, err := rg.resultManager.GetServiceTestResults()
@@ -151,10 +172,100 @@ func (rg *reportGeneratorImpl) GenerateReport() (*models.NetworkReport, error) {
 		serviceTestResults = make(models.ServiceTestResults)
 	}
 	report.ServiceTestSummary = rg.calculateServiceTestSummary(serviceTestResults)
+	report.Pairs = append(report.Pairs, rg.collectServicePairs(serviceTestResults)...)
+
+	rg.mu.RLock()
+	enrichers := rg.enrichers
+	rg.mu.RUnlock()
+	for _, enricher := range enrichers {
+		enricher.Enrich(report)
+	}
 
 	return report, nil
 }
 
+// AddEnricher 添加报告补充内容的来源
+func (rg *reportGeneratorImpl) AddEnricher(enricher Enricher) {
+	if enricher == nil {
+		return
+	}
+
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	rg.enrichers = append(rg.enrichers, enricher)
+}
+
+// writeOutputs 将报告写入所有输出目标，单个目标失败不影响其他目标
+func (rg *reportGeneratorImpl) writeOutputs(report *models.NetworkReport) {
+	for _, output := range rg.outputs {
+		if err := output.Write(report); err != nil {
+			log.Printf("输出报告失败: %v", err)
+		}
+	}
+}
+
+// collectPairs 将宿主机或Pod测试结果展开为探测对列表，按源IP、目标IP排序
+func (rg *reportGeneratorImpl) collectPairs(testType string, results map[string]map[string]models.TestStatus) []models.PairResult {
+	pairs := make([]models.PairResult, 0)
+	for sourceIP, targets := range results {
+		for targetIP, status := range targets {
+			pair := models.PairResult{
+				TestType:     testType,
+				SourceIP:     sourceIP,
+				TargetIP:     targetIP,
+				Ping:         status.Ping,
+				PortStatus:   status.PortStatus,
+				TestDuration: status.TestDuration,
+				Latency:      status.Latency,
+				Success:      status.Succeeded(),
+			}
+			if health := status.Health; health != nil {
+				pair.State = health.State
+				pair.DownSince = health.DownSince
+				pair.ConsecutiveFailures = health.ConsecutiveFailures
+				pair.FlapCount = health.FlapCount
+				if health.State == models.PairStateRecovered {
+					pair.RecoveredAt = health.RecoveredAt
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sortPairs(pairs)
+	return pairs
+}
+
+// collectServicePairs 将自定义服务测试结果展开为探测对列表
+func (rg *reportGeneratorImpl) collectServicePairs(results models.ServiceTestResults) []models.PairResult {
+	pairs := make([]models.PairResult, 0, len(results))
+	for sourceIP, result := range results {
+		pairs = append(pairs, models.PairResult{
+			TestType:     models.TestTypeService,
+			SourceIP:     sourceIP,
+			TargetIP:     result.TargetIP,
+			Ping:         result.PingStatus,
+			PortStatus:   result.PortSummary(),
+			TestDuration: result.TestDuration,
+			Latency:      result.Latency,
+			Success:      result.Succeeded(models.TestTypeService),
+		})
+	}
+
+	sortPairs(pairs)
+	return pairs
+}
+
+// sortPairs 按源IP、目标IP排序，保证输出稳定
+func sortPairs(pairs []models.PairResult) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].SourceIP != pairs[j].SourceIP {
+			return pairs[i].SourceIP < pairs[j].SourceIP
+		}
+		return pairs[i].TargetIP < pairs[j].TargetIP
+	})
+}
+
 // calculateTestSummary 计算测试统计信息
 // 适用于HostTestResults和PodTestResults
 func (rg *reportGeneratorImpl) calculateTestSummary(results map[string]map[string]models.TestStatus) models.TestSummary {
@@ -173,12 +284,22 @@ func (rg *reportGeneratorImpl) calculateTestSummary(results map[string]map[strin
 			summary.TotalTests++
 			summary.TotalTestDuration += status.TestDuration
 
-			// 判断测试是否成功：ping可达且端口开放
-			if status.Ping == "reachable" && status.PortStatus == "open" {
+			// 判断测试是否成功：有状态记录时按有效状态，否则ping可达且端口开放
+			if succeeded(status) {
 				summary.SuccessfulTests++
 			} else {
 				summary.FailedTests++
 			}
+			if status.Health != nil {
+				switch status.Health.State {
+				case models.PairStateDown:
+					summary.DownPairs++
+				case models.PairStateFlapping:
+					summary.FlappingPairs++
+				case models.PairStateRecovered:
+					summary.RecoveredPairs++
+				}
+			}
 		}
 	}
 
@@ -191,6 +312,15 @@ func (rg *reportGeneratorImpl) calculateTestSummary(results map[string]map[strin
 	return summary
 }
 
+// succeeded 判断探测对是否成功
+// 有状态记录时按有效状态判断，偶发的单次失败不计为失败，down 与 flapping 计为失败
+func succeeded(status models.TestStatus) bool {
+	if status.Health != nil {
+		return !status.Health.Failing()
+	}
+	return status.Succeeded()
+}
+
 // calculateServiceTestSummary 计算自定义服务测试统计信息
 func (rg *reportGeneratorImpl) calculateServiceTestSummary(results models.ServiceTestResults) models.ServiceTestSummary {
 	summary := models.ServiceTestSummary{
@@ -211,7 +341,7 @@ func (rg *reportGeneratorImpl) calculateServiceTestSummary(results models.Servic
 		}
 
 		// 判断测试是否成功：ping可达
-		if result.PingStatus == "reachable" {
+		if result.Succeeded(models.TestTypeService) {
 			summary.SuccessfulTests++
 		} else {
 			summary.FailedTests++
@@ -226,79 +356,6 @@ func (rg *reportGeneratorImpl) calculateServiceTestSummary(results models.Servic
 	return summary
 }
 
-// printReport 格式化输出报告到控制台
-func (rg *reportGeneratorImpl) printReport(report *models.NetworkReport) {
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("网络连通性报告")
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("生成时间: %s\n", report.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Println(strings.Repeat("-", 80))
-
-	// 活跃客户端信息
-	fmt.Printf("活跃客户端数量: %d\n", report.ActiveClientCount)
-	fmt.Println()
-
-	// 宿主机IP列表
-	fmt.Printf("宿主机IP列表 (共%d个):\n", len(report.HostIPs))
-	if len(report.HostIPs) > 0 {
-		for i, ip := range report.HostIPs {
-			fmt.Printf("  %d. %s\n", i+1, ip)
-		}
-	} else {
-		fmt.Println("  无")
-	}
-	fmt.Println()
-
-	// Pod IP列表
-	fmt.Printf("Pod IP列表 (共%d个):\n", len(report.PodIPs))
-	if len(report.PodIPs) > 0 {
-		for i, ip := range report.PodIPs {
-			fmt.Printf("  %d. %s\n", i+1, ip)
-		}
-	} else {
-		fmt.Println("  无")
-	}
-	fmt.Println()
-
-	// 宿主机测试统计
-	fmt.Println("宿主机连通性测试统计:")
-	fmt.Printf("  总测试数: %d\n", report.HostTestSummary.TotalTests)
-	fmt.Printf("  成功: %d\n", report.HostTestSummary.SuccessfulTests)
-	fmt.Printf("  失败: %d\n", report.HostTestSummary.FailedTests)
-	fmt.Printf("  成功率: %.2f%%\n", report.HostTestSummary.SuccessRate)
-	if report.HostTestSummary.TotalTests > 0 {
-		fmt.Printf("  平均耗时: %v\n", report.HostTestSummary.AvgTestDuration)
-		fmt.Printf("  总耗时: %v\n", report.HostTestSummary.TotalTestDuration)
-	}
-	fmt.Println()
-
-	// Pod测试统计
-	fmt.Println("Pod连通性测试统计:")
-	fmt.Printf("  总测试数: %d\n", report.PodTestSummary.TotalTests)
-	fmt.Printf("  成功: %d\n", report.PodTestSummary.SuccessfulTests)
-	fmt.Printf("  失败: %d\n", report.PodTestSummary.FailedTests)
-	fmt.Printf("  成功率: %.2f%%\n", report.PodTestSummary.SuccessRate)
-	if report.PodTestSummary.TotalTests > 0 {
-		fmt.Printf("  平均耗时: %v\n", report.PodTestSummary.AvgTestDuration)
-		fmt.Printf("  总耗时: %v\n", report.PodTestSummary.TotalTestDuration)
-	}
-	fmt.Println()
-
-	// 自定义服务测试统计
-	if report.ServiceTestSummary.TotalTests > 0 {
-		fmt.Println("自定义服务连通性测试统计:")
-		fmt.Printf("  服务名称: %s\n", report.ServiceTestSummary.ServiceName)
-		fmt.Printf("  总测试数: %d\n", report.ServiceTestSummary.TotalTests)
-		fmt.Printf("  成功: %d\n", report.ServiceTestSummary.SuccessfulTests)
-		fmt.Printf("  失败: %d\n", report.ServiceTestSummary.FailedTests)
-		fmt.Printf("  成功率: %.2f%%\n", report.ServiceTestSummary.SuccessRate)
-		fmt.Println()
-	}
-
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Println()
-}
-
 // GetReportIntervalFromEnv 从环境变量获取报告生成间隔
 // 默认300秒（5分钟）
 func GetReportIntervalFromEnv() time.Duration {
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yezihack/k8snet-checker/pkg/client"
 	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/result"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,6 +39,15 @@ func (m *MockClientManager) GetAllPodIPs() ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockClientManager) GetAllClients() (map[string]*models.ClientRecord, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]*models.ClientRecord), args.Error(1)
+}
+
+func (m *MockClientManager) AddObserver(observer client.HeartbeatObserver) {
+	m.Called(observer)
+}
+
 // MockTestResultManager 是TestResultManager的mock实现
 type MockTestResultManager struct {
 	mock.Mock
@@ -72,6 +83,18 @@ func (m *MockTestResultManager) GetServiceTestResults() (models.ServiceTestResul
 	return args.Get(0).(models.ServiceTestResults), args.Error(1)
 }
 
+func (m *MockTestResultManager) AddObserver(observer result.ResultObserver) {
+	m.Called(observer)
+}
+
+func (m *MockTestResultManager) AddHealthObserver(observer result.HealthObserver) {
+	m.Called(observer)
+}
+
+func (m *MockTestResultManager) OnHeartbeat(info *models.NodeInfo) {
+	m.Called(info)
+}
+
 // TestNewReportGenerator 测试创建ReportGenerator
 func TestNewReportGenerator(t *testing.T) {
 	mockClientManager := new(MockClientManager)
@@ -131,6 +154,10 @@ func TestGenerateReport(t *testing.T) {
 	assert.Equal(t, 2, report.PodTestSummary.TotalTests)
 	assert.Equal(t, 1, report.PodTestSummary.SuccessfulTests)
 	assert.Equal(t, 1, report.PodTestSummary.FailedTests)
+	assert.Len(t, report.Pairs, 4)
+	assert.Equal(t, models.TestTypeHost, report.Pairs[0].TestType)
+	assert.False(t, report.Pairs[2].Success)
+	assert.Equal(t, "10.0.0.3", report.Pairs[2].TargetIP)
 
 	mockClientManager.AssertExpectations(t)
 	mockResultManager.AssertExpectations(t)
@@ -169,7 +196,24 @@ func TestCalculateTestSummary(t *testing.T) {
 	assert.Equal(t, 1, summary2.FailedTests)
 	assert.Equal(t, 50.0, summary2.SuccessRate)
 
-	// 测试用例3: 空结果
+	// 测试用例3: 按有效状态统计，偶发失败计为成功
+	results4 := map[string]map[string]models.TestStatus{
+		"source1": {
+			"target1": models.TestStatus{Ping: "unreachable", PortStatus: "closed", Health: &models.PairHealth{State: models.PairStateUp, ConsecutiveFailures: 1}},
+			"target2": models.TestStatus{Ping: "unreachable", PortStatus: "closed", Health: &models.PairHealth{State: models.PairStateDown}},
+			"target3": models.TestStatus{Ping: "reachable", PortStatus: "open", Health: &models.PairHealth{State: models.PairStateFlapping}},
+			"target4": models.TestStatus{Ping: "reachable", PortStatus: "open", Health: &models.PairHealth{State: models.PairStateRecovered}},
+		},
+	}
+	summary4 := generator.calculateTestSummary(results4)
+	assert.Equal(t, 4, summary4.TotalTests)
+	assert.Equal(t, 2, summary4.SuccessfulTests)
+	assert.Equal(t, 2, summary4.FailedTests)
+	assert.Equal(t, 1, summary4.DownPairs)
+	assert.Equal(t, 1, summary4.FlappingPairs)
+	assert.Equal(t, 1, summary4.RecoveredPairs)
+
+	// 测试用例4: 空结果
 	results3 := map[string]map[string]models.TestStatus{}
 	summary3 := generator.calculateTestSummary(results3)
 	assert.Equal(t, 0, summary3.TotalTests)
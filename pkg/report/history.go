@@ -0,0 +1,122 @@
+package report
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// HistoryEntry 历史报告摘要
+type HistoryEntry struct {
+	ID                string    `json:"id"`
+	Timestamp         time.Time `json:"timestamp"`
+	ActiveClientCount int       `json:"active_client_count"`
+	TotalPairs        int       `json:"total_pairs"`
+	FailedPairs       int       `json:"failed_pairs"`
+
+	// 趋势数据，耗时以毫秒为单位便于绘图
+	HostSuccessRate   float64 `json:"host_success_rate"`
+	PodSuccessRate    float64 `json:"pod_success_rate"`
+	HostAvgDurationMs float64 `json:"host_avg_duration_ms"`
+	PodAvgDurationMs  float64 `json:"pod_avg_duration_ms"`
+}
+
+// History 保存最近生成的报告，超出容量时丢弃最旧的报告
+// History 同时实现了 Output，可作为报告生成器的输出目标
+type History interface {
+	Output
+
+	// Get 根据ID获取报告，id为 "latest" 时返回最新报告
+	Get(id string) (*models.NetworkReport, bool)
+
+	// List 按时间从新到旧返回所有历史报告摘要
+	List() []HistoryEntry
+}
+
+// historyImpl 是History的实现
+type historyImpl struct {
+	mu       sync.RWMutex
+	reports  []*models.NetworkReport
+	capacity int
+	seq      uint64
+}
+
+// NewHistory 创建报告历史，capacity 为保留的报告数量
+func NewHistory(capacity int) History {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &historyImpl{
+		reports:  make([]*models.NetworkReport, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// Write 保存报告并分配ID
+// 保存的是报告的副本，调用方后续修改不会影响历史记录
+func (h *historyImpl) Write(report *models.NetworkReport) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	stored := *report
+	stored.ID = fmt.Sprintf("%s-%d", report.Timestamp.Format("20060102-150405"), h.seq)
+
+	if len(h.reports) >= h.capacity {
+		copy(h.reports, h.reports[1:])
+		h.reports = h.reports[:len(h.reports)-1]
+	}
+	h.reports = append(h.reports, &stored)
+
+	report.ID = stored.ID
+	return nil
+}
+
+// Get 根据ID获取报告
+func (h *historyImpl) Get(id string) (*models.NetworkReport, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.reports) == 0 {
+		return nil, false
+	}
+	if id == "latest" {
+		return h.reports[len(h.reports)-1], true
+	}
+	for _, report := range h.reports {
+		if report.ID == id {
+			return report, true
+		}
+	}
+	return nil, false
+}
+
+// List 返回历史报告摘要，最新的在前
+func (h *historyImpl) List() []HistoryEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	entries := make([]HistoryEntry, 0, len(h.reports))
+	for i := len(h.reports) - 1; i >= 0; i-- {
+		report := h.reports[i]
+		entries = append(entries, HistoryEntry{
+			ID:                report.ID,
+			Timestamp:         report.Timestamp,
+			ActiveClientCount: report.ActiveClientCount,
+			TotalPairs:        len(report.Pairs),
+			FailedPairs:       len(failedPairs(report.Pairs)),
+			HostSuccessRate:   report.HostTestSummary.SuccessRate,
+			PodSuccessRate:    report.PodTestSummary.SuccessRate,
+			HostAvgDurationMs: durationMs(report.HostTestSummary.AvgTestDuration),
+			PodAvgDurationMs:  durationMs(report.PodTestSummary.AvgTestDuration),
+		})
+	}
+	return entries
+}
+
+// durationMs 将耗时转换为毫秒
+func durationMs(d models.Duration) float64 {
+	return float64(time.Duration(d)) / float64(time.Millisecond)
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistory 测试历史报告的保存、查询与容量限制
+func TestHistory(t *testing.T) {
+	history := NewHistory(2)
+
+	_, ok := history.Get("latest")
+	assert.False(t, ok)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		report := newTestReport()
+		report.Timestamp = report.Timestamp.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, history.Write(report))
+		require.NotEmpty(t, report.ID)
+		ids = append(ids, report.ID)
+	}
+
+	// 最旧的报告被丢弃
+	_, ok = history.Get(ids[0])
+	assert.False(t, ok)
+
+	report, ok := history.Get(ids[1])
+	require.True(t, ok)
+	assert.Equal(t, ids[1], report.ID)
+
+	latest, ok := history.Get("latest")
+	require.True(t, ok)
+	assert.Equal(t, ids[2], latest.ID)
+
+	entries := history.List()
+	require.Len(t, entries, 2)
+	assert.Equal(t, ids[2], entries[0].ID)
+	assert.Equal(t, 2, entries[0].TotalPairs)
+	assert.Equal(t, 1, entries[0].FailedPairs)
+}
+
+// TestNegotiateRenderer 测试格式协商
+func TestNegotiateRenderer(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		accept   string
+		expected string
+		wantErr  bool
+	}{
+		{name: "默认JSON", expected: FormatJSON},
+		{name: "显式格式优先", format: "csv", accept: "text/html", expected: FormatCSV},
+		{name: "浏览器请求", accept: "text/html,application/xhtml+xml,*/*;q=0.8", expected: FormatHTML},
+		{name: "带参数的类型", accept: "application/x-yaml; charset=utf-8", expected: FormatYAML},
+		{name: "未知类型回退JSON", accept: "image/png", expected: FormatJSON},
+		{name: "无效格式", format: "pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := NegotiateRenderer(tt.format, tt.accept)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, renderer.Format())
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yezihack/k8snet-checker/pkg/fileutil"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// fileTimeFormat 报告文件名中的时间格式，精确到纳秒且宽度固定，同一秒内的报告不会互相覆盖，按名称排序即按时间排序
+const fileTimeFormat = "20060102-150405.000000000"
+
+// Output 定义报告输出目标接口
+type Output interface {
+	// Write 输出一份报告
+	Write(report *models.NetworkReport) error
+}
+
+// writerOutput 将报告渲染后写入io.Writer
+type writerOutput struct {
+	mu       sync.Mutex
+	w        io.Writer
+	renderer Renderer
+}
+
+// NewWriterOutput 创建写入io.Writer的输出目标
+func NewWriterOutput(w io.Writer, renderer Renderer) Output {
+	return &writerOutput{
+		w:        w,
+		renderer: renderer,
+	}
+}
+
+// Write 渲染报告并写入
+func (o *writerOutput) Write(report *models.NetworkReport) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.renderer.Render(o.w, report)
+}
+
+// discardOutput 丢弃报告，用于关闭所有定期输出
+type discardOutput struct{}
+
+// NewDiscardOutput 创建不输出任何内容的输出目标
+func NewDiscardOutput() Output {
+	return discardOutput{}
+}
+
+// Write 丢弃报告
+func (discardOutput) Write(report *models.NetworkReport) error {
+	return nil
+}
+
+// fileOutput 将报告写入目录，每份报告一个文件，超出数量时删除最旧的文件
+type fileOutput struct {
+	mu       sync.Mutex
+	dir      string
+	renderer Renderer
+	maxFiles int
+}
+
+// NewFileOutput 创建写入目录的输出目标
+// 文件名格式为 report-20060102-150405.000000000.<扩展名>，maxFiles <= 0 时不清理旧文件
+func NewFileOutput(dir string, renderer Renderer, maxFiles int) (Output, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("报告输出目录不能为空")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建报告输出目录失败: %w", err)
+	}
+
+	return &fileOutput{
+		dir:      dir,
+		renderer: renderer,
+		maxFiles: maxFiles,
+	}, nil
+}
+
+// Write 渲染报告并写入新文件
+func (o *fileOutput) Write(report *models.NetworkReport) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := o.renderer.Render(&buf, report); err != nil {
+		return fmt.Errorf("渲染%s报告失败: %w", o.renderer.Format(), err)
+	}
+
+	name := fmt.Sprintf("report-%s.%s", report.Timestamp.Format(fileTimeFormat), o.renderer.FileExtension())
+	if err := fileutil.WriteFile(filepath.Join(o.dir, name), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入报告文件失败: %w", err)
+	}
+
+	o.rotate()
+	return nil
+}
+
+// rotate 删除超出数量限制的旧报告文件，仅处理当前格式的文件
+func (o *fileOutput) rotate() {
+	if o.maxFiles <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		log.Printf("读取报告输出目录失败: %v", err)
+		return
+	}
+
+	suffix := "." + o.renderer.FileExtension()
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "report-") || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		files = append(files, name)
+	}
+
+	if len(files) <= o.maxFiles {
+		return
+	}
+
+	// 文件名包含时间戳，按名称排序即按时间排序
+	sort.Strings(files)
+	for _, name := range files[:len(files)-o.maxFiles] {
+		if err := os.Remove(filepath.Join(o.dir, name)); err != nil {
+			log.Printf("删除旧报告文件失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriterOutput 测试写入io.Writer
+func TestWriterOutput(t *testing.T) {
+	var buf bytes.Buffer
+	output := NewWriterOutput(&buf, &jsonRenderer{})
+
+	require.NoError(t, output.Write(newTestReport()))
+	assert.Contains(t, buf.String(), `"active_client_count": 2`)
+}
+
+// TestFileOutputRotation 测试文件输出及旧文件清理
+func TestFileOutputRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	// 其他格式的文件不受清理影响
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report-20000101-000000.csv"), []byte("x"), 0644))
+
+	output, err := NewFileOutput(dir, &jsonRenderer{}, 2)
+	require.NoError(t, err)
+
+	report := newTestReport()
+	for i := 0; i < 4; i++ {
+		report.Timestamp = report.Timestamp.Add(time.Minute)
+		require.NoError(t, output.Write(report))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "report-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "report-20240102-030705.000000000.json", filepath.Base(files[0]))
+	assert.Equal(t, "report-20240102-030805.000000000.json", filepath.Base(files[1]))
+	assert.FileExists(t, filepath.Join(dir, "report-20000101-000000.csv"))
+}
+
+// TestFileOutputSubSecond 测试同一秒内的报告写入不同文件，并按时间清理
+func TestFileOutputSubSecond(t *testing.T) {
+	dir := t.TempDir()
+	output, err := NewFileOutput(dir, &jsonRenderer{}, 2)
+	require.NoError(t, err)
+
+	report := newTestReport()
+	start := report.Timestamp
+	for _, offset := range []time.Duration{0, 5 * time.Millisecond, 50 * time.Millisecond} {
+		report.Timestamp = start.Add(offset)
+		require.NoError(t, output.Write(report))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "report-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "report-20240102-030405.005000000.json", filepath.Base(files[0]))
+	assert.Equal(t, "report-20240102-030405.050000000.json", filepath.Base(files[1]))
+}
+
+// TestNewFileOutputEmptyDir 测试输出目录为空
+func TestNewFileOutputEmptyDir(t *testing.T) {
+	_, err := NewFileOutput("", &jsonRenderer{}, 10)
+	assert.Error(t, err)
+}
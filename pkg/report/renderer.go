@@ -0,0 +1,222 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// 报告格式
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatCSV      = "csv"
+	FormatJUnit    = "junit"
+)
+
+// Renderer 定义报告渲染器接口
+type Renderer interface {
+	// Format 返回格式名称
+	Format() string
+
+	// ContentType 返回HTTP响应使用的Content-Type
+	ContentType() string
+
+	// FileExtension 返回写入文件时使用的扩展名（不含点）
+	FileExtension() string
+
+	// Render 将报告渲染到w
+	Render(w io.Writer, report *models.NetworkReport) error
+}
+
+// renderers 已注册的渲染器
+var renderers = map[string]Renderer{
+	FormatText:     &textRenderer{},
+	FormatJSON:     &jsonRenderer{},
+	FormatYAML:     &yamlRenderer{},
+	FormatMarkdown: &markdownRenderer{},
+	FormatHTML:     &htmlRenderer{},
+	FormatCSV:      &csvRenderer{},
+	FormatJUnit:    &junitRenderer{},
+}
+
+// NewRenderer 根据格式名称获取渲染器
+func NewRenderer(format string) (Renderer, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "md" {
+		format = FormatMarkdown
+	}
+
+	renderer, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("不支持的报告格式: %s（支持: %s）", format, strings.Join(SupportedFormats(), ", "))
+	}
+	return renderer, nil
+}
+
+// ParseRenderers 解析逗号分隔的格式列表
+func ParseRenderers(formats string) ([]Renderer, error) {
+	var result []Renderer
+	for _, format := range strings.Split(formats, ",") {
+		if strings.TrimSpace(format) == "" {
+			continue
+		}
+		renderer, err := NewRenderer(format)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, renderer)
+	}
+	return result, nil
+}
+
+// mediaTypes MIME类型到报告格式的映射，用于HTTP内容协商
+var mediaTypes = map[string]string{
+	"application/json":   FormatJSON,
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/markdown":      FormatMarkdown,
+	"text/html":          FormatHTML,
+	"text/csv":           FormatCSV,
+	"application/xml":    FormatJUnit,
+	"text/xml":           FormatJUnit,
+	"text/plain":         FormatText,
+}
+
+// NegotiateRenderer 根据显式格式或Accept请求头选择渲染器
+// format 不为空时优先使用；否则按Accept中的顺序选择第一个支持的类型，均不支持时使用JSON
+func NegotiateRenderer(format, accept string) (Renderer, error) {
+	if strings.TrimSpace(format) != "" {
+		return NewRenderer(format)
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if f, ok := mediaTypes[mediaType]; ok {
+			return renderers[f], nil
+		}
+	}
+	return renderers[FormatJSON], nil
+}
+
+// SupportedFormats 返回所有支持的格式名称
+func SupportedFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// textRenderer 控制台文本格式
+type textRenderer struct{}
+
+func (r *textRenderer) Format() string        { return FormatText }
+func (r *textRenderer) ContentType() string   { return "text/plain; charset=utf-8" }
+func (r *textRenderer) FileExtension() string { return "txt" }
+
+// Render 格式化输出报告文本
+func (r *textRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	var b strings.Builder
+
+	b.WriteString("\n" + strings.Repeat("=", 80) + "\n")
+	b.WriteString("网络连通性报告\n")
+	b.WriteString(strings.Repeat("=", 80) + "\n")
+	fmt.Fprintf(&b, "生成时间: %s\n", report.Timestamp.Format("2006-01-02 15:04:05"))
+	b.WriteString(strings.Repeat("-", 80) + "\n")
+
+	// 活跃客户端信息
+	fmt.Fprintf(&b, "活跃客户端数量: %d\n", report.ActiveClientCount)
+	b.WriteString("\n")
+
+	// 宿主机IP列表
+	fmt.Fprintf(&b, "宿主机IP列表 (共%d个):\n", len(report.HostIPs))
+	if len(report.HostIPs) > 0 {
+		for i, ip := range report.HostIPs {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, ip)
+		}
+	} else {
+		b.WriteString("  无\n")
+	}
+	b.WriteString("\n")
+
+	// Pod IP列表
+	fmt.Fprintf(&b, "Pod IP列表 (共%d个):\n", len(report.PodIPs))
+	if len(report.PodIPs) > 0 {
+		for i, ip := range report.PodIPs {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, ip)
+		}
+	} else {
+		b.WriteString("  无\n")
+	}
+	b.WriteString("\n")
+
+	// 宿主机测试统计
+	writeTextSummary(&b, "宿主机连通性测试统计:", report.HostTestSummary)
+
+	// Pod测试统计
+	writeTextSummary(&b, "Pod连通性测试统计:", report.PodTestSummary)
+
+	// 自定义服务测试统计
+	if report.ServiceTestSummary.TotalTests > 0 {
+		b.WriteString("自定义服务连通性测试统计:\n")
+		fmt.Fprintf(&b, "  服务名称: %s\n", report.ServiceTestSummary.ServiceName)
+		fmt.Fprintf(&b, "  总测试数: %d\n", report.ServiceTestSummary.TotalTests)
+		fmt.Fprintf(&b, "  成功: %d\n", report.ServiceTestSummary.SuccessfulTests)
+		fmt.Fprintf(&b, "  失败: %d\n", report.ServiceTestSummary.FailedTests)
+		fmt.Fprintf(&b, "  成功率: %.2f%%\n", report.ServiceTestSummary.SuccessRate)
+		b.WriteString("\n")
+	}
+
+	// 可用性统计
+	if report.SLO != nil {
+		fmt.Fprintf(&b, "可用性 (目标 %.3f%%):\n", report.SLO.Objective)
+		for _, row := range sloRows(report.SLO) {
+			fmt.Fprintf(&b, "  %s: 1h %s, 24h %s, 30d %s, 剩余错误预算 %.2f%%\n", row.Name,
+				availability(row.Status, models.SLOWindowHour), availability(row.Status, models.SLOWindowDay),
+				availability(row.Status, models.SLOWindowMonth), row.Status.ErrorBudgetRemaining)
+		}
+		b.WriteString("\n")
+	}
+
+	// 延迟异常
+	if len(report.LatencyAnomalies) > 0 {
+		fmt.Fprintf(&b, "延迟异常 (共%d个):\n", len(report.LatencyAnomalies))
+		for _, anomaly := range report.LatencyAnomalies {
+			fmt.Fprintf(&b, "  %s %s -> %s: 延迟 %s, 基线 %s (%.1f倍), 自 %s\n",
+				anomaly.TestType, anomaly.SourceIP, anomaly.TargetIP, anomaly.Latency,
+				anomaly.Baseline.EWMA, anomaly.Ratio, anomaly.Since.Format("15:04:05"))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(strings.Repeat("=", 80) + "\n\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTextSummary 输出宿主机/Pod测试统计
+func writeTextSummary(b *strings.Builder, title string, summary models.TestSummary) {
+	b.WriteString(title + "\n")
+	fmt.Fprintf(b, "  总测试数: %d\n", summary.TotalTests)
+	fmt.Fprintf(b, "  成功: %d\n", summary.SuccessfulTests)
+	fmt.Fprintf(b, "  失败: %d\n", summary.FailedTests)
+	fmt.Fprintf(b, "  成功率: %.2f%%\n", summary.SuccessRate)
+	if summary.DownPairs > 0 || summary.FlappingPairs > 0 || summary.RecoveredPairs > 0 {
+		fmt.Fprintf(b, "  故障: %d, 抖动: %d, 已恢复: %d\n", summary.DownPairs, summary.FlappingPairs, summary.RecoveredPairs)
+	}
+	if summary.TotalTests > 0 {
+		fmt.Fprintf(b, "  平均耗时: %v\n", summary.AvgTestDuration)
+		fmt.Fprintf(b, "  总耗时: %v\n", summary.TotalTestDuration)
+	}
+	b.WriteString("\n")
+}
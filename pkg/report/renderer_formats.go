@@ -0,0 +1,446 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonRenderer JSON格式
+type jsonRenderer struct{}
+
+func (r *jsonRenderer) Format() string        { return FormatJSON }
+func (r *jsonRenderer) ContentType() string   { return "application/json; charset=utf-8" }
+func (r *jsonRenderer) FileExtension() string { return "json" }
+
+// Render 输出缩进的JSON
+func (r *jsonRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// yamlRenderer YAML格式，字段名与JSON保持一致
+type yamlRenderer struct{}
+
+func (r *yamlRenderer) Format() string        { return FormatYAML }
+func (r *yamlRenderer) ContentType() string   { return "application/x-yaml; charset=utf-8" }
+func (r *yamlRenderer) FileExtension() string { return "yaml" }
+
+// Render 先序列化为JSON再转换为YAML节点，保留字段顺序和JSON字段名
+func (r *yamlRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	return renderYAML(w, report)
+}
+
+// renderYAML 将任意值按JSON字段名输出为块风格YAML
+func renderYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化报告失败: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("转换YAML失败: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// clearYAMLStyle 清除从JSON继承的流式风格
+func clearYAMLStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.DoubleQuotedStyle {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// markdownRenderer Markdown格式
+type markdownRenderer struct{}
+
+func (r *markdownRenderer) Format() string        { return FormatMarkdown }
+func (r *markdownRenderer) ContentType() string   { return "text/markdown; charset=utf-8" }
+func (r *markdownRenderer) FileExtension() string { return "md" }
+
+// Render 输出Markdown报告
+func (r *markdownRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	var b strings.Builder
+
+	b.WriteString("# 网络连通性报告\n\n")
+	fmt.Fprintf(&b, "- 生成时间: %s\n", report.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 活跃客户端数量: %d\n", report.ActiveClientCount)
+	fmt.Fprintf(&b, "- 宿主机数量: %d\n", len(report.HostIPs))
+	fmt.Fprintf(&b, "- Pod数量: %d\n\n", len(report.PodIPs))
+
+	b.WriteString("## 测试统计\n\n")
+	b.WriteString("| 类型 | 总测试数 | 成功 | 失败 | 成功率 | 平均耗时 |\n")
+	b.WriteString("|------|----------|------|------|--------|----------|\n")
+	fmt.Fprintf(&b, "| 宿主机 | %d | %d | %d | %.2f%% | %s |\n",
+		report.HostTestSummary.TotalTests, report.HostTestSummary.SuccessfulTests,
+		report.HostTestSummary.FailedTests, report.HostTestSummary.SuccessRate, report.HostTestSummary.AvgTestDuration)
+	fmt.Fprintf(&b, "| Pod | %d | %d | %d | %.2f%% | %s |\n",
+		report.PodTestSummary.TotalTests, report.PodTestSummary.SuccessfulTests,
+		report.PodTestSummary.FailedTests, report.PodTestSummary.SuccessRate, report.PodTestSummary.AvgTestDuration)
+	if report.ServiceTestSummary.TotalTests > 0 {
+		fmt.Fprintf(&b, "| 服务 (%s) | %d | %d | %d | %.2f%% | - |\n",
+			report.ServiceTestSummary.ServiceName, report.ServiceTestSummary.TotalTests,
+			report.ServiceTestSummary.SuccessfulTests, report.ServiceTestSummary.FailedTests,
+			report.ServiceTestSummary.SuccessRate)
+	}
+	b.WriteString("\n")
+
+	if report.SLO != nil {
+		fmt.Fprintf(&b, "## 可用性 (目标 %.3f%%)\n\n", report.SLO.Objective)
+		b.WriteString("| 对象 | 1h | 24h | 30d | 30d消耗速率 | 剩余错误预算 |\n")
+		b.WriteString("|------|----|-----|-----|-------------|--------------|\n")
+		for _, row := range sloRows(report.SLO) {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %.2f | %.2f%% |\n", row.Name,
+				availability(row.Status, models.SLOWindowHour), availability(row.Status, models.SLOWindowDay),
+				availability(row.Status, models.SLOWindowMonth), row.Status.Window(models.SLOWindowMonth).BurnRate,
+				row.Status.ErrorBudgetRemaining)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.LatencyAnomalies) > 0 {
+		fmt.Fprintf(&b, "## 延迟异常 (共%d个)\n\n", len(report.LatencyAnomalies))
+		b.WriteString("| 类型 | 源 | 目标 | 延迟 | 基线 | P99 | 倍数 | 开始时间 |\n")
+		b.WriteString("|------|----|------|------|------|-----|------|----------|\n")
+		for _, anomaly := range report.LatencyAnomalies {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %.1f | %s |\n",
+				anomaly.TestType, anomaly.SourceIP, anomaly.TargetIP, anomaly.Latency,
+				anomaly.Baseline.EWMA, anomaly.Baseline.P99, anomaly.Ratio, anomaly.Since.Format("15:04:05"))
+		}
+		b.WriteString("\n")
+	}
+
+	failed := failedPairs(report.Pairs)
+	fmt.Fprintf(&b, "## 失败的探测对 (共%d个)\n\n", len(failed))
+	if len(failed) == 0 {
+		b.WriteString("无\n\n")
+	} else {
+		writeMarkdownPairs(&b, failed)
+	}
+
+	fmt.Fprintf(&b, "## 全部探测对 (共%d个)\n\n", len(report.Pairs))
+	if len(report.Pairs) == 0 {
+		b.WriteString("无\n")
+	} else {
+		writeMarkdownPairs(&b, report.Pairs)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownPairs 输出探测对表格
+func writeMarkdownPairs(b *strings.Builder, pairs []models.PairResult) {
+	b.WriteString("| 类型 | 源 | 目标 | Ping | 端口 | 耗时 | 结果 |\n")
+	b.WriteString("|------|----|------|------|------|------|------|\n")
+	for _, pair := range pairs {
+		outcome := "✅"
+		if pair.Failing() {
+			outcome = "❌"
+		}
+		if state := describeState(pair); state != "" {
+			outcome += " " + state
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			pair.TestType, pair.SourceIP, pair.TargetIP, pair.Ping, pair.PortStatus, pair.TestDuration, outcome)
+	}
+	b.WriteString("\n")
+}
+
+// htmlRenderer HTML格式，样式内联，无外部依赖
+type htmlRenderer struct{}
+
+func (r *htmlRenderer) Format() string        { return FormatHTML }
+func (r *htmlRenderer) ContentType() string   { return "text/html; charset=utf-8" }
+func (r *htmlRenderer) FileExtension() string { return "html" }
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"formatTime":   func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"percent":      func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) + "%" },
+	"state":        describeState,
+	"sloRows":      sloRows,
+	"availability": availability,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>网络连通性报告 {{formatTime .Report.Timestamp}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 24px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f2f2f2; }
+tr.fail td { background: #fde2e2; }
+</style>
+</head>
+<body>
+<h1>网络连通性报告</h1>
+<p>生成时间: {{formatTime .Report.Timestamp}} &nbsp; 活跃客户端数量: {{.Report.ActiveClientCount}}</p>
+<h2>测试统计</h2>
+<table>
+<tr><th>类型</th><th>总测试数</th><th>成功</th><th>失败</th><th>成功率</th></tr>
+<tr><td>宿主机</td><td>{{.Report.HostTestSummary.TotalTests}}</td><td>{{.Report.HostTestSummary.SuccessfulTests}}</td><td>{{.Report.HostTestSummary.FailedTests}}</td><td>{{percent .Report.HostTestSummary.SuccessRate}}</td></tr>
+<tr><td>Pod</td><td>{{.Report.PodTestSummary.TotalTests}}</td><td>{{.Report.PodTestSummary.SuccessfulTests}}</td><td>{{.Report.PodTestSummary.FailedTests}}</td><td>{{percent .Report.PodTestSummary.SuccessRate}}</td></tr>
+{{- if .Report.ServiceTestSummary.TotalTests}}
+<tr><td>服务 ({{.Report.ServiceTestSummary.ServiceName}})</td><td>{{.Report.ServiceTestSummary.TotalTests}}</td><td>{{.Report.ServiceTestSummary.SuccessfulTests}}</td><td>{{.Report.ServiceTestSummary.FailedTests}}</td><td>{{percent .Report.ServiceTestSummary.SuccessRate}}</td></tr>
+{{- end}}
+</table>
+{{- with .Report.SLO}}
+<h2>可用性 (目标 {{printf "%.3f" .Objective}}%)</h2>
+<table>
+<tr><th>对象</th><th>1h</th><th>24h</th><th>30d</th><th>剩余错误预算</th></tr>
+{{- range sloRows .}}
+<tr><td>{{.Name}}</td><td>{{availability .Status "1h"}}</td><td>{{availability .Status "24h"}}</td><td>{{availability .Status "30d"}}</td><td>{{printf "%.2f" .Status.ErrorBudgetRemaining}}%</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Report.LatencyAnomalies}}
+<h2>延迟异常 (共{{len .}}个)</h2>
+<table>
+<tr><th>类型</th><th>源</th><th>目标</th><th>延迟</th><th>基线</th><th>P99</th><th>倍数</th><th>开始时间</th></tr>
+{{- range .}}
+<tr class="fail"><td>{{.TestType}}</td><td>{{.SourceIP}}</td><td>{{.TargetIP}}</td><td>{{.Latency}}</td><td>{{.Baseline.EWMA}}</td><td>{{.Baseline.P99}}</td><td>{{printf "%.1f" .Ratio}}</td><td>{{formatTime .Since}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>探测对 (共{{len .Report.Pairs}}个，失败{{.FailedCount}}个)</h2>
+<table>
+<tr><th>类型</th><th>源</th><th>目标</th><th>Ping</th><th>端口</th><th>耗时</th><th>状态</th></tr>
+{{- range .Report.Pairs}}
+<tr{{if .Failing}} class="fail"{{end}}><td>{{.TestType}}</td><td>{{.SourceIP}}</td><td>{{.TargetIP}}</td><td>{{.Ping}}</td><td>{{.PortStatus}}</td><td>{{.TestDuration}}</td><td>{{state .}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// Render 输出HTML报告
+func (r *htmlRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	return htmlTemplate.Execute(w, struct {
+		Report      *models.NetworkReport
+		FailedCount int
+	}{
+		Report:      report,
+		FailedCount: len(failedPairs(report.Pairs)),
+	})
+}
+
+// csvRenderer 探测对CSV格式，每行一个探测对
+type csvRenderer struct{}
+
+func (r *csvRenderer) Format() string        { return FormatCSV }
+func (r *csvRenderer) ContentType() string   { return "text/csv; charset=utf-8" }
+func (r *csvRenderer) FileExtension() string { return "csv" }
+
+// Render 输出探测对CSV
+func (r *csvRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"timestamp", "test_type", "source_ip", "target_ip", "ping", "port_status", "test_duration_ms", "success", "state", "down_since"}); err != nil {
+		return err
+	}
+
+	timestamp := report.Timestamp.Format(time.RFC3339)
+	for _, pair := range report.Pairs {
+		record := []string{
+			timestamp,
+			pair.TestType,
+			pair.SourceIP,
+			pair.TargetIP,
+			pair.Ping,
+			pair.PortStatus,
+			strconv.FormatFloat(float64(time.Duration(pair.TestDuration))/float64(time.Millisecond), 'f', 3, 64),
+			strconv.FormatBool(pair.Success),
+			pair.State,
+			formatTime(pair.DownSince),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// junitRenderer JUnit XML格式，每个探测对为一个测试用例，按测试类型分为测试套件
+type junitRenderer struct{}
+
+func (r *junitRenderer) Format() string        { return FormatJUnit }
+func (r *junitRenderer) ContentType() string   { return "application/xml; charset=utf-8" }
+func (r *junitRenderer) FileExtension() string { return "xml" }
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Render 输出JUnit XML
+func (r *junitRenderer) Render(w io.Writer, report *models.NetworkReport) error {
+	suites := junitTestSuites{Name: "k8snet-checker"}
+	index := make(map[string]int)
+	var total time.Duration
+	suiteDurations := make(map[string]time.Duration)
+
+	for _, pair := range report.Pairs {
+		i, ok := index[pair.TestType]
+		if !ok {
+			i = len(suites.Suites)
+			index[pair.TestType] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{
+				Name:      pair.TestType,
+				Timestamp: report.Timestamp.Format("2006-01-02T15:04:05"),
+			})
+		}
+
+		duration := time.Duration(pair.TestDuration)
+		testCase := junitTestCase{
+			ClassName: "k8snet-checker." + pair.TestType,
+			Name:      pair.SourceIP + " -> " + pair.TargetIP,
+			Time:      formatSeconds(duration),
+		}
+		if pair.Failing() {
+			message := fmt.Sprintf("ping=%s, port=%s", pair.Ping, pair.PortStatus)
+			testCase.Failure = &junitFailure{Message: message, Type: "ConnectivityFailure", Text: message}
+			suites.Suites[i].Failures++
+			suites.Failures++
+		}
+
+		suites.Suites[i].Cases = append(suites.Suites[i].Cases, testCase)
+		suites.Suites[i].Tests++
+		suites.Tests++
+		suiteDurations[pair.TestType] += duration
+		total += duration
+	}
+
+	for i := range suites.Suites {
+		suites.Suites[i].Time = formatSeconds(suiteDurations[suites.Suites[i].Name])
+	}
+	suites.Time = formatSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// failedPairs 返回处于故障的探测对，偶发失败未达到阈值的探测对不计入
+func failedPairs(pairs []models.PairResult) []models.PairResult {
+	failed := make([]models.PairResult, 0)
+	for _, pair := range pairs {
+		if pair.Failing() {
+			failed = append(failed, pair)
+		}
+	}
+	return failed
+}
+
+// describeState 返回探测对有效状态的说明，没有状态记录时返回空字符串
+func describeState(pair models.PairResult) string {
+	switch pair.State {
+	case models.PairStateDown:
+		return fmt.Sprintf("down（自 %s）", pair.DownSince.Format("15:04:05"))
+	case models.PairStateFlapping:
+		return fmt.Sprintf("flapping（切换%d次）", pair.FlapCount)
+	case models.PairStateUp:
+		if pair.ConsecutiveFailures > 0 {
+			return fmt.Sprintf("up（连续失败%d次）", pair.ConsecutiveFailures)
+		}
+	}
+	return pair.State
+}
+
+// sloRow 可用性表格中的一行
+type sloRow struct {
+	Name   string
+	Status models.SLOStatus
+}
+
+// sloRows 返回集群与各节点（分别作为源和目标）的可用性
+func sloRows(report *models.SLOReport) []sloRow {
+	rows := []sloRow{{Name: "集群", Status: report.Cluster}}
+	for _, node := range report.Nodes {
+		rows = append(rows,
+			sloRow{Name: node.Node + " (源)", Status: node.AsSource},
+			sloRow{Name: node.Node + " (目标)", Status: node.AsTarget})
+	}
+	return rows
+}
+
+// availability 格式化指定窗口的可用性，窗口内没有探测时返回 -
+func availability(status models.SLOStatus, window string) string {
+	w := status.Window(window)
+	if w.Total == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(w.Availability, 'f', 3, 64) + "%"
+}
+
+// formatTime 以RFC3339格式化时间，零值返回空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatSeconds 以秒为单位格式化耗时
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
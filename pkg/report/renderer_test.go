@@ -0,0 +1,176 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// newTestReport 构造测试用报告
+func newTestReport() *models.NetworkReport {
+	return &models.NetworkReport{
+		Timestamp:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ActiveClientCount: 2,
+		HostIPs:           []string{"192.168.1.1", "192.168.1.2"},
+		PodIPs:            []string{"10.0.0.1", "10.0.0.2"},
+		HostTestSummary:   models.TestSummary{TotalTests: 1, SuccessfulTests: 1, SuccessRate: 100},
+		PodTestSummary:    models.TestSummary{TotalTests: 1, FailedTests: 1, DownPairs: 1},
+		Pairs: []models.PairResult{
+			{TestType: models.TestTypeHost, SourceIP: "192.168.1.1", TargetIP: "192.168.1.2", Ping: "reachable", PortStatus: "open", TestDuration: models.Duration(10 * time.Millisecond), Success: true},
+			{TestType: models.TestTypePod, SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", Ping: "unreachable", PortStatus: "closed", TestDuration: models.Duration(2 * time.Second), Success: false,
+				State: models.PairStateDown, DownSince: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC), ConsecutiveFailures: 3},
+		},
+	}
+}
+
+// render 使用指定格式渲染测试报告
+func render(t *testing.T, format string) string {
+	renderer, err := NewRenderer(format)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, renderer.Render(&buf, newTestReport()))
+	return buf.String()
+}
+
+// TestNewRenderer 测试按名称获取渲染器
+func TestNewRenderer(t *testing.T) {
+	for _, format := range SupportedFormats() {
+		renderer, err := NewRenderer(format)
+		require.NoError(t, err)
+		assert.Equal(t, format, renderer.Format())
+		assert.NotEmpty(t, renderer.ContentType())
+		assert.NotEmpty(t, renderer.FileExtension())
+	}
+
+	renderer, err := NewRenderer(" MD ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, renderer.Format())
+
+	_, err = NewRenderer("pdf")
+	assert.Error(t, err)
+}
+
+// TestParseRenderers 测试解析格式列表
+func TestParseRenderers(t *testing.T) {
+	renderers, err := ParseRenderers("json, csv,,junit")
+	require.NoError(t, err)
+	require.Len(t, renderers, 3)
+	assert.Equal(t, FormatCSV, renderers[1].Format())
+
+	_, err = ParseRenderers("json,unknown")
+	assert.Error(t, err)
+}
+
+// TestJSONRenderer 测试JSON渲染
+func TestJSONRenderer(t *testing.T) {
+	var report models.NetworkReport
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatJSON)), &report))
+	assert.Equal(t, 2, report.ActiveClientCount)
+	assert.Len(t, report.Pairs, 2)
+}
+
+// TestYAMLRenderer 测试YAML渲染，字段名与JSON一致
+func TestYAMLRenderer(t *testing.T) {
+	output := render(t, FormatYAML)
+	assert.Contains(t, output, "active_client_count: 2")
+	assert.Contains(t, output, "test_duration: 2.00s")
+
+	var decoded map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &decoded))
+	assert.Len(t, decoded["pairs"], 2)
+}
+
+// TestMarkdownRenderer 测试Markdown渲染
+func TestMarkdownRenderer(t *testing.T) {
+	output := render(t, FormatMarkdown)
+	assert.Contains(t, output, "# 网络连通性报告")
+	assert.Contains(t, output, "## 失败的探测对 (共1个)")
+	assert.Contains(t, output, "| pod | 10.0.0.1 | 10.0.0.2 | unreachable | closed | 2.00s | ❌ down（自 03:00:00） |")
+}
+
+// TestHTMLRenderer 测试HTML渲染并转义内容
+func TestHTMLRenderer(t *testing.T) {
+	report := newTestReport()
+	report.Pairs[0].TargetIP = "<script>"
+
+	renderer, err := NewRenderer(FormatHTML)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, renderer.Render(&buf, report))
+
+	output := buf.String()
+	assert.Contains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, `class="fail"`)
+	assert.Contains(t, output, "&lt;script&gt;")
+	assert.NotContains(t, output, "<script>")
+}
+
+// TestCSVRenderer 测试CSV渲染
+func TestCSVRenderer(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(render(t, FormatCSV))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "test_type", records[0][1])
+	assert.Equal(t, []string{"2024-01-02T03:04:05Z", "pod", "10.0.0.1", "10.0.0.2", "unreachable", "closed", "2000.000", "false", "down", "2024-01-02T03:00:00Z"}, records[2])
+	assert.Equal(t, "", records[1][8])
+}
+
+// TestJUnitRenderer 测试JUnit渲染
+func TestJUnitRenderer(t *testing.T) {
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(render(t, FormatJUnit)), &suites))
+
+	assert.Equal(t, 2, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "pod", suites.Suites[1].Name)
+	require.Len(t, suites.Suites[1].Cases, 1)
+	require.NotNil(t, suites.Suites[1].Cases[0].Failure)
+	assert.Equal(t, "10.0.0.1 -> 10.0.0.2", suites.Suites[1].Cases[0].Name)
+	assert.Nil(t, suites.Suites[0].Cases[0].Failure)
+}
+
+// TestTextRenderer 测试文本渲染
+func TestTextRenderer(t *testing.T) {
+	output := render(t, FormatText)
+	assert.Contains(t, output, "活跃客户端数量: 2")
+	assert.Contains(t, output, "宿主机IP列表 (共2个)")
+	assert.Contains(t, output, "故障: 1, 抖动: 0, 已恢复: 0")
+	assert.NotContains(t, output, "可用性")
+
+	report := newTestReport()
+	report.SLO = &models.SLOReport{
+		Objective: 99.9,
+		Cluster: models.SLOStatus{
+			Windows:              []models.SLOWindow{{Window: models.SLOWindowHour, Total: 1000, Good: 999, Availability: 99.9, BurnRate: 1}},
+			ErrorBudgetRemaining: 100,
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, (&textRenderer{}).Render(&buf, report))
+	assert.Contains(t, buf.String(), "可用性 (目标 99.900%):")
+	assert.Contains(t, buf.String(), "集群: 1h 99.900%, 24h -, 30d -, 剩余错误预算 100.00%")
+	assert.NotContains(t, buf.String(), "延迟异常")
+
+	report.LatencyAnomalies = []models.LatencyAnomaly{{
+		TestType: models.TestTypePod, SourceIP: "10.0.0.1", TargetIP: "10.0.0.2",
+		Latency:  models.Duration(8 * time.Millisecond),
+		Baseline: models.LatencyBaseline{EWMA: models.Duration(400 * time.Microsecond), Samples: 20},
+		Ratio:    20, Consecutive: 3, Since: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	buf.Reset()
+	require.NoError(t, (&textRenderer{}).Render(&buf, report))
+	assert.Contains(t, buf.String(), "延迟异常 (共1个):")
+	assert.Contains(t, buf.String(), "pod 10.0.0.1 -> 10.0.0.2: 延迟 8.00ms, 基线 400.00µs (20.0倍), 自 12:00:00")
+}
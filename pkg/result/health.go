@@ -0,0 +1,113 @@
+package result
+
+import (
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// HealthConfig 探测对有效状态的滞后阈值
+type HealthConfig struct {
+	FailureThreshold  int           // 连续失败达到该次数时进入 down
+	RecoveryThreshold int           // down 或 flapping 状态下连续成功达到该次数时恢复
+	FlapThreshold     int           // 抖动窗口内结果切换次数达到该值时进入 flapping
+	FlapWindow        time.Duration // 统计结果切换次数的时间窗口
+	RecoveredHold     time.Duration // 恢复后保持 recovered 状态的时长，之后回到 up
+}
+
+// DefaultHealthConfig 返回默认的滞后阈值
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		FailureThreshold:  3,
+		RecoveryThreshold: 2,
+		FlapThreshold:     4,
+		FlapWindow:        10 * time.Minute,
+		RecoveredHold:     10 * time.Minute,
+	}
+}
+
+// withDefaults 将未设置的阈值替换为默认值
+func (c HealthConfig) withDefaults() HealthConfig {
+	defaults := DefaultHealthConfig()
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaults.FailureThreshold
+	}
+	if c.RecoveryThreshold <= 0 {
+		c.RecoveryThreshold = defaults.RecoveryThreshold
+	}
+	if c.FlapThreshold <= 0 {
+		c.FlapThreshold = defaults.FlapThreshold
+	}
+	if c.FlapWindow <= 0 {
+		c.FlapWindow = defaults.FlapWindow
+	}
+	if c.RecoveredHold <= 0 {
+		c.RecoveredHold = defaults.RecoveredHold
+	}
+	return c
+}
+
+// next 根据上一次的状态与本次测试结果计算探测对的新状态，previous 为nil表示第一次测试
+func (c HealthConfig) next(previous *models.PairHealth, succeeded bool, now time.Time) *models.PairHealth {
+	health := &models.PairHealth{State: models.PairStateUp}
+	if previous != nil {
+		*health = *previous
+	}
+
+	// 只保留窗口内的切换记录，本次结果与上一次不同时记录一次切换
+	flips := make([]time.Time, 0, len(health.Flips)+1)
+	for _, flip := range health.Flips {
+		if now.Sub(flip) < c.FlapWindow {
+			flips = append(flips, flip)
+		}
+	}
+	if previous != nil && succeeded != (previous.ConsecutiveSuccesses > 0) {
+		flips = append(flips, now)
+	}
+	health.Flips = flips
+	health.FlapCount = len(flips)
+
+	if succeeded {
+		health.ConsecutiveSuccesses++
+		health.ConsecutiveFailures = 0
+		health.FirstFailedAt = time.Time{}
+	} else {
+		if health.ConsecutiveFailures == 0 {
+			health.FirstFailedAt = now
+		}
+		health.ConsecutiveFailures++
+		health.ConsecutiveSuccesses = 0
+	}
+
+	failing := health.Failing()
+	switch {
+	case health.FlapCount >= c.FlapThreshold:
+		if !failing {
+			health.DownSince = now
+		}
+		health.State = models.PairStateFlapping
+	case failing && health.ConsecutiveSuccesses >= c.RecoveryThreshold:
+		health.State = models.PairStateRecovered
+		health.RecoveredAt = now
+		health.DownSince = time.Time{}
+	case failing:
+		// 故障期间偶发的成功不改变状态，抖动平息后仍持续失败时转为 down
+		if health.ConsecutiveFailures >= c.FailureThreshold {
+			health.State = models.PairStateDown
+		}
+	case health.ConsecutiveFailures >= c.FailureThreshold:
+		health.State = models.PairStateDown
+		health.DownSince = health.FirstFailedAt
+	default:
+		health.State = c.effectiveState(health, now)
+	}
+	return health
+}
+
+// effectiveState 返回不考虑新结果时的状态，recovered 超过保持时长后视为 up
+func (c HealthConfig) effectiveState(health *models.PairHealth, now time.Time) string {
+	if health.State == models.PairStateRecovered && now.Sub(health.RecoveredAt) >= c.RecoveredHold {
+		return models.PairStateUp
+	}
+	return health.State
+}
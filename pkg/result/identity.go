@@ -0,0 +1,70 @@
+package result
+
+import (
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// identities 记录每个IP当前对应的Pod或节点身份
+// 使用某IP的客户端身份变化（例如Pod重建后复用了旧Pod的IP）时递增该IP的代数，
+// 旧代数的测试结果随之失效；客户端过期后仍保留最后的身份，IP再次被使用时据此判断是否变化
+type identities struct {
+	endpoints map[string]models.Endpoint // key为IP
+}
+
+// newIdentities 创建空的身份记录
+func newIdentities() *identities {
+	return &identities{endpoints: make(map[string]models.Endpoint)}
+}
+
+// resolve 返回IP当前的身份，未见过的IP返回代数为0的身份
+func (r *identities) resolve(ip string) models.Endpoint {
+	if endpoint, ok := r.endpoints[ip]; ok {
+		return endpoint
+	}
+	return models.Endpoint{IP: ip}
+}
+
+// current 判断测试结果中记录的身份是否为该IP当前的身份
+func (r *identities) current(endpoint *models.Endpoint) bool {
+	return endpoint != nil && r.resolve(endpoint.IP).Generation == endpoint.Generation
+}
+
+// observe 记录IP当前的身份，身份变化（包括首次见到该IP）时递增代数并返回变化前的身份
+func (r *identities) observe(ip, name string) (models.Endpoint, bool) {
+	previous := r.resolve(ip)
+	if previous.Generation > 0 && previous.Name == name {
+		return previous, false
+	}
+	r.endpoints[ip] = models.Endpoint{IP: ip, Name: name, Generation: previous.Generation + 1}
+	return previous, true
+}
+
+// podIdentity 返回Pod的身份名称，优先使用UID，客户端未上报UID时使用Pod名称
+func podIdentity(podUID, podName string) string {
+	if podUID != "" {
+		return podUID
+	}
+	return podName
+}
+
+// hostIdentity 返回宿主机的身份名称，客户端未上报节点名称时使用节点IP
+func hostIdentity(nodeName, nodeIP string) string {
+	if nodeName != "" {
+		return nodeName
+	}
+	return nodeIP
+}
+
+// reportedIdentity 返回测试结果中客户端标注的目标身份名称，未标注时返回空字符串
+func reportedIdentity(testType string, target *models.Target) string {
+	if target == nil {
+		return ""
+	}
+	if testType == models.TestTypeHost {
+		if target.NodeName == "" {
+			return ""
+		}
+		return hostIdentity(target.NodeName, target.IP)
+	}
+	return podIdentity(target.PodUID, target.PodName)
+}
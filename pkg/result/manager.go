@@ -2,12 +2,18 @@ package result
 
 import (
 	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/cache"
 	"github.com/yezihack/k8snet-checker/pkg/models"
 )
 
 // TestResultManager 定义测试结果管理接口
+// 宿主机与Pod测试结果按源与目标的身份 (名称, IP, 代数) 保存，查询时返回按IP索引的当前身份的结果；
+// IP背后的Pod或节点变化时，该IP旧身份的结果自动失效，不会与新Pod的结果合并
 type TestResultManager interface {
 	SaveHostTestResults(sourceIP string, results []models.ConnectivityResult) error
 	SavePodTestResults(sourceIP string, results []models.ConnectivityResult) error
@@ -15,109 +21,424 @@ type TestResultManager interface {
 	GetHostTestResults() (models.HostTestResults, error)
 	GetPodTestResults() (models.PodTestResults, error)
 	GetServiceTestResults() (models.ServiceTestResults, error)
+
+	// AddObserver 注册测试结果观察者，结果保存成功后会被通知
+	AddObserver(observer ResultObserver)
+
+	// AddHealthObserver 注册探测对有效状态观察者，有效状态变化的结果保存成功后会被通知
+	AddHealthObserver(observer HealthObserver)
+
+	// OnHeartbeat 实现 client.HeartbeatObserver，根据客户端心跳更新IP的身份
+	OnHeartbeat(info *models.NodeInfo)
+}
+
+// ResultObserver 定义测试结果观察者接口
+// testType 取值为 models.TestTypeHost、models.TestTypePod 或 models.TestTypeService
+type ResultObserver interface {
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+}
+
+// HealthObserver 定义探测对有效状态观察者接口
+// 有效状态经过滞后阈值平滑，from 与 to 取值为 models.PairState*；探测对的第一次测试不视为状态变化
+type HealthObserver interface {
+	OnHealthChange(testType string, sourceIP string, targetIP string, from string, to string)
+}
+
+// healthChange 一个探测对的有效状态变化
+type healthChange struct {
+	targetIP string
+	from     string
+	to       string
 }
 
 // testResultManagerImpl 是TestResultManager的实现
 type testResultManagerImpl struct {
-	cacheManager cache.CacheManager
+	cacheManager    cache.CacheManager
+	mu              sync.RWMutex
+	observers       []ResultObserver
+	healthObservers []HealthObserver
+
+	retention time.Duration // 大于0时同一源的结果按目标合并，保留该时长内的结果
+	health    HealthConfig  // 探测对有效状态的滞后阈值
+	saveMu    sync.Mutex    // 合并结果时保证读取与写回之间不被其他上报覆盖，同时保护身份记录
+	now       func() time.Time
+
+	pods  *identities // Pod IP的身份，用于测试结果的源与Pod测试的目标
+	hosts *identities // 宿主机IP的身份，用于宿主机测试的目标
+}
+
+// Option TestResultManager的可选配置项
+type Option func(m *testResultManagerImpl)
+
+// WithRetention 将同一源新上报的结果合并到已有结果中，而不是整体替换
+// 启用目标分片后每轮只测试部分目标，合并后完整的互探矩阵在多轮内逐步填满；
+// 超过 retention 未更新的目标被删除
+func WithRetention(retention time.Duration) Option {
+	return func(m *testResultManagerImpl) {
+		m.retention = retention
+	}
+}
+
+// WithHealthConfig 设置判断探测对有效状态（up、down、flapping、recovered）的滞后阈值，未设置的阈值使用默认值
+func WithHealthConfig(cfg HealthConfig) Option {
+	return func(m *testResultManagerImpl) {
+		m.health = cfg
+	}
 }
 
 // NewTestResultManager 创建一个新的TestResultManager实例
-func NewTestResultManager(cacheManager cache.CacheManager) TestResultManager {
-	return &testResultManagerImpl{
+func NewTestResultManager(cacheManager cache.CacheManager, opts ...Option) TestResultManager {
+	m := &testResultManagerImpl{
 		cacheManager: cacheManager,
+		now:          time.Now,
+		pods:         newIdentities(),
+		hosts:        newIdentities(),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.health = m.health.withDefaults()
+	return m
 }
 
 // SaveHostTestResults 保存宿主机测试结果
 // 将ConnectivityResult列表转换为TestStatus格式并存储
 func (m *testResultManagerImpl) SaveHostTestResults(sourceIP string, results []models.ConnectivityResult) error {
+	return m.save(models.TestTypeHost, sourceIP, results)
+}
+
+// SavePodTestResults 保存Pod测试结果
+// 将ConnectivityResult列表转换为TestStatus格式并存储
+func (m *testResultManagerImpl) SavePodTestResults(sourceIP string, results []models.ConnectivityResult) error {
+	return m.save(models.TestTypePod, sourceIP, results)
+}
+
+// save 按源身份保存宿主机或Pod测试结果，目标身份与客户端标注不一致的结果视为过期并丢弃
+func (m *testResultManagerImpl) save(testType, sourceIP string, results []models.ConnectivityResult) error {
 	if sourceIP == "" {
 		return fmt.Errorf("源IP不能为空")
 	}
 
-	// 转换ConnectivityResult为TestStatus格式
-	testStatusMap := make(map[string]models.TestStatus)
-	for _, result := range results {
-		if result.TargetIP == "" {
-			continue // 跳过无效的目标IP
-		}
+	m.saveMu.Lock()
+	source := m.pods.resolve(sourceIP)
+	store := m.store(testType)
+	existing, err := store.get()
+	if err != nil {
+		m.saveMu.Unlock()
+		return err
+	}
+	accepted, testStatusMap := m.toTestStatus(testType, source, results, existing[source.Key()])
+	changes := healthChanges(existing[source.Key()], testStatusMap)
 
-		// 提取端口状态（取第一个端口的状态）
-		portStatus := "unknown"
-		for _, status := range result.PortStatus {
-			portStatus = status
-			break // 只取第一个端口状态
-		}
+	// 保存到缓存
+	if m.retention > 0 {
+		m.merge(testStatusMap, existing[source.Key()], m.targetIdentities(testType))
+	}
+	err = store.save(source.Key(), testStatusMap)
+	m.saveMu.Unlock()
+	if err != nil {
+		return err
+	}
 
-		testStatusMap[result.TargetIP] = models.TestStatus{
-			Ping:         result.PingStatus,
-			PortStatus:   portStatus,
-			TestDuration: result.TestDuration,
+	m.notifyObservers(testType, sourceIP, accepted)
+	m.notifyHealthObservers(testType, sourceIP, changes)
+	return nil
+}
+
+// healthChanges 比较已有结果与本次结果，返回有效状态发生变化的探测对
+func healthChanges(existing, latest map[string]models.TestStatus) []healthChange {
+	var changes []healthChange
+	for key, status := range latest {
+		previous := existing[key].Health
+		if previous == nil || status.Health == nil || previous.State == status.Health.State {
+			continue
 		}
+		changes = append(changes, healthChange{targetIP: status.Target.IP, from: previous.State, to: status.Health.State})
 	}
-
-	// 保存到缓存
-	return m.cacheManager.SaveHostTestResults(sourceIP, testStatusMap)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].targetIP < changes[j].targetIP })
+	return changes
 }
 
-// SavePodTestResults 保存Pod测试结果
-// 将ConnectivityResult列表转换为TestStatus格式并存储
-func (m *testResultManagerImpl) SavePodTestResults(sourceIP string, results []models.ConnectivityResult) error {
+// SaveServiceTestResult 保存自定义服务测试结果
+func (m *testResultManagerImpl) SaveServiceTestResult(sourceIP string, result *models.ConnectivityResult) error {
 	if sourceIP == "" {
 		return fmt.Errorf("源IP不能为空")
 	}
 
-	// 转换ConnectivityResult为TestStatus格式
+	if result == nil {
+		return fmt.Errorf("测试结果不能为空")
+	}
+
+	// 直接保存ConnectivityResult
+	if err := m.cacheManager.SaveServiceTestResults(sourceIP, result); err != nil {
+		return err
+	}
+
+	m.notifyObservers(models.TestTypeService, sourceIP, []models.ConnectivityResult{*result})
+	return nil
+}
+
+// toTestStatus 将ConnectivityResult列表转换为按目标身份键索引的TestStatus（调用者需持有 saveMu）
+// 根据该源已有的结果更新探测对的有效状态；返回未过期的测试结果，客户端探测时目标IP背后已是其他Pod或节点的结果被丢弃
+func (m *testResultManagerImpl) toTestStatus(testType string, source models.Endpoint, results []models.ConnectivityResult, existing map[string]models.TestStatus) ([]models.ConnectivityResult, map[string]models.TestStatus) {
+	now := m.now()
+	targets := m.targetIdentities(testType)
+	accepted := make([]models.ConnectivityResult, 0, len(results))
 	testStatusMap := make(map[string]models.TestStatus)
 	for _, result := range results {
 		if result.TargetIP == "" {
 			continue // 跳过无效的目标IP
 		}
 
-		// 提取端口状态（取第一个端口的状态）
-		portStatus := "unknown"
-		for _, status := range result.PortStatus {
-			portStatus = status
-			break // 只取第一个端口状态
+		target := targets.resolve(result.TargetIP)
+		if reported := reportedIdentity(testType, result.Target); reported != "" && target.Generation > 0 && reported != target.Name {
+			log.Printf("丢弃过期的测试结果: source=%s, target=%s, 探测时的目标=%s, 当前目标=%s",
+				source.IP, result.TargetIP, reported, target.Name)
+			continue
 		}
+		accepted = append(accepted, result)
 
-		testStatusMap[result.TargetIP] = models.TestStatus{
+		// 汇总端口状态（任一端口关闭即为关闭）
+		testedAt := collectedAt(result, now)
+		status := models.TestStatus{
 			Ping:         result.PingStatus,
-			PortStatus:   portStatus,
+			PortStatus:   result.PortSummary(),
 			TestDuration: result.TestDuration,
+			Latency:      result.Latency,
+			UpdatedAt:    testedAt,
+			Source:       &source,
+			Target:       &target,
 		}
+		status.Health = m.health.next(existing[target.Key()].Health, status.Succeeded(), testedAt)
+		testStatusMap[target.Key()] = status
 	}
+	return accepted, testStatusMap
+}
 
-	// 保存到缓存
-	return m.cacheManager.SavePodTestResults(sourceIP, testStatusMap)
+// collectedAt 返回结果的采集时间，使客户端从结果队列重放的结果保留原采集时间
+// 结果未标注时间或时间晚于服务器当前时间（客户端时钟超前）时使用服务器当前时间
+func collectedAt(result models.ConnectivityResult, now time.Time) time.Time {
+	if result.Timestamp.IsZero() || result.Timestamp.After(now) {
+		return now
+	}
+	return result.Timestamp
 }
 
-// SaveServiceTestResult 保存自定义服务测试结果
-func (m *testResultManagerImpl) SaveServiceTestResult(sourceIP string, result *models.ConnectivityResult) error {
-	if sourceIP == "" {
-		return fmt.Errorf("源IP不能为空")
+// merge 将本次未测试、目标身份未变化且未超过保留时长的已有结果合并到新结果中
+func (m *testResultManagerImpl) merge(latest, existing map[string]models.TestStatus, targets *identities) {
+	now := m.now()
+	for key, status := range existing {
+		if _, ok := latest[key]; ok {
+			continue
+		}
+		if targets.current(status.Target) && now.Sub(status.UpdatedAt) < m.retention {
+			latest[key] = status
+		}
 	}
+}
 
-	if result == nil {
-		return fmt.Errorf("测试结果不能为空")
+// resultStore 宿主机或Pod测试结果在缓存中的读写方法，键为源身份
+type resultStore struct {
+	get    func() (map[string]map[string]models.TestStatus, error)
+	save   func(sourceKey string, results map[string]models.TestStatus) error
+	remove func(sourceKey string) error
+}
+
+// store 返回测试类型对应的缓存读写方法
+func (m *testResultManagerImpl) store(testType string) resultStore {
+	if testType == models.TestTypeHost {
+		return resultStore{
+			get:    func() (map[string]map[string]models.TestStatus, error) { return m.cacheManager.GetHostTestResults() },
+			save:   m.cacheManager.SaveHostTestResults,
+			remove: m.cacheManager.DeleteHostTestResults,
+		}
+	}
+	return resultStore{
+		get:    func() (map[string]map[string]models.TestStatus, error) { return m.cacheManager.GetPodTestResults() },
+		save:   m.cacheManager.SavePodTestResults,
+		remove: m.cacheManager.DeletePodTestResults,
 	}
+}
 
-	// 直接保存ConnectivityResult
-	return m.cacheManager.SaveServiceTestResults(sourceIP, result)
+// targetIdentities 返回测试类型的目标身份记录
+func (m *testResultManagerImpl) targetIdentities(testType string) *identities {
+	if testType == models.TestTypeHost {
+		return m.hosts
+	}
+	return m.pods
+}
+
+// OnHeartbeat 根据客户端心跳更新Pod IP与宿主机IP的身份，已记录的身份被替换时删除旧身份的测试结果
+// 首次见到IP时只记录身份，不扫描已保存的结果；多个客户端使用同一IP时（旧Pod的记录尚未过期）以最近一次心跳的客户端为准
+func (m *testResultManagerImpl) OnHeartbeat(info *models.NodeInfo) {
+	if info == nil {
+		return
+	}
+
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	replaced := false
+	if ip := info.PodIP; ip != "" {
+		previous, ok := m.pods.observe(ip, podIdentity(info.PodUID, info.PodName))
+		if ok && previous.Generation > 0 {
+			log.Printf("Pod IP %s 已被新Pod使用: %s -> %s，旧Pod的测试结果已失效", ip, previous.Name, info.PodName)
+			m.cacheManager.DeleteServiceTestResults(ip)
+			replaced = true
+		}
+	}
+	if ip := info.NodeIP; ip != "" {
+		previous, ok := m.hosts.observe(ip, hostIdentity(info.NodeName, ip))
+		if ok && previous.Generation > 0 {
+			log.Printf("宿主机 IP %s 已属于其他节点: %s -> %s，旧节点的测试结果已失效", ip, previous.Name, info.NodeName)
+			replaced = true
+		}
+	}
+	if replaced {
+		m.purge(models.TestTypeHost)
+		m.purge(models.TestTypePod)
+	}
+}
+
+// purge 删除源或目标身份已失效的测试结果（调用者需持有 saveMu）
+func (m *testResultManagerImpl) purge(testType string) {
+	store := m.store(testType)
+	stored, err := store.get()
+	if err != nil {
+		log.Printf("清理失效的测试结果失败: %v", err)
+		return
+	}
+
+	targets := m.targetIdentities(testType)
+	for sourceKey, statuses := range stored {
+		if source := sourceOf(statuses); source == nil || !m.pods.current(source) {
+			store.remove(sourceKey) // 没有结果的源无法判断身份，也不包含任何信息
+			continue
+		}
+		valid := make(map[string]models.TestStatus, len(statuses))
+		for key, status := range statuses {
+			if targets.current(status.Target) {
+				valid[key] = status
+			}
+		}
+		if len(valid) < len(statuses) {
+			store.save(sourceKey, valid)
+		}
+	}
 }
 
-// GetHostTestResults 获取所有宿主机测试结果
+// ipView 将按身份保存的测试结果转换为按源IP、目标IP索引的当前身份的结果（调用者需持有 saveMu）
+func (m *testResultManagerImpl) ipView(testType string, stored map[string]map[string]models.TestStatus) map[string]map[string]models.TestStatus {
+	now := m.now()
+	targets := m.targetIdentities(testType)
+	view := make(map[string]map[string]models.TestStatus, len(stored))
+	for _, statuses := range stored {
+		source := sourceOf(statuses)
+		if source == nil || !m.pods.current(source) {
+			continue
+		}
+		row := make(map[string]models.TestStatus, len(statuses))
+		for _, status := range statuses {
+			if !targets.current(status.Target) {
+				continue
+			}
+			if status.Health != nil {
+				health := *status.Health
+				health.State = m.health.effectiveState(&health, now)
+				status.Health = &health
+			}
+			row[status.Target.IP] = status
+		}
+		view[source.IP] = row
+	}
+	return view
+}
+
+// sourceOf 返回一组测试结果的源身份，结果为空时返回nil
+func sourceOf(statuses map[string]models.TestStatus) *models.Endpoint {
+	for _, status := range statuses {
+		return status.Source
+	}
+	return nil
+}
+
+// GetHostTestResults 获取所有宿主机测试结果，按源IP、目标IP索引
 func (m *testResultManagerImpl) GetHostTestResults() (models.HostTestResults, error) {
-	return m.cacheManager.GetHostTestResults()
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	stored, err := m.store(models.TestTypeHost).get()
+	if err != nil {
+		return nil, err
+	}
+	return m.ipView(models.TestTypeHost, stored), nil
 }
 
-// GetPodTestResults 获取所有Pod测试结果
+// GetPodTestResults 获取所有Pod测试结果，按源IP、目标IP索引
 func (m *testResultManagerImpl) GetPodTestResults() (models.PodTestResults, error) {
-	return m.cacheManager.GetPodTestResults()
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	stored, err := m.store(models.TestTypePod).get()
+	if err != nil {
+		return nil, err
+	}
+	return m.ipView(models.TestTypePod, stored), nil
 }
 
 // GetServiceTestResults 获取所有自定义服务测试结果
 func (m *testResultManagerImpl) GetServiceTestResults() (models.ServiceTestResults, error) {
 	return m.cacheManager.GetServiceTestResults()
 }
+
+// AddObserver 注册测试结果观察者
+func (m *testResultManagerImpl) AddObserver(observer ResultObserver) {
+	if observer == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observers = append(m.observers, observer)
+}
+
+// AddHealthObserver 注册探测对有效状态观察者
+func (m *testResultManagerImpl) AddHealthObserver(observer HealthObserver) {
+	if observer == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.healthObservers = append(m.healthObservers, observer)
+}
+
+// notifyHealthObservers 通知所有探测对有效状态观察者
+func (m *testResultManagerImpl) notifyHealthObservers(testType string, sourceIP string, changes []healthChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	m.mu.RLock()
+	observers := make([]HealthObserver, len(m.healthObservers))
+	copy(observers, m.healthObservers)
+	m.mu.RUnlock()
+
+	for _, observer := range observers {
+		for _, change := range changes {
+			observer.OnHealthChange(testType, sourceIP, change.targetIP, change.from, change.to)
+		}
+	}
+}
+
+// notifyObservers 通知所有观察者
+func (m *testResultManagerImpl) notifyObservers(testType string, sourceIP string, results []models.ConnectivityResult) {
+	m.mu.RLock()
+	observers := make([]ResultObserver, len(m.observers))
+	copy(observers, m.observers)
+	m.mu.RUnlock()
+
+	for _, observer := range observers {
+		observer.OnTestResults(testType, sourceIP, results)
+	}
+}
@@ -29,7 +29,7 @@ func TestSaveHostTestResults(t *testing.T) {
 			TargetIP:   "192.168.1.2",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{22: "open"},
-			Latency:    10 * time.Millisecond,
+			Latency:    models.Duration(10 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 		{
@@ -88,7 +88,7 @@ func TestSavePodTestResults(t *testing.T) {
 			TargetIP:   "10.244.1.2",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{6100: "open"},
-			Latency:    5 * time.Millisecond,
+			Latency:    models.Duration(5 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 		{
@@ -96,7 +96,7 @@ func TestSavePodTestResults(t *testing.T) {
 			TargetIP:   "10.244.1.3",
 			PingStatus: "reachable",
 			PortStatus: map[int]string{6100: "open"},
-			Latency:    8 * time.Millisecond,
+			Latency:    models.Duration(8 * time.Millisecond),
 			Timestamp:  time.Now(),
 		},
 	}
@@ -141,7 +141,7 @@ func TestSaveServiceTestResult(t *testing.T) {
 		TargetIP:   "kubernetes.default.svc.cluster.local",
 		PingStatus: "reachable",
 		PortStatus: map[int]string{443: "open"},
-		Latency:    15 * time.Millisecond,
+		Latency:    models.Duration(15 * time.Millisecond),
 		Timestamp:  time.Now(),
 	}
 
@@ -291,3 +291,248 @@ func TestUpdateExistingResults(t *testing.T) {
 	assert.Contains(t, allResults[sourceIP], "192.168.1.3")
 	assert.NotContains(t, allResults[sourceIP], "192.168.1.2", "旧结果应被覆盖")
 }
+
+// recordingObserver 记录收到的通知
+type recordingObserver struct {
+	testTypes []string
+	sourceIPs []string
+	counts    []int
+}
+
+func (o *recordingObserver) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	o.testTypes = append(o.testTypes, testType)
+	o.sourceIPs = append(o.sourceIPs, sourceIP)
+	o.counts = append(o.counts, len(results))
+}
+
+// recordingHealthObserver 记录收到的有效状态变化
+type recordingHealthObserver struct {
+	changes []string
+}
+
+func (o *recordingHealthObserver) OnHealthChange(testType string, sourceIP string, targetIP string, from string, to string) {
+	o.changes = append(o.changes, sourceIP+"->"+targetIP+": "+from+"->"+to)
+}
+
+func TestResultObserverNotified(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager)
+
+	observer := &recordingObserver{}
+	manager.AddObserver(observer)
+
+	results := []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}
+
+	assert.NoError(t, manager.SaveHostTestResults("192.168.1.1", results))
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", results))
+	assert.NoError(t, manager.SaveServiceTestResult("10.0.0.1", &results[0]))
+
+	// 保存失败时不应通知
+	assert.Error(t, manager.SavePodTestResults("", results))
+
+	assert.Equal(t, []string{models.TestTypeHost, models.TestTypePod, models.TestTypeService}, observer.testTypes)
+	assert.Equal(t, []string{"192.168.1.1", "10.0.0.1", "10.0.0.1"}, observer.sourceIPs)
+	assert.Equal(t, []int{1, 1, 1}, observer.counts)
+}
+
+// TestResultsKeyedByIdentity 测试IP被新Pod复用后旧Pod的结果失效，探测旧Pod的过期结果被丢弃
+func TestResultsKeyedByIdentity(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager, WithRetention(time.Minute))
+	observer := &recordingObserver{}
+	manager.AddObserver(observer)
+	register := func(podName, podUID, podIP string) {
+		manager.OnHeartbeat(&models.NodeInfo{PodName: podName, PodUID: podUID, PodIP: podIP, NodeIP: "192.168.1.1", NodeName: "node-1"})
+	}
+	register("pod-1", "uid-1", "10.0.0.1")
+	register("pod-a", "uid-a", "10.0.0.2")
+
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.2", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}, Target: &models.Target{IP: "10.0.0.2", PodName: "pod-a", PodUID: "uid-a"}},
+	}))
+	results, err := manager.GetPodTestResults()
+	assert.NoError(t, err)
+	status := results["10.0.0.1"]["10.0.0.2"]
+	assert.Equal(t, "unreachable", status.Ping)
+	assert.Equal(t, &models.Endpoint{IP: "10.0.0.2", Name: "uid-a", Generation: 1}, status.Target)
+	assert.Equal(t, &models.Endpoint{IP: "10.0.0.1", Name: "uid-1", Generation: 1}, status.Source)
+
+	// 新Pod复用了旧Pod的IP，旧Pod的失败结果不再出现，也不会合并到新结果中
+	register("pod-b", "uid-b", "10.0.0.2")
+	results, err = manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Empty(t, results["10.0.0.1"])
+
+	// 客户端探测的仍是旧Pod时结果被丢弃，不通知观察者
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.2", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}, Target: &models.Target{IP: "10.0.0.2", PodName: "pod-a", PodUID: "uid-a"}},
+	}))
+	results, err = manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Empty(t, results["10.0.0.1"])
+
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}, Target: &models.Target{IP: "10.0.0.2", PodName: "pod-b", PodUID: "uid-b"}},
+	}))
+	results, err = manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Equal(t, "reachable", results["10.0.0.1"]["10.0.0.2"].Ping)
+	assert.Equal(t, int64(2), results["10.0.0.1"]["10.0.0.2"].Target.Generation)
+	assert.Equal(t, []int{1, 0, 1}, observer.counts)
+
+	// 源IP被新Pod复用后，旧Pod上报的结果同样失效
+	assert.NoError(t, manager.SaveServiceTestResult("10.0.0.1", &models.ConnectivityResult{TargetIP: "kubernetes.default", PingStatus: "reachable"}))
+	register("pod-2", "uid-2", "10.0.0.1")
+	results, err = manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.NotContains(t, results, "10.0.0.1")
+	serviceResults, err := manager.GetServiceTestResults()
+	assert.NoError(t, err)
+	assert.NotContains(t, serviceResults, "10.0.0.1")
+}
+
+// TestFirstHeartbeatKeepsResults 测试首次见到IP时只记录身份，已记录的身份被替换时才清理旧结果
+func TestFirstHeartbeatKeepsResults(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager)
+
+	// 源IP尚未发送心跳时按代数0保存
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.3", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.1", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}))
+	stored, err := cacheManager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Contains(t, stored, "10.0.0.3//0")
+
+	manager.OnHeartbeat(&models.NodeInfo{PodName: "pod-3", PodUID: "uid-3", PodIP: "10.0.0.3", NodeIP: "192.168.1.3"})
+	stored, err = cacheManager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Contains(t, stored, "10.0.0.3//0")
+
+	manager.OnHeartbeat(&models.NodeInfo{PodName: "pod-4", PodUID: "uid-4", PodIP: "10.0.0.3", NodeIP: "192.168.1.3"})
+	stored, err = cacheManager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestRetentionMergesResults(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager, WithRetention(time.Minute)).(*testResultManagerImpl)
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	// 每轮只测试部分目标，结果按目标合并
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}))
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.3", PingStatus: "unreachable", PortStatus: map[int]string{6100: "closed"}},
+	}))
+
+	results, err := manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Len(t, results["10.0.0.1"], 2)
+	assert.Equal(t, "reachable", results["10.0.0.1"]["10.0.0.2"].Ping)
+
+	// 超过保留时长未更新的目标被删除
+	now = now.Add(45 * time.Second)
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.4", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+	}))
+	results, err = manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Len(t, results["10.0.0.1"], 2)
+	assert.NotContains(t, results["10.0.0.1"], "10.0.0.2")
+	assert.Contains(t, results["10.0.0.1"], "10.0.0.3")
+}
+
+func TestResultsKeepCollectionTime(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager).(*testResultManagerImpl)
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	// 从客户端结果队列重放的结果保留原采集时间，未标注或超前的时间使用服务器时间
+	collected := now.Add(-10 * time.Minute)
+	assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+		{TargetIP: "10.0.0.2", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}, Timestamp: collected},
+		{TargetIP: "10.0.0.3", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}},
+		{TargetIP: "10.0.0.4", PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}, Timestamp: now.Add(time.Minute)},
+	}))
+
+	results, err := manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Equal(t, collected, results["10.0.0.1"]["10.0.0.2"].UpdatedAt)
+	assert.Equal(t, now, results["10.0.0.1"]["10.0.0.3"].UpdatedAt)
+	assert.Equal(t, now, results["10.0.0.1"]["10.0.0.4"].UpdatedAt)
+}
+
+func TestPairHealth(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	manager := NewTestResultManager(cacheManager).(*testResultManagerImpl)
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+	observer := &recordingHealthObserver{}
+	manager.AddHealthObserver(observer)
+
+	save := func(ping string) models.PairHealth {
+		now = now.Add(30 * time.Second)
+		assert.NoError(t, manager.SavePodTestResults("10.0.0.1", []models.ConnectivityResult{
+			{TargetIP: "10.0.0.2", PingStatus: ping, PortStatus: map[int]string{6100: "open"}},
+		}))
+		results, err := manager.GetPodTestResults()
+		assert.NoError(t, err)
+		health := results["10.0.0.1"]["10.0.0.2"].Health
+		if assert.NotNil(t, health) {
+			return *health
+		}
+		return models.PairHealth{}
+	}
+
+	// 偶发失败仍为 up
+	assert.Equal(t, models.PairStateUp, save("reachable").State)
+	health := save("unreachable")
+	assert.Equal(t, models.PairStateUp, health.State)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	firstFailed := now
+
+	// 连续失败达到阈值后进入 down，down_since 为首次失败时间
+	save("unreachable")
+	health = save("unreachable")
+	assert.Equal(t, models.PairStateDown, health.State)
+	assert.Equal(t, 3, health.ConsecutiveFailures)
+	assert.True(t, health.DownSince.Equal(firstFailed))
+
+	// 一次成功不足以恢复，连续成功达到阈值后进入 recovered
+	assert.Equal(t, models.PairStateDown, save("reachable").State)
+	health = save("reachable")
+	assert.Equal(t, models.PairStateRecovered, health.State)
+	assert.True(t, health.DownSince.IsZero())
+	assert.True(t, health.RecoveredAt.Equal(now))
+
+	// 超过保持时长后回到 up
+	now = now.Add(DefaultHealthConfig().RecoveredHold)
+	results, err := manager.GetPodTestResults()
+	assert.NoError(t, err)
+	assert.Equal(t, models.PairStateUp, results["10.0.0.1"]["10.0.0.2"].Health.State)
+
+	// 窗口内结果频繁切换时进入 flapping
+	for _, ping := range []string{"unreachable", "reachable", "unreachable"} {
+		save(ping)
+	}
+	health = save("reachable")
+	assert.Equal(t, models.PairStateFlapping, health.State)
+	assert.Equal(t, 4, health.FlapCount)
+	assert.False(t, health.DownSince.IsZero())
+
+	// 只在有效状态变化时通知，偶发失败与第一次测试不通知
+	assert.Equal(t, []string{
+		"10.0.0.1->10.0.0.2: up->down",
+		"10.0.0.1->10.0.0.2: down->recovered",
+		"10.0.0.1->10.0.0.2: recovered->up",
+		"10.0.0.1->10.0.0.2: up->flapping",
+	}, observer.changes)
+}
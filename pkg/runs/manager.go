@@ -0,0 +1,451 @@
+// Package runs 管理由服务器发起的按需测试任务
+// 任务通过客户端长轮询下发，客户端上报的测试结果带有任务ID，服务器据此汇总每个任务的完整结果
+package runs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// 任务状态
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusTimeout   = "timeout"
+	StatusCancelled = "cancelled"
+)
+
+// DefaultTimeout 默认任务超时时间，超时后仍未完成的客户端保留在 Pending 中
+const DefaultTimeout = 5 * time.Minute
+
+// maxRuns 内存中保留的任务数量，超出后删除最早结束的任务
+const maxRuns = 100
+
+var (
+	// ErrNotFound 任务不存在
+	ErrNotFound = errors.New("测试任务不存在")
+	// ErrNoClients 没有可执行测试的客户端
+	ErrNoClients = errors.New("没有可执行测试的客户端")
+)
+
+// Manager 定义按需测试任务管理接口
+type Manager interface {
+	// Create 创建按需测试任务，clients 为空时下发给所有已注册客户端，testTypes 为空时执行全部测试
+	Create(clients []string, testTypes []string) (*models.Run, error)
+
+	// Get 获取任务状态与已收到的结果
+	Get(id string) (*models.Run, error)
+
+	// Wait 等待任务结束，ctx 结束时返回当前状态
+	Wait(ctx context.Context, id string) (*models.Run, error)
+
+	// List 返回所有保留的任务，按创建时间倒序
+	List() []*models.Run
+
+	// Poll 获取下发给客户端的待执行任务，没有任务时等待到 ctx 结束
+	// 每个任务只下发一次
+	Poll(ctx context.Context, podName string) []models.RunRequest
+
+	// Requeue 撤销 Poll 返回但未能送达客户端的任务，使其在客户端下次轮询时重新下发
+	Requeue(podName string, requests []models.RunRequest)
+
+	// Complete 记录客户端已完成任务
+	Complete(id, podName string) error
+
+	// Cancel 取消运行中的任务，已领取任务且未完成的客户端在下次轮询时收到取消通知
+	Cancel(id string) (*models.Run, error)
+
+	// OnTestResults 实现 result.ResultObserver，收集带有任务ID的测试结果
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+}
+
+// runState 任务的内部状态
+type runState struct {
+	run       models.Run
+	delivered map[string]bool // 已下发的客户端
+	completed map[string]bool // 已完成的客户端
+	cancelled map[string]bool // 已下发取消通知的客户端
+}
+
+// managerImpl 是Manager的实现
+type managerImpl struct {
+	clientManager client.ClientManager
+	timeout       time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	runs    map[string]*runState
+	order   []string      // 按创建顺序排列的任务ID
+	changed chan struct{} // 任务有变化时关闭并替换，用于唤醒等待者
+	seq     int64
+}
+
+// NewManager 创建任务管理器，timeout <= 0 时使用 DefaultTimeout
+func NewManager(clientManager client.ClientManager, timeout time.Duration) Manager {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &managerImpl{
+		clientManager: clientManager,
+		timeout:       timeout,
+		now:           time.Now,
+		runs:          make(map[string]*runState),
+		changed:       make(chan struct{}),
+	}
+}
+
+// Create 创建按需测试任务
+func (m *managerImpl) Create(clients []string, testTypes []string) (*models.Run, error) {
+	testTypes, err := normalizeTestTypes(testTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := m.clientManager.GetAllClients()
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) == 0 {
+		for podName := range registered {
+			clients = append(clients, podName)
+		}
+	} else {
+		for _, podName := range clients {
+			if _, ok := registered[podName]; !ok {
+				return nil, fmt.Errorf("客户端未注册: %s", podName)
+			}
+		}
+	}
+	if len(clients) == 0 {
+		return nil, ErrNoClients
+	}
+	clients = uniqueSorted(clients)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	now := m.now()
+	state := &runState{
+		run: models.Run{
+			ID:        fmt.Sprintf("%s-%d", now.Format("20060102-150405"), m.seq),
+			Status:    StatusRunning,
+			TestTypes: testTypes,
+			Clients:   clients,
+			CreatedAt: now,
+			Deadline:  now.Add(m.timeout),
+			Results:   make(map[string][]models.ConnectivityResult),
+		},
+		delivered: make(map[string]bool),
+		completed: make(map[string]bool),
+		cancelled: make(map[string]bool),
+	}
+	m.runs[state.run.ID] = state
+	m.order = append(m.order, state.run.ID)
+	m.prune()
+	m.notify()
+
+	log.Printf("创建按需测试任务: id=%s, clients=%d, test_types=%v", state.run.ID, len(clients), testTypes)
+	return m.snapshot(state), nil
+}
+
+// Get 获取任务状态
+func (m *managerImpl) Get(id string) (*models.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.runs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.expire(state)
+	return m.snapshot(state), nil
+}
+
+// Wait 等待任务结束
+func (m *managerImpl) Wait(ctx context.Context, id string) (*models.Run, error) {
+	for {
+		m.mu.Lock()
+		state, ok := m.runs[id]
+		if !ok {
+			m.mu.Unlock()
+			return nil, ErrNotFound
+		}
+		m.expire(state)
+		run := m.snapshot(state)
+		changed := m.changed
+		m.mu.Unlock()
+
+		if run.Status != StatusRunning {
+			return run, nil
+		}
+
+		timer := time.NewTimer(run.Deadline.Sub(m.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return run, nil
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// List 返回所有任务
+func (m *managerImpl) List() []*models.Run {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	runs := make([]*models.Run, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		state := m.runs[m.order[i]]
+		m.expire(state)
+		runs = append(runs, m.snapshot(state))
+	}
+	return runs
+}
+
+// Poll 获取下发给客户端的待执行任务
+func (m *managerImpl) Poll(ctx context.Context, podName string) []models.RunRequest {
+	for {
+		m.mu.Lock()
+		var requests []models.RunRequest
+		for _, id := range m.order {
+			state := m.runs[id]
+			m.expire(state)
+			if state.run.Status == StatusCancelled && state.delivered[podName] && !state.completed[podName] && !state.cancelled[podName] {
+				// 通知执行中的客户端停止探测
+				state.cancelled[podName] = true
+				requests = append(requests, models.RunRequest{ID: state.run.ID, Cancel: true})
+				continue
+			}
+			if state.run.Status != StatusRunning || state.delivered[podName] || !contains(state.run.Clients, podName) {
+				continue
+			}
+			state.delivered[podName] = true
+			requests = append(requests, models.RunRequest{
+				ID:        state.run.ID,
+				TestTypes: state.run.TestTypes,
+				CreatedAt: state.run.CreatedAt,
+				Deadline:  state.run.Deadline,
+			})
+		}
+		changed := m.changed
+		m.mu.Unlock()
+
+		if len(requests) > 0 {
+			return requests
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// Requeue 撤销未送达客户端的任务的下发记录
+func (m *managerImpl) Requeue(podName string, requests []models.RunRequest) {
+	if len(requests) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, request := range requests {
+		state, ok := m.runs[request.ID]
+		if !ok {
+			continue
+		}
+		if request.Cancel {
+			delete(state.cancelled, podName)
+		} else {
+			delete(state.delivered, podName)
+		}
+	}
+	m.notify()
+}
+
+// Complete 记录客户端已完成任务
+func (m *managerImpl) Complete(id, podName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.runs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !contains(state.run.Clients, podName) {
+		return fmt.Errorf("客户端 %s 不属于测试任务 %s", podName, id)
+	}
+	m.expire(state)
+	if state.run.Status != StatusRunning {
+		return nil
+	}
+
+	state.completed[podName] = true
+	if len(state.completed) == len(state.run.Clients) {
+		m.finish(state, StatusCompleted)
+		log.Printf("按需测试任务完成: id=%s", id)
+	}
+	m.notify()
+	return nil
+}
+
+// Cancel 取消运行中的任务
+func (m *managerImpl) Cancel(id string) (*models.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.runs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.expire(state)
+	if state.run.Status == StatusRunning {
+		m.finish(state, StatusCancelled)
+		log.Printf("按需测试任务已取消: id=%s, completed=%d/%d", id, len(state.completed), len(state.run.Clients))
+		m.notify()
+	}
+	return m.snapshot(state), nil
+}
+
+// OnTestResults 收集带有任务ID的测试结果
+// 客户端重放离线缓存的结果时可能重复上报，同一源与目标的结果只保留最新一条
+func (m *managerImpl) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := false
+	for _, result := range results {
+		if result.RunID == "" {
+			continue
+		}
+		state, ok := m.runs[result.RunID]
+		if !ok || state.run.Status != StatusRunning {
+			continue
+		}
+		state.run.Results[testType] = mergeResult(state.run.Results[testType], result)
+		updated = true
+	}
+	if updated {
+		m.notify()
+	}
+}
+
+// mergeResult 将结果合并到列表中，替换同一源与目标的旧结果
+func mergeResult(results []models.ConnectivityResult, result models.ConnectivityResult) []models.ConnectivityResult {
+	for i, existing := range results {
+		if existing.SourceIP == result.SourceIP && existing.TargetIP == result.TargetIP {
+			results[i] = result
+			return results
+		}
+	}
+	return append(results, result)
+}
+
+// expire 将超过截止时间的任务标记为超时，调用方需持有锁
+func (m *managerImpl) expire(state *runState) {
+	if state.run.Status == StatusRunning && !m.now().Before(state.run.Deadline) {
+		m.finish(state, StatusTimeout)
+		log.Printf("按需测试任务超时: id=%s, completed=%d/%d", state.run.ID, len(state.completed), len(state.run.Clients))
+		m.notify()
+	}
+}
+
+// finish 结束任务，调用方需持有锁
+func (m *managerImpl) finish(state *runState, status string) {
+	now := m.now()
+	state.run.Status = status
+	state.run.FinishedAt = &now
+}
+
+// notify 唤醒所有等待者，调用方需持有锁
+func (m *managerImpl) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// prune 超出保留数量时删除最早结束的任务，运行中的任务不会被删除，调用方需持有锁
+func (m *managerImpl) prune() {
+	for i := 0; len(m.order) > maxRuns && i < len(m.order); {
+		id := m.order[i]
+		if m.runs[id].run.Status == StatusRunning {
+			i++
+			continue
+		}
+		delete(m.runs, id)
+		m.order = append(m.order[:i], m.order[i+1:]...)
+	}
+}
+
+// snapshot 复制任务状态，调用方需持有锁
+func (m *managerImpl) snapshot(state *runState) *models.Run {
+	run := state.run
+	run.TestTypes = append([]string(nil), state.run.TestTypes...)
+	run.Clients = append([]string(nil), state.run.Clients...)
+	run.Completed = []string{}
+	run.Pending = []string{}
+	for _, podName := range state.run.Clients {
+		if state.completed[podName] {
+			run.Completed = append(run.Completed, podName)
+		} else {
+			run.Pending = append(run.Pending, podName)
+		}
+	}
+	run.Results = make(map[string][]models.ConnectivityResult, len(state.run.Results))
+	for testType, results := range state.run.Results {
+		run.Results[testType] = append([]models.ConnectivityResult(nil), results...)
+	}
+	return &run
+}
+
+// normalizeTestTypes 校验测试类型，为空时返回全部类型
+func normalizeTestTypes(testTypes []string) ([]string, error) {
+	if len(testTypes) == 0 {
+		return []string{models.TestTypeHost, models.TestTypePod, models.TestTypeService}, nil
+	}
+	for _, testType := range testTypes {
+		switch testType {
+		case models.TestTypeHost, models.TestTypePod, models.TestTypeService:
+		default:
+			return nil, fmt.Errorf("不支持的测试类型: %s", testType)
+		}
+	}
+	return uniqueSorted(testTypes), nil
+}
+
+// uniqueSorted 去重并排序
+func uniqueSorted(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// contains 判断列表中是否包含指定元素
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package runs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupManager 创建带有两个已注册客户端的任务管理器
+func setupManager(t *testing.T, timeout time.Duration) *managerImpl {
+	clientManager := client.NewClientManager(cache.NewCacheManager())
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1"}))
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{PodName: "pod-2", NodeIP: "192.168.1.2", PodIP: "10.0.0.2"}))
+	return NewManager(clientManager, timeout).(*managerImpl)
+}
+
+// TestCreateValidation 测试创建任务的参数校验
+func TestCreateValidation(t *testing.T) {
+	manager := setupManager(t, 0)
+
+	_, err := manager.Create(nil, []string{"udp"})
+	assert.Error(t, err)
+
+	_, err = manager.Create([]string{"pod-9"}, nil)
+	assert.Error(t, err)
+
+	run, err := manager.Create(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, run.Status)
+	assert.Equal(t, []string{"pod-1", "pod-2"}, run.Clients)
+	assert.Equal(t, []string{"host", "pod", "service"}, run.TestTypes)
+
+	empty := NewManager(client.NewClientManager(cache.NewCacheManager()), 0)
+	_, err = empty.Create(nil, nil)
+	assert.ErrorIs(t, err, ErrNoClients)
+}
+
+// TestRunLifecycle 测试任务下发、结果收集与完成
+func TestRunLifecycle(t *testing.T) {
+	manager := setupManager(t, 0)
+
+	run, err := manager.Create([]string{"pod-1", "pod-2"}, []string{models.TestTypePod})
+	require.NoError(t, err)
+
+	// 每个客户端只收到一次任务，未被选中的客户端收不到
+	requests := manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	assert.Equal(t, run.ID, requests[0].ID)
+	assert.Equal(t, []string{models.TestTypePod}, requests[0].TestTypes)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Empty(t, manager.Poll(ctx, "pod-1"))
+
+	// 只收集带有任务ID的结果
+	manager.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "reachable", RunID: run.ID},
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.3", PingStatus: "reachable"},
+	})
+	// 重放的重复结果替换原有结果
+	manager.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{
+		{SourceIP: "10.0.0.1", TargetIP: "10.0.0.2", PingStatus: "unreachable", RunID: run.ID},
+	})
+	require.NoError(t, manager.Complete(run.ID, "pod-1"))
+
+	current, err := manager.Get(run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, current.Status)
+	assert.Equal(t, []string{"pod-1"}, current.Completed)
+	assert.Equal(t, []string{"pod-2"}, current.Pending)
+	require.Len(t, current.Results[models.TestTypePod], 1)
+	assert.Equal(t, "unreachable", current.Results[models.TestTypePod][0].PingStatus)
+
+	// 等待者在最后一个客户端完成后返回
+	done := make(chan *models.Run, 1)
+	go func() {
+		finished, _ := manager.Wait(context.Background(), run.ID)
+		done <- finished
+	}()
+
+	assert.Error(t, manager.Complete(run.ID, "pod-9"))
+	require.NoError(t, manager.Complete(run.ID, "pod-2"))
+
+	select {
+	case finished := <-done:
+		assert.Equal(t, StatusCompleted, finished.Status)
+		assert.NotNil(t, finished.FinishedAt)
+		assert.Empty(t, finished.Pending)
+	case <-time.After(time.Second):
+		t.Fatal("等待任务完成超时")
+	}
+
+	_, err = manager.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestPollWakesOnCreate 测试长轮询在任务创建后立即返回
+func TestPollWakesOnCreate(t *testing.T) {
+	manager := setupManager(t, 0)
+
+	received := make(chan []models.RunRequest, 1)
+	go func() {
+		received <- manager.Poll(context.Background(), "pod-2")
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	run, err := manager.Create([]string{"pod-2"}, nil)
+	require.NoError(t, err)
+
+	select {
+	case requests := <-received:
+		require.Len(t, requests, 1)
+		assert.Equal(t, run.ID, requests[0].ID)
+	case <-time.After(time.Second):
+		t.Fatal("长轮询未被唤醒")
+	}
+}
+
+// TestRequeue 测试未送达的任务在下次轮询时重新下发
+func TestRequeue(t *testing.T) {
+	manager := setupManager(t, 0)
+
+	run, err := manager.Create([]string{"pod-1"}, nil)
+	require.NoError(t, err)
+
+	requests := manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	manager.Requeue("pod-1", requests)
+
+	requests = manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	assert.Equal(t, run.ID, requests[0].ID)
+	assert.False(t, requests[0].Cancel)
+
+	// 未送达的取消通知同样重新下发
+	_, err = manager.Cancel(run.ID)
+	require.NoError(t, err)
+	requests = manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	manager.Requeue("pod-1", requests)
+	requests = manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	assert.True(t, requests[0].Cancel)
+}
+
+// TestRunCancel 测试取消任务后向执行中的客户端下发一次取消通知
+func TestRunCancel(t *testing.T) {
+	manager := setupManager(t, 0)
+
+	run, err := manager.Create(nil, []string{models.TestTypeHost})
+	require.NoError(t, err)
+
+	requests := manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	assert.Equal(t, run.Deadline, requests[0].Deadline)
+	assert.False(t, requests[0].Cancel)
+
+	cancelled, err := manager.Cancel(run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.FinishedAt)
+
+	// 已领取任务的客户端收到取消通知，未领取的客户端收不到任务
+	requests = manager.Poll(context.Background(), "pod-1")
+	require.Len(t, requests, 1)
+	assert.Equal(t, run.ID, requests[0].ID)
+	assert.True(t, requests[0].Cancel)
+
+	for _, podName := range []string{"pod-1", "pod-2"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		assert.Empty(t, manager.Poll(ctx, podName))
+		cancel()
+	}
+
+	_, err = manager.Cancel("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestRunTimeout 测试任务超时后不再下发，且保留未完成的客户端
+func TestRunTimeout(t *testing.T) {
+	manager := setupManager(t, time.Minute)
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	run, err := manager.Create(nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, manager.Complete(run.ID, "pod-1"))
+
+	now = now.Add(2 * time.Minute)
+	current, err := manager.Get(run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTimeout, current.Status)
+	assert.Equal(t, []string{"pod-2"}, current.Pending)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Empty(t, manager.Poll(ctx, "pod-2"))
+
+	// 超时后的结果不再收集
+	manager.OnTestResults(models.TestTypeHost, "192.168.1.2", []models.ConnectivityResult{{TargetIP: "192.168.1.1", RunID: run.ID}})
+	current, _ = manager.Get(run.ID)
+	assert.Empty(t, current.Results)
+
+	assert.Len(t, manager.List(), 1)
+}
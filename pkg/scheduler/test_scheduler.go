@@ -2,166 +2,582 @@ package scheduler
 
 import (
 	"context"
+	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/yezihack/k8snet-checker/pkg/api/client"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
 	"github.com/yezihack/k8snet-checker/pkg/network"
+	"github.com/yezihack/k8snet-checker/pkg/outbox"
 	"go.uber.org/zap"
 )
 
 // TestScheduler 测试任务调度器
+// 每种测试类型按各自的间隔独立调度，每轮增加随机抖动，避免所有客户端同时探测
 type TestScheduler struct {
-	apiClient         client.APIClient
-	networkTester     network.NetworkTester
-	customServiceName string
-	logger            *zap.Logger
-	interval          time.Duration
+	apiClient     client.APIClient
+	networkTester network.NetworkTester
+	metrics       *metrics.ClientMetrics
+	logger        *zap.Logger
+
+	podName   string                 // 非空时接收服务器下发的按需测试任务
+	running   map[string]*sync.Mutex // 各测试类型执行中持有，保证同类型的定期测试与按需测试不会重叠
+	outbox    outbox.Outbox          // 可选，上报失败的结果在服务器恢复后重放
+	recovered chan struct{}          // 服务器恢复时写入，立即重放结果队列
+
+	configMu sync.Mutex
+	config   models.ProbeConfig // 当前探测配置，提供测试间隔、测试类型、服务名称与版本
+	changed  chan struct{}      // 探测配置变更时关闭并替换，通知调度循环重新计时
+}
+
+// 按需测试任务长轮询参数
+const (
+	runPollWait       = 30 * time.Second
+	runPollRetryDelay = 10 * time.Second
+)
+
+// 结果队列重放间隔，重放失败时指数增长
+const (
+	outboxRetryMin = 5 * time.Second
+	outboxRetryMax = 5 * time.Minute
+)
+
+// testTypeNames 测试类型在日志中的名称
+var testTypeNames = map[string]string{
+	models.TestTypeHost:    "宿主机",
+	models.TestTypePod:     "Pod",
+	models.TestTypeService: "自定义服务",
+}
+
+// allTestTypes 支持的测试类型，每种类型有独立的调度循环
+var allTestTypes = []string{models.TestTypeHost, models.TestTypePod, models.TestTypeService}
+
+// Option TestScheduler的可选配置项
+type Option func(s *TestScheduler)
+
+// WithRemoteRuns 通过长轮询接收服务器下发的按需测试任务，podName 为当前客户端的Pod名称
+func WithRemoteRuns(podName string) Option {
+	return func(s *TestScheduler) {
+		s.podName = podName
+	}
+}
+
+// WithOutbox 上报失败时将结果放入队列，服务器恢复后按原顺序重放
+func WithOutbox(o outbox.Outbox) Option {
+	return func(s *TestScheduler) {
+		s.outbox = o
+	}
 }
 
 // NewTestScheduler 创建测试调度器
+// clientMetrics 可为 nil
 func NewTestScheduler(
 	apiClient client.APIClient,
 	networkTester network.NetworkTester,
 	customServiceName string,
+	clientMetrics *metrics.ClientMetrics,
 	logger *zap.Logger,
+	opts ...Option,
 ) *TestScheduler {
-	return &TestScheduler{
-		apiClient:         apiClient,
-		networkTester:     networkTester,
-		customServiceName: customServiceName,
-		logger:            logger,
-		interval:          60 * time.Second,
+	s := &TestScheduler{
+		apiClient:     apiClient,
+		networkTester: networkTester,
+		metrics:       clientMetrics,
+		logger:        logger,
+		running:       make(map[string]*sync.Mutex, len(allTestTypes)),
+		config: models.ProbeConfig{
+			TestInterval: models.Duration(60 * time.Second),
+			TestTypes:    allTestTypes,
+			ServiceName:  customServiceName,
+		},
+		changed:   make(chan struct{}),
+		recovered: make(chan struct{}, 1),
+	}
+	for _, testType := range allTestTypes {
+		s.running[testType] = &sync.Mutex{}
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// OnProbeConfig 实现 probeconfig.Observer，应用服务器下发的调度配置、测试类型与服务名称
+// 测试间隔变化的测试类型从当前时间起按新间隔重新计时
+func (s *TestScheduler) OnProbeConfig(cfg models.ProbeConfig) {
+	s.configMu.Lock()
+	cfg.TestTypes = append([]string(nil), cfg.TestTypes...)
+	s.config = cfg
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.configMu.Unlock()
+
+	s.logger.Info("测试调度器已应用探测配置",
+		zap.Int64("version", cfg.Version),
+		zap.Duration("host_interval", cfg.IntervalFor(models.TestTypeHost)),
+		zap.Duration("pod_interval", cfg.IntervalFor(models.TestTypePod)),
+		zap.Duration("service_interval", cfg.IntervalFor(models.TestTypeService)),
+		zap.Duration("jitter", time.Duration(cfg.Jitter)),
+		zap.Strings("test_types", cfg.TestTypes),
+		zap.String("service_name", cfg.ServiceName),
+	)
+}
+
+// currentConfig 返回当前探测配置及其变更通知通道
+func (s *TestScheduler) currentConfig() (models.ProbeConfig, <-chan struct{}) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.config, s.changed
 }
 
-// Start 启动测试调度器
+// Start 启动测试调度器，阻塞直到 ctx 结束
 func (s *TestScheduler) Start(ctx context.Context) {
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	if s.podName != "" {
+		go s.pollRuns(ctx)
+	}
+	if s.outbox != nil {
+		s.metrics.ObserveOutboxDepth(s.outbox.Len())
+		go s.replayOutbox(ctx)
+	}
+
+	var wg sync.WaitGroup
+	for _, testType := range allTestTypes {
+		wg.Add(1)
+		go func(testType string) {
+			defer wg.Done()
+			s.scheduleLoop(ctx, testType)
+		}(testType)
+	}
+	wg.Wait()
 
-	// 立即执行第一次测试
-	s.runTests()
+	s.logger.Info("测试调度器收到停止信号")
+}
+
+// scheduleLoop 按测试类型的间隔定期触发测试
+// 首轮在初始延迟后执行，之后每轮间隔为测试间隔加随机抖动
+func (s *TestScheduler) scheduleLoop(ctx context.Context, testType string) {
+	cfg, changed := s.currentConfig()
+	interval := cfg.IntervalFor(testType)
+	timer := time.NewTimer(withJitter(time.Duration(cfg.InitialDelay), time.Duration(cfg.Jitter)))
+	defer timer.Stop()
 
-	// 定期执行测试
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.Info("测试调度器收到停止信号")
 			return
-		case <-ticker.C:
-			s.runTests()
+		case <-changed:
+			cfg, changed = s.currentConfig()
+			if cfg.IntervalFor(testType) != interval {
+				interval = cfg.IntervalFor(testType)
+				resetTimer(timer, withJitter(interval, time.Duration(cfg.Jitter)))
+			}
+		case <-timer.C:
+			cfg, changed = s.currentConfig()
+			interval = cfg.IntervalFor(testType)
+			timer.Reset(withJitter(interval, time.Duration(cfg.Jitter)))
+			if cfg.HasTestType(testType) {
+				s.trigger(ctx, testType, cfg)
+			}
 		}
 	}
 }
 
-// runTests 执行所有网络连通性测试
-func (s *TestScheduler) runTests() {
-	s.logger.Info("开始执行网络连通性测试")
+// trigger 在后台执行一轮定期测试，ctx 结束时中止执行中的探测
+// 上一轮尚未完成时跳过本轮并记录为错过的周期，耗时超过测试间隔时记录为超时的周期
+func (s *TestScheduler) trigger(ctx context.Context, testType string, cfg models.ProbeConfig) {
+	running := s.running[testType]
+	if !running.TryLock() {
+		s.metrics.ObserveMissedCycle(testType)
+		s.logger.Warn("上一轮测试尚未完成，跳过本轮", zap.String("test_type", testType))
+		return
+	}
+
+	go func() {
+		defer running.Unlock()
+
+		start := time.Now()
+		s.runTest(ctx, "", testType, cfg)
+
+		interval := cfg.IntervalFor(testType)
+		if elapsed := time.Since(start); elapsed > interval {
+			s.metrics.ObserveOverrunCycle(testType)
+			s.logger.Warn("测试耗时超过测试间隔",
+				zap.String("test_type", testType),
+				zap.Duration("elapsed", elapsed),
+				zap.Duration("interval", interval),
+			)
+		}
+	}()
+}
+
+// execute 执行按需测试任务，各测试类型并行执行，同类型的定期测试完成后才开始，结果带有任务ID
+// ctx 在任务截止、被服务器取消或客户端退出时结束
+func (s *TestScheduler) execute(ctx context.Context, runID string, testTypes []string) {
+	cfg, _ := s.currentConfig()
+	s.logger.Info("开始执行网络连通性测试",
+		zap.String("run_id", runID),
+		zap.Strings("test_types", testTypes),
+		zap.Int64("config_version", cfg.Version),
+	)
+
+	var wg sync.WaitGroup
+	for _, testType := range testTypes {
+		running, ok := s.running[testType]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(testType string) {
+			defer wg.Done()
+			running.Lock()
+			defer running.Unlock()
+			if ctx.Err() != nil {
+				return
+			}
+			s.runTest(ctx, runID, testType, cfg)
+		}(testType)
+	}
+	wg.Wait()
 
-	// 测试宿主机连通性
-	s.testHostConnectivity()
+	if ctx.Err() != nil {
+		s.logger.Info("网络连通性测试已中止", zap.String("run_id", runID), zap.Error(ctx.Err()))
+		return
+	}
+	s.logger.Info("网络连通性测试完成", zap.String("run_id", runID))
+}
 
-	// 测试Pod连通性
-	s.testPodConnectivity()
+// runTest 执行指定类型的网络连通性测试，runID 非空时为按需测试
+func (s *TestScheduler) runTest(ctx context.Context, runID string, testType string, cfg models.ProbeConfig) {
+	switch testType {
+	case models.TestTypeHost:
+		// 测试宿主机连通性
+		s.testHostConnectivity(ctx, runID, cfg.Version)
+	case models.TestTypePod:
+		// 测试Pod连通性
+		s.testPodConnectivity(ctx, runID, cfg.Version)
+	case models.TestTypeService:
+		// 测试自定义服务连通性
+		if cfg.ServiceName != "" {
+			s.testServiceConnectivity(ctx, runID, cfg.Version, cfg.ServiceName)
+		} else {
+			s.logger.Debug("跳过自定义服务探测（未配置CUSTOM_SERVICE_NAME）")
+		}
+	}
+}
 
-	// 测试自定义服务连通性
-	if s.customServiceName != "" {
-		s.testServiceConnectivity()
-	} else {
-		s.logger.Debug("跳过自定义服务探测（未配置CUSTOM_SERVICE_NAME）")
+// withJitter 返回 base 加上 [0, jitter) 内的随机时长
+func withJitter(base, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return base
 	}
+	return base + time.Duration(rand.Int63n(int64(jitter)))
+}
 
-	s.logger.Info("网络连通性测试完成")
+// resetTimer 停止计时器并丢弃未读取的触发，再按新的时长重新计时
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
+// pollRuns 循环长轮询服务器下发的按需测试任务，在后台执行并在完成后报告
+// 任务到达截止时间或被服务器取消时中止其执行中的探测
+func (s *TestScheduler) pollRuns(ctx context.Context) {
+	var mu sync.Mutex
+	active := make(map[string]context.CancelFunc) // 执行中的任务
+
+	for ctx.Err() == nil {
+		requests, err := s.apiClient.PollRuns(s.podName, runPollWait)
+		if err != nil {
+			s.logger.Warn("获取按需测试任务失败", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(runPollRetryDelay):
+			}
+			continue
+		}
+
+		for _, request := range requests {
+			if request.Cancel {
+				mu.Lock()
+				cancel, ok := active[request.ID]
+				mu.Unlock()
+				if ok {
+					s.logger.Info("按需测试任务已被服务器取消", zap.String("run_id", request.ID))
+					cancel()
+				}
+				continue
+			}
+
+			s.logger.Info("收到按需测试任务", zap.String("run_id", request.ID), zap.Strings("test_types", request.TestTypes))
+			var runCtx context.Context
+			var cancel context.CancelFunc
+			if request.Deadline.IsZero() {
+				runCtx, cancel = context.WithCancel(ctx)
+			} else {
+				runCtx, cancel = context.WithDeadline(ctx, request.Deadline)
+			}
+			mu.Lock()
+			active[request.ID] = cancel
+			mu.Unlock()
+
+			go func(request models.RunRequest) {
+				defer func() {
+					mu.Lock()
+					delete(active, request.ID)
+					mu.Unlock()
+					cancel()
+				}()
+
+				s.execute(runCtx, request.ID, request.TestTypes)
+				// 被取消或到达截止时间的任务结果不完整，不报告完成，由服务器将其标记为取消或超时
+				if err := runCtx.Err(); err != nil {
+					if ctx.Err() == nil {
+						s.logger.Info("按需测试任务未完成", zap.String("run_id", request.ID), zap.Error(err))
+					}
+					return
+				}
+				if err := s.apiClient.CompleteRun(request.ID, s.podName); err != nil {
+					s.logger.Error("报告按需测试任务完成失败", zap.String("run_id", request.ID), zap.Error(err))
+				}
+			}(request)
+		}
+	}
+}
+
+// tagResults 为测试结果设置任务ID与探测配置版本
+func tagResults(results []models.ConnectivityResult, runID string, configVersion int64) {
+	for i := range results {
+		results[i].RunID = runID
+		results[i].ConfigVersion = configVersion
+	}
 }
 
 // testHostConnectivity 测试宿主机连通性
-func (s *TestScheduler) testHostConnectivity() {
+func (s *TestScheduler) testHostConnectivity(ctx context.Context, runID string, configVersion int64) {
 	s.logger.Info("开始宿主机连通性测试")
 
-	hostIPs, err := s.apiClient.GetHostIPs()
+	hosts, err := s.apiClient.GetHostTargets()
 	if err != nil {
-		s.logger.Error("获取宿主机IP列表失败", zap.Error(err))
+		s.logger.Error("获取宿主机探测目标失败", zap.Error(err))
 		return
 	}
 
-	if len(hostIPs) == 0 {
-		s.logger.Info("没有宿主机IP需要测试")
+	if len(hosts) == 0 {
+		s.logger.Info("没有宿主机目标需要测试")
 		return
 	}
 
-	s.logger.Info("获取到宿主机IP列表", zap.Int("count", len(hostIPs)))
+	s.logger.Info("获取到宿主机探测目标", zap.Int("count", len(hosts)))
 
-	results, err := s.networkTester.TestHostConnectivity(hostIPs)
+	results, err := s.networkTester.TestHostConnectivity(ctx, hosts)
+	if ctx.Err() != nil {
+		s.logger.Info("宿主机连通性测试已取消，不上报结果", zap.String("run_id", runID))
+		return
+	}
 	if err != nil {
 		s.logger.Error("宿主机连通性测试失败", zap.Error(err))
 		return
 	}
 
 	s.logger.Info("宿主机连通性测试完成", zap.Int("results_count", len(results)))
+	tagResults(results, runID, configVersion)
+	s.metrics.ObserveProbeResults(models.TestTypeHost, results)
 
 	if len(results) > 0 {
-		if err := s.apiClient.ReportHostTestResults(results); err != nil {
-			s.logger.Error("上报宿主机测试结果失败", zap.Error(err))
-			return
-		}
-		s.logger.Info("宿主机测试结果上报成功")
+		s.report(models.TestTypeHost, runID, results)
 	}
 }
 
 // testPodConnectivity 测试Pod连通性
-func (s *TestScheduler) testPodConnectivity() {
+func (s *TestScheduler) testPodConnectivity(ctx context.Context, runID string, configVersion int64) {
 	s.logger.Info("开始Pod连通性测试")
 
-	podIPs, err := s.apiClient.GetPodIPs()
+	pods, err := s.apiClient.GetPodTargets()
 	if err != nil {
-		s.logger.Error("获取Pod IP列表失败", zap.Error(err))
+		s.logger.Error("获取Pod探测目标失败", zap.Error(err))
 		return
 	}
 
-	if len(podIPs) == 0 {
-		s.logger.Info("没有Pod IP需要测试")
+	if len(pods) == 0 {
+		s.logger.Info("没有Pod目标需要测试")
 		return
 	}
 
-	s.logger.Info("获取到Pod IP列表", zap.Int("count", len(podIPs)))
+	s.logger.Info("获取到Pod探测目标", zap.Int("count", len(pods)))
 
-	results, err := s.networkTester.TestPodConnectivity(podIPs)
+	results, err := s.networkTester.TestPodConnectivity(ctx, pods)
+	if ctx.Err() != nil {
+		s.logger.Info("Pod连通性测试已取消，不上报结果", zap.String("run_id", runID))
+		return
+	}
 	if err != nil {
 		s.logger.Error("Pod连通性测试失败", zap.Error(err))
 		return
 	}
 
 	s.logger.Info("Pod连通性测试完成", zap.Int("results_count", len(results)))
+	tagResults(results, runID, configVersion)
+	s.metrics.ObserveProbeResults(models.TestTypePod, results)
 
 	if len(results) > 0 {
-		if err := s.apiClient.ReportPodTestResults(results); err != nil {
-			s.logger.Error("上报Pod测试结果失败", zap.Error(err))
-			return
-		}
-		s.logger.Info("Pod测试结果上报成功")
+		s.report(models.TestTypePod, runID, results)
 	}
 }
 
 // testServiceConnectivity 测试自定义服务连通性
-func (s *TestScheduler) testServiceConnectivity() {
-	s.logger.Info("开始自定义服务连通性测试", zap.String("service_name", s.customServiceName))
+func (s *TestScheduler) testServiceConnectivity(ctx context.Context, runID string, configVersion int64, serviceName string) {
+	s.logger.Info("开始自定义服务连通性测试", zap.String("service_name", serviceName))
 
-	result, err := s.networkTester.TestServiceConnectivity(s.customServiceName)
+	result, err := s.networkTester.TestServiceConnectivity(ctx, serviceName)
+	if ctx.Err() != nil {
+		s.logger.Info("自定义服务连通性测试已取消，不上报结果", zap.String("run_id", runID))
+		return
+	}
 	if err != nil {
 		s.logger.Error("自定义服务连通性测试失败", zap.Error(err))
 		return
 	}
 
 	s.logger.Info("自定义服务连通性测试完成",
-		zap.String("service_name", s.customServiceName),
+		zap.String("service_name", serviceName),
 		zap.String("target_ip", result.TargetIP),
 		zap.String("ping_status", result.PingStatus),
 	)
+	result.RunID = runID
+	result.ConfigVersion = configVersion
+	results := []models.ConnectivityResult{*result}
+	s.metrics.ObserveProbeResults(models.TestTypeService, results)
+	s.report(models.TestTypeService, runID, results)
+}
+
+// report 上报测试结果，失败时放入结果队列等待重放
+// 队列中仍有未上报的批次时，新结果排在其后，保证服务器按测试顺序收到结果
+func (s *TestScheduler) report(testType, runID string, results []models.ConnectivityResult) {
+	name := testTypeNames[testType]
+	if s.outbox != nil && s.outbox.Len() > 0 {
+		s.logger.Info("结果队列中有未上报的批次，"+name+"测试结果排队等待上报", zap.Int("pending", s.outbox.Len()))
+		s.enqueue(testType, runID, results)
+		return
+	}
 
-	if err := s.apiClient.ReportServiceTestResults(result); err != nil {
-		s.logger.Error("上报自定义服务测试结果失败", zap.Error(err))
+	err := s.send(testType, results)
+	if err == nil {
+		s.logger.Info(name + "测试结果上报成功")
 		return
 	}
 
-	s.logger.Info("自定义服务测试结果上报成功")
+	s.metrics.ObserveReportUploadError(testType)
+	s.logger.Error("上报"+name+"测试结果失败", zap.Error(err))
+	if s.outbox != nil && !client.IsPermanent(err) {
+		s.enqueue(testType, runID, results)
+	}
+}
+
+// send 按测试类型上报结果
+func (s *TestScheduler) send(testType string, results []models.ConnectivityResult) error {
+	switch testType {
+	case models.TestTypeHost:
+		return s.apiClient.ReportHostTestResults(results)
+	case models.TestTypePod:
+		return s.apiClient.ReportPodTestResults(results)
+	case models.TestTypeService:
+		return s.apiClient.ReportServiceTestResults(&results[0])
+	}
+	return nil
+}
+
+// enqueue 将结果放入队列
+func (s *TestScheduler) enqueue(testType, runID string, results []models.ConnectivityResult) {
+	dropped, err := s.outbox.Enqueue(outbox.Batch{TestType: testType, RunID: runID, Results: results})
+	if err != nil {
+		s.metrics.ObserveOutboxDropped("error", 1)
+		s.logger.Error("测试结果放入队列失败", zap.String("test_type", testType), zap.Error(err))
+		return
+	}
+	if dropped > 0 {
+		s.metrics.ObserveOutboxDropped("full", dropped)
+		s.logger.Warn("结果队列已满，丢弃最早的批次", zap.Int("dropped", dropped))
+	}
+	s.metrics.ObserveOutboxDepth(s.outbox.Len())
+}
+
+// OnServerRecovered 实现 heartbeat.RecoveryObserver，服务器恢复后立即重放结果队列
+func (s *TestScheduler) OnServerRecovered() {
+	select {
+	case s.recovered <- struct{}{}:
+	default:
+	}
+}
+
+// replayOutbox 定期重放队列中的结果，失败时按指数退避加随机抖动延长间隔
+// 服务器恢复时不再等待退避，立即重放
+func (s *TestScheduler) replayOutbox(ctx context.Context) {
+	delay := outboxRetryMin
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.recovered:
+			if s.outbox.Len() > 0 {
+				s.logger.Info("服务器已恢复，立即重新上报队列中的测试结果", zap.Int("pending", s.outbox.Len()))
+			}
+			delay = outboxRetryMin
+		}
+
+		if err := s.flushOutbox(ctx); err != nil {
+			delay = min(delay*2, outboxRetryMax)
+			s.logger.Warn("重新上报测试结果失败",
+				zap.Int("pending", s.outbox.Len()),
+				zap.Duration("retry_in", delay),
+				zap.Error(err),
+			)
+		} else {
+			delay = outboxRetryMin
+		}
+		resetTimer(timer, withJitter(delay/2, delay/2))
+	}
+}
+
+// flushOutbox 按顺序上报队列中的批次，遇到可重试的错误时停止
+// 服务器拒绝的批次重试也无法成功，直接丢弃
+func (s *TestScheduler) flushOutbox(ctx context.Context) error {
+	for ctx.Err() == nil {
+		batch, ok := s.outbox.Peek()
+		if !ok {
+			return nil
+		}
+
+		if err := s.send(batch.TestType, batch.Results); err != nil {
+			if !client.IsPermanent(err) {
+				return err
+			}
+			s.metrics.ObserveOutboxDropped("rejected", 1)
+			s.logger.Warn("服务器拒绝队列中的测试结果，已丢弃", zap.String("batch_id", batch.ID), zap.Error(err))
+		} else {
+			s.metrics.ObserveOutboxReplayed(batch.TestType)
+			s.logger.Info("队列中的测试结果重新上报成功",
+				zap.String("test_type", batch.TestType),
+				zap.String("run_id", batch.RunID),
+				zap.Time("created_at", batch.CreatedAt),
+			)
+		}
+
+		if err := s.outbox.Remove(batch.ID); err != nil {
+			return err
+		}
+		s.metrics.ObserveOutboxDepth(s.outbox.Len())
+	}
+	return ctx.Err()
 }
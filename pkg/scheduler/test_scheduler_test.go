@@ -0,0 +1,287 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/api/client"
+	"github.com/yezihack/k8snet-checker/pkg/metrics"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+	"github.com/yezihack/k8snet-checker/pkg/outbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockAPIClient 返回固定的Pod IP列表并记录上报的结果
+type mockAPIClient struct {
+	mu        sync.Mutex
+	reports   [][]models.ConnectivityResult
+	err       error               // 非nil时上报Pod结果返回该错误
+	runs      []models.RunRequest // 下一次获取任务时返回的任务
+	completed []string            // 已报告完成的任务ID
+}
+
+func (m *mockAPIClient) SendHeartbeat(info *models.NodeInfo) (*models.HeartbeatResponse, error) {
+	return &models.HeartbeatResponse{}, nil
+}
+
+func (m *mockAPIClient) GetHostIPs() ([]string, error) {
+	return []string{"192.168.1.2"}, nil
+}
+
+func (m *mockAPIClient) GetPodIPs() ([]string, error) {
+	return []string{"10.0.0.2"}, nil
+}
+
+func (m *mockAPIClient) GetHostTargets() ([]models.Target, error) {
+	return []models.Target{{IP: "192.168.1.2"}}, nil
+}
+
+func (m *mockAPIClient) GetPodTargets() ([]models.Target, error) {
+	return []models.Target{{IP: "10.0.0.2"}}, nil
+}
+
+func (m *mockAPIClient) ReportHostTestResults(results []models.ConnectivityResult) error {
+	return nil
+}
+
+func (m *mockAPIClient) ReportPodTestResults(results []models.ConnectivityResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.reports = append(m.reports, results)
+	return nil
+}
+
+func (m *mockAPIClient) ReportServiceTestResults(result *models.ConnectivityResult) error {
+	return nil
+}
+
+func (m *mockAPIClient) PollRuns(podName string, wait time.Duration) ([]models.RunRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.runs) == 0 {
+		// 模拟长轮询等待，避免调用方空转
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	}
+	runs := m.runs
+	m.runs = nil
+	return runs, nil
+}
+
+func (m *mockAPIClient) CompleteRun(runID string, podName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed = append(m.completed, runID)
+	return nil
+}
+
+// completedRuns 返回已报告完成的任务ID
+func (m *mockAPIClient) completedRuns() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.completed...)
+}
+
+// reportCount 返回已上报的结果批次数
+func (m *mockAPIClient) reportCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.reports)
+}
+
+// mockNetworkTester Pod测试在 release 关闭或 ctx 结束前阻塞
+type mockNetworkTester struct {
+	release chan struct{}
+	delay   time.Duration
+}
+
+func (m *mockNetworkTester) PingTest(ctx context.Context, targetIP string, count int) (bool, time.Duration, error) {
+	return true, 0, nil
+}
+
+func (m *mockNetworkTester) PortTest(ctx context.Context, targetIP string, port int, timeout time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (m *mockNetworkTester) TestHostConnectivity(ctx context.Context, hosts []models.Target) ([]models.ConnectivityResult, error) {
+	return nil, nil
+}
+
+func (m *mockNetworkTester) TestPodConnectivity(ctx context.Context, pods []models.Target) ([]models.ConnectivityResult, error) {
+	if m.release != nil {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	time.Sleep(m.delay)
+	return []models.ConnectivityResult{{TargetIP: pods[0].IP, PingStatus: "reachable"}}, nil
+}
+
+func (m *mockNetworkTester) TestServiceConnectivity(ctx context.Context, serviceName string) (*models.ConnectivityResult, error) {
+	return nil, nil
+}
+
+func (m *mockNetworkTester) OnProbeConfig(cfg models.ProbeConfig) {}
+
+// counterValue 从指标注册表中读取计数器的值
+func counterValue(t *testing.T, m *metrics.ClientMetrics, name, testType string) float64 {
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "type" && label.GetValue() == testType {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// TestWithJitter 测试随机抖动范围
+func TestWithJitter(t *testing.T) {
+	assert.Equal(t, time.Minute, withJitter(time.Minute, 0))
+	for i := 0; i < 100; i++ {
+		d := withJitter(time.Minute, 5*time.Second)
+		assert.GreaterOrEqual(t, d, time.Minute)
+		assert.Less(t, d, time.Minute+5*time.Second)
+	}
+}
+
+// TestTriggerSkipsOverlappingRuns 测试上一轮未完成时跳过本轮并记录
+func TestTriggerSkipsOverlappingRuns(t *testing.T) {
+	apiClient := &mockAPIClient{}
+	tester := &mockNetworkTester{release: make(chan struct{})}
+	clientMetrics := metrics.NewClientMetrics()
+	s := NewTestScheduler(apiClient, tester, "", clientMetrics, zap.NewNop())
+
+	cfg := models.ProbeConfig{TestInterval: models.Duration(time.Minute), TestTypes: []string{models.TestTypePod}}
+	s.trigger(context.Background(), models.TestTypePod, cfg)
+	s.trigger(context.Background(), models.TestTypePod, cfg)
+	assert.Equal(t, float64(1), counterValue(t, clientMetrics, "k8snet_checker_client_scheduler_missed_cycles_total", models.TestTypePod))
+
+	close(tester.release)
+	assert.Eventually(t, func() bool { return apiClient.reportCount() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+// TestTriggerRecordsOverrun 测试耗时超过测试间隔时记录
+func TestTriggerRecordsOverrun(t *testing.T) {
+	apiClient := &mockAPIClient{}
+	tester := &mockNetworkTester{delay: 20 * time.Millisecond}
+	clientMetrics := metrics.NewClientMetrics()
+	s := NewTestScheduler(apiClient, tester, "", clientMetrics, zap.NewNop())
+
+	s.trigger(context.Background(), models.TestTypePod, models.ProbeConfig{TestInterval: models.Duration(time.Millisecond)})
+	assert.Eventually(t, func() bool {
+		return counterValue(t, clientMetrics, "k8snet_checker_client_scheduler_overrun_cycles_total", models.TestTypePod) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+// TestOnProbeConfig 测试配置版本随结果上报，并按新的测试类型调度
+func TestOnProbeConfig(t *testing.T) {
+	apiClient := &mockAPIClient{}
+	s := NewTestScheduler(apiClient, &mockNetworkTester{}, "", nil, zap.NewNop())
+
+	s.OnProbeConfig(models.ProbeConfig{
+		Version:      7,
+		TestInterval: models.Duration(time.Minute),
+		PodInterval:  models.Duration(10 * time.Second),
+		TestTypes:    []string{models.TestTypePod},
+	})
+
+	cfg, _ := s.currentConfig()
+	assert.Equal(t, 10*time.Second, cfg.IntervalFor(models.TestTypePod))
+	assert.Equal(t, time.Minute, cfg.IntervalFor(models.TestTypeHost))
+	assert.False(t, cfg.HasTestType(models.TestTypeHost))
+
+	s.execute(context.Background(), "run-1", []string{models.TestTypePod})
+	require.Equal(t, 1, apiClient.reportCount())
+	assert.Equal(t, int64(7), apiClient.reports[0][0].ConfigVersion)
+	assert.Equal(t, "run-1", apiClient.reports[0][0].RunID)
+}
+
+// TestExecuteCancelled 测试任务取消后立即中止探测且不上报结果
+func TestExecuteCancelled(t *testing.T) {
+	apiClient := &mockAPIClient{}
+	s := NewTestScheduler(apiClient, &mockNetworkTester{release: make(chan struct{})}, "", nil, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.execute(ctx, "run-1", []string{models.TestTypePod})
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("取消后测试未中止")
+	}
+	assert.Equal(t, 0, apiClient.reportCount())
+}
+
+// TestPollRunsSkipsExpiredRuns 测试到达截止时间的任务不报告完成
+func TestPollRunsSkipsExpiredRuns(t *testing.T) {
+	apiClient := &mockAPIClient{runs: []models.RunRequest{
+		{ID: "run-expired", TestTypes: []string{models.TestTypePod}, Deadline: time.Now().Add(-time.Second)},
+		{ID: "run-ok", TestTypes: []string{models.TestTypePod}},
+	}}
+	s := NewTestScheduler(apiClient, &mockNetworkTester{}, "pod-1", nil, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.pollRuns(ctx)
+
+	assert.Eventually(t, func() bool { return len(apiClient.completedRuns()) > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"run-ok"}, apiClient.completedRuns())
+}
+
+// TestOutboxReplay 测试上报失败的结果进入队列，服务器恢复后按顺序重放
+func TestOutboxReplay(t *testing.T) {
+	apiClient := &mockAPIClient{err: errors.New("connection refused")}
+	queue, err := outbox.New(10, "")
+	require.NoError(t, err)
+	clientMetrics := metrics.NewClientMetrics()
+	s := NewTestScheduler(apiClient, &mockNetworkTester{}, "", clientMetrics, zap.NewNop(), WithOutbox(queue))
+
+	s.execute(context.Background(), "run-1", []string{models.TestTypePod})
+	s.execute(context.Background(), "", []string{models.TestTypePod})
+	assert.Equal(t, 2, queue.Len())
+	assert.Error(t, s.flushOutbox(context.Background()))
+	assert.Equal(t, 2, queue.Len())
+
+	// 服务器恢复后按原顺序重放
+	apiClient.mu.Lock()
+	apiClient.err = nil
+	apiClient.mu.Unlock()
+	require.NoError(t, s.flushOutbox(context.Background()))
+	assert.Equal(t, 0, queue.Len())
+	require.Equal(t, 2, apiClient.reportCount())
+	assert.Equal(t, "run-1", apiClient.reports[0][0].RunID)
+	assert.Equal(t, "", apiClient.reports[1][0].RunID)
+	assert.Equal(t, float64(2), counterValue(t, clientMetrics, "k8snet_checker_client_outbox_replayed_batches_total", models.TestTypePod))
+
+	// 服务器拒绝的结果不进入队列
+	apiClient.mu.Lock()
+	apiClient.err = &client.StatusError{StatusCode: 400, Message: "INVALID_REQUEST"}
+	apiClient.mu.Unlock()
+	s.execute(context.Background(), "", []string{models.TestTypePod})
+	assert.Equal(t, 0, queue.Len())
+}
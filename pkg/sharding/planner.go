@@ -0,0 +1,227 @@
+// Package sharding 为大规模集群中的客户端分配每轮测试的目标子集
+// 每个客户端每轮只测试固定数量的目标，探测总量随集群规模线性增长；
+// 目标按轮次轮换，K 轮内覆盖全部目标，同节点与跨可用区的目标每轮都会测试。
+// 客户端上报了本轮的测试结果后，下一次请求才进入新的一轮，轮换节奏跟随客户端实际的测试间隔与抖动
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// ErrUnknownClient 客户端未注册，调用方应返回全部目标
+var ErrUnknownClient = errors.New("客户端未注册")
+
+// Planner 定义目标分片接口
+type Planner interface {
+	// Assign 返回客户端本轮应测试的目标，收到本轮的测试结果前重复调用返回相同的目标
+	// testType 取值为 models.TestTypeHost 或 models.TestTypePod
+	Assign(podName, testType string) ([]string, *models.TargetShard, error)
+
+	// OnTestResults 实现 result.ResultObserver，收到客户端的测试结果后，下一次 Assign 进入新的一轮
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+}
+
+// candidate 候选目标
+type candidate struct {
+	ip     string
+	nodeIP string
+	zone   string
+}
+
+// plannerImpl 是Planner的实现
+type plannerImpl struct {
+	clientManager   client.ClientManager
+	targetsPerCycle int
+
+	mu     sync.Mutex
+	cycles map[cycleKey]*cycleState // 各客户端每种测试类型的轮次
+}
+
+// cycleKey 轮次键，测试结果按源IP上报，因此使用客户端的Pod IP
+type cycleKey struct {
+	podIP    string
+	testType string
+}
+
+// cycleState 客户端一种测试类型的轮次
+type cycleState struct {
+	cycle    int64
+	reported bool // 本轮分片下发后是否收到了测试结果
+}
+
+// NewPlanner 创建目标分片器，targetsPerCycle 为每个客户端每轮测试的目标数量
+func NewPlanner(clientManager client.ClientManager, targetsPerCycle int) Planner {
+	if targetsPerCycle < 1 {
+		targetsPerCycle = 1
+	}
+	return &plannerImpl{
+		clientManager:   clientManager,
+		targetsPerCycle: targetsPerCycle,
+		cycles:          make(map[cycleKey]*cycleState),
+	}
+}
+
+// Assign 返回客户端本轮应测试的目标
+func (p *plannerImpl) Assign(podName, testType string) ([]string, *models.TargetShard, error) {
+	records, err := p.clientManager.GetAllClients()
+	if err != nil {
+		return nil, nil, err
+	}
+	self, ok := records[podName]
+	if !ok {
+		return nil, nil, ErrUnknownClient
+	}
+
+	var candidates []candidate
+	switch testType {
+	case models.TestTypeHost:
+		candidates = hostCandidates(records)
+	case models.TestTypePod:
+		candidates = podCandidates(records, podName)
+	default:
+		return nil, nil, fmt.Errorf("不支持的测试类型: %s", testType)
+	}
+
+	cycle := p.cycle(self.NodeInfo.PodIP, testType, records)
+	offset := int64(hashString(podName))
+
+	// 同节点的目标每轮都测试
+	pinned := make(map[string]bool)
+	var rest []candidate
+	zones := make(map[string][]candidate)
+	for _, c := range candidates {
+		if c.nodeIP == self.NodeInfo.NodeIP {
+			pinned[c.ip] = true
+			continue
+		}
+		rest = append(rest, c)
+		if c.zone != "" && c.zone != self.NodeInfo.Zone {
+			zones[c.zone] = append(zones[c.zone], c)
+		}
+	}
+
+	// 每个其他可用区每轮测试一个目标，按轮次轮换
+	for _, members := range zones {
+		pinned[members[(offset+cycle)%int64(len(members))].ip] = true
+	}
+
+	// 其余目标分为 count 个分片，每轮测试一个分片，count 轮内覆盖全部目标
+	perCycle := p.targetsPerCycle - len(pinned)
+	if perCycle < 1 {
+		perCycle = 1
+	}
+	count := (len(rest) + perCycle - 1) / perCycle
+	if count < 1 {
+		count = 1
+	}
+	index := int((offset + cycle) % int64(count))
+
+	targets := make([]string, 0, len(pinned)+perCycle)
+	for ip := range pinned {
+		targets = append(targets, ip)
+	}
+	for i, c := range rest {
+		if i%count == index && !pinned[c.ip] {
+			targets = append(targets, c.ip)
+		}
+	}
+	sort.Strings(targets)
+
+	return targets, &models.TargetShard{
+		Cycle:  cycle,
+		Index:  index,
+		Count:  count,
+		Total:  len(candidates),
+		Pinned: len(pinned),
+	}, nil
+}
+
+// OnTestResults 记录客户端已上报本轮的测试结果
+func (p *plannerImpl) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if state, ok := p.cycles[cycleKey{podIP: sourceIP, testType: testType}]; ok {
+		state.reported = true
+	}
+}
+
+// cycle 返回客户端此测试类型的轮次，上一轮的分片已收到测试结果时进入新的一轮，同时清理已注销客户端的轮次
+func (p *plannerImpl) cycle(podIP, testType string, records map[string]*models.ClientRecord) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	registered := make(map[string]bool, len(records))
+	for _, record := range records {
+		registered[record.NodeInfo.PodIP] = true
+	}
+	for key := range p.cycles {
+		if !registered[key.podIP] {
+			delete(p.cycles, key)
+		}
+	}
+
+	key := cycleKey{podIP: podIP, testType: testType}
+	state, ok := p.cycles[key]
+	if !ok {
+		state = &cycleState{}
+		p.cycles[key] = state
+	} else if state.reported {
+		state.cycle++
+		state.reported = false
+	}
+	return state.cycle
+}
+
+// hostCandidates 返回所有宿主机IP，按IP排序
+func hostCandidates(records map[string]*models.ClientRecord) []candidate {
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, record := range records {
+		nodeIP := record.NodeInfo.NodeIP
+		if nodeIP == "" || seen[nodeIP] {
+			continue
+		}
+		seen[nodeIP] = true
+		candidates = append(candidates, candidate{ip: nodeIP, nodeIP: nodeIP, zone: record.NodeInfo.Zone})
+	}
+	sortCandidates(candidates)
+	return candidates
+}
+
+// podCandidates 返回除自身外的所有Pod IP，按IP排序
+func podCandidates(records map[string]*models.ClientRecord, self string) []candidate {
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for podName, record := range records {
+		podIP := record.NodeInfo.PodIP
+		if podName == self || podIP == "" || seen[podIP] {
+			continue
+		}
+		seen[podIP] = true
+		candidates = append(candidates, candidate{ip: podIP, nodeIP: record.NodeInfo.NodeIP, zone: record.NodeInfo.Zone})
+	}
+	sortCandidates(candidates)
+	return candidates
+}
+
+// sortCandidates 按IP排序，保证同一组目标在各轮中的分片一致
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ip < candidates[j].ip
+	})
+}
+
+// hashString 计算字符串哈希，使不同客户端从不同的分片开始，分散对同一目标的探测
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
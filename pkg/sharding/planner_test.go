@@ -0,0 +1,149 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupClients 注册 count 个客户端，前一半在 zone-a，后一半在 zone-b；pod-0 与 pod-1 在同一节点
+func setupClients(t *testing.T, count int) client.ClientManager {
+	clientManager := client.NewClientManager(cache.NewCacheManager())
+	for i := 0; i < count; i++ {
+		zone := "zone-a"
+		if i >= count/2 {
+			zone = "zone-b"
+		}
+		node := i
+		if i == 1 {
+			node = 0
+		}
+		require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{
+			PodName: fmt.Sprintf("pod-%d", i),
+			NodeIP:  fmt.Sprintf("192.168.1.%d", 100+node),
+			PodIP:   fmt.Sprintf("10.0.0.%d", 100+i),
+			Zone:    zone,
+		}))
+	}
+	return clientManager
+}
+
+// report 模拟客户端上报一轮Pod测试结果
+func report(planner Planner, sourceIP string, targets []string) {
+	results := make([]models.ConnectivityResult, 0, len(targets))
+	for _, ip := range targets {
+		results = append(results, models.ConnectivityResult{TargetIP: ip})
+	}
+	planner.OnTestResults(models.TestTypePod, sourceIP, results)
+}
+
+// TestAssignCoverage 测试每轮目标数量受限，且分片数轮内覆盖全部目标
+func TestAssignCoverage(t *testing.T) {
+	planner := NewPlanner(setupClients(t, 20), 5)
+
+	covered := make(map[string]bool)
+	targets, shard, err := planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, 19, shard.Total)
+	assert.Equal(t, int64(0), shard.Cycle)
+
+	for cycle := 0; cycle < shard.Count; cycle++ {
+		if cycle > 0 {
+			report(planner, "10.0.0.100", targets)
+			targets, shard, err = planner.Assign("pod-0", models.TestTypePod)
+			require.NoError(t, err)
+			assert.Equal(t, int64(cycle), shard.Cycle)
+		}
+		assert.LessOrEqual(t, len(targets), 6)
+		// 同节点与跨可用区的目标每轮都测试
+		assert.Contains(t, targets, "10.0.0.101")
+		crossZone := false
+		for _, ip := range targets {
+			covered[ip] = true
+			if ip >= "10.0.0.110" {
+				crossZone = true
+			}
+		}
+		assert.True(t, crossZone, "每轮应包含跨可用区目标")
+	}
+	assert.Len(t, covered, 19)
+	assert.NotContains(t, covered, "10.0.0.100")
+}
+
+// TestAssignStableWithinCycle 测试上报结果前重复请求返回相同的分片
+func TestAssignStableWithinCycle(t *testing.T) {
+	planner := NewPlanner(setupClients(t, 20), 5)
+
+	first, shard, err := planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), shard.Cycle)
+
+	// 重试请求、其他客户端与其他测试类型的结果不影响轮次
+	again, _, err := planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+	report(planner, "10.0.0.102", first)
+	planner.OnTestResults(models.TestTypeHost, "10.0.0.100", nil)
+	again, shard, err = planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), shard.Cycle)
+	assert.Equal(t, first, again)
+
+	report(planner, "10.0.0.100", first)
+	next, shard, err := planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), shard.Cycle)
+	assert.NotEqual(t, first, next)
+}
+
+// TestAssignFollowsClientInterval 测试轮换节奏跟随各客户端的测试间隔，而不是固定的时间间隔
+func TestAssignFollowsClientInterval(t *testing.T) {
+	planner := NewPlanner(setupClients(t, 20), 5)
+
+	// pod-0 每15秒测试一次，pod-2 每90秒测试一次：3分钟内 pod-0 完成12轮，pod-2 完成2轮
+	for elapsed := 0; elapsed < 180; elapsed += 15 {
+		targets, _, err := planner.Assign("pod-0", models.TestTypePod)
+		require.NoError(t, err)
+		report(planner, "10.0.0.100", targets)
+		if elapsed%90 == 0 {
+			targets, _, err = planner.Assign("pod-2", models.TestTypePod)
+			require.NoError(t, err)
+			report(planner, "10.0.0.102", targets)
+		}
+	}
+
+	_, shard, err := planner.Assign("pod-0", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), shard.Cycle)
+	_, shard, err = planner.Assign("pod-2", models.TestTypePod)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), shard.Cycle)
+}
+
+// TestAssignHosts 测试宿主机目标分片包含本节点
+func TestAssignHosts(t *testing.T) {
+	planner := NewPlanner(setupClients(t, 10), 3)
+
+	targets, shard, err := planner.Assign("pod-2", models.TestTypeHost)
+	require.NoError(t, err)
+	assert.Equal(t, 9, shard.Total)
+	assert.Contains(t, targets, "192.168.1.102")
+	assert.LessOrEqual(t, len(targets), 3)
+}
+
+// TestAssignErrors 测试未注册客户端与不支持的测试类型
+func TestAssignErrors(t *testing.T) {
+	planner := NewPlanner(setupClients(t, 4), 2)
+
+	_, _, err := planner.Assign("missing", models.TestTypePod)
+	assert.ErrorIs(t, err, ErrUnknownClient)
+
+	_, _, err = planner.Assign("pod-0", models.TestTypeService)
+	assert.Error(t, err)
+}
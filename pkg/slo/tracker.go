@@ -0,0 +1,558 @@
+// Package slo 根据探测历史统计节点与集群在滚动窗口（1h、24h、30d）内的可用性，
+// 并按可用性目标计算错误预算的消耗速率与剩余预算。
+// 历史按分钟与小时两种精度分桶保存：1h窗口使用分钟桶，24h与30d窗口使用小时桶，
+// 统计边界的精度为一个分桶。探测对数量随节点数平方增长，小时桶只保留24h，
+// 30d窗口降采样为天桶统计。探测结果按探测时间计入分桶，
+// 缺少探测时间或探测时间晚于当前时间时按收到结果的时间计入。
+// 配置状态文件后定期写入磁盘，服务器重启后继续统计
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/fileutil"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+const (
+	fineResolution   = time.Minute    // 1h窗口的分桶精度
+	coarseResolution = time.Hour      // 24h与30d窗口的分桶精度
+	dailyResolution  = 24 * time.Hour // 探测对30d窗口的分桶精度
+
+	pairRetention = 24 * time.Hour // 探测对小时桶的保留时长，更早的历史只保留天桶
+
+	// DefaultObjective 默认的可用性目标（百分比）
+	DefaultObjective = 99.9
+)
+
+// window 统计窗口
+type window struct {
+	name   string
+	length time.Duration
+}
+
+// windows 按长度递增，最后一个为错误预算窗口
+var windows = []window{
+	{models.SLOWindowHour, time.Hour},
+	{models.SLOWindowDay, 24 * time.Hour},
+	{models.SLOWindowMonth, 30 * 24 * time.Hour},
+}
+
+// retention 节点与集群小时桶以及探测对天桶的保留时长
+var retention = windows[len(windows)-1].length
+
+// 节点在探测中的角色
+const (
+	roleSource = "source"
+	roleTarget = "target"
+)
+
+// Config SLO统计配置
+type Config struct {
+	Objective float64 // 可用性目标（百分比），取值 (0, 100)
+	StateFile string  // 状态文件路径，为空时只保存在内存中
+}
+
+// Tracker 定义SLO统计接口
+type Tracker interface {
+	// OnTestResults 实现 result.ResultObserver，记录宿主机与Pod探测的每次结果
+	OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult)
+
+	// Snapshot 返回当前的可用性统计，includePairs 为 false 时不含探测对明细
+	Snapshot(includePairs bool) *models.SLOReport
+
+	// Enrich 实现 report.Enricher，在报告中加入集群与节点的可用性
+	Enrich(report *models.NetworkReport)
+
+	// Start 启动定期清理过期历史并写入状态文件，ctx 取消时再写入一次
+	Start(ctx context.Context, interval time.Duration) error
+
+	// Save 将历史写入状态文件，未配置状态文件时不做任何事
+	Save() error
+}
+
+// bucket 一个分桶内的探测次数
+type bucket struct {
+	Start int64 `json:"start"` // 分桶开始的Unix时间（秒）
+	Good  int64 `json:"good"`
+	Total int64 `json:"total"`
+}
+
+// pairKey 探测对标识
+type pairKey struct {
+	testType string
+	sourceIP string
+	targetIP string
+}
+
+// history 一个统计对象的探测历史
+type history struct {
+	Fine   []bucket `json:"fine"`            // 分钟桶，保留1h
+	Coarse []bucket `json:"coarse"`          // 小时桶，探测对保留24h，节点与集群保留30d
+	Daily  []bucket `json:"daily,omitempty"` // 天桶，只用于探测对，保留30d
+}
+
+// pairHistory 探测对的探测历史
+type pairHistory struct {
+	TestType   string `json:"test_type"`
+	SourceIP   string `json:"source_ip"`
+	TargetIP   string `json:"target_ip"`
+	SourceNode string `json:"source_node,omitempty"`
+	TargetNode string `json:"target_node,omitempty"`
+	history
+}
+
+// nodeKey 节点统计标识
+type nodeKey struct {
+	node string
+	role string
+}
+
+// nodeHistory 节点作为探测源或探测目标的探测历史
+type nodeHistory struct {
+	Node string `json:"node"`
+	Role string `json:"role"` // source 或 target
+	history
+}
+
+// state 状态文件内容
+type state struct {
+	Cluster history        `json:"cluster"`
+	Nodes   []*nodeHistory `json:"nodes"`
+	Pairs   []*pairHistory `json:"pairs"`
+}
+
+// counts 各窗口的探测次数，顺序与 windows 一致
+type counts [3]bucket
+
+// trackerImpl 是Tracker的实现
+type trackerImpl struct {
+	config        Config
+	clientManager client.ClientManager
+
+	mu      sync.Mutex
+	cluster history
+	nodes   map[nodeKey]*nodeHistory
+	pairs   map[pairKey]*pairHistory
+	running bool
+	now     func() time.Time
+}
+
+// NewTracker 创建SLO统计器，配置了状态文件且文件存在时加载其中的历史
+func NewTracker(cfg Config, clientManager client.ClientManager) (Tracker, error) {
+	if cfg.Objective <= 0 || cfg.Objective >= 100 {
+		cfg.Objective = DefaultObjective
+	}
+
+	t := &trackerImpl{
+		config:        cfg,
+		clientManager: clientManager,
+		nodes:         make(map[nodeKey]*nodeHistory),
+		pairs:         make(map[pairKey]*pairHistory),
+		now:           time.Now,
+	}
+	if cfg.StateFile != "" {
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// OnTestResults 记录探测结果，自定义服务探测不计入
+func (t *trackerImpl) OnTestResults(testType string, sourceIP string, results []models.ConnectivityResult) {
+	if testType != models.TestTypeHost && testType != models.TestTypePod {
+		return
+	}
+	nodes := t.nodesByIP()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for _, result := range results {
+		if result.TargetIP == "" {
+			continue
+		}
+		key := pairKey{testType: testType, sourceIP: sourceIP, targetIP: result.TargetIP}
+		history, ok := t.pairs[key]
+		if !ok {
+			history = &pairHistory{TestType: testType, SourceIP: sourceIP, TargetIP: result.TargetIP}
+			t.pairs[key] = history
+		}
+
+		// 保留最后已知的节点，客户端下线后历史仍归属原节点
+		if node := nodes[sourceIP]; node != "" {
+			history.SourceNode = node
+		}
+		if node := targetNode(result.Target); node != "" {
+			history.TargetNode = node
+		} else if node := nodes[result.TargetIP]; node != "" {
+			history.TargetNode = node
+		}
+
+		good := result.Succeeded(testType)
+		at := probedAt(result, now)
+		history.record(at, now, pairRetention, good)
+		t.cluster.record(at, now, retention, good)
+		if history.SourceNode != "" {
+			t.node(history.SourceNode, roleSource).record(at, now, retention, good)
+		}
+		if history.TargetNode != "" {
+			t.node(history.TargetNode, roleTarget).record(at, now, retention, good)
+		}
+	}
+}
+
+// probedAt 返回结果的探测时间，缺少探测时间或晚于 now 时（客户端时钟超前）返回 now
+func probedAt(result models.ConnectivityResult, now time.Time) time.Time {
+	if result.Timestamp.IsZero() || result.Timestamp.After(now) {
+		return now
+	}
+	return result.Timestamp
+}
+
+// node 返回节点在指定角色下的探测历史，不存在时创建（调用者需持有锁）
+func (t *trackerImpl) node(name, role string) *nodeHistory {
+	key := nodeKey{node: name, role: role}
+	history, ok := t.nodes[key]
+	if !ok {
+		history = &nodeHistory{Node: name, Role: role}
+		t.nodes[key] = history
+	}
+	return history
+}
+
+// nodesByIP 返回已注册客户端的Pod IP与节点IP对应的节点
+func (t *trackerImpl) nodesByIP() map[string]string {
+	nodes := make(map[string]string)
+	if t.clientManager == nil {
+		return nodes
+	}
+	records, err := t.clientManager.GetAllClients()
+	if err != nil {
+		log.Printf("获取客户端列表失败: %v", err)
+		return nodes
+	}
+	for _, record := range records {
+		node := nodeName(record.NodeInfo.NodeName, record.NodeInfo.NodeIP)
+		nodes[record.NodeInfo.PodIP] = node
+		nodes[record.NodeInfo.NodeIP] = node
+	}
+	return nodes
+}
+
+// Snapshot 返回当前的可用性统计
+func (t *trackerImpl) Snapshot(includePairs bool) *models.SLOReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	report := &models.SLOReport{
+		Timestamp: now,
+		Objective: t.config.Objective,
+		Cluster:   t.status(t.cluster.counts(now, retention), windows),
+		Nodes:     []models.NodeSLO{},
+	}
+
+	names := make(map[string]bool)
+	for key := range t.nodes {
+		names[key.node] = true
+	}
+	for name := range names {
+		var source, target counts
+		if history, ok := t.nodes[nodeKey{node: name, role: roleSource}]; ok {
+			source = history.counts(now, retention)
+		}
+		if history, ok := t.nodes[nodeKey{node: name, role: roleTarget}]; ok {
+			target = history.counts(now, retention)
+		}
+		report.Nodes = append(report.Nodes, models.NodeSLO{
+			Node:     name,
+			AsSource: t.status(source, windows),
+			AsTarget: t.status(target, windows),
+		})
+	}
+
+	if includePairs {
+		for _, history := range t.pairs {
+			report.Pairs = append(report.Pairs, models.PairSLO{
+				TestType:   history.TestType,
+				SourceIP:   history.SourceIP,
+				TargetIP:   history.TargetIP,
+				SourceNode: history.SourceNode,
+				TargetNode: history.TargetNode,
+				SLOStatus:  t.status(history.counts(now, pairRetention), windows),
+			})
+		}
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Node < report.Nodes[j].Node })
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		a, b := report.Pairs[i], report.Pairs[j]
+		if a.TestType != b.TestType {
+			return a.TestType < b.TestType
+		}
+		if a.SourceIP != b.SourceIP {
+			return a.SourceIP < b.SourceIP
+		}
+		return a.TargetIP < b.TargetIP
+	})
+	return report
+}
+
+// Enrich 在报告中加入集群与节点的可用性，探测对明细通过SLO接口获取
+func (t *trackerImpl) Enrich(report *models.NetworkReport) {
+	report.SLO = t.Snapshot(false)
+}
+
+// status 根据 ws 中各窗口的探测次数计算可用性，错误预算按 ws 的最后一个窗口计算
+func (t *trackerImpl) status(c counts, ws []window) models.SLOStatus {
+	budget := 1 - t.config.Objective/100
+	status := models.SLOStatus{Windows: make([]models.SLOWindow, len(ws))}
+	for i, w := range ws {
+		sw := models.SLOWindow{Window: w.name, Total: c[i].Total, Good: c[i].Good, Availability: 100}
+		if c[i].Total > 0 {
+			errorRate := float64(c[i].Total-c[i].Good) / float64(c[i].Total)
+			sw.Availability = (1 - errorRate) * 100
+			sw.BurnRate = errorRate / budget
+		}
+		status.Windows[i] = sw
+	}
+	// 预算窗口的消耗速率为1时恰好耗尽预算
+	status.ErrorBudgetRemaining = (1 - status.Windows[len(ws)-1].BurnRate) * 100
+	return status
+}
+
+// Start 启动定期清理与持久化
+func (t *trackerImpl) Start(ctx context.Context, interval time.Duration) error {
+	t.mu.Lock()
+	if t.running {
+		t.mu.Unlock()
+		return fmt.Errorf("SLO统计器已经在运行")
+	}
+	t.running = true
+	t.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("SLO统计器已启动，可用性目标: %.3f%%, 状态文件: %q", t.config.Objective, t.config.StateFile)
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("SLO统计器收到context取消信号，正在停止...")
+				if err := t.Save(); err != nil {
+					log.Printf("保存SLO状态失败: %v", err)
+				}
+				t.mu.Lock()
+				t.running = false
+				t.mu.Unlock()
+				return
+			case <-ticker.C:
+				t.mu.Lock()
+				t.prune(t.now())
+				t.mu.Unlock()
+				if err := t.Save(); err != nil {
+					log.Printf("保存SLO状态失败: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Save 将历史写入状态文件
+func (t *trackerImpl) Save() error {
+	if t.config.StateFile == "" {
+		return nil
+	}
+
+	// 持有锁时只复制历史，序列化在锁外进行，避免阻塞结果记录
+	t.mu.Lock()
+	snapshot := state{
+		Cluster: t.cluster.clone(),
+		Nodes:   make([]*nodeHistory, 0, len(t.nodes)),
+		Pairs:   make([]*pairHistory, 0, len(t.pairs)),
+	}
+	for _, history := range t.nodes {
+		copied := *history
+		copied.history = history.clone()
+		snapshot.Nodes = append(snapshot.Nodes, &copied)
+	}
+	for _, history := range t.pairs {
+		copied := *history
+		copied.history = history.clone()
+		snapshot.Pairs = append(snapshot.Pairs, &copied)
+	}
+	t.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("序列化SLO状态失败: %w", err)
+	}
+
+	if err := fileutil.WriteFile(t.config.StateFile, data, 0o644); err != nil {
+		return fmt.Errorf("写入SLO状态文件失败: %w", err)
+	}
+	return nil
+}
+
+// load 加载状态文件中的历史，文件不存在时从空历史开始
+func (t *trackerImpl) load() error {
+	data, err := os.ReadFile(t.config.StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取SLO状态文件失败: %w", err)
+	}
+
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("解析SLO状态文件失败: %w", err)
+	}
+	t.cluster = saved.Cluster
+	for _, history := range saved.Nodes {
+		t.nodes[nodeKey{node: history.Node, role: history.Role}] = history
+	}
+	for _, history := range saved.Pairs {
+		t.pairs[pairKey{testType: history.TestType, sourceIP: history.SourceIP, targetIP: history.TargetIP}] = history
+	}
+	t.prune(t.now())
+	log.Printf("已加载SLO状态: 节点数量=%d, 探测对数量=%d", len(t.nodes), len(t.pairs))
+	return nil
+}
+
+// prune 删除超出保留时长的分桶，以及保留时长内没有探测的节点与探测对（调用者需持有锁）
+func (t *trackerImpl) prune(now time.Time) {
+	t.cluster.trim(now, retention)
+	for key, history := range t.nodes {
+		if history.trim(now, retention) {
+			delete(t.nodes, key)
+		}
+	}
+	for key, history := range t.pairs {
+		if history.trim(now, pairRetention) {
+			delete(t.pairs, key)
+		}
+	}
+}
+
+// record 将 at 时刻的一次探测结果计入分钟桶与小时桶，小时桶保留 coarseRetention，
+// coarseRetention 短于 retention 时另计入保留 retention 的天桶
+func (h *history) record(at, now time.Time, coarseRetention time.Duration, good bool) {
+	h.Fine = record(h.Fine, at, now, fineResolution, time.Hour, good)
+	h.Coarse = record(h.Coarse, at, now, coarseResolution, coarseRetention, good)
+	if coarseRetention < retention {
+		h.Daily = record(h.Daily, at, now, dailyResolution, retention, good)
+	}
+}
+
+// trim 删除超出保留时长的分桶，返回历史是否已为空
+func (h *history) trim(now time.Time, coarseRetention time.Duration) bool {
+	h.Fine = trim(h.Fine, now, fineResolution, time.Hour)
+	h.Coarse = trim(h.Coarse, now, coarseResolution, coarseRetention)
+	h.Daily = trim(h.Daily, now, dailyResolution, retention)
+	return len(h.Coarse) == 0 && len(h.Daily) == 0
+}
+
+// clone 返回历史的副本
+func (h *history) clone() history {
+	return history{
+		Fine:   append([]bucket(nil), h.Fine...),
+		Coarse: append([]bucket(nil), h.Coarse...),
+		Daily:  append([]bucket(nil), h.Daily...),
+	}
+}
+
+// counts 返回各窗口的探测次数，1h窗口使用分钟桶，不超过小时桶保留时长 coarseRetention
+// 的窗口使用小时桶，更长的窗口使用天桶
+func (h *history) counts(now time.Time, coarseRetention time.Duration) counts {
+	var c counts
+	for i, w := range windows {
+		switch {
+		case w.length <= time.Hour:
+			c[i] = sum(h.Fine, now, fineResolution, w.length)
+		case w.length <= coarseRetention:
+			c[i] = sum(h.Coarse, now, coarseResolution, w.length)
+		default:
+			c[i] = sum(h.Daily, now, dailyResolution, w.length)
+		}
+	}
+	return c
+}
+
+// record 将 at 时刻的一次探测结果计入所在的分桶，并删除超出保留时长的分桶
+// 分桶按时间递增，早于保留时长的结果不计入
+func record(buckets []bucket, at, now time.Time, resolution, retention time.Duration, good bool) []bucket {
+	seconds := int64(resolution / time.Second)
+	start := at.Unix() - at.Unix()%seconds
+	if start+seconds > now.Add(-retention).Unix() {
+		i := sort.Search(len(buckets), func(i int) bool { return buckets[i].Start >= start })
+		if i == len(buckets) || buckets[i].Start != start {
+			buckets = append(buckets, bucket{})
+			copy(buckets[i+1:], buckets[i:])
+			buckets[i] = bucket{Start: start}
+		}
+		buckets[i].Total++
+		if good {
+			buckets[i].Good++
+		}
+	}
+	return trim(buckets, now, resolution, retention)
+}
+
+// trim 删除结束时间早于保留时长的分桶
+func trim(buckets []bucket, now time.Time, resolution, retention time.Duration) []bucket {
+	cutoff := now.Add(-retention).Unix()
+	seconds := int64(resolution / time.Second)
+	i := 0
+	for i < len(buckets) && buckets[i].Start+seconds <= cutoff {
+		i++
+	}
+	return buckets[i:]
+}
+
+// sum 累加与窗口有重叠的分桶
+func sum(buckets []bucket, now time.Time, resolution, length time.Duration) bucket {
+	cutoff := now.Add(-length).Unix()
+	seconds := int64(resolution / time.Second)
+	var total bucket
+	for _, b := range buckets {
+		if b.Start+seconds > cutoff {
+			total.Good += b.Good
+			total.Total += b.Total
+		}
+	}
+	return total
+}
+
+// targetNode 返回测试结果中标注的目标节点
+func targetNode(target *models.Target) string {
+	if target == nil {
+		return ""
+	}
+	return nodeName(target.NodeName, target.NodeIP)
+}
+
+// nodeName 返回节点名称，客户端未上报节点名称时使用节点IP
+func nodeName(name, ip string) string {
+	if name != "" {
+		return name
+	}
+	return ip
+}
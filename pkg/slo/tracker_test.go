@@ -0,0 +1,198 @@
+package slo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probe 返回一次Pod探测结果
+func probe(targetIP, targetNode string, ok bool) models.ConnectivityResult {
+	result := models.ConnectivityResult{TargetIP: targetIP, PingStatus: "reachable", PortStatus: map[int]string{6100: "open"}}
+	if !ok {
+		result.PingStatus = "unreachable"
+	}
+	if targetNode != "" {
+		result.Target = &models.Target{IP: targetIP, NodeName: targetNode}
+	}
+	return result
+}
+
+// newTestTracker 创建使用固定时间的统计器，注册 node-1 上的客户端 10.0.0.1
+func newTestTracker(t *testing.T, stateFile string, now *time.Time) *trackerImpl {
+	clientManager := client.NewClientManager(cache.NewCacheManager())
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{
+		PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", NodeName: "node-1",
+	}))
+	tracker, err := NewTracker(Config{Objective: 99, StateFile: stateFile}, clientManager)
+	require.NoError(t, err)
+	impl := tracker.(*trackerImpl)
+	impl.now = func() time.Time { return *now }
+	return impl
+}
+
+// TestSnapshotWindows 测试各滚动窗口的可用性、消耗速率与剩余错误预算
+func TestSnapshotWindows(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, "", &now)
+
+	// 两天前: 100次探测失败10次
+	now = now.Add(-48 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{probe("10.0.0.2", "node-2", i >= 10)})
+	}
+	// 两小时前: 100次探测全部成功
+	now = now.Add(46 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{probe("10.0.0.2", "node-2", true)})
+	}
+	// 最近一小时: 100次探测失败1次
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{probe("10.0.0.2", "node-2", i > 0)})
+	}
+	// 自定义服务探测不计入
+	tracker.OnTestResults(models.TestTypeService, "10.0.0.1", []models.ConnectivityResult{probe("10.96.0.1", "", false)})
+
+	report := tracker.Snapshot(true)
+	assert.Equal(t, float64(99), report.Objective)
+
+	hour := report.Cluster.Window(models.SLOWindowHour)
+	assert.Equal(t, int64(100), hour.Total)
+	assert.Equal(t, int64(99), hour.Good)
+	assert.InDelta(t, 99, hour.Availability, 1e-9)
+	assert.InDelta(t, 1, hour.BurnRate, 1e-9)
+
+	day := report.Cluster.Window(models.SLOWindowDay)
+	assert.Equal(t, int64(200), day.Total)
+	assert.InDelta(t, 99.5, day.Availability, 1e-9)
+
+	month := report.Cluster.Window(models.SLOWindowMonth)
+	assert.Equal(t, int64(300), month.Total)
+	assert.Equal(t, int64(289), month.Good)
+	// 30d错误率 11/300，预算 1%，消耗速率约3.67，预算已超支
+	assert.InDelta(t, 11.0/3, month.BurnRate, 1e-9)
+	assert.InDelta(t, (1-11.0/3)*100, report.Cluster.ErrorBudgetRemaining, 1e-9)
+
+	// 源节点来自客户端注册信息，目标节点来自结果中的目标身份
+	require.Len(t, report.Nodes, 2)
+	assert.Equal(t, "node-1", report.Nodes[0].Node)
+	assert.Equal(t, int64(300), report.Nodes[0].AsSource.Window(models.SLOWindowMonth).Total)
+	assert.Equal(t, int64(0), report.Nodes[0].AsTarget.Window(models.SLOWindowMonth).Total)
+	assert.Equal(t, float64(100), report.Nodes[0].AsTarget.Window(models.SLOWindowMonth).Availability)
+	assert.Equal(t, "node-2", report.Nodes[1].Node)
+	assert.Equal(t, int64(289), report.Nodes[1].AsTarget.Window(models.SLOWindowMonth).Good)
+
+	// 探测对同样统计三个窗口，错误预算按30d窗口计算
+	require.Len(t, report.Pairs, 1)
+	assert.Equal(t, "node-1", report.Pairs[0].SourceNode)
+	assert.Equal(t, "node-2", report.Pairs[0].TargetNode)
+	require.Len(t, report.Pairs[0].Windows, 3)
+	assert.Equal(t, int64(200), report.Pairs[0].Window(models.SLOWindowDay).Total)
+	assert.Equal(t, int64(300), report.Pairs[0].Window(models.SLOWindowMonth).Total)
+	assert.InDelta(t, (1-11.0/3)*100, report.Pairs[0].ErrorBudgetRemaining, 1e-9)
+	assert.Empty(t, tracker.Snapshot(false).Pairs)
+
+	// 探测对小时桶24h后过期，30d窗口由天桶继续统计
+	now = now.Add(25 * time.Hour)
+	report = tracker.Snapshot(true)
+	require.Len(t, report.Pairs, 1)
+	assert.Equal(t, int64(0), report.Pairs[0].Window(models.SLOWindowDay).Total)
+	assert.Equal(t, int64(300), report.Pairs[0].Window(models.SLOWindowMonth).Total)
+	assert.Len(t, report.Nodes, 2)
+	assert.Equal(t, int64(300), report.Cluster.Window(models.SLOWindowMonth).Total)
+
+	// 超过30d后历史全部过期
+	now = now.Add(30 * 24 * time.Hour)
+	report = tracker.Snapshot(true)
+	assert.Empty(t, report.Pairs)
+	assert.Empty(t, report.Nodes)
+	assert.Equal(t, float64(100), report.Cluster.ErrorBudgetRemaining)
+}
+
+// TestProbeTimestamp 测试结果按探测时间计入分桶
+func TestProbeTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 30, 0, 0, time.UTC)
+	tracker := newTestTracker(t, "", &now)
+
+	// 重放三小时前的失败结果，不计入1h窗口
+	earlier := probe("10.0.0.2", "node-2", false)
+	earlier.Timestamp = now.Add(-3 * time.Hour)
+	// 时间晚于当前时间的结果按当前时间计入
+	future := probe("10.0.0.2", "node-2", true)
+	future.Timestamp = now.Add(time.Hour)
+	// 早于30d的结果不计入
+	expired := probe("10.0.0.2", "node-2", false)
+	expired.Timestamp = now.Add(-31 * 24 * time.Hour)
+
+	tracker.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{
+		probe("10.0.0.2", "node-2", true), earlier, future, expired,
+	})
+
+	report := tracker.Snapshot(true)
+	for _, status := range []models.SLOStatus{report.Cluster, report.Pairs[0].SLOStatus} {
+		hour := status.Window(models.SLOWindowHour)
+		assert.Equal(t, int64(2), hour.Total)
+		assert.Equal(t, int64(2), hour.Good)
+		day := status.Window(models.SLOWindowDay)
+		assert.Equal(t, int64(3), day.Total)
+		assert.Equal(t, int64(2), day.Good)
+		assert.Equal(t, int64(3), status.Window(models.SLOWindowMonth).Total)
+	}
+
+	// 重放的结果插入到按时间排序的分桶中
+	pair := tracker.pairs[pairKey{testType: models.TestTypePod, sourceIP: "10.0.0.1", targetIP: "10.0.0.2"}]
+	require.Len(t, pair.Coarse, 2)
+	assert.Equal(t, now.Add(-3*time.Hour).Truncate(time.Hour).Unix(), pair.Coarse[0].Start)
+	assert.Equal(t, int64(0), pair.Coarse[0].Good)
+	assert.Equal(t, int64(2), pair.Coarse[1].Total)
+}
+
+// TestEnrich 测试报告中加入不含探测对明细的可用性
+func TestEnrich(t *testing.T) {
+	now := time.Now()
+	tracker := newTestTracker(t, "", &now)
+	tracker.OnTestResults(models.TestTypeHost, "10.0.0.1", []models.ConnectivityResult{probe("192.168.1.2", "node-2", true)})
+
+	report := &models.NetworkReport{}
+	tracker.Enrich(report)
+	require.NotNil(t, report.SLO)
+	assert.Equal(t, int64(1), report.SLO.Cluster.Window(models.SLOWindowHour).Total)
+	assert.Len(t, report.SLO.Nodes, 2)
+	assert.Empty(t, report.SLO.Pairs)
+}
+
+// TestStateFile 测试历史写入状态文件，重启后继续统计
+func TestStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "slo.json")
+	now := time.Now()
+	tracker := newTestTracker(t, stateFile, &now)
+	tracker.OnTestResults(models.TestTypePod, "10.0.0.1", []models.ConnectivityResult{
+		probe("10.0.0.2", "node-2", true),
+		probe("10.0.0.3", "node-2", false),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, tracker.Start(ctx, time.Hour))
+	assert.Error(t, tracker.Start(ctx, time.Hour))
+	cancel()
+	assert.Eventually(t, func() bool {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		return !tracker.running
+	}, time.Second, 10*time.Millisecond)
+
+	restored := newTestTracker(t, stateFile, &now)
+	report := restored.Snapshot(true)
+	require.Len(t, report.Pairs, 2)
+	assert.Equal(t, int64(1), report.Cluster.Window(models.SLOWindowHour).Good)
+	assert.Equal(t, "node-1", report.Pairs[1].SourceNode)
+}
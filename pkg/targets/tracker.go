@@ -0,0 +1,267 @@
+// Package targets 为宿主机与Pod目标列表维护版本号与变更记录
+// 列表内容变化时才产生新版本，客户端可携带已有版本获取增量变更，
+// 列表未变化时无需重复传输，减少大规模集群中的带宽与序列化开销
+package targets
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+)
+
+// defaultMaxChanges 每种列表保留的变更记录数量，更早的版本只能获取完整列表
+const defaultMaxChanges = 128
+
+// List 目标列表，或自指定版本以来的增量变更
+type List struct {
+	ETag    string          // 当前版本标识，客户端下次请求时携带
+	Version int64           // 当前版本号，取自列表变化时的全局版本号
+	Delta   bool            // 是否为增量变更
+	Targets []models.Target // 按IP排序的完整列表，Delta 为 false 时有效
+	Added   []models.Target // 新增或属性变化的目标，Delta 为 true 时有效
+	Removed []string        // 移除的目标IP，Delta 为 true 时有效
+	Count   int             // 当前列表中的目标数量
+}
+
+// NotModified 判断客户端已有的版本标识是否为当前版本
+func (l *List) NotModified(etag string) bool {
+	return etag != "" && etag == l.ETag
+}
+
+// Tracker 定义目标列表版本管理接口
+type Tracker interface {
+	// Lookup 返回当前目标列表，testType 取值为 models.TestTypeHost 或 models.TestTypePod
+	// since 为客户端已有版本的标识，变更记录覆盖该版本时返回增量变更，否则返回完整列表；
+	// 目标的IP不变但节点、可用区或标签变化时同样产生新版本
+	Lookup(testType, since string) (*List, error)
+}
+
+// change 一次列表变化
+type change struct {
+	version int64
+	added   []models.Target
+	removed []string
+}
+
+// listState 一种目标列表的当前内容与变更记录
+type listState struct {
+	version int64
+	targets map[string]models.Target // key为目标IP
+	base    int64                    // 最早一条变更记录之前的版本号
+	changes []change                 // 按版本号递增
+}
+
+// trackerImpl 是Tracker的实现
+type trackerImpl struct {
+	clientManager client.ClientManager
+	cacheManager  cache.CacheManager
+	epoch         string // 服务器实例标识，重启后版本号重新计数，旧版本标识不再匹配
+	maxChanges    int
+
+	mu    sync.Mutex
+	lists map[string]*listState
+}
+
+// NewTracker 创建目标列表版本管理器，版本号取自 CacheManager 的全局版本号
+func NewTracker(clientManager client.ClientManager, cacheManager cache.CacheManager) Tracker {
+	return &trackerImpl{
+		clientManager: clientManager,
+		cacheManager:  cacheManager,
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		maxChanges:    defaultMaxChanges,
+		lists:         make(map[string]*listState),
+	}
+}
+
+// Lookup 返回当前目标列表或增量变更
+func (t *trackerImpl) Lookup(testType, since string) (*List, error) {
+	if testType != models.TestTypeHost && testType != models.TestTypePod {
+		return nil, fmt.Errorf("不支持的测试类型: %s", testType)
+	}
+	records, err := t.clientManager.GetAllClients()
+	if err != nil {
+		return nil, err
+	}
+	targets := FromClients(records, testType)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.lists[testType]
+	if !ok {
+		state = &listState{targets: make(map[string]models.Target)}
+		t.lists[testType] = state
+	}
+	if err := t.update(state, targets); err != nil {
+		return nil, err
+	}
+
+	list := &List{
+		ETag:    t.etag(testType, state.version),
+		Version: state.version,
+		Count:   len(state.targets),
+	}
+	if version, ok := t.parseETag(testType, since); ok && version >= state.base && version <= state.version {
+		list.Delta = true
+		list.Added, list.Removed = state.since(version)
+		return list, nil
+	}
+
+	list.Targets = sortedTargets(state.targets)
+	return list, nil
+}
+
+// update 列表内容变化时记录变更并产生新版本（调用者需持有锁）
+func (t *trackerImpl) update(state *listState, targets []models.Target) error {
+	current := make(map[string]models.Target, len(targets))
+	var added []models.Target
+	for _, target := range targets {
+		current[target.IP] = target
+		if previous, ok := state.targets[target.IP]; !ok || !equalTargets(previous, target) {
+			added = append(added, target)
+		}
+	}
+	var removed []string
+	for ip := range state.targets {
+		if _, ok := current[ip]; !ok {
+			removed = append(removed, ip)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 && state.version > 0 {
+		return nil
+	}
+
+	// 客户端过期不会递增全局版本号，此时在上一版本的基础上递增
+	version, err := t.cacheManager.GetCurrentVersion()
+	if err != nil {
+		return fmt.Errorf("获取当前版本号失败: %w", err)
+	}
+	if version <= state.version {
+		version = state.version + 1
+	}
+
+	if state.version == 0 {
+		// 首个版本没有可供增量计算的旧版本
+		state.base = version
+	} else {
+		sort.Slice(added, func(i, j int) bool { return added[i].IP < added[j].IP })
+		sort.Strings(removed)
+		state.changes = append(state.changes, change{version: version, added: added, removed: removed})
+		if len(state.changes) > t.maxChanges {
+			state.base = state.changes[0].version
+			state.changes = state.changes[1:]
+		}
+	}
+	state.version = version
+	state.targets = current
+	return nil
+}
+
+// since 合并 version 之后的全部变更（调用者需持有锁）
+// 客户端按集合语义应用变更：先删除 removed，再以 added 覆盖同IP的目标
+func (s *listState) since(version int64) ([]models.Target, []string) {
+	added := make(map[string]models.Target)
+	removed := make(map[string]bool)
+	for _, c := range s.changes {
+		if c.version <= version {
+			continue
+		}
+		for _, target := range c.added {
+			delete(removed, target.IP)
+			added[target.IP] = target
+		}
+		for _, ip := range c.removed {
+			delete(added, ip)
+			removed[ip] = true
+		}
+	}
+	return sortedTargets(added), sortedKeys(removed)
+}
+
+// etag 返回版本标识，由服务器实例标识、列表类型与版本号组成
+func (t *trackerImpl) etag(testType string, version int64) string {
+	return t.epoch + "-" + testType + "-" + strconv.FormatInt(version, 10)
+}
+
+// parseETag 解析本实例为该类型列表产生的版本标识，其他标识返回 false
+func (t *trackerImpl) parseETag(testType, etag string) (int64, bool) {
+	version, ok := strings.CutPrefix(etag, t.epoch+"-"+testType+"-")
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// FromClients 根据已注册客户端生成探测目标，按IP排序
+// 宿主机目标每个节点一个，取Pod名称最小的客户端的节点信息；Pod目标每个客户端一个
+func FromClients(records map[string]*models.ClientRecord, testType string) []models.Target {
+	names := slices.Sorted(maps.Keys(records))
+	targets := make(map[string]models.Target, len(records))
+	for _, name := range names {
+		info := records[name].NodeInfo
+		switch testType {
+		case models.TestTypeHost:
+			if _, ok := targets[info.NodeIP]; ok || info.NodeIP == "" {
+				continue
+			}
+			targets[info.NodeIP] = models.Target{
+				IP:       info.NodeIP,
+				NodeName: info.NodeName,
+				NodeIP:   info.NodeIP,
+				Zone:     info.Zone,
+			}
+		case models.TestTypePod:
+			if info.PodIP == "" {
+				continue
+			}
+			targets[info.PodIP] = models.Target{
+				IP:       info.PodIP,
+				PodName:  info.PodName,
+				PodUID:   info.PodUID,
+				NodeName: info.NodeName,
+				NodeIP:   info.NodeIP,
+				Zone:     info.Zone,
+				Labels:   info.Labels,
+			}
+		}
+	}
+	return sortedTargets(targets)
+}
+
+// equalTargets 判断两个目标的身份与属性是否相同
+func equalTargets(a, b models.Target) bool {
+	return a.IP == b.IP && a.PodName == b.PodName && a.PodUID == b.PodUID && a.NodeName == b.NodeName && a.NodeIP == b.NodeIP &&
+		a.Zone == b.Zone && slices.Equal(a.Ports, b.Ports) && maps.Equal(a.Labels, b.Labels)
+}
+
+// sortedTargets 返回按IP排序的目标
+func sortedTargets(targets map[string]models.Target) []models.Target {
+	result := make([]models.Target, 0, len(targets))
+	for _, ip := range slices.Sorted(maps.Keys(targets)) {
+		result = append(result, targets[ip])
+	}
+	return result
+}
+
+// sortedKeys 返回排序后的集合元素
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
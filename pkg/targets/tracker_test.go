@@ -0,0 +1,145 @@
+package targets
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yezihack/k8snet-checker/pkg/cache"
+	"github.com/yezihack/k8snet-checker/pkg/client"
+	"github.com/yezihack/k8snet-checker/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// heartbeat 注册一个客户端，第 i 个客户端的Pod IP为 10.0.0.i
+func heartbeat(t *testing.T, clientManager client.ClientManager, i int) {
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{
+		PodName: fmt.Sprintf("pod-%d", i),
+		NodeIP:  fmt.Sprintf("192.168.1.%d", i),
+		PodIP:   fmt.Sprintf("10.0.0.%d", i),
+	}))
+}
+
+// TestLookupDelta 测试列表不变时版本不变，变化后返回自旧版本以来的增量
+func TestLookupDelta(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	tracker := NewTracker(clientManager, cacheManager)
+	heartbeat(t, clientManager, 1)
+	heartbeat(t, clientManager, 2)
+
+	first, err := tracker.Lookup(models.TestTypePod, "")
+	require.NoError(t, err)
+	assert.False(t, first.Delta)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, models.TargetIPs(first.Targets))
+	assert.Equal(t, 2, first.Count)
+
+	// 重复心跳递增全局版本号，但列表内容不变
+	heartbeat(t, clientManager, 1)
+	again, err := tracker.Lookup(models.TestTypePod, first.ETag)
+	require.NoError(t, err)
+	assert.Equal(t, first.ETag, again.ETag)
+	assert.True(t, again.NotModified(first.ETag))
+
+	// 新增与移除客户端后返回合并的增量
+	heartbeat(t, clientManager, 3)
+	_, err = tracker.Lookup(models.TestTypePod, "")
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.DeleteClient("pod-1"))
+	heartbeat(t, clientManager, 4)
+
+	delta, err := tracker.Lookup(models.TestTypePod, first.ETag)
+	require.NoError(t, err)
+	assert.True(t, delta.Delta)
+	assert.False(t, delta.NotModified(first.ETag))
+	assert.Greater(t, delta.Version, first.Version)
+	assert.Equal(t, []string{"10.0.0.3", "10.0.0.4"}, models.TargetIPs(delta.Added))
+	assert.Equal(t, "pod-3", delta.Added[0].PodName)
+	assert.Equal(t, []string{"10.0.0.1"}, delta.Removed)
+	assert.Equal(t, 3, delta.Count)
+
+	// 宿主机列表独立维护版本，Pod列表的版本标识不适用
+	hosts, err := tracker.Lookup(models.TestTypeHost, first.ETag)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.2", "192.168.1.3", "192.168.1.4"}, models.TargetIPs(hosts.Targets))
+
+	_, err = tracker.Lookup(models.TestTypeService, "")
+	assert.Error(t, err)
+}
+
+// TestLookupFullList 测试无法计算增量时返回完整列表
+func TestLookupFullList(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	tracker := NewTracker(clientManager, cacheManager).(*trackerImpl)
+	tracker.maxChanges = 2
+	heartbeat(t, clientManager, 1)
+
+	first, err := tracker.Lookup(models.TestTypePod, "")
+	require.NoError(t, err)
+
+	// 其他服务器实例产生的版本标识
+	other := NewTracker(clientManager, cacheManager).(*trackerImpl)
+	other.epoch = "other"
+	foreign, err := other.Lookup(models.TestTypePod, "")
+	require.NoError(t, err)
+	list, err := tracker.Lookup(models.TestTypePod, foreign.ETag)
+	require.NoError(t, err)
+	assert.False(t, list.Delta)
+	assert.Equal(t, []string{"10.0.0.1"}, models.TargetIPs(list.Targets))
+
+	// 变更记录超过上限后，最早的版本只能获取完整列表
+	for i := 2; i <= 4; i++ {
+		heartbeat(t, clientManager, i)
+		_, err = tracker.Lookup(models.TestTypePod, "")
+		require.NoError(t, err)
+	}
+	list, err = tracker.Lookup(models.TestTypePod, first.ETag)
+	require.NoError(t, err)
+	assert.False(t, list.Delta)
+	assert.Len(t, list.Targets, 4)
+}
+
+// TestLookupTargetChanged 测试目标IP不变而属性变化时产生新版本，增量中包含更新后的目标
+func TestLookupTargetChanged(t *testing.T) {
+	cacheManager := cache.NewCacheManager()
+	clientManager := client.NewClientManager(cacheManager)
+	tracker := NewTracker(clientManager, cacheManager)
+	heartbeat(t, clientManager, 1)
+
+	first, err := tracker.Lookup(models.TestTypePod, "")
+	require.NoError(t, err)
+
+	require.NoError(t, clientManager.HandleHeartbeat(&models.NodeInfo{
+		PodName: "pod-1", NodeIP: "192.168.1.1", PodIP: "10.0.0.1", NodeName: "node-1", Labels: map[string]string{"app": "checker"},
+	}))
+	delta, err := tracker.Lookup(models.TestTypePod, first.ETag)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ETag, delta.ETag)
+	require.Len(t, delta.Added, 1)
+	assert.Equal(t, "node-1", delta.Added[0].NodeName)
+	assert.Equal(t, map[string]string{"app": "checker"}, delta.Added[0].Labels)
+	assert.Empty(t, delta.Removed)
+}
+
+// TestFromClients 测试宿主机目标按节点去重，Pod目标携带Pod与节点身份
+func TestFromClients(t *testing.T) {
+	records := map[string]*models.ClientRecord{
+		"pod-b": {NodeInfo: models.NodeInfo{PodName: "pod-b", PodIP: "10.0.0.2", NodeIP: "192.168.1.1", NodeName: "node-1", Zone: "zone-a"}},
+		"pod-a": {NodeInfo: models.NodeInfo{PodName: "pod-a", PodIP: "10.0.0.1", NodeIP: "192.168.1.1", NodeName: "node-1", Zone: "zone-a", Labels: map[string]string{"app": "checker"}}},
+		"pod-c": {NodeInfo: models.NodeInfo{PodName: "pod-c", PodIP: "10.0.0.3", NodeIP: "192.168.1.2", NodeName: "node-2"}},
+	}
+
+	assert.Equal(t, []models.Target{
+		{IP: "192.168.1.1", NodeName: "node-1", NodeIP: "192.168.1.1", Zone: "zone-a"},
+		{IP: "192.168.1.2", NodeName: "node-2", NodeIP: "192.168.1.2"},
+	}, FromClients(records, models.TestTypeHost))
+
+	pods := FromClients(records, models.TestTypePod)
+	require.Len(t, pods, 3)
+	assert.Equal(t, models.Target{
+		IP: "10.0.0.1", PodName: "pod-a", NodeName: "node-1", NodeIP: "192.168.1.1", Zone: "zone-a", Labels: map[string]string{"app": "checker"},
+	}, pods[0])
+	assert.Equal(t, "pod-c", pods[2].PodName)
+}
@@ -0,0 +1,235 @@
+// Package tlsutil 提供客户端与服务器之间 TLS/双向TLS 所需的证书加载工具
+// 证书、私钥和CA文件变更后会自动重新加载，无需重启进程
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval 默认检查证书文件是否变更的最小间隔
+const DefaultCheckInterval = 10 * time.Second
+
+// Reloader 从文件加载证书与CA证书池
+// 每次获取时按检查间隔比较文件修改时间，文件变更后重新加载；重新加载失败时继续使用旧证书
+type Reloader interface {
+	// Certificate 返回当前证书，未配置证书文件时返回nil
+	Certificate() *tls.Certificate
+
+	// CAPool 返回当前CA证书池，未配置CA文件时返回nil
+	CAPool() *x509.CertPool
+}
+
+// fileState 记录文件的修改时间与大小，用于判断文件是否变更
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileReloader 是Reloader的实现
+type fileReloader struct {
+	certFile      string
+	keyFile       string
+	caFile        string
+	checkInterval time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	states    map[string]fileState
+	lastCheck time.Time
+}
+
+// NewReloader 创建证书加载器并立即加载一次
+// certFile 与 keyFile 需同时配置或同时为空；checkInterval <= 0 时使用 DefaultCheckInterval
+func NewReloader(certFile, keyFile, caFile string, checkInterval time.Duration) (Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("证书文件与私钥文件需同时配置")
+	}
+	if certFile == "" && caFile == "" {
+		return nil, fmt.Errorf("未配置证书文件或CA文件")
+	}
+	if checkInterval <= 0 {
+		checkInterval = DefaultCheckInterval
+	}
+
+	r := &fileReloader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		caFile:        caFile,
+		checkInterval: checkInterval,
+		now:           time.Now,
+	}
+	states, err := r.statFiles()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(states); err != nil {
+		return nil, err
+	}
+	r.lastCheck = r.now()
+	return r, nil
+}
+
+// Certificate 返回当前证书
+func (r *fileReloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloadIfChanged()
+	return r.cert
+}
+
+// CAPool 返回当前CA证书池
+func (r *fileReloader) CAPool() *x509.CertPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloadIfChanged()
+	return r.pool
+}
+
+// reloadIfChanged 距上次检查超过检查间隔且文件有变更时重新加载，调用方需持有锁
+func (r *fileReloader) reloadIfChanged() {
+	now := r.now()
+	if now.Sub(r.lastCheck) < r.checkInterval {
+		return
+	}
+	r.lastCheck = now
+
+	states, err := r.statFiles()
+	if err != nil {
+		log.Printf("检查证书文件失败，继续使用当前证书: %v", err)
+		return
+	}
+	changed := false
+	for path, state := range states {
+		if r.states[path] != state {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// 证书与私钥可能不是同时写入的，失败时保留旧状态，下次检查时重试
+	if err := r.load(states); err != nil {
+		log.Printf("重新加载证书失败，继续使用当前证书: %v", err)
+		return
+	}
+	log.Printf("证书文件已变更，重新加载完成: cert=%s, ca=%s", r.certFile, r.caFile)
+}
+
+// statFiles 获取所有已配置文件的状态
+func (r *fileReloader) statFiles() (map[string]fileState, error) {
+	states := make(map[string]fileState)
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取证书文件状态失败: %w", err)
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states, nil
+}
+
+// load 加载证书与CA证书池，成功后记录文件状态
+func (r *fileReloader) load(states map[string]fileState) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("加载证书失败: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("读取CA文件失败: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("CA文件中没有有效的证书: %s", r.caFile)
+		}
+	}
+
+	r.cert = cert
+	r.pool = pool
+	r.states = states
+	return nil
+}
+
+// ServerConfig 创建服务器端TLS配置，每次握手时获取最新的证书与CA
+// 配置了CA时校验客户端提供的证书，是否必须提供证书由上层按接口决定，
+// 以便浏览器访问仪表盘等查询接口时不需要客户端证书
+func ServerConfig(r Reloader) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return nil, fmt.Errorf("服务器未配置证书")
+			}
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return nil, fmt.Errorf("服务器未配置证书")
+			}
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+			}
+			if pool := r.CAPool(); pool != nil {
+				config.ClientCAs = pool
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig 使用当前证书与CA创建客户端TLS配置
+// 未配置CA时使用系统根证书；serverName 为空时由调用方按连接地址填写
+func ClientConfig(r Reloader, serverName string) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    r.CAPool(),
+		ServerName: serverName,
+	}
+	if cert := r.Certificate(); cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	return config
+}
+
+// Identities 返回证书的身份标识，包括 Subject CN 和 DNS 类型的 SAN
+func Identities(cert *x509.Certificate) []string {
+	var identities []string
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return append(identities, cert.DNSNames...)
+}
+
+// HasIdentity 判断证书的 CN 或 SAN 是否与给定名称一致
+func HasIdentity(cert *x509.Certificate, name string) bool {
+	for _, identity := range Identities(cert) {
+		if identity == name {
+			return true
+		}
+	}
+	return false
+}
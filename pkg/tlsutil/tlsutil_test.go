@@ -0,0 +1,211 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA 测试用的内存CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCA 生成自签名CA
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue 签发证书，返回PEM格式的证书与私钥
+func (ca *testCA) issue(t *testing.T, commonName string, dnsNames []string, ips []net.IP) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile 写入文件并设置修改时间
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// TestNewReloaderValidation 测试配置校验
+func TestNewReloaderValidation(t *testing.T) {
+	_, err := NewReloader("cert.pem", "", "", 0)
+	assert.Error(t, err)
+
+	_, err = NewReloader("", "", "", 0)
+	assert.Error(t, err)
+
+	_, err = NewReloader("", "", filepath.Join(t.TempDir(), "missing.pem"), 0)
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, []byte("not a certificate"), time.Now())
+	_, err = NewReloader("", "", caFile, 0)
+	assert.Error(t, err)
+}
+
+// TestReloaderReloadsOnChange 测试文件变更后重新加载，加载失败时保留旧证书
+func TestReloaderReloadsOnChange(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	modTime := time.Now().Add(-time.Minute)
+	certPEM, keyPEM := ca.issue(t, "pod-1", nil, nil)
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+	writeFile(t, caFile, ca.pem, modTime)
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, time.Second)
+	require.NoError(t, err)
+	impl := reloader.(*fileReloader)
+	now := time.Now()
+	impl.now = func() time.Time { return now }
+
+	first := reloader.Certificate()
+	require.NotNil(t, first)
+	assert.NotNil(t, reloader.CAPool())
+	assert.Equal(t, "pod-1", first.Leaf.Subject.CommonName)
+
+	// 写入新证书，检查间隔内不重新加载
+	certPEM, keyPEM = ca.issue(t, "pod-2", nil, nil)
+	writeFile(t, certFile, certPEM, modTime.Add(time.Second))
+	writeFile(t, keyFile, keyPEM, modTime.Add(time.Second))
+	assert.Same(t, first, reloader.Certificate())
+
+	now = now.Add(2 * time.Second)
+	second := reloader.Certificate()
+	assert.Equal(t, "pod-2", second.Leaf.Subject.CommonName)
+
+	// 私钥与证书不匹配时继续使用旧证书
+	certPEM, _ = ca.issue(t, "pod-3", nil, nil)
+	writeFile(t, certFile, certPEM, modTime.Add(2*time.Second))
+	now = now.Add(2 * time.Second)
+	assert.Same(t, second, reloader.Certificate())
+}
+
+// TestMutualTLS 测试使用生成的证书建立双向TLS连接
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	dir := t.TempDir()
+	modTime := time.Now()
+
+	serverCert, serverKey := ca.issue(t, "k8snet-checker-server", nil, []net.IP{net.ParseIP("127.0.0.1")})
+	writeFile(t, filepath.Join(dir, "server.crt"), serverCert, modTime)
+	writeFile(t, filepath.Join(dir, "server.key"), serverKey, modTime)
+	clientCert, clientKey := ca.issue(t, "pod-1", []string{"pod-1.kube-system"}, nil)
+	writeFile(t, filepath.Join(dir, "client.crt"), clientCert, modTime)
+	writeFile(t, filepath.Join(dir, "client.key"), clientKey, modTime)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem, modTime)
+
+	serverReloader, err := NewReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"), 0)
+	require.NoError(t, err)
+
+	var identities []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) > 0 {
+			identities = Identities(r.TLS.PeerCertificates[0])
+		}
+	}))
+	ts.TLS = ServerConfig(serverReloader)
+	ts.StartTLS()
+	defer ts.Close()
+
+	// 提供客户端证书
+	clientReloader, err := NewReloader(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"), 0)
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(clientReloader, "127.0.0.1")}}
+	resp, err := httpClient.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"pod-1", "pod-1.kube-system"}, identities)
+
+	// 只配置CA的客户端可以连接，但没有客户端身份
+	identities = nil
+	caOnly, err := NewReloader("", "", filepath.Join(dir, "ca.crt"), 0)
+	require.NoError(t, err)
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(caOnly, "127.0.0.1")}}
+	resp, err = httpClient.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Nil(t, identities)
+
+	// 不信任服务器CA的客户端无法连接
+	otherCA := newTestCA(t, "other-ca")
+	writeFile(t, filepath.Join(dir, "other.crt"), otherCA.pem, modTime)
+	untrusted, err := NewReloader("", "", filepath.Join(dir, "other.crt"), 0)
+	require.NoError(t, err)
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(untrusted, "127.0.0.1")}}
+	_, err = httpClient.Get(ts.URL)
+	assert.Error(t, err)
+}
+
+// TestHasIdentity 测试证书身份匹配
+func TestHasIdentity(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "pod-1"},
+		DNSNames: []string{"pod-1.kube-system.pod"},
+	}
+	assert.True(t, HasIdentity(cert, "pod-1"))
+	assert.True(t, HasIdentity(cert, "pod-1.kube-system.pod"))
+	assert.False(t, HasIdentity(cert, "pod-2"))
+}